@@ -283,3 +283,53 @@ TEST_F(CCLTest, EncryptionStats) {
     DBClose(db);
   }
 }
+
+TEST_F(CCLTest, SSTableKeyIDs) {
+  // We need a real directory.
+  TempDirHandler dir;
+
+  // Write a key.
+  ASSERT_OK(WriteAES128KeyFile(rocksdb::Env::Default(), dir.Path("aes-128.key")));
+
+  DBOptions db_opts = defaultDBOptions();
+  DBEngine* db;
+  db_opts.use_file_registry = true;
+
+  cockroach::ccl::baseccl::EncryptionOptions enc_opts;
+  enc_opts.set_key_source(cockroach::ccl::baseccl::KeyFiles);
+  enc_opts.set_data_key_rotation_period(3600);
+  enc_opts.mutable_key_files()->set_current_key(dir.Path("aes-128.key"));
+  enc_opts.mutable_key_files()->set_old_key("plain");
+
+  std::string tmpstr;
+  ASSERT_TRUE(enc_opts.SerializeToString(&tmpstr));
+  db_opts.extra_options = ToDBSlice(tmpstr);
+
+  EXPECT_STREQ(DBOpen(&db, ToDBSlice(dir.Path("")), db_opts).data, NULL);
+
+  // Write a key and force a compaction to produce an sstable.
+  EXPECT_STREQ(DBPut(db, ToDBKey("foo"), ToDBSlice("foo's value")).data, NULL);
+  ASSERT_EQ(DBCompact(db).data, nullptr);
+
+  DBEnvStatsResult stats;
+  EXPECT_STREQ(DBGetEnvStats(db, &stats).data, NULL);
+  enginepbccl::EncryptionStatus enc_status;
+  ASSERT_TRUE(
+      enc_status.ParseFromArray(stats.encryption_status.data, stats.encryption_status.len));
+  const std::string active_key_id = enc_status.active_data_key().key_id();
+  EXPECT_NE(active_key_id, "");
+
+  int n;
+  DBSSTable* tables = DBGetSSTables(db, &n);
+  ASSERT_GT(n, 0);
+  for (int i = 0; i < n; i++) {
+    // All sstables were written after encryption was turned on.
+    EXPECT_EQ(ToString(tables[i].key_id), active_key_id);
+    free(tables[i].start_key.key.data);
+    free(tables[i].end_key.key.data);
+    free(tables[i].key_id.data);
+  }
+  free(tables);
+
+  DBClose(db);
+}
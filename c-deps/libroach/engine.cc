@@ -28,11 +28,11 @@ DBEngine::~DBEngine() {}
 
 DBStatus DBEngine::AssertPreClose() { return kSuccess; }
 
-DBSSTable* DBEngine::GetSSTables(int* n) {
-  std::vector<rocksdb::LiveFileMetaData> metadata;
-  rep->GetLiveFilesMetaData(&metadata);
-  *n = metadata.size();
-  // We malloc the result so it can be deallocated by the caller using free().
+namespace {
+
+// AllocSSTables fills in a malloc'ed array of DBSSTable from the given
+// metadata. The result can be deallocated by the caller using free().
+DBSSTable* AllocSSTables(const std::vector<rocksdb::LiveFileMetaData>& metadata) {
   const int size = metadata.size() * sizeof(DBSSTable);
   DBSSTable* tables = reinterpret_cast<DBSSTable*>(malloc(size));
   memset(tables, 0, size);
@@ -57,6 +57,16 @@ DBSSTable* DBEngine::GetSSTables(int* n) {
   return tables;
 }
 
+}  // namespace
+
+DBSSTable* DBEngine::GetSSTables(int* n) {
+  std::vector<rocksdb::LiveFileMetaData> metadata;
+  rep->GetLiveFilesMetaData(&metadata);
+  *n = metadata.size();
+  // We malloc the result so it can be deallocated by the caller using free().
+  return AllocSSTables(metadata);
+}
+
 DBString DBEngine::GetUserProperties() {
   rocksdb::TablePropertiesCollection props;
   rocksdb::Status status = rep->GetPropertiesOfAllTables(&props);
@@ -243,6 +253,32 @@ DBStatus DBImpl::GetEnvStats(DBEnvStatsResult* stats) {
   return kSuccess;
 }
 
+DBSSTable* DBImpl::GetSSTables(int* n) {
+  std::vector<rocksdb::LiveFileMetaData> metadata;
+  rep->GetLiveFilesMetaData(&metadata);
+  *n = metadata.size();
+  DBSSTable* tables = AllocSSTables(metadata);
+
+  if (env_mgr->env_stats_handler == nullptr || env_mgr->file_registry == nullptr) {
+    // We can't lookup key IDs if we don't have a file registry or stats handler.
+    // This happens in OSS mode or when encryption has not been turned on.
+    return tables;
+  }
+
+  for (int i = 0; i < metadata.size(); i++) {
+    // LiveFileMetaData.name is relative to the db directory (eg: "/000123.sst").
+    auto entry = env_mgr->file_registry->GetFileEntry(metadata[i].name, true /* relative */);
+    std::string key_id;
+    auto status = env_mgr->env_stats_handler->GetFileEntryKeyID(entry.get(), &key_id);
+    if (!status.ok()) {
+      // Leave the key ID empty: we don't know what this file is using.
+      continue;
+    }
+    tables[i].key_id = ToDBString(key_id);
+  }
+  return tables;
+}
+
 // EnvWriteFile writes the given data as a new "file" in the given engine.
 DBStatus DBImpl::EnvWriteFile(DBSlice path, DBSlice contents) {
   rocksdb::Status s;
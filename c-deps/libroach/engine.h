@@ -52,7 +52,7 @@ struct DBEngine {
   virtual DBStatus EnvDeleteDirAndFiles(DBSlice dir) = 0;
   virtual DBStatus EnvLinkFile(DBSlice oldname, DBSlice newname) = 0;
 
-  DBSSTable* GetSSTables(int* n);
+  virtual DBSSTable* GetSSTables(int* n);
   DBString GetUserProperties();
 };
 
@@ -96,6 +96,7 @@ struct DBImpl : public DBEngine {
   virtual DBStatus EnvDeleteFile(DBSlice path);
   virtual DBStatus EnvDeleteDirAndFiles(DBSlice dir);
   virtual DBStatus EnvLinkFile(DBSlice oldname, DBSlice newname);
+  virtual DBSSTable* GetSSTables(int* n);
 };
 
 }  // namespace cockroach
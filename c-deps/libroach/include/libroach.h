@@ -355,11 +355,15 @@ typedef struct {
   uint64_t size;
   DBKey start_key;
   DBKey end_key;
+  // ID of the data key used to encrypt this sstable, or "plain" if the
+  // sstable is not encrypted. Empty when the file registry is not in use
+  // or the encryption stats handler is not available (OSS mode).
+  DBString key_id;
 } DBSSTable;
 
 // Retrieve stats about all of the live sstables. Note that the tables
-// array must be freed along with the start_key and end_key of each
-// table.
+// array must be freed along with the start_key, end_key and key_id of
+// each table.
 DBSSTable* DBGetSSTables(DBEngine* db, int* n);
 
 // DBGetUserProperties fetches the user properties stored in each sstable's
@@ -1101,6 +1101,18 @@ func (b *backupResumer) Resume(
 		// implementations.
 		log.Warningf(ctx, "unable to load backup checkpoint while resuming job %d: %v", *job.ID(), err)
 	}
+	// Prevent the data being backed up from being garbage collected while the
+	// backup runs. Incremental backups (and backups with revision history)
+	// read all the revisions since the start time.
+	protectTS := backupDesc.StartTime
+	if protectTS == (hlc.Timestamp{}) {
+		protectTS = backupDesc.EndTime
+	}
+	if len(backupDesc.Spans) > 0 {
+		if err := job.ProtectTimestamp(ctx, protectTS, backupDesc.Spans); err != nil {
+			return err
+		}
+	}
 	res, err := backup(
 		ctx,
 		p.ExecCfg().DB,
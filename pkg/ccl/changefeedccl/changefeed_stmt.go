@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -308,12 +309,35 @@ func (b *changefeedResumer) Resume(
 	execCfg := planHookState.(sql.PlanHookState).ExecCfg()
 	details := job.Details().(jobspb.ChangefeedDetails)
 	progress := job.Progress()
+
+	// Prevent the changes which have yet to be emitted from being garbage
+	// collected if the changefeed falls behind. The protected timestamp is
+	// advanced along with the job's high-water.
+	protectTS := execCfg.Clock.Now()
+	if h := progress.GetHighWater(); h != nil && *h != (hlc.Timestamp{}) {
+		protectTS = *h
+	}
+	if err := job.ProtectTimestamp(ctx, protectTS, targetSpans(details.Targets)); err != nil {
+		return err
+	}
+
 	err := runChangefeedFlow(ctx, execCfg, details, progress, startedCh, job.HighWaterProgressed)
 	if err != nil {
 		log.Infof(ctx, `CHANGEFEED job %d returning with error: %+v`, *job.ID(), err)
 	}
 	return err
 }
+
+// targetSpans returns the spans of the tables watched by a changefeed.
+func targetSpans(targets map[sqlbase.ID]string) []roachpb.Span {
+	spans := make([]roachpb.Span, 0, len(targets))
+	for tableID := range targets {
+		prefix := roachpb.Key(keys.MakeTablePrefix(uint32(tableID)))
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	return spans
+}
+
 func (b *changefeedResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error { return nil }
 func (b *changefeedResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error      { return nil }
 func (b *changefeedResumer) OnTerminal(
@@ -9,17 +9,44 @@
 package cliccl
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/cliccl/cliflagsccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
 	"github.com/cockroachdb/cockroach/pkg/cli"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 )
 
-// This does not define new commands, only adds the encryption flag to debug commands in
-// `pkg/cli/debug.go` and registers a callback to generate encryption options.
+// This adds the encryption flag to debug commands in `pkg/cli/debug.go`,
+// registers a callback to generate encryption options, and defines the
+// enterprise-only `debug encryption-status` command.
+
+var debugEncryptionStatusCmd = &cobra.Command{
+	Use:   "encryption-status <directory>",
+	Short: "show encryption-at-rest key usage for a store",
+	Long: `
+Shows the active store and data keys for an encrypted store, along with the
+number of sstables and bytes encrypted with each data key. After a key
+rotation, sstables using older data keys are gradually re-encrypted in the
+background.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: cli.MaybeDecorateGRPCError(runDebugEncryptionStatus),
+}
 
 func init() {
-	for _, cmd := range cli.DebugCmdsForRocksDB {
+	cli.AddDebugCmd(debugEncryptionStatusCmd)
+
+	for _, cmd := range append(cli.DebugCmdsForRocksDB, debugEncryptionStatusCmd) {
 		// storeEncryptionSpecs is in start.go.
 		cli.VarFlag(cmd.Flags(), &storeEncryptionSpecs, cliflagsccl.EnterpriseEncryption)
 	}
@@ -41,3 +68,52 @@ func fillEncryptionOptionsForStore(cfg *engine.RocksDBConfig) error {
 	}
 	return nil
 }
+
+func runDebugEncryptionStatus(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := cli.OpenExistingStore(args[0], stopper, true /* readOnly */)
+	if err != nil {
+		return err
+	}
+
+	stats, err := db.GetEnvStats()
+	if err != nil {
+		return err
+	}
+	if len(stats.EncryptionStatus) == 0 {
+		return errors.New("store is not using encryption-at-rest")
+	}
+	var status enginepbccl.EncryptionStatus
+	if err := protoutil.Unmarshal(stats.EncryptionStatus, &status); err != nil {
+		return errors.Wrap(err, "while decoding encryption status")
+	}
+
+	printKey := func(name string, info *enginepbccl.KeyInfo) {
+		if info == nil {
+			fmt.Printf("%s: none\n", name)
+			return
+		}
+		fmt.Printf("%s: %s (%s, source: %s)\n", name, info.KeyId, info.EncryptionType, info.Source)
+	}
+	printKey("active store key", status.ActiveStoreKey)
+	printKey("active data key", status.ActiveDataKey)
+
+	sstables := db.GetSSTables()
+	var totalBytes int64
+	for _, t := range sstables {
+		totalBytes += t.Size
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 2, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "data key\tsstables\tbytes\t% of bytes")
+	for _, u := range sstables.ByDataKey() {
+		var pct float64
+		if totalBytes > 0 {
+			pct = 100 * float64(u.Bytes) / float64(totalBytes)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\n", u.KeyID, u.Files, humanizeutil.IBytes(u.Bytes), pct)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// plainKeyID is the key ID used by libroach for files that are not encrypted.
+const plainKeyID = "plain"
+
+var reencryptionRate = settings.RegisterByteSizeSetting(
+	"rocksdb.encryption.reencryption_rate",
+	"maximum number of bytes of sstables using a rotated-out data key that are "+
+		"rewritten per minute; 0 disables background re-encryption",
+	64<<20, // 64 MB
+)
+
+// reencryptionInterval is the period at which sstables are examined for
+// re-encryption. The reencryption_rate setting is the budget per interval.
+const reencryptionInterval = time.Minute
+
+func init() {
+	storage.EngineMaintenanceHook = startReencryptor
+}
+
+// reencryptionEngine is the subset of *engine.RocksDB used by the
+// re-encryptor.
+type reencryptionEngine interface {
+	GetSSTables() engine.SSTableInfos
+	GetEnvStats() (*engine.EnvStats, error)
+	CompactRange(start, end roachpb.Key, forceBottommost bool) error
+}
+
+// reencryptor periodically rewrites sstables that are encrypted with a data
+// key other than the active one so that, after a key rotation, old data keys
+// eventually stop protecting any data.
+type reencryptor struct {
+	st  *cluster.Settings
+	eng reencryptionEngine
+}
+
+func startReencryptor(
+	ctx context.Context, st *cluster.Settings, eng *engine.RocksDB, stopper *stop.Stopper,
+) {
+	r := &reencryptor{st: st, eng: eng}
+	ctx = log.WithLogTagStr(ctx, "reencryptor", "")
+
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(reencryptionInterval)
+		for {
+			select {
+			case <-stopper.ShouldStop():
+				return
+			case <-timer.C:
+				timer.Read = true
+				if _, err := r.reencrypt(ctx); err != nil {
+					log.Warningf(ctx, "failed to re-encrypt sstables: %s", err)
+				}
+				timer.Reset(reencryptionInterval)
+			}
+		}
+	})
+}
+
+// activeDataKeyID returns the ID of the active data key, or the empty string
+// if encryption-at-rest is not in use for the engine.
+func (r *reencryptor) activeDataKeyID() (string, error) {
+	stats, err := r.eng.GetEnvStats()
+	if err != nil {
+		return "", err
+	}
+	if len(stats.EncryptionStatus) == 0 {
+		return "", nil
+	}
+	var status enginepbccl.EncryptionStatus
+	if err := protoutil.Unmarshal(stats.EncryptionStatus, &status); err != nil {
+		return "", err
+	}
+	if status.ActiveDataKey == nil {
+		return plainKeyID, nil
+	}
+	return status.ActiveDataKey.KeyId, nil
+}
+
+// reencrypt compacts the key spans of sstables that use a data key other than
+// the active one, up to the configured per-interval budget. It returns the
+// number of sstable bytes that were targeted.
+func (r *reencryptor) reencrypt(ctx context.Context) (int64, error) {
+	budget := reencryptionRate.Get(&r.st.SV)
+	if budget <= 0 {
+		return 0, nil
+	}
+	active, err := r.activeDataKeyID()
+	if err != nil || active == "" {
+		return 0, err
+	}
+
+	var done []engine.SSTableInfo
+	var processed int64
+	for _, t := range r.eng.GetSSTables() {
+		if processed >= budget {
+			break
+		}
+		if t.KeyID == "" || t.KeyID == active {
+			continue
+		}
+		// An earlier compaction in this pass may already have rewritten this
+		// sstable.
+		covered := false
+		for _, d := range done {
+			if !t.Start.Less(d.Start) && !d.End.Less(t.End) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		log.VEventf(ctx, 2, "re-encrypting %s of sstables in [%s, %s] using key %s",
+			humanizeutil.IBytes(t.Size), t.Start, t.End, t.KeyID)
+		if err := r.eng.CompactRange(t.Start.Key, t.End.Key.Next(), true /* forceBottommost */); err != nil {
+			return processed, err
+		}
+		done = append(done, t)
+		processed += t.Size
+	}
+	if processed > 0 {
+		log.Infof(ctx, "re-encrypted %s of sstables with data key %s",
+			humanizeutil.IBytes(processed), active)
+	}
+	return processed, nil
+}
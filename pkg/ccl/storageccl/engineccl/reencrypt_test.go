@@ -0,0 +1,146 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+type fakeReencryptionEngine struct {
+	status    *enginepbccl.EncryptionStatus
+	sstables  engine.SSTableInfos
+	compacted [][2]string
+}
+
+func (f *fakeReencryptionEngine) GetSSTables() engine.SSTableInfos {
+	return f.sstables
+}
+
+func (f *fakeReencryptionEngine) GetEnvStats() (*engine.EnvStats, error) {
+	stats := &engine.EnvStats{}
+	if f.status != nil {
+		var err error
+		if stats.EncryptionStatus, err = protoutil.Marshal(f.status); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func (f *fakeReencryptionEngine) CompactRange(start, end roachpb.Key, _ bool) error {
+	f.compacted = append(f.compacted, [2]string{string(start), string(end)})
+	return nil
+}
+
+func TestReencrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sst := func(start, end, keyID string, size int64) engine.SSTableInfo {
+		return engine.SSTableInfo{
+			Size:  size,
+			Start: engine.MakeMVCCMetadataKey(roachpb.Key(start)),
+			End:   engine.MakeMVCCMetadataKey(roachpb.Key(end)),
+			KeyID: keyID,
+		}
+	}
+	active := &enginepbccl.EncryptionStatus{
+		ActiveDataKey: &enginepbccl.KeyInfo{KeyId: "new"},
+	}
+
+	testCases := []struct {
+		name      string
+		status    *enginepbccl.EncryptionStatus
+		rate      int64
+		sstables  engine.SSTableInfos
+		expected  [][2]string
+		processed int64
+	}{
+		{
+			name:     "no encryption",
+			rate:     100,
+			sstables: engine.SSTableInfos{sst("a", "b", "", 10)},
+		},
+		{
+			name:     "disabled",
+			status:   active,
+			sstables: engine.SSTableInfos{sst("a", "b", "old", 10)},
+		},
+		{
+			name:   "rotated",
+			status: active,
+			rate:   100,
+			sstables: engine.SSTableInfos{
+				sst("a", "b", "old", 10),
+				sst("c", "d", "new", 10),
+				sst("e", "f", "plain", 10),
+			},
+			expected:  [][2]string{{"a", "b\x00"}, {"e", "f\x00"}},
+			processed: 20,
+		},
+		{
+			name:   "budget",
+			status: active,
+			rate:   15,
+			sstables: engine.SSTableInfos{
+				sst("a", "b", "old", 10),
+				sst("c", "d", "old", 10),
+				sst("e", "f", "old", 10),
+			},
+			expected:  [][2]string{{"a", "b\x00"}, {"c", "d\x00"}},
+			processed: 20,
+		},
+		{
+			name:   "covered",
+			status: active,
+			rate:   100,
+			sstables: engine.SSTableInfos{
+				sst("a", "z", "old", 10),
+				sst("c", "d", "old", 10),
+			},
+			expected:  [][2]string{{"a", "z\x00"}},
+			processed: 10,
+		},
+		{
+			name:      "plain active",
+			status:    &enginepbccl.EncryptionStatus{ActiveStoreKey: &enginepbccl.KeyInfo{KeyId: "plain"}},
+			rate:      100,
+			sstables:  engine.SSTableInfos{sst("a", "b", "old", 10), sst("c", "d", "plain", 10)},
+			expected:  [][2]string{{"a", "b\x00"}},
+			processed: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := cluster.MakeTestingClusterSettings()
+			reencryptionRate.Override(&st.SV, tc.rate)
+			eng := &fakeReencryptionEngine{status: tc.status, sstables: tc.sstables}
+			r := &reencryptor{st: st, eng: eng}
+			processed, err := r.reencrypt(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if processed != tc.processed {
+				t.Errorf("expected %d bytes processed, got %d", tc.processed, processed)
+			}
+			if !reflect.DeepEqual(tc.expected, eng.compacted) {
+				t.Errorf("expected compactions %q, got %q", tc.expected, eng.compacted)
+			}
+		})
+	}
+}
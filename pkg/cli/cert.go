@@ -15,12 +15,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -297,6 +300,77 @@ func runListCerts(cmd *cobra.Command, args []string) error {
 	return printQueryOutput(os.Stdout, certTableHeaders, newRowSliceIter(rows, alignment))
 }
 
+// A statusCertsCmd command reports the status of the certificates of the
+// nodes of a running cluster.
+var statusCertsCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the status of the node certificates",
+	Long: `
+Show the expiration of the certificate of each node of the cluster, whether
+the node holds the key of the built-in certificate authority and the status
+of the automatic renewal of the certificate.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runStatusCerts),
+}
+
+var statusCertsColumnHeaders = []string{
+	"node_id",
+	"ca_key",
+	"node_cert_expires",
+	"next_renewal",
+	"renewal_error",
+}
+
+const certStatusTimeFormat = "2006/01/02 15:04:05"
+
+// runStatusCerts retrieves the certificates of all the nodes.
+func runStatusCerts(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, _, finish, err := getClientGRPCConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	status := serverpb.NewStatusClient(conn)
+	nodes, err := status.Nodes(ctx, &serverpb.NodesRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve the nodes from server")
+	}
+
+	var rows [][]string
+	for _, node := range nodes.Nodes {
+		nodeID := node.Desc.NodeID
+		resp, err := status.Certificates(ctx, &serverpb.CertificatesRequest{NodeId: nodeID.String()})
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: node %d: %s\n", nodeID, err)
+			continue
+		}
+
+		var expires, nextRenewal string
+		for _, cert := range resp.Certificates {
+			if cert.Type == serverpb.CertificateDetails_NODE && len(cert.Fields) > 0 {
+				expires = time.Unix(cert.Fields[0].ValidUntil, 0).UTC().Format(certStatusTimeFormat)
+			}
+		}
+		if resp.NextRenewal != 0 {
+			nextRenewal = time.Unix(resp.NextRenewal, 0).UTC().Format(certStatusTimeFormat)
+		}
+		rows = append(rows, []string{
+			nodeID.String(),
+			strconv.FormatBool(resp.CAKey),
+			expires,
+			nextRenewal,
+			resp.RenewalError,
+		})
+	}
+
+	return printQueryOutput(os.Stdout, statusCertsColumnHeaders, newRowSliceIter(rows, "rllll"))
+}
+
 var certCmds = []*cobra.Command{
 	createCACertCmd,
 	createClientCACertCmd,
@@ -315,4 +389,7 @@ var certCmd = &cobra.Command{
 
 func init() {
 	certCmd.AddCommand(certCmds...)
+	// The status command connects to a node, its flags are registered with
+	// the other client commands.
+	certCmd.AddCommand(statusCertsCmd)
 }
@@ -1941,6 +1941,32 @@ func TestNodeStatus(t *testing.T) {
 	checkNodeStatus(t, c, out, start)
 }
 
+func TestCertStatus(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{})
+	defer c.cleanup()
+
+	out, err := c.RunWithCapture("cert status --format=tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm, err := c.Cfg.GetCertificateManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server doesn't hold the CA key and renewal is disabled.
+	expires := cm.NodeCert().ExpirationTime.UTC().Format(certStatusTimeFormat)
+	for _, e := range []string{
+		"node_id\tca_key\tnode_cert_expires\tnext_renewal\trenewal_error\n",
+		fmt.Sprintf("1\tfalse\t%s\t\t\n", expires),
+	} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected %q in the output, got:\n%s", e, out)
+		}
+	}
+}
+
 func checkNodeStatus(t *testing.T, c cliTest, output string, start time.Time) {
 	buf := bytes.NewBufferString(output)
 	s := bufio.NewScanner(buf)
@@ -2261,6 +2287,10 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/20
   debug/nodes/1/ranges/21
   debug/nodes/1/ranges/22
+  debug/nodes/1/ranges/23
+  debug/nodes/1/ranges/24
+  debug/nodes/1/ranges/25
+  debug/nodes/1/ranges/26
   debug/reports/problemranges
   debug/schema/defaultdb@details
   debug/schema/postgres@details
@@ -2271,11 +2301,15 @@ writing ` + os.DevNull + `
   debug/schema/system/lease
   debug/schema/system/locations
   debug/schema/system/namespace
+  debug/schema/system/protected_ts
   debug/schema/system/rangelog
   debug/schema/system/role_members
   debug/schema/system/settings
+  debug/schema/system/statement_statistics
   debug/schema/system/table_statistics
+  debug/schema/system/transaction_statistics
   debug/schema/system/ui
+  debug/schema/system/user_login_status
   debug/schema/system/users
   debug/schema/system/web_sessions
   debug/schema/system/zones
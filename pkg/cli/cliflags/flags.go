@@ -172,6 +172,22 @@ If non-empty, create a SQL audit log in this drectory.
 `,
 	}
 
+	SQLAuditLogFileMaxSize = FlagInfo{
+		Name: "sql-audit-log-file-max-size",
+		Description: `
+Maximum size of each SQL audit log file. Defaults to the value of
+--log-file-max-size.
+`,
+	}
+
+	SQLAuditLogDirMaxSize = FlagInfo{
+		Name: "sql-audit-log-dir-max-size",
+		Description: `
+Maximum combined size of all the SQL audit log files. Older files are removed
+when it is exceeded. Defaults to the value of --log-dir-max-size.
+`,
+	}
+
 	SQLTempStorage = FlagInfo{
 		Name: "max-disk-temp-storage",
 		Description: `
@@ -494,6 +510,17 @@ disabled by setting the environment variable COCKROACH_SKIP_KEY_PERMISSION_CHECK
 		Description: `Path to the CA key.`,
 	}
 
+	ServerCAKey = FlagInfo{
+		Name: "ca-key",
+		Description: `
+Path to the key of the CA certificate (ca.crt in the certificates directory).
+When specified, the key is imported into the first store, which is encrypted
+if encryption at rest is enabled, and the node signs the certificates of the
+nodes renewing them with the built-in certificate authority. The key file can
+be removed once the node has started. See the server.internal_ca.enabled
+cluster setting.`,
+	}
+
 	// TODO(tschottdorf): once clockless mode becomes non-experimental, explain it here:
 	// <PRE>
 	//
@@ -700,6 +727,32 @@ If specified, print the system config contents. Beware that the output will be
 long and not particularly human-readable.`,
 	}
 
+	TimeSeriesDumpFormat = FlagInfo{
+		Name: "format",
+		Description: `
+The format of the output. "text" (default) prints the values in a human-readable
+form. "raw" writes a binary file which can be loaded into a demo cluster with
+cockroach demo --tsdump.`,
+	}
+
+	DemoTimeSeriesDump = FlagInfo{
+		Name: "tsdump",
+		Description: `
+Load the time series from a file written by cockroach debug tsdump --format=raw
+into the demo cluster, so that they can be browsed in the Admin UI. The demo
+cluster does not record its own time series when this flag is set.`,
+	}
+
+	ZipRedact = FlagInfo{
+		Name: "redact",
+		Description: `
+Remove user data from the zip file, so that it can be shared with third
+parties. The keys of ranges are hashed, the constants in SQL statements are
+hidden, the user data in log messages is removed and the values of string
+cluster settings are removed. Log messages are removed entirely unless the nodes
+run with --redactable-logs.`,
+	}
+
 	Decommission = FlagInfo{
 		Name: "decommission",
 		Description: `
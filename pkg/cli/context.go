@@ -96,6 +96,12 @@ func initCLIDefaults() {
 	debugCtx.maxResults = 1000
 	debugCtx.ballastSize = base.SizeSpec{}
 
+	zipCtx.redact = false
+
+	tsDumpCtx.format = tsDumpText
+
+	demoCtx.tsDumpFile = ""
+
 	zoneCtx.zoneConfig = ""
 	zoneCtx.zoneDisableReplication = false
 
@@ -104,6 +110,7 @@ func initCLIDefaults() {
 	serverCfg.PIDFile = ""
 	startCtx.serverInsecure = baseCfg.Insecure
 	startCtx.serverSSLCertsDir = base.DefaultCertsDirectory
+	startCtx.serverSSLCAKey = ""
 	startCtx.serverListenAddr = ""
 	startCtx.tempDir = ""
 	startCtx.externalIODir = ""
@@ -205,6 +212,28 @@ var debugCtx struct {
 	maxResults        int64
 }
 
+// tsDumpCtx captures the command-line parameters of the `debug tsdump`
+// command.
+// Defaults set by InitCLIDefaults() above.
+var tsDumpCtx struct {
+	// format is the format of the dumped time series.
+	format tsDumpFormat
+}
+
+// demoCtx captures the command-line parameters of the `demo` command.
+// Defaults set by InitCLIDefaults() above.
+var demoCtx struct {
+	// tsDumpFile is a raw time series dump to load into the demo cluster.
+	tsDumpFile string
+}
+
+// zipCtx captures the command-line parameters of the `debug zip` command.
+// Defaults set by InitCLIDefaults() above.
+var zipCtx struct {
+	// redact removes the user data from the zip file.
+	redact bool
+}
+
 // zoneCtx captures the command-line parameters of the `zone` command.
 // Defaults set by InitCLIDefaults() above.
 var zoneCtx struct {
@@ -218,6 +247,7 @@ var startCtx struct {
 	// server-specific values of some flags.
 	serverInsecure    bool
 	serverSSLCertsDir string
+	serverSSLCAKey    string
 	serverListenAddr  string
 
 	// temporary directory to use to spill computation results to disk.
@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -97,7 +98,9 @@ func parseRangeID(arg string) (roachpb.RangeID, error) {
 	return roachpb.RangeID(rangeIDInt), nil
 }
 
-func openExistingStore(dir string, stopper *stop.Stopper, readOnly bool) (*engine.RocksDB, error) {
+// OpenExistingStore opens the rocksdb engine rooted at 'dir'.
+// If 'readOnly' is true, opens the store in read-only mode.
+func OpenExistingStore(dir string, stopper *stop.Stopper, readOnly bool) (*engine.RocksDB, error) {
 	cache := engine.NewRocksDBCache(server.DefaultCacheSize)
 	defer cache.Release()
 	maxOpenFiles, err := server.SetOpenFileLimitForOneStore()
@@ -168,7 +171,7 @@ func runDebugKeys(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -251,7 +254,7 @@ func runDebugRangeData(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -505,7 +508,7 @@ func runDebugRangeDescriptors(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -607,7 +610,7 @@ func runDebugRaftLog(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -651,7 +654,7 @@ func runDebugGCCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -696,6 +699,7 @@ func runDebugGCCmd(cmd *cobra.Command, args []string) error {
 			snap,
 			hlc.Timestamp{WallTime: timeutil.Now().UnixNano()},
 			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */},
+			hlc.Timestamp{}, /* maxThreshold */
 			storage.NoopGCer{},
 			func(_ context.Context, _ []roachpb.Intent) error { return nil },
 			func(_ context.Context, _ *roachpb.Transaction, _ []roachpb.Intent) error { return nil },
@@ -737,7 +741,7 @@ func runDebugCheckStoreCmd(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -944,7 +948,7 @@ func runDebugCompact(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, false /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, false /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -1003,7 +1007,7 @@ func runDebugSSTables(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, true /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -1122,11 +1126,84 @@ func parseGossipValues(gossipInfo *gossip.InfoStatus) (string, error) {
 	return strings.Join(output, "\n"), nil
 }
 
+var debugHotRangesCmd = &cobra.Command{
+	Use:   "hot-ranges",
+	Short: "show the ranges with the highest load",
+	Long: `
+Shows the ranges with the highest queries per second and write throughput on
+each store of the cluster, or of a single node if --node is specified.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDebugHotRanges),
+}
+
+var hotRangesOpts struct {
+	nodeID string
+	limit  int
+}
+
+var hotRangesColumnHeaders = []string{
+	"node_id",
+	"store_id",
+	"range_id",
+	"queries_per_second",
+	"write_bytes_per_second",
+	"database",
+	"table",
+	"index",
+	"start_key",
+	"end_key",
+}
+
+func runDebugHotRanges(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, _, finish, err := getClientGRPCConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	status := serverpb.NewStatusClient(conn)
+	resp, err := status.HotRanges(ctx, &serverpb.HotRangesRequest{
+		NodeID: hotRangesOpts.nodeID,
+		Limit:  int32(hotRangesOpts.limit),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve hot ranges from server")
+	}
+
+	for _, e := range resp.Errors {
+		fmt.Fprintf(stderr, "warning: node %d: %s\n", e.NodeID, e.Message)
+	}
+	rows := make([][]string, len(resp.Ranges))
+	for i, r := range resp.Ranges {
+		rows[i] = []string{
+			strconv.FormatInt(int64(r.NodeID), 10),
+			strconv.FormatInt(int64(r.StoreID), 10),
+			strconv.FormatInt(int64(r.RangeID), 10),
+			strconv.FormatFloat(r.QueriesPerSecond, 'f', 2, 64),
+			strconv.FormatFloat(r.WriteBytesPerSecond, 'f', 2, 64),
+			r.DatabaseName,
+			r.TableName,
+			r.IndexName,
+			r.StartKey,
+			r.EndKey,
+		}
+	}
+	return printQueryOutput(os.Stdout, hotRangesColumnHeaders, newRowSliceIter(rows, "rrrrrlllll"))
+}
+
 var debugTimeSeriesDumpCmd = &cobra.Command{
 	Use:   "tsdump",
 	Short: "dump all the raw timeseries values in a cluster",
 	Long: `
 Dumps all of the raw timeseries values in a cluster.
+
+With --format=raw, the values are written in a binary format which can be
+loaded into a demo cluster with cockroach demo --tsdump, for example to browse
+them in the Admin UI.
 `,
 	RunE: MaybeDecorateGRPCError(runTimeSeriesDump),
 }
@@ -1147,6 +1224,7 @@ func runTimeSeriesDump(cmd *cobra.Command, args []string) error {
 		log.Fatal(context.Background(), err)
 	}
 
+	w := bufio.NewWriter(os.Stdout)
 	var name, source string
 	for {
 		data, err := stream.Recv()
@@ -1154,18 +1232,71 @@ func runTimeSeriesDump(cmd *cobra.Command, args []string) error {
 			if err != io.EOF {
 				return err
 			}
-			return nil
+			return w.Flush()
+		}
+		if tsDumpCtx.format == tsDumpRaw {
+			if err := writeRawTimeSeriesData(w, data); err != nil {
+				return err
+			}
+			continue
 		}
 		if name != data.Name || source != data.Source {
 			name, source = data.Name, data.Source
-			fmt.Printf("%s %s\n", name, source)
+			fmt.Fprintf(w, "%s %s\n", name, source)
 		}
 		for _, d := range data.Datapoints {
-			fmt.Printf("%d %v\n", d.TimestampNanos, d.Value)
+			fmt.Fprintf(w, "%d %v\n", d.TimestampNanos, d.Value)
 		}
 	}
 }
 
+// writeRawTimeSeriesData writes data to w in the raw tsdump format: each
+// tspb.TimeSeriesData is encoded as its length, as a uvarint, followed by its
+// marshaled bytes.
+func writeRawTimeSeriesData(w io.Writer, data *tspb.TimeSeriesData) error {
+	bytes, err := protoutil.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(bytes)))
+	if _, err := w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(bytes)
+	return err
+}
+
+// maxRawTimeSeriesDataSize is the maximum size of a tspb.TimeSeriesData in
+// the raw tsdump format. A year of 10s samples of a series is about 64 MiB.
+const maxRawTimeSeriesDataSize = 256 << 20 // 256 MiB
+
+// readRawTimeSeriesData reads the next tspb.TimeSeriesData written by
+// writeRawTimeSeriesData from r. It returns io.EOF once r is exhausted.
+func readRawTimeSeriesData(r *bufio.Reader) (*tspb.TimeSeriesData, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRawTimeSeriesDataSize {
+		return nil, errors.Errorf("corrupt time series dump: %d bytes of data exceed the maximum of %d",
+			size, maxRawTimeSeriesDataSize)
+	}
+	bytes := make([]byte, size)
+	if _, err := io.ReadFull(r, bytes); err != nil {
+		if err == io.EOF {
+			// The data is missing altogether after its size.
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	data := &tspb.TimeSeriesData{}
+	if err := protoutil.Unmarshal(bytes, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 var debugSyncTestCmd = &cobra.Command{
 	Use:   "synctest [directory]",
 	Short: "Run a performance test for WAL sync speed",
@@ -1230,7 +1361,7 @@ func runDebugUnsafeRemoveDeadReplicas(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	db, err := openExistingStore(args[0], stopper, false /* readOnly */)
+	db, err := OpenExistingStore(args[0], stopper, false /* readOnly */)
 	if err != nil {
 		return err
 	}
@@ -1373,6 +1504,12 @@ func init() {
 	f = debugUnsafeRemoveDeadReplicasCmd.Flags()
 	f.IntSliceVar(&removeDeadReplicasOpts.deadStoreIDs, "dead-store-ids", nil,
 		"list of dead store IDs")
+
+	f = debugHotRangesCmd.Flags()
+	f.StringVar(&hotRangesOpts.nodeID, "node", "",
+		"only show the hot ranges of this node")
+	f.IntVar(&hotRangesOpts.limit, "limit", 0,
+		"number of ranges to show per store for each of QPS and write throughput")
 }
 
 // DebugCmdsForRocksDB lists debug commands that access rocksdb.
@@ -1387,12 +1524,19 @@ var DebugCmdsForRocksDB = []*cobra.Command{
 	debugSSTablesCmd,
 }
 
+// AddDebugCmd adds a subcommand to `cockroach debug`. It is used by CCL code
+// to register enterprise-only debug commands.
+func AddDebugCmd(c *cobra.Command) {
+	debugCmd.AddCommand(c)
+}
+
 // All other debug commands go here.
 var debugCmds = append(DebugCmdsForRocksDB,
 	debugBallastCmd,
 	debugDecodeKeyCmd,
 	debugRocksDBCmd,
 	debugGossipValuesCmd,
+	debugHotRangesCmd,
 	debugTimeSeriesDumpCmd,
 	debugSyncTestCmd,
 	debugUnsafeRemoveDeadReplicasCmd,
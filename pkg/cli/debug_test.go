@@ -16,9 +16,15 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -31,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 )
@@ -78,7 +85,7 @@ func TestOpenExistingStore(t *testing.T) {
 		},
 	} {
 		t.Run(fmt.Sprintf("dir=%s", test.dir), func(t *testing.T) {
-			_, err := openExistingStore(test.dir, stopper, false /* readOnly */)
+			_, err := OpenExistingStore(test.dir, stopper, false /* readOnly */)
 			if !testutils.IsError(err, test.expErr) {
 				t.Errorf("wanted %s but got %v", test.expErr, err)
 			}
@@ -111,7 +118,7 @@ func TestOpenReadOnlyStore(t *testing.T) {
 		},
 	} {
 		t.Run(fmt.Sprintf("readOnly=%t", test.readOnly), func(t *testing.T) {
-			db, err := openExistingStore(storePath, stopper, test.readOnly)
+			db, err := OpenExistingStore(storePath, stopper, test.readOnly)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -194,7 +201,7 @@ func TestRemoveDeadReplicas(t *testing.T) {
 		stopper := stop.NewStopper()
 		defer stopper.Stop(ctx)
 
-		db, err := openExistingStore(storePath, stopper, false /* readOnly */)
+		db, err := OpenExistingStore(storePath, stopper, false /* readOnly */)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -250,3 +257,72 @@ func TestRemoveDeadReplicas(t *testing.T) {
 		t.Fatalf("expected old setting to be present, got %s instead", org)
 	}
 }
+
+func TestRawTimeSeriesDumpRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	expected := []tspb.TimeSeriesData{
+		{
+			Name:   "cr.node.sql.conns",
+			Source: "1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{TimestampNanos: 10, Value: 1},
+				{TimestampNanos: 20, Value: 2},
+			},
+		},
+		{
+			Name:   "cr.store.replicas",
+			Source: "2",
+		},
+	}
+
+	var buf bytes.Buffer
+	for i := range expected {
+		if err := writeRawTimeSeriesData(&buf, &expected[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := bufio.NewReader(&buf)
+	var actual []tspb.TimeSeriesData
+	for {
+		data, err := readRawTimeSeriesData(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual = append(actual, *data)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRawTimeSeriesDumpCorrupt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var valid bytes.Buffer
+	if err := writeRawTimeSeriesData(&valid, &tspb.TimeSeriesData{Name: "cr.node.sql.conns"}); err != nil {
+		t.Fatal(err)
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	testCases := []struct {
+		name     string
+		dump     []byte
+		expected string
+	}{
+		{"size too large", lenBuf[:binary.PutUvarint(lenBuf[:], math.MaxUint64)], "corrupt time series dump"},
+		{"missing data", valid.Bytes()[:1], "unexpected EOF"},
+		{"truncated data", valid.Bytes()[:valid.Len()-1], "unexpected EOF"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := readRawTimeSeriesData(bufio.NewReader(bytes.NewReader(tc.dump)))
+			if !testutils.IsError(err, tc.expected) {
+				t.Fatalf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
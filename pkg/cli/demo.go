@@ -15,15 +15,24 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 )
 
 var demoCmd = &cobra.Command{
@@ -33,9 +42,10 @@ var demoCmd = &cobra.Command{
 Start an in-memory, standalone, single-node CockroachDB instance, and open an
 interactive SQL prompt to it.
 `,
-	Example: `  cockroach demo`,
-	Args:    cobra.NoArgs,
-	RunE:    MaybeDecorateGRPCError(runDemo),
+	Example: `  cockroach demo
+  cockroach demo --tsdump=tsdump.raw`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDemo),
 }
 
 func setupTransientServer() (connURL string, adminURL string, cleanup func(), err error) {
@@ -47,9 +57,7 @@ func setupTransientServer() (connURL string, adminURL string, cleanup func(), er
 	}
 	cleanup = func() { stopper.Stop(ctx) }
 
-	args := base.TestServerArgs{
-		Insecure: true,
-	}
+	args := demoServerArgs()
 	server := server.TestServerFactory.New(args).(*server.TestServer)
 	if err := server.Start(args); err != nil {
 		return connURL, adminURL, cleanup, err
@@ -57,6 +65,12 @@ func setupTransientServer() (connURL string, adminURL string, cleanup func(), er
 	prevCleanup := cleanup
 	cleanup = func() { prevCleanup(); server.Stopper().Stop(ctx) }
 
+	if demoCtx.tsDumpFile != "" {
+		if err := loadTimeSeriesDump(ctx, server, demoCtx.tsDumpFile); err != nil {
+			return connURL, adminURL, cleanup, err
+		}
+	}
+
 	options := url.Values{}
 	options.Add("sslmode", "disable")
 	options.Add("application_name", "cockroach demo")
@@ -70,6 +84,73 @@ func setupTransientServer() (connURL string, adminURL string, cleanup func(), er
 	return url.String(), server.AdminURL(), cleanup, nil
 }
 
+// tsImportBatchSize is the number of series written at a time when loading a
+// time series dump.
+const tsImportBatchSize = 100
+
+// demoTimeSeriesTTL is the maximum age of the time series of the demo server
+// when a time series dump is loaded. It is large enough for the datapoints of
+// the dump not to be rolled up or deleted, however old they are.
+const demoTimeSeriesTTL = 100 * 365 * 24 * time.Hour
+
+// demoServerArgs returns the parameters of the demo server. When a time series
+// dump is loaded, the server doesn't record its own time series, so that they
+// don't get mixed with the loaded ones, and the time series TTL is raised from
+// the start.
+func demoServerArgs() base.TestServerArgs {
+	args := base.TestServerArgs{
+		Insecure: true,
+	}
+	if demoCtx.tsDumpFile != "" {
+		args.DisableTimeSeriesRecording = true
+		args.Settings = cluster.MakeClusterSettings(cluster.BinaryMinimumSupportedVersion, cluster.BinaryServerVersion)
+		ts.Resolution10sStorageTTL.Override(&args.Settings.SV, demoTimeSeriesTTL)
+	}
+	return args
+}
+
+// loadTimeSeriesDump writes the time series from a file produced by debug
+// tsdump --format=raw into the demo server. The raised time series TTL is
+// persisted first, so that it isn't reset to its default when the settings
+// are refreshed.
+func loadTimeSeriesDump(ctx context.Context, s *server.TestServer, path string) error {
+	if _, err := s.InternalExecutor().(*sql.InternalExecutor).Exec(
+		ctx, "set-timeseries-ttl", nil, /* txn */
+		fmt.Sprintf("SET CLUSTER SETTING timeseries.storage.10s_resolution_ttl = '%s'", demoTimeSeriesTTL.String()),
+	); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var batch []tspb.TimeSeriesData
+	for {
+		data, err := readRawTimeSeriesData(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read time series dump %s", path)
+		}
+		batch = append(batch, *data)
+		if len(batch) == tsImportBatchSize {
+			if err := s.TsDB().StoreData(ctx, ts.Resolution10s, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		return s.TsDB().StoreData(ctx, ts.Resolution10s, batch)
+	}
+	return nil
+}
+
 func runDemo(cmd *cobra.Command, _ []string) error {
 	connURL, adminURL, cleanup, err := setupTransientServer()
 	defer cleanup()
@@ -0,0 +1,110 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestDemoLoadTimeSeriesDump(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	// The datapoints are older than the default time series TTL.
+	start := timeutil.Now().Add(-60 * 24 * time.Hour).Truncate(10 * time.Second)
+	dump := []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{TimestampNanos: start.UnixNano(), Value: 1},
+				{TimestampNanos: start.Add(10 * time.Second).UnixNano(), Value: 2},
+			},
+		},
+		{
+			Name:   "test.metric",
+			Source: "2",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{TimestampNanos: start.UnixNano(), Value: 3},
+				{TimestampNanos: start.Add(10 * time.Second).UnixNano(), Value: 4},
+			},
+		},
+	}
+	path := filepath.Join(dir, "tsdump.raw")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	for i := range dump {
+		if err := writeRawTimeSeriesData(w, &dump[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	demoCtx.tsDumpFile = path
+	defer func() { demoCtx.tsDumpFile = "" }()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, demoServerArgs())
+	defer s.Stopper().Stop(ctx)
+	if err := loadTimeSeriesDump(ctx, s.(*server.TestServer), path); err != nil {
+		t.Fatal(err)
+	}
+
+	r := sqlutils.MakeSQLRunner(db)
+	rows := r.QueryStr(t, `
+SELECT extract(epoch FROM timestamp)::INT - $1, source, value
+  FROM crdb_internal.timeseries('test.metric', $2::TIMESTAMPTZ, $3::TIMESTAMPTZ)`,
+		start.Unix(), start, start.Add(time.Minute),
+	)
+	if e := [][]string{
+		{"0", "1", "1"},
+		{"10", "1", "2"},
+		{"0", "2", "3"},
+		{"10", "2", "4"},
+	}; !reflect.DeepEqual(e, rows) {
+		t.Errorf("expected %v, got %v", e, rows)
+	}
+
+	// The server doesn't record its own time series.
+	r.CheckQueryResults(t, `
+SELECT count(*)
+  FROM crdb_internal.timeseries('cr.node.sql.conns', now() - '1h'::INTERVAL, now())`,
+		[][]string{{"0"}},
+	)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 )
@@ -255,6 +256,7 @@ func init() {
 		// Certificates directory. Use a server-specific flag and value to ignore environment
 		// variables, but share the same default.
 		StringFlag(f, &startCtx.serverSSLCertsDir, cliflags.ServerCertsDir, startCtx.serverSSLCertsDir)
+		StringFlag(f, &startCtx.serverSSLCAKey, cliflags.ServerCAKey, startCtx.serverSSLCAKey)
 
 		// Cluster joining flags.
 		VarFlag(f, &serverCfg.JoinList, cliflags.Join)
@@ -270,6 +272,8 @@ func init() {
 		StringFlag(f, &startCtx.externalIODir, cliflags.ExternalIODir, startCtx.externalIODir)
 
 		VarFlag(f, serverCfg.SQLAuditLogDirName, cliflags.SQLAuditLogDirName)
+		VarFlag(f, humanizeutil.NewBytesValue(&serverCfg.SQLAuditLogFileMaxSize), cliflags.SQLAuditLogFileMaxSize)
+		VarFlag(f, humanizeutil.NewBytesValue(&serverCfg.SQLAuditLogDirMaxSize), cliflags.SQLAuditLogDirMaxSize)
 	}
 
 	for _, cmd := range certCmds {
@@ -314,7 +318,7 @@ func init() {
 	clientCmds = append(clientCmds, userCmds...)
 	clientCmds = append(clientCmds, zoneCmds...)
 	clientCmds = append(clientCmds, nodeCmds...)
-	clientCmds = append(clientCmds, initCmd)
+	clientCmds = append(clientCmds, initCmd, statusCertsCmd)
 	for _, cmd := range clientCmds {
 		f := cmd.PersistentFlags()
 		StringFlag(f, &clientConnHost, cliflags.ClientHost, clientConnHost)
@@ -387,7 +391,7 @@ func init() {
 	}
 
 	// Commands that print tables.
-	tableOutputCommands := []*cobra.Command{sqlShellCmd, genSettingsListCmd, demoCmd}
+	tableOutputCommands := []*cobra.Command{sqlShellCmd, genSettingsListCmd, demoCmd, statusCertsCmd}
 	tableOutputCommands = append(tableOutputCommands, userCmds...)
 	tableOutputCommands = append(tableOutputCommands, nodeCmds...)
 
@@ -431,6 +435,16 @@ func init() {
 		f := debugBallastCmd.Flags()
 		VarFlag(f, &debugCtx.ballastSize, cliflags.Size)
 	}
+	{
+		f := debugZipCmd.Flags()
+		BoolFlag(f, &zipCtx.redact, cliflags.ZipRedact, zipCtx.redact)
+	}
+	{
+		f := debugTimeSeriesDumpCmd.Flags()
+		VarFlag(f, &tsDumpCtx.format, cliflags.TimeSeriesDumpFormat)
+	}
+
+	StringFlag(demoCmd.Flags(), &demoCtx.tsDumpFile, cliflags.DemoTimeSeriesDump, demoCtx.tsDumpFile)
 }
 
 func extraServerFlagInit() {
@@ -92,6 +92,40 @@ func (m *dumpMode) Set(s string) error {
 	return nil
 }
 
+type tsDumpFormat int
+
+const (
+	tsDumpText tsDumpFormat = iota
+	tsDumpRaw
+)
+
+// Type implements the pflag.Value interface.
+func (m *tsDumpFormat) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (m *tsDumpFormat) String() string {
+	switch *m {
+	case tsDumpText:
+		return "text"
+	case tsDumpRaw:
+		return "raw"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (m *tsDumpFormat) Set(s string) error {
+	switch s {
+	case "text":
+		*m = tsDumpText
+	case "raw":
+		*m = tsDumpRaw
+	default:
+		return fmt.Errorf("invalid value for --format: %s", s)
+	}
+	return nil
+}
+
 type mvccKey engine.MVCCKey
 
 // Type implements the pflag.Value interface.
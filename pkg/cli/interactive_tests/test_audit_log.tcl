@@ -55,6 +55,27 @@ eexpect root@
 system "grep 'helloworld.*:READWRITE.*ALTER TABLE.*SET OFF.*OK' $logfile"
 end_test
 
+start_test "Check that the statements of the members of audited roles are logged"
+send "SET CLUSTER SETTING sql.audit.roles = 'admin';\r"
+eexpect root@
+send "SELECT 'audited by role';\r"
+eexpect root@
+system "grep -q 'role:admin.*audited by role.*\"Outcome\":\"OK\"' $logfile"
+send "SET CLUSTER SETTING sql.audit.roles = '';\r"
+eexpect root@
+end_test
+
+start_test "Check that the statements of audited classes are logged"
+send "SET CLUSTER SETTING sql.audit.statement_classes = 'ddl';\r"
+eexpect root@
+send "CREATE TABLE classaudit(x INT); SELECT 'not audited';\r"
+eexpect root@
+system "grep -q 'class:ddl.*CREATE TABLE classaudit.*\"Outcome\":\"OK\"' $logfile"
+system "if grep -q 'not audited' $logfile; then false; fi"
+send "SET CLUSTER SETTING sql.audit.statement_classes = '';\r"
+eexpect root@
+end_test
+
 interrupt
 eexpect eof
 
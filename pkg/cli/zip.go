@@ -37,12 +37,20 @@ var debugZipCmd = &cobra.Command{
 	Long: `
 
 Gather cluster debug data into a zip file. Data includes cluster events, node
-liveness, node status, range status, node stack traces, log files, and SQL
-schema.
+liveness, node status, range status, node stack traces, log files, profiles
+captured by the continuous profiler, and SQL schema.
 
 Retrieval of per-node details (status, stack traces, range status) requires the
 node to be live and operating properly. Retrieval of SQL data requires the
 cluster to be live.
+
+With --redact, user data is removed from the zip file so that it can be shared
+with third parties: the keys of ranges are hashed, the constants in SQL
+statements are hidden, the user data in log messages is removed (log messages
+are removed entirely unless the nodes run with --redactable-logs), the values
+of string cluster settings, the details of events other than their statements
+and the command lines and environments of the nodes are removed, and the
+gossip dumps, the node metrics and the profiles are left out.
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: MaybeDecorateGRPCError(runDebugZip),
@@ -139,6 +147,16 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 	z := newZipper(out)
 	defer z.close()
 
+	var redactor *zipRedactor
+	if zipCtx.redact {
+		if redactor, err = newZipRedactor(); err != nil {
+			return err
+		}
+	}
+	omit := func(name string) bool {
+		return redactor != nil && redactor.omitFile(name)
+	}
+
 	timeoutCtx := func(baseCtx context.Context) (context.Context, func()) {
 		timeout := 10 * time.Second
 		if cliCtx.cmdTimeout != 0 {
@@ -155,6 +173,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 				return err
 			}
 		} else {
+			if redactor != nil {
+				redactor.redactEvents(events)
+			}
 			if err := z.createJSON(eventsName, events); err != nil {
 				return err
 			}
@@ -183,6 +204,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 				return err
 			}
 		} else {
+			if redactor != nil {
+				redactor.redactSettings(settings)
+			}
 			if err := z.createJSON(settingsName, settings); err != nil {
 				return err
 			}
@@ -197,11 +221,15 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 		if err := dumpTableDataForZip(z, sqlConn, queryLiveness, gossipLName); err != nil {
 			return err
 		}
-		if err := dumpTableDataForZip(z, sqlConn, queryNodes, gossipNName); err != nil {
-			return err
+		if !omit(gossipNName) {
+			if err := dumpTableDataForZip(z, sqlConn, queryNodes, gossipNName); err != nil {
+				return err
+			}
 		}
-		if err := dumpTableDataForZip(z, sqlConn, queryMetrics, metricsName); err != nil {
-			return err
+		if !omit(metricsName) {
+			if err := dumpTableDataForZip(z, sqlConn, queryMetrics, metricsName); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -216,11 +244,14 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 			for _, node := range nodes.Nodes {
 				id := fmt.Sprintf("%d", node.Desc.NodeID)
 				prefix := fmt.Sprintf("%s/%s", nodesPrefix, id)
+				if redactor != nil {
+					redactor.redactNodeStatus(&node)
+				}
 				if err := z.createJSON(prefix+"/status", node); err != nil {
 					return err
 				}
 
-				{
+				if !omit(prefix + "/gossip") {
 					ctx, cancel := timeoutCtx(baseCtx)
 					defer cancel()
 					if gossip, err := status.Gossip(ctx, &serverpb.GossipRequest{NodeId: id}); err != nil {
@@ -259,6 +290,36 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 					}
 				}
 
+				if !omit(prefix + "/profiles") {
+					ctx, cancel := timeoutCtx(baseCtx)
+					defer cancel()
+					if profiles, err := status.ProfileFiles(
+						ctx, &serverpb.ProfileFilesRequest{NodeId: id, ListOnly: true}); err != nil {
+						if err := z.createError(prefix+"/profiles", err); err != nil {
+							return err
+						}
+					} else {
+						for _, file := range profiles.Files {
+							name := prefix + "/profiles/" + file.Name
+							ctx, cancel := timeoutCtx(baseCtx)
+							defer cancel()
+							profile, err := status.ProfileFiles(
+								ctx, &serverpb.ProfileFilesRequest{NodeId: id, Name: file.Name})
+							if err != nil {
+								if err := z.createError(name, err); err != nil {
+									return err
+								}
+								continue
+							}
+							for _, f := range profile.Files {
+								if err := z.createRaw(name, f.Contents); err != nil {
+									return err
+								}
+							}
+						}
+					}
+				}
+
 				{
 					ctx, cancel := timeoutCtx(baseCtx)
 					defer cancel()
@@ -285,6 +346,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 								return err
 							}
 							for _, e := range entries.Entries {
+								if redactor != nil {
+									redactor.redactLogEntry(&e)
+								}
 								if err := e.Format(logOut); err != nil {
 									return err
 								}
@@ -307,6 +371,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 						})
 						for _, r := range ranges.Ranges {
 							name := fmt.Sprintf("%s/ranges/%s", prefix, r.State.Desc.RangeID)
+							if redactor != nil {
+								redactor.redactRangeInfo(&r)
+							}
 							if err := z.createJSON(name, r); err != nil {
 								return err
 							}
@@ -365,6 +432,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 						}
 						continue
 					}
+					if redactor != nil {
+						redactor.redactTableDetails(table)
+					}
 					if err := z.createJSON(name, table); err != nil {
 						return err
 					}
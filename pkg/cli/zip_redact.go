@@ -0,0 +1,266 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// zipRedactor removes the user data from the debug data collected by
+// `debug zip --redact`, so that the zip file can be shared with third
+// parties:
+//
+//   - The keys in range descriptors are replaced by the prefix of the table
+//     and index they belong to, followed by a keyed hash of the rest of the
+//     key. The key of the hash is random and not stored in the zip file, so
+//     the hashes can't be reversed by guessing the keys, but equal keys still
+//     have equal hashes in the same zip file so that the bounds of adjacent
+//     ranges can be matched up. The same goes for the keys addressed by meta
+//     keys.
+//   - The constants in SQL statements and expressions are replaced by
+//     placeholders (see tree.FmtHideConstants).
+//   - The details of events are removed, except for their statements, which
+//     are redacted, and the names and IDs of the objects they refer to.
+//   - The command lines and environments of the nodes are removed.
+//   - The redactable parts of the messages of log entries, including the
+//     values of their tags, are removed. The messages of entries which aren't
+//     redactable, because the node didn't run with --redactable-logs, are
+//     removed entirely.
+//   - The values of string cluster settings, which may contain URLs, license
+//     keys and the like, are removed.
+//   - The files which can't be redacted are left out (see omitFile).
+type zipRedactor struct {
+	hashKey []byte
+}
+
+func newZipRedactor() (*zipRedactor, error) {
+	r := &zipRedactor{hashKey: make([]byte, 32)}
+	if _, err := rand.Read(r.hashKey); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// redactedHashLen is the number of bytes of the hash that replaces the
+// redacted part of a key.
+const redactedHashLen = 8
+
+// redactKey returns a copy of the key with its user data replaced by a
+// hash. The keys outside of the table data don't contain user data and are
+// returned as is, and so are the table and index prefixes of table keys.
+// Meta keys are suffixed by the key they address, which is redacted in turn.
+func (r *zipRedactor) redactKey(key roachpb.RKey) roachpb.RKey {
+	if !key.Less(roachpb.RKey(keys.MetaMin)) && key.Less(roachpb.RKey(keys.MetaMax)) {
+		// The meta1 and meta2 prefixes have the same length.
+		prefix := key[:len(keys.Meta1Prefix)]
+		return append(append(roachpb.RKey(nil), prefix...), r.redactKey(key[len(prefix):])...)
+	}
+	if key.Less(roachpb.RKey(keys.TableDataMin)) || !key.Less(roachpb.RKey(keys.TableDataMax)) {
+		return key
+	}
+	rest, _, err := keys.DecodeTablePrefix(roachpb.Key(key))
+	if err == nil && len(rest) > 0 {
+		// Keep the index ID as well, if there is one.
+		if indexRest, _, err := encoding.DecodeUvarintAscending(rest); err == nil {
+			rest = indexRest
+		}
+	}
+	if len(rest) == 0 {
+		return key
+	}
+	prefix := key[:len(key)-len(rest)]
+	mac := hmac.New(sha256.New, r.hashKey)
+	_, _ = mac.Write(rest)
+	hash := hex.EncodeToString(mac.Sum(nil)[:redactedHashLen])
+	return encoding.EncodeBytesAscending(append(roachpb.RKey(nil), prefix...), []byte(hash))
+}
+
+// redactRangeInfo redacts the keys of a range. The keys are missing
+// altogether if the node doesn't allow remote debugging.
+func (r *zipRedactor) redactRangeInfo(info *serverpb.RangeInfo) {
+	if desc := info.State.Desc; desc != nil && len(desc.EndKey) > 0 {
+		redacted := *desc
+		redacted.StartKey = r.redactKey(desc.StartKey)
+		redacted.EndKey = r.redactKey(desc.EndKey)
+		info.State.Desc = &redacted
+		info.Span = serverpb.PrettySpan{
+			StartKey: redacted.StartKey.String(),
+			EndKey:   redacted.EndKey.String(),
+		}
+	}
+}
+
+// redactStatement replaces the constants in a SQL statement, or list of
+// statements, by placeholders. Statements which can't be parsed are removed
+// entirely.
+func (r *zipRedactor) redactStatement(sql string) string {
+	stmts, err := parser.Parse(sql)
+	if err != nil {
+		return log.RedactedMarker
+	}
+	redacted := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		redacted[i] = tree.AsStringWithFlags(stmt, tree.FmtHideConstants)
+	}
+	return strings.Join(redacted, "; ")
+}
+
+// redactExpr replaces the constants in a SQL expression by placeholders.
+func (r *zipRedactor) redactExpr(expr string) string {
+	if expr == "" {
+		return ""
+	}
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return log.RedactedMarker
+	}
+	return tree.AsStringWithFlags(e, tree.FmtHideConstants)
+}
+
+// eventInfoSafeKeys are the keys of the details of events whose values are
+// the names of schema objects, users and cluster settings. These don't
+// contain user data; the names of schema objects are found in the schema
+// dumped in the zip file anyway.
+var eventInfoSafeKeys = map[string]bool{
+	"CascadeDroppedViews":  true,
+	"DatabaseName":         true,
+	"DroppedSchemaObjects": true,
+	"IndexName":            true,
+	"SequenceName":         true,
+	"SettingName":          true,
+	"TableName":            true,
+	"Target":               true,
+	"User":                 true,
+	"UserName":             true,
+	"ViewName":             true,
+}
+
+// redactEvents redacts the details of events. The statements are redacted,
+// the names (see eventInfoSafeKeys), numbers and booleans are kept and all the
+// other values, such as the values of cluster settings, zone configs and
+// error messages, are removed.
+func (r *zipRedactor) redactEvents(events *serverpb.EventsResponse) {
+	for i := range events.Events {
+		e := &events.Events[i]
+		if e.Info == "" {
+			continue
+		}
+		var info map[string]interface{}
+		if err := json.Unmarshal([]byte(e.Info), &info); err != nil {
+			e.Info = log.RedactedMarker
+			continue
+		}
+		for k, v := range info {
+			if eventInfoSafeKeys[k] {
+				continue
+			}
+			switch v := v.(type) {
+			case nil, bool, float64:
+			case string:
+				if k == "Statement" {
+					info[k] = r.redactStatement(v)
+				} else {
+					info[k] = log.RedactedMarker
+				}
+			default:
+				info[k] = log.RedactedMarker
+			}
+		}
+		b, err := json.Marshal(info)
+		if err != nil {
+			e.Info = log.RedactedMarker
+			continue
+		}
+		e.Info = string(b)
+	}
+}
+
+// redactNodeStatus removes the command line and the environment of a node,
+// which may contain credentials, from its status.
+func (r *zipRedactor) redactNodeStatus(node *status.NodeStatus) {
+	node.Args = nil
+	node.Env = nil
+}
+
+// redactSettings removes the values of the string settings.
+func (r *zipRedactor) redactSettings(settings *serverpb.SettingsResponse) {
+	for k, v := range settings.KeyValues {
+		if v.Type == "s" && v.Value != "" {
+			v.Value = log.RedactedMarker
+			settings.KeyValues[k] = v
+		}
+	}
+}
+
+// redactTableDetails redacts the constants in the schema of a table, such as
+// the default values of columns.
+func (r *zipRedactor) redactTableDetails(table *serverpb.TableDetailsResponse) {
+	for i := range table.Columns {
+		c := &table.Columns[i]
+		c.DefaultValue = r.redactExpr(c.DefaultValue)
+		c.GenerationExpression = r.redactExpr(c.GenerationExpression)
+	}
+	table.CreateTableStatement = r.redactStatement(table.CreateTableStatement)
+}
+
+// redactLogEntry redacts the message of a log entry.
+func (r *zipRedactor) redactLogEntry(entry *log.Entry) {
+	entry.Redact()
+}
+
+// omittedFiles are the patterns of the names of the files which are left out
+// of the zip file when redacting, because their user data can't be told apart
+// from the rest:
+//
+//   - The gossip dumps contain the system config, with the zone configs and
+//     the default values of columns, and the addresses and localities of the
+//     nodes.
+//   - The profiles captured by the continuous profiler are labelled with the
+//     statements that were running.
+//   - The dumps of crdb_internal.gossip_nodes and crdb_internal.node_metrics
+//     contain the addresses and localities of the nodes and everything they
+//     report about their hosts.
+var omittedFiles = []string{
+	"debug/gossip/nodes",
+	"debug/metrics",
+	"debug/nodes/*/gossip",
+	"debug/nodes/*/profiles",
+}
+
+// omitFile returns whether the file (or directory) with the given name is left
+// out of the zip file.
+func (r *zipRedactor) omitFile(name string) bool {
+	for _, pattern := range omittedFiles {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
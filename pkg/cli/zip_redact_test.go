@@ -0,0 +1,156 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/testutils/datadriven"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// TestZipRedact runs the redaction rules of `debug zip --redact` against the
+// corpus in testdata/zip_redact. File format is from the datadriven package.
+//
+// The commands supported in the data file are:
+//
+// statement: redacts the SQL statements in the input.
+//
+// expr: redacts the SQL expression in the input.
+//
+// key [meta=<level>] [table=<id>] [index=<id>] [vals=(<val>, ...)]: redacts
+// a table key made of the given table ID, index ID and column values, which
+// are encoded as integers or strings. Without a table ID, a node liveness key
+// is used. With a meta level, the meta1 or meta2 key addressing that key is
+// redacted instead. The output is the pretty-printed redacted key. The keys
+// are hashed with an all-zero key.
+//
+// log [redactable]: redacts a log entry with the input as its message.
+//
+// setting type=<type>: redacts a cluster setting of the given type with the
+// input as its value.
+//
+// event: redacts an event with the input as its info.
+//
+// node-status: redacts a node status with the input lines as its command line
+// arguments and environment. The output is the remaining ones.
+//
+// omit: prints whether the file with the input as its name is left out of the
+// zip file.
+func TestZipRedact(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := &zipRedactor{hashKey: make([]byte, 32)}
+	datadriven.RunTest(t, "testdata/zip_redact", func(d *datadriven.TestData) string {
+		switch d.Cmd {
+		case "statement":
+			return r.redactStatement(d.Input) + "\n"
+
+		case "expr":
+			return r.redactExpr(d.Input) + "\n"
+
+		case "key":
+			key := keys.NodeLivenessKey(1)
+			var metaPrefix roachpb.Key
+			for _, arg := range d.CmdArgs {
+				switch arg.Key {
+				case "meta":
+					switch arg.Vals[0] {
+					case "1":
+						metaPrefix = keys.Meta1Prefix
+					case "2":
+						metaPrefix = keys.Meta2Prefix
+					default:
+						d.Fatalf(t, "invalid meta level: %s", arg.Vals[0])
+					}
+				case "table":
+					id, err := strconv.ParseUint(arg.Vals[0], 10, 32)
+					if err != nil {
+						d.Fatalf(t, "%v", err)
+					}
+					key = keys.MakeTablePrefix(uint32(id))
+				case "index":
+					id, err := strconv.ParseUint(arg.Vals[0], 10, 32)
+					if err != nil {
+						d.Fatalf(t, "%v", err)
+					}
+					key = encoding.EncodeUvarintAscending(key, id)
+				case "vals":
+					for _, v := range arg.Vals {
+						if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+							key = encoding.EncodeVarintAscending(key, i)
+						} else {
+							key = encoding.EncodeStringAscending(key, v)
+						}
+					}
+				default:
+					d.Fatalf(t, "unknown argument: %s", arg.Key)
+				}
+			}
+			if metaPrefix != nil {
+				key = append(append(roachpb.Key(nil), metaPrefix...), key...)
+			}
+			return r.redactKey(roachpb.RKey(key)).String() + "\n"
+
+		case "log":
+			entry := log.Entry{Message: d.Input, Redactable: len(d.CmdArgs) > 0}
+			r.redactLogEntry(&entry)
+			return entry.Message + "\n"
+
+		case "setting":
+			var typ string
+			for _, arg := range d.CmdArgs {
+				if arg.Key == "type" {
+					typ = arg.Vals[0]
+				}
+			}
+			settings := &serverpb.SettingsResponse{
+				KeyValues: map[string]serverpb.SettingsResponse_Value{
+					"test": {Value: d.Input, Type: typ},
+				},
+			}
+			r.redactSettings(settings)
+			return settings.KeyValues["test"].Value + "\n"
+
+		case "event":
+			events := &serverpb.EventsResponse{
+				Events: []serverpb.EventsResponse_Event{{Info: d.Input}},
+			}
+			r.redactEvents(events)
+			return events.Events[0].Info + "\n"
+
+		case "node-status":
+			lines := strings.Split(d.Input, "\n")
+			node := &status.NodeStatus{Args: lines, Env: lines}
+			r.redactNodeStatus(node)
+			return fmt.Sprintf("args=%q env=%q\n", node.Args, node.Env)
+
+		case "omit":
+			return fmt.Sprintf("%t\n", r.omitFile(d.Input))
+
+		default:
+			return fmt.Sprintf("unknown command: %s\n", d.Cmd)
+		}
+	})
+}
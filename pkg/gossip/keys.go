@@ -89,6 +89,12 @@ const (
 	// statistic was computed. The statistics themselves are not stored in gossip;
 	// the keys are used to notify nodes to invalidate table statistic caches.
 	KeyTableStatAddedPrefix = "table-stat-added"
+
+	// KeySQLNotificationPrefix is the key prefix for gossiping the
+	// asynchronous notifications sent by the SQL transactions committed on a
+	// node. The suffix is the node ID and a sequence number, and the value
+	// holds the notifications of one transaction.
+	KeySQLNotificationPrefix = "sql-notification"
 )
 
 // MakeKey creates a canonical key under which to gossip a piece of
@@ -169,6 +175,12 @@ func MakeTableStatAddedKey(tableID uint32) string {
 	return MakeKey(KeyTableStatAddedPrefix, strconv.FormatUint(uint64(tableID), 10 /* base */))
 }
 
+// MakeSQLNotificationKey returns the gossip key for the seq'th batch of
+// notifications sent from the given node.
+func MakeSQLNotificationKey(nodeID roachpb.NodeID, seq int64) string {
+	return MakeKey(KeySQLNotificationPrefix, nodeID.String(), strconv.FormatInt(seq, 10 /* base */))
+}
+
 // TableIDFromTableStatAddedKey attempts to extract the table ID from the
 // provided key.
 // The key should have been constructed by MakeTableStatAddedKey.
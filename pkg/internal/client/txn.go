@@ -1209,10 +1209,10 @@ func (txn *Txn) Send(
 	return br, nil
 }
 
-// firstWriteIndex returns the index of the first transactional write in the
-// BatchRequest. Returns -1 if the batch has not intention to write. It also
-// verifies that if an EndTransactionRequest is included, then it is the last
-// request in the batch.
+// firstWriteIndex returns the index of the first transactional write (or
+// locking read) in the BatchRequest. Returns -1 if the batch has not intention
+// to write. It also verifies that if an EndTransactionRequest is included,
+// then it is the last request in the batch.
 func firstWriteIndex(ba roachpb.BatchRequest) (int, *roachpb.Error) {
 	for i, ru := range ba.Requests {
 		args := ru.GetInner()
@@ -1221,7 +1221,10 @@ func firstWriteIndex(ba roachpb.BatchRequest) (int, *roachpb.Error) {
 				return -1, roachpb.NewErrorf("%s sent as non-terminal call", args.Method())
 			}
 		}
-		if roachpb.IsTransactionWrite(args) {
+		// Locking reads are treated like writes: the locks they acquire are
+		// released when the transaction ends, which requires an EndTransaction,
+		// and conflicting transactions need a transaction record to push.
+		if roachpb.IsTransactionWrite(args) || roachpb.IsLocking(args) {
 			return i, nil
 		}
 	}
@@ -23,8 +23,10 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -211,10 +213,11 @@ func (j *Job) FractionDetailProgressed(
 
 // HighWaterProgressed updates the progress of the tracked job. It sets the
 // job's HighWater field to the value returned by progressedFn and persists
-// progressedFn's modifications to the job's progress details, if any.
+// progressedFn's modifications to the job's progress details, if any. The
+// timestamp protected by the job, if any, is advanced to the new high-water.
 func (j *Job) HighWaterProgressed(ctx context.Context, progressedFn HighWaterProgressedFn) error {
 	return j.updateRow(ctx, updateProgressOnly,
-		func(_ *client.Txn, status *Status, payload *jobspb.Payload, progress *jobspb.Progress) (bool, error) {
+		func(txn *client.Txn, status *Status, payload *jobspb.Payload, progress *jobspb.Progress) (bool, error) {
 			if *status != StatusRunning {
 				return false, &InvalidStatusError{*j.id, *status, "update progress on", payload.Error}
 			}
@@ -225,6 +228,11 @@ func (j *Job) HighWaterProgressed(ctx context.Context, progressedFn HighWaterPro
 					highWater, j.id,
 				)
 			}
+			if highWater != (hlc.Timestamp{}) {
+				if err := protectedts.UpdateJob(ctx, j.registry.ex, txn, *j.id, highWater); err != nil {
+					return false, err
+				}
+			}
 			progress.Progress = &jobspb.Progress_HighWater{
 				HighWater: &highWater,
 			}
@@ -291,6 +299,9 @@ func (j *Job) canceled(
 			return false, fmt.Errorf("job with status %s cannot be canceled", *status)
 		}
 		*status = StatusCanceled
+		if err := protectedts.ReleaseJob(ctx, j.registry.ex, txn, *j.id); err != nil {
+			return false, err
+		}
 		if fn != nil {
 			if err := fn(ctx, txn, j); err != nil {
 				return false, err
@@ -315,6 +326,9 @@ func (j *Job) Failed(
 			return false, nil
 		}
 		*status = StatusFailed
+		if err := protectedts.ReleaseJob(ctx, j.registry.ex, txn, *j.id); err != nil {
+			return false, err
+		}
 		if fn != nil {
 			if err := fn(ctx, txn, j); err != nil {
 				return false, err
@@ -337,6 +351,9 @@ func (j *Job) Succeeded(
 			return false, nil
 		}
 		*status = StatusSucceeded
+		if err := protectedts.ReleaseJob(ctx, j.registry.ex, txn, *j.id); err != nil {
+			return false, err
+		}
 		if fn != nil {
 			if err := fn(ctx, txn, j); err != nil {
 				return false, err
@@ -368,6 +385,22 @@ func (j *Job) SetProgress(ctx context.Context, details interface{}) error {
 	)
 }
 
+// ProtectTimestamp prevents the data in the given spans which is visible at
+// ts from being garbage collected until the job finishes, replacing any
+// timestamp previously protected by the job. See package protectedts.
+func (j *Job) ProtectTimestamp(ctx context.Context, ts hlc.Timestamp, spans []roachpb.Span) error {
+	if j.id == nil {
+		return errors.New("Job: cannot protect timestamp: job not created")
+	}
+	return j.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if err := protectedts.ReleaseJob(ctx, j.registry.ex, txn, *j.id); err != nil {
+			return err
+		}
+		_, err := protectedts.Protect(ctx, j.registry.ex, txn, ts, spans, *j.id)
+		return err
+	})
+}
+
 // Payload returns the most recently sent Payload for this Job.
 func (j *Job) Payload() jobspb.Payload {
 	j.mu.Lock()
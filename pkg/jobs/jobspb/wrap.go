@@ -26,6 +26,8 @@ var _ Details = BackupDetails{}
 var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
 var _ Details = ChangefeedDetails{}
+var _ Details = RevertDetails{}
+var _ Details = StatsCleanupDetails{}
 
 // ProgressDetails is a marker interface for job progress details proto structs.
 type ProgressDetails interface{}
@@ -34,6 +36,8 @@ var _ ProgressDetails = BackupProgress{}
 var _ ProgressDetails = RestoreProgress{}
 var _ ProgressDetails = SchemaChangeProgress{}
 var _ ProgressDetails = ChangefeedProgress{}
+var _ ProgressDetails = RevertProgress{}
+var _ ProgressDetails = StatsCleanupProgress{}
 
 // Type returns the payload's job type.
 func (p *Payload) Type() Type {
@@ -53,6 +57,10 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeImport
 	case *Payload_Changefeed:
 		return TypeChangefeed
+	case *Payload_Revert:
+		return TypeRevert
+	case *Payload_StatsCleanup:
+		return TypeStatsCleanup
 	default:
 		panic(fmt.Sprintf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -77,6 +85,10 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_Import{Import: &d}
 	case ChangefeedProgress:
 		return &Progress_Changefeed{Changefeed: &d}
+	case RevertProgress:
+		return &Progress_Revert{Revert: &d}
+	case StatsCleanupProgress:
+		return &Progress_StatsCleanup{StatsCleanup: &d}
 	default:
 		panic(fmt.Sprintf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -96,6 +108,10 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.Import
 	case *Payload_Changefeed:
 		return *d.Changefeed
+	case *Payload_Revert:
+		return *d.Revert
+	case *Payload_StatsCleanup:
+		return *d.StatsCleanup
 	default:
 		return nil
 	}
@@ -115,6 +131,10 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.Import
 	case *Progress_Changefeed:
 		return *d.Changefeed
+	case *Progress_Revert:
+		return *d.Revert
+	case *Progress_StatsCleanup:
+		return *d.StatsCleanup
 	default:
 		return nil
 	}
@@ -147,6 +167,10 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_Import{Import: &d}
 	case ChangefeedDetails:
 		return &Payload_Changefeed{Changefeed: &d}
+	case RevertDetails:
+		return &Payload_Revert{Revert: &d}
+	case StatsCleanupDetails:
+		return &Payload_StatsCleanup{StatsCleanup: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
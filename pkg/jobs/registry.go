@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -206,6 +207,23 @@ func (r *Registry) StartJob(
 	return j, errCh, nil
 }
 
+// CreateAdoptableJobWithTxn creates a running job from record in txn, without
+// starting it. Its lease is empty, so once txn commits the job is started by
+// the first node which looks for jobs to adopt. This allows creating a job
+// only if some condition checked in txn holds.
+func (r *Registry) CreateAdoptableJobWithTxn(
+	ctx context.Context, record Record, txn *client.Txn,
+) (*Job, error) {
+	j := r.NewJob(record).WithTxn(txn)
+	if err := j.insert(ctx, r.makeJobID(), &jobspb.Lease{}); err != nil {
+		return nil, err
+	}
+	if err := j.Started(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
 // NewJob creates a new Job.
 func (r *Registry) NewJob(record Record) *Job {
 	job := &Job{
@@ -254,6 +272,12 @@ var DefaultCancelInterval = base.DefaultHeartbeatInterval
 // Registry.Start has been called will not have any effect.
 var DefaultAdoptInterval = 30 * time.Second
 
+// reclaimInterval is the interval at which each node removes the protected
+// timestamp records of jobs which are no longer running. Records are normally
+// removed when their job finishes; this catches the ones which were left
+// behind, e.g. because the job's record was deleted.
+const reclaimInterval = 10 * time.Minute
+
 // Start polls the current node for liveness failures and cancels all registered
 // jobs if it observes a failure.
 func (r *Registry) Start(
@@ -289,6 +313,33 @@ func (r *Registry) Start(
 			}
 		}
 	})
+
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(reclaimInterval):
+				if err := r.reclaimProtectedTimestamps(ctx); err != nil {
+					log.Warningf(ctx, "error while reclaiming protected timestamps: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// reclaimProtectedTimestamps removes the protected timestamp records owned by
+// jobs which are not pending, running or paused.
+func (r *Registry) reclaimProtectedTimestamps(ctx context.Context) error {
+	liveStatuses := []string{string(StatusPending), string(StatusRunning), string(StatusPaused)}
+	n, err := protectedts.ReclaimStale(ctx, r.ex, nil /* txn */, liveStatuses...)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Infof(ctx, "reclaimed %d stale protected timestamp records", n)
+	}
 	return nil
 }
 
@@ -324,4 +324,8 @@ const (
 	LocationsTableID       = 21
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
+	ProtectedTsTableID     = 24
+	StatementStatsTableID  = 25
+	TxnStatsTableID        = 26
+	UserLoginStatusTableID = 27
 )
@@ -478,7 +478,7 @@ func (ds *DistSender) initAndVerifyBatch(
 		for _, req := range ba.Requests {
 			inner := req.GetInner()
 			switch inner.(type) {
-			case *roachpb.ScanRequest, *roachpb.DeleteRangeRequest:
+			case *roachpb.ScanRequest, *roachpb.DeleteRangeRequest, *roachpb.RevertRangeRequest:
 				// Accepted range requests. All other range requests are still
 				// not supported. Note that ReverseScanRequest is _not_ handled here.
 				// TODO(vivek): don't enumerate all range requests.
@@ -830,10 +830,15 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			}
 			// If the request is more than but ends with EndTransaction, we
 			// want the caller to come again with the EndTransaction in an
-			// extra call.
-			if l := len(ba.Requests) - 1; l > 0 && ba.Requests[l].GetInner().Method() == roachpb.EndTransaction {
-				responseCh <- response{pErr: errNo1PCTxn}
-				return
+			// extra call. The exception is an EndTransaction with in-flight
+			// writes, which is meant to be sent in parallel with the rest of
+			// the batch (see txnCommitter).
+			if l := len(ba.Requests) - 1; l > 0 {
+				et, ok := ba.Requests[l].GetInner().(*roachpb.EndTransactionRequest)
+				if ok && len(et.InFlightWrites) == 0 {
+					responseCh <- response{pErr: errNo1PCTxn}
+					return
+				}
 			}
 		}
 
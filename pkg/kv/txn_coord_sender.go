@@ -137,11 +137,12 @@ type TxnCoordSender struct {
 	// is embedded in the interceptorAlloc struct, so the entire stack is
 	// allocated together with TxnCoordSender without any additional heap
 	// allocations necessary.
-	interceptorStack [3]txnInterceptor
+	interceptorStack [4]txnInterceptor
 	interceptorAlloc struct {
 		txnIntentCollector
 		txnPipeliner
 		txnSpanRefresher
+		txnCommitter
 		txnLockGatekeeper // not in interceptorStack array.
 	}
 
@@ -415,6 +416,12 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		canAutoRetry:     typ == client.RootTxn,
 		autoRetryCounter: tcs.metrics.AutoRetries,
 	}
+	tcs.interceptorAlloc.txnCommitter = txnCommitter{
+		st:      tcf.st,
+		stopper: tcf.stopper,
+		ambient: tcf.AmbientContext,
+		sender:  tcf.wrapped,
+	}
 	tcs.interceptorAlloc.txnLockGatekeeper = txnLockGatekeeper{
 		mu:      &tcs.mu,
 		wrapped: tcs.wrapped,
@@ -423,6 +430,7 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		&tcs.interceptorAlloc.txnIntentCollector,
 		&tcs.interceptorAlloc.txnPipeliner,
 		&tcs.interceptorAlloc.txnSpanRefresher,
+		&tcs.interceptorAlloc.txnCommitter,
 	}
 	for i, reqInt := range tcs.interceptorStack {
 		if i < len(tcs.interceptorStack)-1 {
@@ -900,6 +908,12 @@ func (tc *TxnCoordSender) heartbeat(ctx context.Context) bool {
 		respTxn = br.Responses[0].GetInner().(*roachpb.HeartbeatTxnResponse).Txn
 	}
 
+	// A STAGING transaction record is the result of a parallel commit that
+	// is still in progress. Its outcome is determined by the txnCommitter.
+	if respTxn.Status == roachpb.STAGING {
+		return true
+	}
+
 	// Update our txn. In particular, we need to make sure that the client will
 	// notice when the txn has been aborted (in which case we'll give them an
 	// error on their next request).
@@ -0,0 +1,298 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+var parallelCommitsEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.parallel_commits_enabled",
+	"if enabled, transactional commits are parallelized with transactional writes",
+	true,
+)
+
+// txnCommitter is a txnInterceptor that performs "parallel commits". Without
+// it, a committing EndTransaction request must wait for all of the writes
+// that precede it, including the QueryIntent requests that the txnPipeliner
+// adds to prove outstanding writes, before it can be evaluated. This costs a
+// transaction that spans multiple ranges two rounds of consensus to commit.
+//
+// Instead, the txnCommitter attaches the set of writes that are in flight
+// with an EndTransaction request to it and allows the DistSender to send the
+// EndTransaction in parallel with them. Instead of committing the transaction,
+// such an EndTransaction moves the transaction record to the STAGING status.
+// A STAGING transaction is "implicitly committed" once all of its in-flight
+// writes have succeeded at or below the timestamp of its transaction record.
+// If the batch containing the EndTransaction succeeds without the
+// transaction's timestamp having been pushed, the txnCommitter knows that the
+// transaction is implicitly committed, reports it as committed to the client
+// and asynchronously moves the transaction record to COMMITTED to make the
+// commit "explicit". If the transaction's timestamp was pushed, the
+// txnCommitter synchronously sends a second EndTransaction request which
+// attempts to commit the transaction explicitly at its new timestamp.
+//
+// If the coordinator dies while its transaction record is STAGING, other
+// transactions which run into the transaction's intents will eventually find
+// the record expired and recover the transaction's outcome by querying its
+// in-flight writes. See intentResolver.recoverTxn.
+type txnCommitter struct {
+	st      *cluster.Settings
+	stopper *stop.Stopper
+	ambient log.AmbientContext
+	// sender is used to make the commit of implicitly committed transactions
+	// explicit. Its requests are sent asynchronously, outside of the
+	// TxnCoordSender's lock.
+	sender  client.Sender
+	wrapped lockedSender
+}
+
+// SendLocked implements the lockedSender interface.
+func (tc *txnCommitter) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	inFlightWrites := tc.inFlightWrites(ba)
+	if len(inFlightWrites) == 0 {
+		return tc.wrapped.SendLocked(ctx, ba)
+	}
+
+	// Attach the in-flight writes to a copy of the EndTransaction request.
+	// We don't want to modify the batch's request slice directly, so fork
+	// it before modifying it.
+	last := len(ba.Requests) - 1
+	et := ba.Requests[last].GetInner().(*roachpb.EndTransactionRequest)
+	etCopy := *et
+	etCopy.InFlightWrites = inFlightWrites
+	ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests...)
+	ba.Requests[last].MustSetInner(&etCopy)
+
+	br, pErr := tc.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		if isAmbiguousError(pErr) {
+			tc.finalizeAmbiguousCommitLocked(ctx, ba.Txn.TxnMeta, inFlightWrites)
+		}
+		return nil, stripStagingStatus(pErr)
+	}
+	if br.Txn == nil || br.Txn.Status != roachpb.STAGING {
+		return br, nil
+	}
+
+	// If the transaction's timestamp wasn't pushed, all of the in-flight
+	// writes succeeded at the timestamp of the staging transaction record,
+	// so the transaction is implicitly committed.
+	if !ba.Txn.Timestamp.Less(br.Txn.Timestamp) {
+		tc.makeTxnCommitExplicitAsync(ctx, br.Txn, et)
+		br.Txn.Status = roachpb.COMMITTED
+		br.Txn.InFlightWrites = nil
+		return br, nil
+	}
+
+	// Otherwise, the transaction can only commit at its new timestamp, which
+	// requires an explicit commit.
+	log.VEventf(ctx, 2, "parallel commit of %s pushed to %s; committing explicitly",
+		br.Txn.Short(), br.Txn.Timestamp)
+	etBa := roachpb.BatchRequest{}
+	etBa.Header = ba.Header
+	etBa.Txn = br.Txn
+	etBa.Add(et)
+	etBr, pErr := tc.wrapped.SendLocked(ctx, etBa)
+	if pErr != nil {
+		return nil, stripStagingStatus(pErr)
+	}
+	br.Txn = etBr.Txn
+	br.Responses[last] = etBr.Responses[0]
+	return br, nil
+}
+
+// inFlightWrites returns the writes that a committing EndTransaction request
+// in the batch would be in flight with if it was sent in parallel with the
+// rest of the batch. It returns nil if the batch can't perform a parallel
+// commit. This is the case unless the batch consists solely of transactional
+// point writes and QueryIntent requests proving earlier writes, followed by
+// the EndTransaction.
+func (tc *txnCommitter) inFlightWrites(ba roachpb.BatchRequest) []roachpb.SequencedWrite {
+	if len(ba.Requests) < 2 || ba.Txn == nil {
+		return nil
+	}
+	et, ok := ba.Requests[len(ba.Requests)-1].GetInner().(*roachpb.EndTransactionRequest)
+	if !ok || !et.Commit || et.InternalCommitTrigger != nil {
+		return nil
+	}
+	if !tc.st.Version.IsActive(cluster.VersionParallelCommits) ||
+		!parallelCommitsEnabled.Get(&tc.st.SV) {
+		return nil
+	}
+	// An implicitly committed transaction must not be able to commit at a
+	// timestamp above that of its writes, and the in-flight writes of a
+	// SNAPSHOT transaction can't be prevented. See QueryIntentRequest.
+	if ba.Txn.Isolation != enginepb.SERIALIZABLE {
+		return nil
+	}
+	writes := make([]roachpb.SequencedWrite, 0, len(ba.Requests)-1)
+	for _, ru := range ba.Requests[:len(ba.Requests)-1] {
+		req := ru.GetInner()
+		if qi, ok := req.(*roachpb.QueryIntentRequest); ok {
+			writes = append(writes, roachpb.SequencedWrite{Key: qi.Key, Sequence: qi.Txn.Sequence})
+			continue
+		}
+		// BeginTransaction requests are excluded here, as the transaction
+		// record must exist before it can be staged.
+		if !roachpb.IsTransactionWrite(req) || roachpb.IsRange(req) {
+			return nil
+		}
+		h := req.Header()
+		writes = append(writes, roachpb.SequencedWrite{Key: h.Key, Sequence: h.Sequence})
+	}
+	return writes
+}
+
+// makeTxnCommitExplicitAsync asynchronously moves the record of an implicitly
+// committed transaction to COMMITTED and resolves its intents. If this fails,
+// the transaction's outcome is recovered by the next transaction to run into
+// one of its intents after its record expires.
+func (tc *txnCommitter) makeTxnCommitExplicitAsync(
+	ctx context.Context, txn *roachpb.Transaction, et *roachpb.EndTransactionRequest,
+) {
+	log.VEventf(ctx, 2, "making txn commit explicit: %s", txn)
+	ba := roachpb.BatchRequest{}
+	txnCopy := txn.Clone()
+	ba.Txn = &txnCopy
+	etCopy := *et
+	ba.Add(&etCopy)
+
+	// NB: We use context.Background() here because we don't want a canceled
+	// context to interrupt the commit.
+	asyncCtx := tc.ambient.AnnotateCtx(context.Background())
+	if err := tc.stopper.RunAsyncTask(
+		asyncCtx, "kv.txnCommitter: making txn commit explicit", func(ctx context.Context) {
+			if _, pErr := tc.sender.Send(ctx, ba); pErr != nil {
+				log.VErrEventf(ctx, 1, "making txn commit explicit failed for %s: %s", txnCopy, pErr)
+			}
+		},
+	); err != nil {
+		log.VErrEventf(ctx, 1, "failed to make txn commit explicit: %s", err)
+	}
+}
+
+// finalizeAmbiguousCommitLocked is called when the outcome of a parallel
+// commit is ambiguous. The transaction record may be STAGING, in which case
+// the transaction may be implicitly committed even though the client is
+// going to see an error, and a subsequent rollback must not be allowed to
+// abort it. To avoid this, the method runs the same recovery procedure as a
+// pusher would and finalizes the transaction record. Errors are ignored, in
+// which case the transaction is recovered by other transactions once its
+// record expires.
+func (tc *txnCommitter) finalizeAmbiguousCommitLocked(
+	ctx context.Context, meta enginepb.TxnMeta, inFlightWrites []roachpb.SequencedWrite,
+) {
+	log.VEventf(ctx, 2, "finalizing ambiguous parallel commit of %s", meta.Short())
+	qBa := roachpb.BatchRequest{}
+	qBa.Add(&roachpb.QueryTxnRequest{
+		RequestHeader: roachpb.RequestHeader{Key: meta.Key},
+		Txn:           meta,
+	})
+	qBr, pErr := tc.wrapped.SendLocked(ctx, qBa)
+	if pErr != nil {
+		log.VErrEventf(ctx, 1, "failed to query txn %s: %s", meta.Short(), pErr)
+		return
+	}
+	txn := qBr.Responses[0].GetInner().(*roachpb.QueryTxnResponse).QueriedTxn
+	if txn.Status != roachpb.STAGING {
+		return
+	}
+
+	qiBa := roachpb.BatchRequest{}
+	for _, w := range txn.InFlightWrites {
+		wMeta := txn.TxnMeta
+		wMeta.Sequence = w.Sequence
+		qiBa.Add(&roachpb.QueryIntentRequest{
+			RequestHeader: roachpb.RequestHeader{Key: w.Key},
+			Txn:           wMeta,
+			IfMissing:     roachpb.QueryIntentRequest_PREVENT,
+		})
+	}
+	implicitlyCommitted := true
+	if len(qiBa.Requests) > 0 {
+		qiBr, pErr := tc.wrapped.SendLocked(ctx, qiBa)
+		if pErr != nil {
+			log.VErrEventf(ctx, 1, "failed to query in-flight writes of %s: %s", meta.Short(), pErr)
+			return
+		}
+		for _, resp := range qiBr.Responses {
+			if !resp.GetInner().(*roachpb.QueryIntentResponse).FoundIntent {
+				implicitlyCommitted = false
+				break
+			}
+		}
+	}
+
+	rBa := roachpb.BatchRequest{}
+	rBa.Add(&roachpb.RecoverTxnRequest{
+		RequestHeader:       roachpb.RequestHeader{Key: txn.Key},
+		Txn:                 txn.TxnMeta,
+		ImplicitlyCommitted: implicitlyCommitted,
+	})
+	if _, pErr := tc.wrapped.SendLocked(ctx, rBa); pErr != nil {
+		log.VErrEventf(ctx, 1, "failed to recover txn %s: %s", meta.Short(), pErr)
+	}
+}
+
+// isAmbiguousError returns whether the error leaves the outcome of the
+// batch that caused it ambiguous.
+func isAmbiguousError(pErr *roachpb.Error) bool {
+	switch t := pErr.GetDetail().(type) {
+	case *roachpb.AmbiguousResultError:
+		return true
+	case *roachpb.MixedSuccessError:
+		return isAmbiguousError(t.Wrapped)
+	}
+	return false
+}
+
+// stripStagingStatus hides the STAGING status of the transaction in an error
+// from the rest of the TxnCoordSender, which only expects to see PENDING
+// transactions until the transaction is finalized.
+func stripStagingStatus(pErr *roachpb.Error) *roachpb.Error {
+	if txn := pErr.GetTxn(); txn != nil && txn.Status == roachpb.STAGING {
+		txnCopy := txn.Clone()
+		txnCopy.Status = roachpb.PENDING
+		txnCopy.InFlightWrites = nil
+		pErr.SetTxn(&txnCopy)
+	}
+	return pErr
+}
+
+// setWrapped implements the txnInterceptor interface.
+func (tc *txnCommitter) setWrapped(wrapped lockedSender) { tc.wrapped = wrapped }
+
+// populateMetaLocked implements the txnInterceptor interface.
+func (*txnCommitter) populateMetaLocked(meta *roachpb.TxnCoordMeta) {}
+
+// augmentMetaLocked implements the txnInterceptor interface.
+func (*txnCommitter) augmentMetaLocked(meta roachpb.TxnCoordMeta) {}
+
+// epochBumpedLocked implements the txnInterceptor interface.
+func (*txnCommitter) epochBumpedLocked() {}
+
+// closeLocked implements the txnInterceptor interface.
+func (*txnCommitter) closeLocked() {}
@@ -0,0 +1,232 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+func makeMockTxnCommitter(
+	stopper *stop.Stopper, sender client.Sender,
+) (txnCommitter, *mockLockedSender) {
+	mockSender := &mockLockedSender{}
+	return txnCommitter{
+		st:      cluster.MakeTestingClusterSettings(),
+		stopper: stopper,
+		ambient: log.AmbientContext{Tracer: tracing.NewTracer()},
+		sender:  sender,
+		wrapped: mockSender,
+	}, mockSender
+}
+
+// TestTxnCommitterParallelCommit tests that the txnCommitter attaches the
+// writes that a committing EndTransaction is sent in parallel with to the
+// request, that it considers a STAGING transaction whose timestamp was not
+// pushed to be committed, and that it then makes the commit explicit
+// asynchronously.
+func TestTxnCommitterParallelCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	explicitCommit := make(chan roachpb.BatchRequest, 1)
+	tc, mockSender := makeMockTxnCommitter(stopper, client.SenderFunc(
+		func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			explicitCommit <- ba
+			br := ba.CreateReply()
+			txn := ba.Txn.Clone()
+			br.Txn = &txn
+			br.Txn.Status = roachpb.COMMITTED
+			return br, nil
+		},
+	))
+
+	txn := makeTxnProto()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	queryMeta := txn.TxnMeta
+	queryMeta.Sequence = 1
+	ba.Add(&roachpb.QueryIntentRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyA},
+		Txn:           queryMeta,
+		IfMissing:     roachpb.QueryIntentRequest_RETURN_ERROR,
+	})
+	putArgs := roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}}
+	putArgs.Sequence = 2
+	ba.Add(&putArgs)
+	etArgs := roachpb.EndTransactionRequest{
+		RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+		Commit:        true,
+		IntentSpans:   []roachpb.Span{{Key: keyA}, {Key: keyB}},
+	}
+	ba.Add(&etArgs)
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Equal(t, 3, len(ba.Requests))
+		et := ba.Requests[2].GetInner().(*roachpb.EndTransactionRequest)
+		require.Equal(t, []roachpb.SequencedWrite{
+			{Key: keyA, Sequence: 1},
+			{Key: keyB, Sequence: 2},
+		}, et.InFlightWrites)
+
+		br := ba.CreateReply()
+		brTxn := ba.Txn.Clone()
+		br.Txn = &brTxn
+		br.Txn.Status = roachpb.STAGING
+		br.Txn.InFlightWrites = et.InFlightWrites
+		return br, nil
+	})
+
+	br, pErr := tc.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, roachpb.COMMITTED, br.Txn.Status)
+	require.Empty(t, br.Txn.InFlightWrites)
+	// The caller's request must not have been modified.
+	require.Empty(t, etArgs.InFlightWrites)
+
+	explicitBa := <-explicitCommit
+	require.Equal(t, 1, len(explicitBa.Requests))
+	explicitET := explicitBa.Requests[0].GetInner().(*roachpb.EndTransactionRequest)
+	require.True(t, explicitET.Commit)
+	require.Empty(t, explicitET.InFlightWrites)
+	require.Equal(t, etArgs.IntentSpans, explicitET.IntentSpans)
+}
+
+// TestTxnCommitterPushedParallelCommit tests that the txnCommitter commits a
+// STAGING transaction whose timestamp was pushed explicitly.
+func TestTxnCommitterPushedParallelCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc, mockSender := makeMockTxnCommitter(stopper, nil /* sender */)
+
+	txn := makeTxnProto()
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	putArgs := roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a")}}
+	putArgs.Sequence = 1
+	ba.Add(&putArgs)
+	ba.Add(&roachpb.EndTransactionRequest{Commit: true})
+
+	calls := 0
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		calls++
+		et := ba.Requests[len(ba.Requests)-1].GetInner().(*roachpb.EndTransactionRequest)
+		br := ba.CreateReply()
+		brTxn := ba.Txn.Clone()
+		br.Txn = &brTxn
+		switch calls {
+		case 1:
+			require.Equal(t, 2, len(ba.Requests))
+			require.Equal(t, 1, len(et.InFlightWrites))
+			br.Txn.Status = roachpb.STAGING
+			br.Txn.Timestamp = br.Txn.Timestamp.Next()
+		case 2:
+			require.Equal(t, 1, len(ba.Requests))
+			require.Empty(t, et.InFlightWrites)
+			require.True(t, txn.Timestamp.Less(ba.Txn.Timestamp))
+			br.Txn.Status = roachpb.COMMITTED
+		default:
+			t.Fatalf("unexpected batch %s", ba)
+		}
+		return br, nil
+	})
+
+	br, pErr := tc.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, 2, calls)
+	require.Equal(t, 2, len(br.Responses))
+	require.Equal(t, roachpb.COMMITTED, br.Txn.Status)
+}
+
+// TestTxnCommitterNoParallelCommit tests batches which the txnCommitter
+// passes through untouched.
+func TestTxnCommitterNoParallelCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc, mockSender := makeMockTxnCommitter(stopper, nil /* sender */)
+
+	keyA := roachpb.Key("a")
+	put := &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}}
+	testCases := []struct {
+		name string
+		iso  enginepb.IsolationType
+		reqs []roachpb.Request
+	}{
+		{"end txn only", enginepb.SERIALIZABLE, []roachpb.Request{
+			&roachpb.EndTransactionRequest{Commit: true},
+		}},
+		{"rollback", enginepb.SERIALIZABLE, []roachpb.Request{
+			put, &roachpb.EndTransactionRequest{Commit: false},
+		}},
+		{"begin txn", enginepb.SERIALIZABLE, []roachpb.Request{
+			&roachpb.BeginTransactionRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}},
+			put, &roachpb.EndTransactionRequest{Commit: true},
+		}},
+		{"ranged write", enginepb.SERIALIZABLE, []roachpb.Request{
+			&roachpb.DeleteRangeRequest{RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyA.Next()}},
+			&roachpb.EndTransactionRequest{Commit: true},
+		}},
+		{"read", enginepb.SERIALIZABLE, []roachpb.Request{
+			&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}},
+			&roachpb.EndTransactionRequest{Commit: true},
+		}},
+		{"snapshot", enginepb.SNAPSHOT, []roachpb.Request{
+			put, &roachpb.EndTransactionRequest{Commit: true},
+		}},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			txn := makeTxnProto()
+			txn.Isolation = c.iso
+			var ba roachpb.BatchRequest
+			ba.Header = roachpb.Header{Txn: &txn}
+			ba.Add(c.reqs...)
+
+			mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+				et := ba.Requests[len(ba.Requests)-1].GetInner().(*roachpb.EndTransactionRequest)
+				require.Empty(t, et.InFlightWrites)
+				br := ba.CreateReply()
+				brTxn := ba.Txn.Clone()
+				br.Txn = &brTxn
+				br.Txn.Status = roachpb.COMMITTED
+				return br, nil
+			})
+			br, pErr := tc.SendLocked(ctx, ba)
+			require.Nil(t, pErr)
+			require.NotNil(t, br)
+		})
+	}
+}
@@ -78,9 +78,10 @@ var pipelinedWritesMaxBatchSize = settings.RegisterNonNegativeIntSetting(
 //
 // The interceptor proves all outstanding writes before committing a transaction
 // by tacking on a QueryIntent request for each one to the front of an
-// EndTransaction(Commit=true) requests. The result of this is that the
+// EndTransaction(Commit=true) requests. Unless the transaction performs a
+// parallel commit (see txnCommitter), the result of this is that the
 // EndTransaction needs to wait at the DistSender level for all of QueryIntent
-// requests to succeed at before executing itself [1]. This is a little
+// requests to succeed at before executing itself. This is a little
 // unfortunate because a transaction could have accumulated a large number of
 // outstanding writes without proving any of them, and the more of these writes
 // there are, the more chance querying one of them gets delayed and delays the
@@ -113,13 +114,9 @@ var pipelinedWritesMaxBatchSize = settings.RegisterNonNegativeIntSetting(
 //    request and be notified immediately after its "replication" phase completes.
 //    This would allow txnPipeliner to prove outstanding writes immediately after
 //    they finish consensus without any extra RPCs.
-// So far, none of these approaches have been integrated.
-//
-// [1] A proposal called "parallel commits" (#24194) exists that would allow all
-//     QueryIntent requests and the EndTransaction request that they are prepended
-//     to to be sent by the DistSender in parallel. This would help with this
-//     issue by hiding the cost of the QueryIntent requests behind the cost of the
-//     "staging" EndTransaction request.
+// So far, none of these approaches have been integrated. Parallel commits
+// hide the cost of the QueryIntent requests behind the cost of the "staging"
+// EndTransaction request in most cases.
 //
 type txnPipeliner struct {
 	st       *cluster.Settings
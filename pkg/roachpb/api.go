@@ -182,6 +182,26 @@ func NeedsRefresh(args Request) bool {
 	return (args.flags() & needsRefresh) != 0
 }
 
+// Locking returns the strength of the locks that the request acquires on the
+// keys it reads and how it handles conflicting locks. Only scans can acquire
+// locks.
+func Locking(args Request) (KeyLockingStrength, LockWaitPolicy) {
+	switch t := args.(type) {
+	case *ScanRequest:
+		return t.KeyLocking, t.LockWaitPolicy
+	case *ReverseScanRequest:
+		return t.KeyLocking, t.LockWaitPolicy
+	}
+	return LOCK_NONE, LOCK_WAIT_BLOCK
+}
+
+// IsLocking returns true if the request acquires locks on the keys it
+// reads.
+func IsLocking(args Request) bool {
+	str, _ := Locking(args)
+	return str != LOCK_NONE
+}
+
 // Request is an interface for RPC requests.
 type Request interface {
 	protoutil.Message
@@ -294,6 +314,19 @@ func (dr *DeleteRangeResponse) combine(c combinable) error {
 	return nil
 }
 
+// combine implements the combinable interface.
+func (rr *RevertRangeResponse) combine(c combinable) error {
+	otherRR := c.(*RevertRangeResponse)
+	if rr != nil {
+		if err := rr.ResponseHeader.combine(otherRR.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ combinable = &RevertRangeResponse{}
+
 // combine implements the combinable interface.
 func (rr *ResolveIntentRangeResponse) combine(c combinable) error {
 	otherRR := c.(*ResolveIntentRangeResponse)
@@ -578,6 +611,12 @@ func (*GetSnapshotForMergeRequest) Method() Method { return GetSnapshotForMerge
 // Method implements the Request interface.
 func (*RangeStatsRequest) Method() Method { return RangeStats }
 
+// Method implements the Request interface.
+func (*RevertRangeRequest) Method() Method { return RevertRange }
+
+// Method implements the Request interface.
+func (*RecoverTxnRequest) Method() Method { return RecoverTxn }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -818,6 +857,18 @@ func (r *RangeStatsRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *RevertRangeRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
+// ShallowCopy implements the Request interface.
+func (r *RecoverTxnRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -1082,6 +1133,12 @@ func (*GetSnapshotForMergeRequest) flags() int { return isRead | isAlone | updat
 
 func (*RangeStatsRequest) flags() int { return isRead }
 
+// Note that RevertRange commands cannot be part of a transaction as
+// they may clear data directly.
+func (*RevertRangeRequest) flags() int { return isWrite | isRange | isAlone | consultsTSCache }
+
+func (*RecoverTxnRequest) flags() int { return isWrite | isAlone }
+
 // Keys returns credentials in an aws.Config.
 func (b *ExportStorage_S3) Keys() *aws.Config {
 	return &aws.Config{
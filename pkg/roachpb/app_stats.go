@@ -45,3 +45,38 @@ func AddNumericStats(a, b NumericStat, countA, countB int64) NumericStat {
 			delta*delta*float64(countA)*float64(countB)/total,
 	}
 }
+
+// Add combines other into this StatementStatistics.
+func (s *StatementStatistics) Add(other *StatementStatistics) {
+	if other.Count == 0 {
+		return
+	}
+	s.FirstAttemptCount += other.FirstAttemptCount
+	if other.MaxRetries > s.MaxRetries {
+		s.MaxRetries = other.MaxRetries
+	}
+	if other.LastErr != "" {
+		s.LastErr = other.LastErr
+		s.LastErrRedacted = other.LastErrRedacted
+	}
+	s.NumRows.Add(other.NumRows, s.Count, other.Count)
+	s.ParseLat.Add(other.ParseLat, s.Count, other.Count)
+	s.PlanLat.Add(other.PlanLat, s.Count, other.Count)
+	s.RunLat.Add(other.RunLat, s.Count, other.Count)
+	s.ServiceLat.Add(other.ServiceLat, s.Count, other.Count)
+	s.OverheadLat.Add(other.OverheadLat, s.Count, other.Count)
+	s.Count += other.Count
+}
+
+// Add combines other into this TransactionStatistics.
+func (s *TransactionStatistics) Add(other *TransactionStatistics) {
+	if other.Count == 0 {
+		return
+	}
+	if other.MaxRetries > s.MaxRetries {
+		s.MaxRetries = other.MaxRetries
+	}
+	s.NumRows.Add(other.NumRows, s.Count, other.Count)
+	s.ServiceLat.Add(other.ServiceLat, s.Count, other.Count)
+	s.Count += other.Count
+}
@@ -85,3 +85,37 @@ func TestAddNumericStats(t *testing.T) {
 		t.Fatalf("a.Add(b) should match add(a, b): %+v vs %+v", a, combined)
 	}
 }
+
+func TestAddStatementStatistics(t *testing.T) {
+	var a, b StatementStatistics
+	for i, v := range []float64{1, 2, 3} {
+		a.Count++
+		a.ServiceLat.Record(a.Count, v)
+		if i == 0 {
+			a.FirstAttemptCount++
+		}
+	}
+	b.Count = 1
+	b.MaxRetries = 2
+	b.LastErr = "boom"
+	b.ServiceLat.Record(b.Count, 6)
+
+	var sum StatementStatistics
+	sum.Add(&a)
+	if sum != a {
+		t.Fatalf("expected adding to empty statistics to copy them, got %+v", sum)
+	}
+	sum.Add(&b)
+	if sum.Count != 4 || sum.FirstAttemptCount != 1 || sum.MaxRetries != 2 || sum.LastErr != "boom" {
+		t.Fatalf("unexpected combined statistics %+v", sum)
+	}
+	if e := math.Abs(sum.ServiceLat.Mean - 3); e > 0.0000001 {
+		t.Fatalf("expected mean 3, got %f", sum.ServiceLat.Mean)
+	}
+	// Adding empty statistics is a no-op, in particular the means don't
+	// become NaN.
+	sum.Add(&StatementStatistics{})
+	if sum.Count != 4 || math.IsNaN(sum.ServiceLat.Mean) {
+		t.Fatalf("unexpected statistics after adding empty ones %+v", sum)
+	}
+}
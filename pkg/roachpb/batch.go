@@ -117,6 +117,17 @@ func (ba *BatchRequest) IsTransactionWrite() bool {
 	return ba.hasFlag(isTxnWrite)
 }
 
+// IsLocking returns true iff the BatchRequest contains a request which
+// acquires locks on the keys it reads.
+func (ba *BatchRequest) IsLocking() bool {
+	for _, union := range ba.Requests {
+		if IsLocking(union.GetInner()) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsRange returns true iff the BatchRequest contains range-based requests.
 func (ba *BatchRequest) IsRange() bool {
 	return ba.hasFlag(isRange)
@@ -246,14 +257,15 @@ func (br *BatchResponse) String() string {
 }
 
 // IntentSpanIterate calls the passed method with the key ranges of the
-// transactional writes contained in the batch. Usually the key spans
+// transactional writes and locking reads contained in the batch, all of which
+// need to be cleaned up when the transaction ends. Usually the key spans
 // contained in the requests are used, but when a response contains a
 // ResumeSpan the ResumeSpan is subtracted from the request span to provide a
 // more minimal span of keys affected by the request.
 func (ba *BatchRequest) IntentSpanIterate(br *BatchResponse, fn func(Span)) {
 	for i, arg := range ba.Requests {
 		req := arg.GetInner()
-		if !IsTransactionWrite(req) {
+		if !IsTransactionWrite(req) && !IsLocking(req) {
 			continue
 		}
 		var resp Response
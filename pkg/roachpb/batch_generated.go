@@ -160,6 +160,10 @@ func (ru RequestUnion) GetInner() Request {
 		return t.GetSnapshotForMerge
 	case *RequestUnion_RangeStats:
 		return t.RangeStats
+	case *RequestUnion_RevertRange:
+		return t.RevertRange
+	case *RequestUnion_RecoverTxn:
+		return t.RecoverTxn
 	default:
 		return nil
 	}
@@ -246,6 +250,10 @@ func (ru ResponseUnion) GetInner() Response {
 		return t.GetSnapshotForMerge
 	case *ResponseUnion_RangeStats:
 		return t.RangeStats
+	case *ResponseUnion_RevertRange:
+		return t.RevertRange
+	case *ResponseUnion_RecoverTxn:
+		return t.RecoverTxn
 	default:
 		return nil
 	}
@@ -406,6 +414,10 @@ func (ru *RequestUnion) SetInner(r Request) bool {
 		union = &RequestUnion_GetSnapshotForMerge{t}
 	case *RangeStatsRequest:
 		union = &RequestUnion_RangeStats{t}
+	case *RevertRangeRequest:
+		union = &RequestUnion_RevertRange{t}
+	case *RecoverTxnRequest:
+		union = &RequestUnion_RecoverTxn{t}
 	default:
 		return false
 	}
@@ -495,6 +507,10 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 		union = &ResponseUnion_GetSnapshotForMerge{t}
 	case *RangeStatsResponse:
 		union = &ResponseUnion_RangeStats{t}
+	case *RevertRangeResponse:
+		union = &ResponseUnion_RevertRange{t}
+	case *RecoverTxnResponse:
+		union = &ResponseUnion_RecoverTxn{t}
 	default:
 		return false
 	}
@@ -502,7 +518,7 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 	return true
 }
 
-type reqCounts [40]int32
+type reqCounts [42]int32
 
 // getReqCounts returns the number of times each
 // request type appears in the batch.
@@ -590,6 +606,10 @@ func (ba *BatchRequest) getReqCounts() reqCounts {
 			counts[38]++
 		case *RequestUnion_RangeStats:
 			counts[39]++
+		case *RequestUnion_RevertRange:
+			counts[40]++
+		case *RequestUnion_RecoverTxn:
+			counts[41]++
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", ru))
 		}
@@ -638,6 +658,8 @@ var requestNames = []string{
 	"RefreshRng",
 	"GetSnapshotForMerge",
 	"RngStats",
+	"RevertRng",
+	"RecoverTxn",
 }
 
 // Summary prints a short summary of the requests in a batch.
@@ -821,6 +843,14 @@ type rangeStatsResponseAlloc struct {
 	union ResponseUnion_RangeStats
 	resp  RangeStatsResponse
 }
+type revertRangeResponseAlloc struct {
+	union ResponseUnion_RevertRange
+	resp  RevertRangeResponse
+}
+type recoverTxnResponseAlloc struct {
+	union ResponseUnion_RecoverTxn
+	resp  RecoverTxnResponse
+}
 
 // CreateReply creates replies for each of the contained requests, wrapped in a
 // BatchResponse. The response objects are batch allocated to minimize
@@ -871,6 +901,8 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 	var buf37 []refreshRangeResponseAlloc
 	var buf38 []getSnapshotForMergeResponseAlloc
 	var buf39 []rangeStatsResponseAlloc
+	var buf40 []revertRangeResponseAlloc
+	var buf41 []recoverTxnResponseAlloc
 
 	for i, r := range ba.Requests {
 		switch r.GetValue().(type) {
@@ -1154,6 +1186,20 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 			buf39[0].union.RangeStats = &buf39[0].resp
 			br.Responses[i].Value = &buf39[0].union
 			buf39 = buf39[1:]
+		case *RequestUnion_RevertRange:
+			if buf40 == nil {
+				buf40 = make([]revertRangeResponseAlloc, counts[40])
+			}
+			buf40[0].union.RevertRange = &buf40[0].resp
+			br.Responses[i].Value = &buf40[0].union
+			buf40 = buf40[1:]
+		case *RequestUnion_RecoverTxn:
+			if buf41 == nil {
+				buf41 = make([]recoverTxnResponseAlloc, counts[41])
+			}
+			buf41[0].union.RecoverTxn = &buf41[0].resp
+			br.Responses[i].Value = &buf41[0].union
+			buf41 = buf41[1:]
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
@@ -496,6 +496,7 @@ var nonZeroTxn = Transaction{
 	Intents:                  []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 	EpochZeroTimestamp:       makeTS(1, 1),
 	OrigTimestampWasObserved: true,
+	InFlightWrites:           []SequencedWrite{{Key: []byte("c"), Sequence: 1}},
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -540,6 +541,30 @@ func TestTransactionUpdateEpochZero(t *testing.T) {
 	}
 }
 
+// TestTransactionUpdateStaging verifies that a STAGING transaction can be
+// finalized, but that a finalized transaction does not move back to STAGING.
+func TestTransactionUpdateStaging(t *testing.T) {
+	for _, tc := range []struct {
+		status, update, exp TransactionStatus
+	}{
+		{PENDING, STAGING, STAGING},
+		{STAGING, PENDING, STAGING},
+		{STAGING, COMMITTED, COMMITTED},
+		{STAGING, ABORTED, ABORTED},
+		{COMMITTED, STAGING, COMMITTED},
+		{ABORTED, STAGING, ABORTED},
+	} {
+		txn := nonZeroTxn.Clone()
+		txn.Status = tc.status
+		o := nonZeroTxn.Clone()
+		o.Status = tc.update
+		txn.Update(&o)
+		if txn.Status != tc.exp {
+			t.Errorf("%s updated with %s: expected %s, got %s", tc.status, tc.update, tc.exp, txn.Status)
+		}
+	}
+}
+
 func TestTransactionClone(t *testing.T) {
 	txn := nonZeroTxn.Clone()
 
@@ -550,6 +575,7 @@ func TestTransactionClone(t *testing.T) {
 	// listed below. If this test fails, please update the list below and/or
 	// Transaction.Clone().
 	expFields := []string{
+		"InFlightWrites.Key",
 		"Intents.EndKey",
 		"Intents.Key",
 		"TxnMeta.Key",
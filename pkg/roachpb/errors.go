@@ -472,6 +472,11 @@ func (e *WriteIntentError) message(_ *Error) string {
 
 var _ ErrorDetailInterface = &WriteIntentError{}
 
+// ContentionTimeLogKey is the key of the field logged in the trace of a
+// request each time it was blocked by a WriteIntentError. The value of the
+// field is the time the request was blocked for, in nanoseconds.
+const ContentionTimeLogKey = "contention_time_ns"
+
 func (e *WriteTooOldError) Error() string {
 	return e.message(nil)
 }
@@ -148,4 +148,12 @@ const (
 	GetSnapshotForMerge
 	// RangeStats returns the MVCC statistics for a range.
 	RangeStats
+	// RevertRange reverts the keys in a span to their state as of a past
+	// timestamp by writing new MVCC versions (or, when possible, clearing
+	// the span outright).
+	RevertRange
+	// RecoverTxn moves an abandoned STAGING transaction record to COMMITTED
+	// or ABORTED, depending on whether all of its in-flight writes were
+	// found.
+	RecoverTxn
 )
@@ -16,8 +16,10 @@ package security
 
 import (
 	"context"
+	"crypto"
 	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -399,6 +401,130 @@ func (cm *CertificateManager) LoadCertificates() error {
 	return nil
 }
 
+// nodeCertsCurrent is the name of the symlink, in the certs directory, to
+// the versioned directory holding the node certificate and key installed by
+// InstallNodeCertificate.
+const nodeCertsCurrent = "node-current"
+
+// nodeCertsDirPrefix is the prefix of the versioned directories holding the
+// node certificate and key. The certificate loader skips directories.
+const nodeCertsDirPrefix = "node-certs-"
+
+// InstallNodeCertificate replaces the node certificate and key with the
+// given DER-encoded certificate and its private key, then reloads the
+// certificates.
+//
+// Every pair is written to a new versioned directory. The node certificate
+// and key are symlinks to the files of the directory pointed to by the
+// node-current symlink, which is replaced with a single rename. Both files
+// are thus switched at once and always match, even after a crash.
+func (cm *CertificateManager) InstallNodeCertificate(cert []byte, key crypto.PrivateKey) error {
+	if !cm.isNodeCertificateLinked() {
+		if err := cm.linkNodeCertificate(); err != nil {
+			return errors.Wrap(err, "could not move the node certificate to a versioned directory")
+		}
+	}
+
+	dir, err := ioutil.TempDir(cm.certsDir, nodeCertsDirPrefix)
+	if err != nil {
+		return err
+	}
+	keyPath := filepath.Join(dir, filepath.Base(cm.NodeKeyPath()))
+	if err := writeKeyToFile(keyPath, key, false /* overwrite */); err != nil {
+		return errors.Errorf("error writing node key to %s: %v", keyPath, err)
+	}
+	certPath := filepath.Join(dir, filepath.Base(cm.NodeCertPath()))
+	if err := writeCertificateToFile(certPath, cert, false /* overwrite */); err != nil {
+		return errors.Errorf("error writing node certificate to %s: %v", certPath, err)
+	}
+	if err := replaceWithSymlink(
+		filepath.Base(dir), filepath.Join(cm.certsDir, nodeCertsCurrent),
+	); err != nil {
+		return err
+	}
+	cm.removeUnusedNodeCertDirs(dir)
+	return cm.LoadCertificates()
+}
+
+// isNodeCertificateLinked returns whether the node certificate and key are
+// symlinks through the node-current symlink.
+func (cm *CertificateManager) isNodeCertificateLinked() bool {
+	for _, path := range []string{cm.NodeCertPath(), cm.NodeKeyPath()} {
+		target, err := os.Readlink(path)
+		if err != nil || target != filepath.Join(nodeCertsCurrent, filepath.Base(path)) {
+			return false
+		}
+	}
+	return true
+}
+
+// linkNodeCertificate copies the node certificate and key to a new
+// versioned directory, points node-current to it, then replaces the node
+// certificate and key with symlinks through node-current. They match at
+// every step.
+func (cm *CertificateManager) linkNodeCertificate() error {
+	dir, err := ioutil.TempDir(cm.certsDir, nodeCertsDirPrefix)
+	if err != nil {
+		return err
+	}
+	paths := []string{cm.NodeCertPath(), cm.NodeKeyPath()}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(
+			filepath.Join(dir, filepath.Base(path)), contents, info.Mode().Perm(),
+		); err != nil {
+			return err
+		}
+	}
+	if err := replaceWithSymlink(
+		filepath.Base(dir), filepath.Join(cm.certsDir, nodeCertsCurrent),
+	); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := replaceWithSymlink(filepath.Join(nodeCertsCurrent, filepath.Base(path)), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUnusedNodeCertDirs removes the versioned directories of the node
+// certificate other than current, including those left behind by a crash.
+func (cm *CertificateManager) removeUnusedNodeCertDirs(current string) {
+	dirs, err := filepath.Glob(filepath.Join(cm.certsDir, nodeCertsDirPrefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if dir == current {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warningf(context.TODO(), "could not remove unused node certificate directory %s: %v", dir, err)
+		}
+	}
+}
+
+// replaceWithSymlink atomically replaces path with a symlink to target.
+func replaceWithSymlink(target, path string) error {
+	tmpPath := path + ".new"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
 // updateMetricsLocked updates the values on the certificate metrics.
 // The metrics may not exist (eg: in tests that build their own CertificateManager).
 // If the corresponding certificate is missing or invalid (Error != nil), we reset the
@@ -15,9 +15,17 @@
 package security_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -61,3 +69,74 @@ func TestManagerWithEmbedded(t *testing.T) {
 		t.Error("unexpected success")
 	}
 }
+
+func TestInstallNodeCertificate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetAssetLoader()
+	defer ResetTest()
+
+	certsDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	if err := generateBaseCerts(certsDir); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := security.NewCertificateManager(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKeyPEM, err := ioutil.ReadFile(filepath.Join(certsDir, security.EmbeddedCAKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey, err := security.PEMToPrivateKey(caKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert := cm.CACert().ParsedCertificates[0]
+
+	// The first installation replaces the node certificate and key with
+	// symlinks, the next ones only switch the versioned directory.
+	for i := 0; i < 2; i++ {
+		key, err := rsa.GenerateKey(rand.Reader, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hosts := []string{"127.0.0.1"}
+		csr, err := security.GenerateNodeCSR(key, hosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := security.SignNodeCSR(caCert, caKey, csr, hosts, 24*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cm.InstallNodeCertificate(cert, key); err != nil {
+			t.Fatal(err)
+		}
+
+		newCert, err := x509.ParseCertificate(cert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cm.NodeCert().ParsedCertificates[0].Equal(newCert) {
+			t.Fatalf("%d: expected the new node certificate to be loaded", i)
+		}
+		// The key matches the certificate.
+		if _, err := cm.GetServerTLSConfig(); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"node.crt", "node.key"} {
+			if target, err := os.Readlink(filepath.Join(certsDir, name)); err != nil {
+				t.Fatal(err)
+			} else if e := filepath.Join("node-current", name); target != e {
+				t.Errorf("%d: expected %s to link to %s, got %s", i, name, e, target)
+			}
+		}
+		if dirs, err := filepath.Glob(filepath.Join(certsDir, "node-certs-*")); err != nil {
+			t.Fatal(err)
+		} else if len(dirs) != 1 {
+			t.Errorf("%d: expected a single versioned directory, got %v", i, dirs)
+		}
+	}
+}
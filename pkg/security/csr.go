@@ -0,0 +1,104 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Certificate signing requests are used by the nodes to renew their
+// certificates with the built-in certificate authority: a node generates a
+// new key and sends a CSR for it to a node holding the CA key, which
+// issues the new node certificate. The private keys never leave the nodes.
+
+// GenerateNodeCSR creates a certificate signing request for a node
+// certificate valid for the given hosts and signed with the new key of the
+// node. It returns the DER-encoded request.
+func GenerateNodeCSR(key crypto.Signer, hosts []string) ([]byte, error) {
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			Organization: []string{"Cockroach"},
+			CommonName:   NodeUser,
+		},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	return x509.CreateCertificateRequest(rand.Reader, template, key)
+}
+
+// SignNodeCSR issues a node certificate for the DER-encoded certificate
+// signing request, using the CA certificate and key. The signature of the
+// request is verified and only node certificates valid for a subset of
+// allowedHosts can be requested. It returns the DER-encoded certificate.
+func SignNodeCSR(
+	caCert *x509.Certificate,
+	caPrivateKey crypto.PrivateKey,
+	csrBytes []byte,
+	allowedHosts []string,
+	lifetime time.Duration,
+) ([]byte, error) {
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse certificate signing request")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "invalid certificate signing request")
+	}
+	if csr.Subject.CommonName != NodeUser {
+		return nil, errors.Errorf("certificate signing request is for %q, only %q certificates can be signed",
+			csr.Subject.CommonName, NodeUser)
+	}
+
+	hosts := append([]string(nil), csr.DNSNames...)
+	for _, ip := range csr.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("certificate signing request does not specify any host")
+	}
+	allowed := make(map[string]struct{}, len(allowedHosts))
+	for _, h := range allowedHosts {
+		allowed[strings.ToLower(h)] = struct{}{}
+	}
+	for _, h := range hosts {
+		if _, ok := allowed[strings.ToLower(h)]; !ok {
+			return nil, errors.Errorf("certificate signing request is for host %q, which is not allowed", h)
+		}
+	}
+	return GenerateServerCert(caCert, caPrivateKey, csr.PublicKey, lifetime, hosts)
+}
+
+// CertificateHosts returns the host names and IP addresses a certificate is
+// valid for.
+func CertificateHosts(cert *x509.Certificate) []string {
+	hosts := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts
+}
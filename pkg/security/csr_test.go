@@ -0,0 +1,109 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestSignNodeCSR(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caBytes, err := security.GenerateCA(caKey, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"localhost", "node1.example.com", "127.0.0.1"}
+	csr, err := security.GenerateNodeCSR(nodeKey, hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := timeutil.Now()
+	certBytes, err := security.SignNodeCSR(caCert, caKey, csr, hosts, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := cert.Subject.CommonName, security.NodeUser; a != e {
+		t.Errorf("expected common name %q, got %q", e, a)
+	}
+	if a, e := security.CertificateHosts(cert), hosts; !reflect.DeepEqual(a, e) {
+		t.Errorf("expected hosts %v, got %v", e, a)
+	}
+	if a, e := cert.NotAfter, now.Add(24*time.Hour); !timesFuzzyEqual(a, e) {
+		t.Errorf("node expiration differs from requested: %s vs %s", a, e)
+	}
+	if !reflect.DeepEqual(cert.PublicKey, nodeKey.Public()) {
+		t.Error("certificate was issued for a different key")
+	}
+
+	// Only node certificates can be requested.
+	clientCSR, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: security.RootUser},
+		DNSNames: []string{"localhost"},
+	}, nodeKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := security.SignNodeCSR(caCert, caKey, clientCSR, hosts, 24*time.Hour); !testutils.IsError(
+		err, `only "node" certificates can be signed`,
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The certificate can only be valid for the allowed hosts.
+	if _, err := security.SignNodeCSR(
+		caCert, caKey, csr, []string{"LOCALHOST", "127.0.0.1"}, 24*time.Hour,
+	); !testutils.IsError(err, `host "node1.example.com", which is not allowed`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Corrupted requests are rejected.
+	csr[len(csr)-1] ^= 0xff
+	if _, err := security.SignNodeCSR(caCert, caKey, csr, hosts, 24*time.Hour); err == nil {
+		t.Fatal("expected corrupted certificate signing request to be rejected")
+	}
+}
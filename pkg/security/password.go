@@ -19,6 +19,8 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"os"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 
@@ -39,8 +41,11 @@ var ErrEmptyPassword = errors.New("empty passwords are not permitted")
 
 // CompareHashAndPassword tests that the provided bytes are equivalent to the
 // hash of the supplied password. If they are not equivalent, returns an
-// error.
+// error. The hash can be either a bcrypt hash or a SCRAM-SHA-256 verifier.
 func CompareHashAndPassword(hashedPassword []byte, password string) error {
+	if IsSCRAMHash(hashedPassword) {
+		return compareSCRAMVerifierAndPassword(hashedPassword, password)
+	}
 	h := sha256.New()
 	return bcrypt.CompareHashAndPassword(hashedPassword, h.Sum([]byte(password)))
 }
@@ -51,6 +56,34 @@ func HashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword(h.Sum([]byte(password)), BcryptCost)
 }
 
+// CheckPasswordPolicy returns an error if the password is shorter than
+// minLength characters or contains characters of fewer than minCharClasses
+// classes among lowercase letters, uppercase letters, digits and other
+// characters.
+func CheckPasswordPolicy(password string, minLength, minCharClasses int) error {
+	if utf8.RuneCountInString(password) < minLength {
+		return errors.Errorf("password must contain at least %d characters", minLength)
+	}
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	if lower+upper+digit+other < minCharClasses {
+		return errors.Errorf("password must contain characters of at least %d of the following "+
+			"classes: lowercase letters, uppercase letters, digits, other characters", minCharClasses)
+	}
+	return nil
+}
+
 // PromptForPassword prompts for a password.
 // This is meant to be used when using a password.
 func PromptForPassword() (string, error) {
@@ -0,0 +1,56 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		password       string
+		minLength      int
+		minCharClasses int
+		expectedErr    string
+	}{
+		{"a", 1, 0, ""},
+		{"abc", 4, 0, "password must contain at least 4 characters"},
+		// Characters are counted, not bytes.
+		{"ééé", 4, 0, "password must contain at least 4 characters"},
+		{"éééé", 4, 0, ""},
+		{"abcdef", 1, 2, "password must contain characters of at least 2 of the following classes"},
+		{"abcDEF", 1, 2, ""},
+		{"abcDEF", 1, 3, "password must contain characters of at least 3 of the following classes"},
+		{"abcDEF12", 1, 3, ""},
+		{"abcDEF12", 1, 4, "password must contain characters of at least 4 of the following classes"},
+		{"abcDEF12!", 8, 4, ""},
+	}
+	for _, tc := range testCases {
+		err := security.CheckPasswordPolicy(tc.password, tc.minLength, tc.minCharClasses)
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tc.password, err)
+			}
+		} else if !testutils.IsError(err, tc.expectedErr) {
+			t.Errorf("%q: expected error %q, got %v", tc.password, tc.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SCRAMMechanism is the name of the SASL mechanism implemented by
+// SCRAMServer.
+const SCRAMMechanism = "SCRAM-SHA-256"
+
+const (
+	// scramIterations is the iteration count of the verifiers created by
+	// HashPasswordSCRAM, which is the one used by PostgreSQL.
+	scramIterations = 4096
+	scramSaltLen    = 16
+	scramNonceLen   = 18
+)
+
+// A SCRAM verifier is stored in the same format as in PostgreSQL:
+//
+//	SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+//
+// where the salt and the keys are encoded in base64. Unlike bcrypt hashes, a
+// verifier allows the server to authenticate a client without receiving its
+// password.
+const scramVerifierPrefix = SCRAMMechanism + "$"
+
+type scramVerifier struct {
+	iterations int
+	salt       []byte
+	storedKey  []byte
+	serverKey  []byte
+}
+
+// IsSCRAMHash returns whether a hashed password is a SCRAM-SHA-256 verifier
+// rather than a bcrypt hash.
+func IsSCRAMHash(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte(scramVerifierPrefix))
+}
+
+// HashPasswordSCRAM takes a raw password and returns a SCRAM-SHA-256 verifier
+// for it with a random salt.
+func HashPasswordSCRAM(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return makeSCRAMVerifier(password, salt, scramIterations).encode(), nil
+}
+
+func makeSCRAMVerifier(password string, salt []byte, iterations int) scramVerifier {
+	saltedPassword := scramHi([]byte(password), salt, iterations)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	return scramVerifier{
+		iterations: iterations,
+		salt:       salt,
+		storedKey:  storedKey[:],
+		serverKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}
+}
+
+func (v scramVerifier) encode() []byte {
+	enc := base64.StdEncoding.EncodeToString
+	return []byte(fmt.Sprintf("%s%d:%s$%s:%s", scramVerifierPrefix,
+		v.iterations, enc(v.salt), enc(v.storedKey), enc(v.serverKey)))
+}
+
+func parseSCRAMVerifier(hashedPassword []byte) (scramVerifier, error) {
+	var v scramVerifier
+	if !IsSCRAMHash(hashedPassword) {
+		return v, errors.New("not a SCRAM verifier")
+	}
+	parts := strings.Split(string(hashedPassword[len(scramVerifierPrefix):]), "$")
+	if len(parts) != 2 {
+		return v, errors.New("malformed SCRAM verifier")
+	}
+	iterSalt := strings.Split(parts[0], ":")
+	keys := strings.Split(parts[1], ":")
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return v, errors.New("malformed SCRAM verifier")
+	}
+	var err error
+	if v.iterations, err = strconv.Atoi(iterSalt[0]); err != nil || v.iterations <= 0 {
+		return v, errors.New("malformed SCRAM verifier")
+	}
+	for _, f := range []struct {
+		dst *[]byte
+		src string
+	}{{&v.salt, iterSalt[1]}, {&v.storedKey, keys[0]}, {&v.serverKey, keys[1]}} {
+		if *f.dst, err = base64.StdEncoding.DecodeString(f.src); err != nil {
+			return v, errors.Wrap(err, "malformed SCRAM verifier")
+		}
+	}
+	return v, nil
+}
+
+// compareSCRAMVerifierAndPassword tests that the verifier was created for the
+// supplied password.
+func compareSCRAMVerifierAndPassword(hashedPassword []byte, password string) error {
+	v, err := parseSCRAMVerifier(hashedPassword)
+	if err != nil {
+		return err
+	}
+	expected := makeSCRAMVerifier(password, v.salt, v.iterations)
+	if subtle.ConstantTimeCompare(expected.storedKey, v.storedKey) != 1 {
+		return errors.New("password does not match verifier")
+	}
+	return nil
+}
+
+// scramHi is the Hi() function of RFC 5802, i.e. PBKDF2 with HMAC-SHA-256
+// producing a single block.
+func scramHi(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	_, _ = mac.Write(salt)
+	_, _ = mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	result := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		_, _ = mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func scramHMAC(key, msg []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// SCRAMServer implements the server side of a SCRAM-SHA-256 exchange, as
+// specified in RFC 5802 and RFC 7677. Channel binding is not supported. The
+// user name sent by the client is ignored in favor of the one of the
+// connection, as in PostgreSQL.
+type SCRAMServer struct {
+	verifier scramVerifier
+
+	gs2Header       string
+	clientFirstBare string
+	serverFirst     string
+	nonce           string
+}
+
+// NewSCRAMServer starts a SCRAM-SHA-256 exchange for a user with the given
+// verifier.
+func NewSCRAMServer(hashedPassword []byte) (*SCRAMServer, error) {
+	v, err := parseSCRAMVerifier(hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &SCRAMServer{verifier: v}, nil
+}
+
+// parseSCRAMAttrs parses a comma-separated list of attributes of the form
+// 'a=value' and checks that their names are the expected ones.
+func parseSCRAMAttrs(msg string, names ...byte) ([]string, error) {
+	attrs := strings.Split(msg, ",")
+	if len(attrs) < len(names) {
+		return nil, errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	values := make([]string, len(names))
+	for i, name := range names {
+		if len(attrs[i]) < 2 || attrs[i][0] != name || attrs[i][1] != '=' {
+			return nil, errors.Errorf("malformed SCRAM message %q: expected attribute %c", msg, name)
+		}
+		values[i] = attrs[i][2:]
+	}
+	return values, nil
+}
+
+// ServerFirst processes the client-first-message and returns the
+// server-first-message.
+func (s *SCRAMServer) ServerFirst(clientFirst []byte) ([]byte, error) {
+	msg := string(clientFirst)
+	// The message starts with the GS2 header: a channel binding flag and an
+	// optional authorization identity.
+	parts := strings.SplitN(msg, ",", 3)
+	if len(parts) != 3 {
+		return nil, errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	switch {
+	case parts[0] == "n", parts[0] == "y":
+	case strings.HasPrefix(parts[0], "p="):
+		return nil, errors.New("SCRAM channel binding is not supported")
+	default:
+		return nil, errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	if parts[1] != "" {
+		return nil, errors.New("SCRAM authorization identities are not supported")
+	}
+	s.gs2Header = parts[0] + "," + parts[1] + ","
+	s.clientFirstBare = parts[2]
+	if strings.HasPrefix(s.clientFirstBare, "m=") {
+		return nil, errors.New("SCRAM extensions are not supported")
+	}
+	attrs, err := parseSCRAMAttrs(s.clientFirstBare, 'n', 'r')
+	if err != nil {
+		return nil, err
+	}
+	clientNonce := attrs[1]
+	if clientNonce == "" {
+		return nil, errors.New("empty SCRAM client nonce")
+	}
+
+	serverNonce := make([]byte, scramNonceLen)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, err
+	}
+	s.nonce = clientNonce + base64.StdEncoding.EncodeToString(serverNonce)
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d",
+		s.nonce, base64.StdEncoding.EncodeToString(s.verifier.salt), s.verifier.iterations)
+	return []byte(s.serverFirst), nil
+}
+
+// ServerFinal processes the client-final-message, verifies the proof of the
+// client and returns the server-final-message.
+func (s *SCRAMServer) ServerFinal(clientFinal []byte) ([]byte, error) {
+	if s.serverFirst == "" {
+		return nil, errors.New("SCRAM client-final-message received before client-first-message")
+	}
+	msg := string(clientFinal)
+	i := strings.LastIndex(msg, ",p=")
+	if i < 0 {
+		return nil, errors.Errorf("malformed SCRAM message %q: missing proof", msg)
+	}
+	clientFinalWithoutProof := msg[:i]
+	proof, err := base64.StdEncoding.DecodeString(msg[i+len(",p="):])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed SCRAM client proof")
+	}
+	attrs, err := parseSCRAMAttrs(clientFinalWithoutProof, 'c', 'r')
+	if err != nil {
+		return nil, err
+	}
+	if attrs[0] != base64.StdEncoding.EncodeToString([]byte(s.gs2Header)) {
+		return nil, errors.New("SCRAM channel binding mismatch")
+	}
+	if attrs[1] != s.nonce {
+		return nil, errors.New("SCRAM nonce mismatch")
+	}
+
+	authMessage := []byte(s.clientFirstBare + "," + s.serverFirst + "," + clientFinalWithoutProof)
+	clientSignature := scramHMAC(s.verifier.storedKey, authMessage)
+	if len(proof) != len(clientSignature) {
+		return nil, errors.New("invalid password")
+	}
+	clientKey := make([]byte, len(proof))
+	for j := range proof {
+		clientKey[j] = proof[j] ^ clientSignature[j]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(storedKey[:], s.verifier.storedKey) != 1 {
+		return nil, errors.New("invalid password")
+	}
+
+	serverSignature := scramHMAC(s.verifier.serverKey, authMessage)
+	return []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature)), nil
+}
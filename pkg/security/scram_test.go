@@ -0,0 +1,139 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSCRAMVerifier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hashed, err := HashPasswordSCRAM("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSCRAMHash(hashed) {
+		t.Fatalf("expected a SCRAM verifier, got %s", hashed)
+	}
+	if err := CompareHashAndPassword(hashed, "pencil"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CompareHashAndPassword(hashed, "pen"); err == nil {
+		t.Fatal("expected an error for a wrong password")
+	}
+
+	bcryptHashed, err := HashPassword("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsSCRAMHash(bcryptHashed) {
+		t.Fatalf("unexpected SCRAM verifier %s", bcryptHashed)
+	}
+
+	for _, malformed := range []string{
+		"SCRAM-SHA-256$",
+		"SCRAM-SHA-256$4096:c2FsdA==",
+		"SCRAM-SHA-256$x:c2FsdA==$a2V5:a2V5",
+		"SCRAM-SHA-256$4096:c2FsdA==$a2V5:!!!",
+	} {
+		if _, err := NewSCRAMServer([]byte(malformed)); !testutils.IsError(err, "malformed SCRAM verifier") {
+			t.Errorf("%s: unexpected error %v", malformed, err)
+		}
+	}
+}
+
+// TestSCRAMServer runs the example exchange of RFC 7677.
+func TestSCRAMServer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := makeSCRAMVerifier("pencil", salt, 4096).encode()
+
+	const (
+		clientFirst = "n,,n=user,r=rOprNGfwEbeRWgbNEkqO"
+		serverFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+		clientFinal = "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+		serverFinal = "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="
+	)
+
+	start := func() *SCRAMServer {
+		t.Helper()
+		s, err := NewSCRAMServer(verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ServerFirst([]byte(clientFirst)); err != nil {
+			t.Fatal(err)
+		}
+		// Use the server nonce of the RFC instead of a random one.
+		s.nonce = "rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+		s.serverFirst = serverFirst
+		return s
+	}
+
+	final, err := start().ServerFinal([]byte(clientFinal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(final) != serverFinal {
+		t.Fatalf("expected %s, got %s", serverFinal, final)
+	}
+
+	for _, tc := range []struct {
+		clientFinal string
+		err         string
+	}{
+		{"c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=eHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", "invalid password"},
+		{"c=biws,r=rOprNGfwEbeRWgbNEkqO," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", "nonce mismatch"},
+		{"c=eSws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", "channel binding mismatch"},
+		{"c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0", "missing proof"},
+	} {
+		if _, err := start().ServerFinal([]byte(tc.clientFinal)); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.clientFinal, tc.err, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		clientFirst string
+		err         string
+	}{
+		{"p=tls-server-end-point,,n=user,r=abc", "channel binding is not supported"},
+		{"n,a=admin,n=user,r=abc", "authorization identities are not supported"},
+		{"n,,m=ext,n=user,r=abc", "extensions are not supported"},
+		{"n,,n=user", "malformed SCRAM message"},
+		{"n,,n=user,r=", "empty SCRAM client nonce"},
+	} {
+		s, err := NewSCRAMServer(verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ServerFirst([]byte(tc.clientFirst)); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.clientFirst, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+)
+
+// Alerts returns the alert rules firing on the requested node, or on all the
+// nodes if no node is requested.
+func (s *statusServer) Alerts(
+	ctx context.Context, req *serverpb.AlertsRequest,
+) (*serverpb.AlertsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	localReq := &serverpb.AlertsRequest{
+		NodeID: "local",
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return s.AlertsLocal(ctx)
+		}
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.Alerts(ctx, localReq)
+	}
+
+	response := &serverpb.AlertsResponse{}
+	nodeAlerts := func(ctx context.Context, status serverpb.StatusClient) (interface{}, error) {
+		return status.Alerts(ctx, localReq)
+	}
+	if err := s.iterateNodes(ctx, "alerts",
+		nodeAlerts,
+		func(nodeID roachpb.NodeID, resp interface{}) {
+			response.Alerts = append(response.Alerts, resp.(*serverpb.AlertsResponse).Alerts...)
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			response.Errors = append(response.Errors,
+				serverpb.AlertsError{NodeID: nodeID, Message: err.Error()})
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(response.Alerts, func(i, j int) bool {
+		a, b := &response.Alerts[i], &response.Alerts[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.StoreID < b.StoreID
+	})
+	sort.Slice(response.Errors, func(i, j int) bool {
+		return response.Errors[i].NodeID < response.Errors[j].NodeID
+	})
+	return response, nil
+}
+
+// AlertsLocal returns the alert rules firing on this node.
+func (s *statusServer) AlertsLocal(ctx context.Context) (*serverpb.AlertsResponse, error) {
+	nodeID := s.gossip.NodeID.Get()
+	response := &serverpb.AlertsResponse{}
+	for _, alert := range s.metricSource.FiringAlerts() {
+		response.Alerts = append(response.Alerts, serverpb.Alert{
+			NodeID:  nodeID,
+			StoreID: alert.StoreID,
+			Rule:    alert.Rule,
+			Metric:  alert.Metric,
+			Value:   alert.Value,
+			Since:   alert.Since,
+		})
+	}
+	return response, nil
+}
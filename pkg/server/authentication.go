@@ -114,16 +114,28 @@ func (s *authenticationServer) UserLogin(
 		)
 	}
 
+	// Reject users whose password expired or who are locked out.
+	if err := sql.CheckUserLoginStatus(ctx, s.server.execCfg, username); err != nil {
+		s.recordLoginFailure(ctx, username, err, false /* wrongPassword */)
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+
 	// Verify the provided username/password pair.
 	verified, err := s.verifyPassword(ctx, username, req.Password)
 	if err != nil {
 		return nil, apiInternalError(ctx, err)
 	}
 	if !verified {
-		return nil, status.Errorf(
+		err := status.Errorf(
 			codes.Unauthenticated,
 			"the provided username and password did not match any credentials on the server",
 		)
+		s.recordLoginFailure(ctx, username, err, true /* wrongPassword */)
+		return nil, err
+	}
+	if err := sql.ResetUserFailedLogins(ctx, s.server.execCfg, username); err != nil {
+		// The user is authenticated regardless.
+		log.Warning(ctx, err)
 	}
 
 	// Create a new database session, generating an ID and secret key.
@@ -275,6 +287,20 @@ func (s *authenticationServer) verifyPassword(
 	return (security.CompareHashAndPassword(hashedPassword, password) == nil), nil
 }
 
+// recordLoginFailure records a failed login to the admin UI in the event log.
+func (s *authenticationServer) recordLoginFailure(
+	ctx context.Context, username string, err error, wrongPassword bool,
+) {
+	info := sql.EventLogUserLoginFailureDetail{
+		User:   username,
+		Method: "web",
+		Error:  err.Error(),
+	}
+	if err := sql.RecordUserLoginFailure(ctx, s.server.execCfg, &info, wrongPassword); err != nil {
+		log.Warningf(ctx, "unable to record the failed login of user %s: %v", username, err)
+	}
+}
+
 // newAuthSession attempts to create a new authentication session for the given
 // user. If successful, returns the ID and secret value for the new session.
 func (s *authenticationServer) newAuthSession(
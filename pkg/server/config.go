@@ -165,6 +165,12 @@ type Config struct {
 	// SQLAuditLogDirName is the target directory name for SQL audit logs.
 	SQLAuditLogDirName *log.DirName
 
+	// SQLAuditLogFileMaxSize and SQLAuditLogDirMaxSize are the maximum size
+	// of a SQL audit log file and the maximum combined size of the SQL audit
+	// log files. Zero defaults to the limits of the main log.
+	SQLAuditLogFileMaxSize int64
+	SQLAuditLogDirMaxSize  int64
+
 	// SQLTableStatCacheSize is the size (number of tables) of the table
 	// statistics cache.
 	SQLTableStatCacheSize int
@@ -234,6 +240,11 @@ type Config struct {
 	// actions.
 	EventLogEnabled bool
 
+	// DisableTimeSeriesRecording prevents the server from recording its own
+	// metrics as time series. Time series written by other means, e.g. loaded
+	// from a dump, are still stored and can be queried.
+	DisableTimeSeriesRecording bool
+
 	// ListeningURLFile indicates the file to which the server writes
 	// its listening URL when it is ready.
 	ListeningURLFile string
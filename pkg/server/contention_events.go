@@ -0,0 +1,115 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// ContentionEvents returns the contention events aggregated by the requested
+// node, or by all the nodes if no node is requested.
+func (s *statusServer) ContentionEvents(
+	ctx context.Context, req *serverpb.ContentionEventsRequest,
+) (*serverpb.ContentionEventsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	localReq := &serverpb.ContentionEventsRequest{
+		NodeID: "local",
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return s.ContentionEventsLocal(ctx)
+		}
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ContentionEvents(ctx, localReq)
+	}
+
+	response := &serverpb.ContentionEventsResponse{}
+	nodeContentionEvents := func(ctx context.Context, status serverpb.StatusClient) (interface{}, error) {
+		return status.ContentionEvents(ctx, localReq)
+	}
+	if err := s.iterateNodes(ctx, "contention events",
+		nodeContentionEvents,
+		func(nodeID roachpb.NodeID, resp interface{}) {
+			response.Events = append(response.Events, resp.(*serverpb.ContentionEventsResponse).Events...)
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			response.Errors = append(response.Errors,
+				serverpb.ContentionEventsError{NodeID: nodeID, Message: err.Error()})
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(response.Events, func(i, j int) bool {
+		return response.Events[i].Timestamp.After(response.Events[j].Timestamp)
+	})
+	sort.Slice(response.Errors, func(i, j int) bool {
+		return response.Errors[i].NodeID < response.Errors[j].NodeID
+	})
+	return response, nil
+}
+
+// ContentionEventsLocal returns the contention events aggregated by this
+// node.
+func (s *statusServer) ContentionEventsLocal(
+	ctx context.Context,
+) (*serverpb.ContentionEventsResponse, error) {
+	includeKeys := debug.GatewayRemoteAllowed(ctx, s.st)
+	nodeID := s.gossip.NodeID.Get()
+
+	response := &serverpb.ContentionEventsResponse{}
+	for _, event := range s.contentionEvents.Events() {
+		contentionEvent := serverpb.ContentionEvent{
+			NodeID:        nodeID,
+			StoreID:       event.StoreID,
+			BlockingTxnID: event.BlockingTxnID,
+			BlockedTxnID:  event.BlockedTxnID,
+			Timestamp:     event.Timestamp,
+			DurationNanos: event.Duration.Nanoseconds(),
+			Count:         event.Count,
+		}
+		if includeKeys {
+			contentionEvent.Key = event.Key
+			contentionEvent.PrettyKey = event.Key.String()
+		} else {
+			contentionEvent.PrettyKey = omittedKeyStr
+		}
+		if _, tableID, indexID, err := sqlbase.DecodeTableIDIndexID(event.Key); err == nil {
+			contentionEvent.TableID = uint32(tableID)
+			contentionEvent.IndexID = uint32(indexID)
+		}
+		response.Events = append(response.Events, contentionEvent)
+	}
+	return response, nil
+}
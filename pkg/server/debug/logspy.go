@@ -187,7 +187,8 @@ func (spy *logSpy) run(ctx context.Context, w io.Writer, opts logSpyOptions) (er
 	}
 
 	spy.setIntercept(ctx, func(entry log.Entry) {
-		if re := opts.Grep.re; re != nil && !re.MatchString(entry.Message) && !re.MatchString(entry.File) {
+		if re := opts.Grep.re; re != nil && !re.MatchString(entry.Message) &&
+			!re.MatchString(entry.File) && !re.MatchString(entry.Tags) {
 			return
 		}
 
@@ -0,0 +1,194 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+)
+
+// defaultHotRangesLimit is the number of ranges returned per store for each
+// of QPS and write throughput when the request doesn't specify a limit.
+const defaultHotRangesLimit = 10
+
+// HotRanges returns the ranges with the highest load on each store of the
+// requested node, or of all the nodes if no node is requested.
+func (s *statusServer) HotRanges(
+	ctx context.Context, req *serverpb.HotRangesRequest,
+) (*serverpb.HotRangesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	localReq := &serverpb.HotRangesRequest{
+		NodeID: "local",
+		Limit:  req.Limit,
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return s.HotRangesLocal(ctx, req.Limit)
+		}
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.HotRanges(ctx, localReq)
+	}
+
+	response := &serverpb.HotRangesResponse{}
+	nodeHotRanges := func(ctx context.Context, status serverpb.StatusClient) (interface{}, error) {
+		return status.HotRanges(ctx, localReq)
+	}
+	if err := s.iterateNodes(ctx, "hot ranges",
+		nodeHotRanges,
+		func(nodeID roachpb.NodeID, resp interface{}) {
+			response.Ranges = append(response.Ranges, resp.(*serverpb.HotRangesResponse).Ranges...)
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			response.Errors = append(response.Errors,
+				serverpb.HotRangesError{NodeID: nodeID, Message: err.Error()})
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(response.Ranges, func(i, j int) bool {
+		a, b := &response.Ranges[i], &response.Ranges[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		if a.StoreID != b.StoreID {
+			return a.StoreID < b.StoreID
+		}
+		return a.QueriesPerSecond > b.QueriesPerSecond
+	})
+	sort.Slice(response.Errors, func(i, j int) bool {
+		return response.Errors[i].NodeID < response.Errors[j].NodeID
+	})
+	return response, nil
+}
+
+// HotRangesLocal returns the ranges with the highest load on each store of
+// this node.
+func (s *statusServer) HotRangesLocal(
+	ctx context.Context, limit int32,
+) (*serverpb.HotRangesResponse, error) {
+	if limit <= 0 {
+		limit = defaultHotRangesLimit
+	}
+	includeKeys := debug.GatewayRemoteAllowed(ctx, s.st)
+	namer := hotRangeNamer{db: s.db, descs: make(map[sqlbase.ID]*sqlbase.Descriptor)}
+	nodeID := s.gossip.NodeID.Get()
+
+	response := &serverpb.HotRangesResponse{}
+	err := s.stores.VisitStores(func(store *storage.Store) error {
+		for _, info := range store.HottestReplicas(int(limit)) {
+			hotRange := serverpb.HotRange{
+				NodeID:              nodeID,
+				StoreID:             store.StoreID(),
+				RangeID:             info.Desc.RangeID,
+				QueriesPerSecond:    info.QueriesPerSecond,
+				WriteBytesPerSecond: info.WriteBytesPerSecond,
+			}
+			if includeKeys {
+				hotRange.StartKey = info.Desc.StartKey.String()
+				hotRange.EndKey = info.Desc.EndKey.String()
+			} else {
+				hotRange.StartKey = omittedKeyStr
+				hotRange.EndKey = omittedKeyStr
+			}
+			if err := namer.name(ctx, &hotRange, info.Desc.StartKey); err != nil {
+				return err
+			}
+			response.Ranges = append(response.Ranges, hotRange)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	return response, nil
+}
+
+// hotRangeNamer fills in the names of the database, table and index the start
+// keys of hot ranges belong to. It caches the descriptors it reads.
+type hotRangeNamer struct {
+	db    *client.DB
+	descs map[sqlbase.ID]*sqlbase.Descriptor
+}
+
+func (n *hotRangeNamer) descriptor(ctx context.Context, id sqlbase.ID) (*sqlbase.Descriptor, error) {
+	if desc, ok := n.descs[id]; ok {
+		return desc, nil
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := n.db.GetProto(ctx, sqlbase.MakeDescMetadataKey(id), desc); err != nil {
+		return nil, err
+	}
+	n.descs[id] = desc
+	return desc, nil
+}
+
+func (n *hotRangeNamer) name(
+	ctx context.Context, hotRange *serverpb.HotRange, startKey roachpb.RKey,
+) error {
+	_, id, err := keys.DecodeTablePrefix(startKey.AsRawKey())
+	if err != nil {
+		// The range doesn't start in the SQL keyspace.
+		return nil
+	}
+	desc, err := n.descriptor(ctx, sqlbase.ID(id))
+	if err != nil {
+		return err
+	}
+	if db := desc.GetDatabase(); db != nil {
+		hotRange.DatabaseName = db.Name
+		return nil
+	}
+	table := desc.GetTable()
+	if table == nil {
+		// The table was dropped.
+		return nil
+	}
+	hotRange.TableName = table.Name
+	parent, err := n.descriptor(ctx, table.ParentID)
+	if err != nil {
+		return err
+	}
+	if db := parent.GetDatabase(); db != nil {
+		hotRange.DatabaseName = db.Name
+	}
+	if _, _, indexID, err := sqlbase.DecodeTableIDIndexID(startKey.AsRawKey()); err == nil {
+		if index, err := table.FindIndexByID(indexID); err == nil {
+			hotRange.IndexName = index.Name
+		}
+	}
+	return nil
+}
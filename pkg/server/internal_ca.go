@@ -0,0 +1,429 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var (
+	internalCAEnabled = settings.RegisterBoolSetting(
+		"server.internal_ca.enabled",
+		"if set, nodes automatically renew their certificates with the built-in certificate "+
+			"authority of the nodes started with the CA key",
+		false,
+	)
+	internalCANodeCertLifetime = settings.RegisterValidatedDurationSetting(
+		"server.internal_ca.node_cert_lifetime",
+		"lifetime of the node certificates issued by the built-in certificate authority",
+		30*24*time.Hour,
+		func(v time.Duration) error {
+			if v < time.Hour {
+				return errors.Errorf("cannot set server.internal_ca.node_cert_lifetime to less than 1h: %s", v)
+			}
+			return nil
+		},
+	)
+	internalCARenewalWindow = settings.RegisterNonNegativeDurationSetting(
+		"server.internal_ca.renewal_window",
+		"nodes renew their certificates when they expire within this duration",
+		7*24*time.Hour,
+	)
+)
+
+var (
+	metaCertificateNextRenewal = metric.Metadata{
+		Name:        "security.certificate.renewal.next",
+		Help:        "Time at which the node certificate is due for renewal. 0 means renewal is disabled.",
+		Measurement: "Certificate Renewal",
+		Unit:        metric.Unit_TIMESTAMP_SEC,
+	}
+	metaCertificateRenewals = metric.Metadata{
+		Name:        "security.certificate.renewal.success",
+		Help:        "Number of successful renewals of the node certificate",
+		Measurement: "Certificate Renewals",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaCertificateRenewalErrors = metric.Metadata{
+		Name:        "security.certificate.renewal.error",
+		Help:        "Number of failed renewals of the node certificate",
+		Measurement: "Certificate Renewals",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaCertificatesSigned = metric.Metadata{
+		Name:        "security.certificate.signed",
+		Help:        "Number of node certificates signed by the built-in certificate authority",
+		Measurement: "Certificates",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// internalCACheckInterval is the interval at which the expiration of the
+// node certificate is checked.
+const internalCACheckInterval = 10 * time.Minute
+
+// internalCAKeyFilename is the name of the file holding the CA key in the
+// auxiliary directory of the first store. It is written through the store's
+// environment, so it is encrypted when encryption at rest is enabled.
+const internalCAKeyFilename = "internal-ca.key"
+
+// internalCAKeySize is the size of the keys of the renewed node
+// certificates, matching the default of `cockroach cert create-node`.
+const internalCAKeySize = 2048
+
+// internalCAMetrics holds the metrics about the renewal of the node
+// certificate. The expiration of the certificates themselves is tracked by
+// security.CertificateMetrics.
+type internalCAMetrics struct {
+	NextRenewal        *metric.Gauge
+	Renewals           *metric.Counter
+	RenewalErrors      *metric.Counter
+	CertificatesSigned *metric.Counter
+}
+
+// internalCA is the built-in certificate authority. While
+// server.internal_ca.enabled is set, every node renews its certificate
+// before it expires: it generates a new key and has a certificate signing
+// request for it signed by a node holding the CA key, then hot-reloads the
+// new certificate. Nodes started with --ca-key keep the CA key in their
+// first store and sign the requests of the other nodes, which authenticate
+// with their current node certificate.
+type internalCA struct {
+	log.AmbientContext
+
+	st      *cluster.Settings
+	cfg     *base.Config
+	metrics internalCAMetrics
+
+	mu struct {
+		syncutil.Mutex
+		// caCert and caKey are nil if this node doesn't hold the CA key.
+		caCert *x509.Certificate
+		caKey  crypto.PrivateKey
+		// nextRenewal is zero if renewal is disabled or the node certificate
+		// couldn't be examined.
+		nextRenewal time.Time
+		// renewalErr is the error of the last failed renewal, if any.
+		renewalErr error
+	}
+}
+
+func newInternalCA(ambient log.AmbientContext, st *cluster.Settings, cfg *base.Config) *internalCA {
+	ambient.AddLogTag("internal-ca", nil)
+	return &internalCA{
+		AmbientContext: ambient,
+		st:             st,
+		cfg:            cfg,
+		metrics: internalCAMetrics{
+			NextRenewal:        metric.NewGauge(metaCertificateNextRenewal),
+			Renewals:           metric.NewCounter(metaCertificateRenewals),
+			RenewalErrors:      metric.NewCounter(metaCertificateRenewalErrors),
+			CertificatesSigned: metric.NewCounter(metaCertificatesSigned),
+		},
+	}
+}
+
+// start loads the CA key, importing it into eng first if the node was
+// started with --ca-key, and starts the worker renewing the node
+// certificate. The status server is used to reach the nodes holding the CA
+// key.
+func (ca *internalCA) start(
+	ctx context.Context, stopper *stop.Stopper, eng engine.Engine, status *statusServer,
+) error {
+	if ca.cfg.Insecure {
+		return nil
+	}
+	ctx = ca.AnnotateCtx(ctx)
+	if err := ca.loadKey(eng); err != nil {
+		return err
+	}
+
+	// The node certificate is also checked as soon as renewal is enabled or
+	// the renewal window changes.
+	settingsCh := make(chan struct{}, 1)
+	onChange := func() {
+		select {
+		case settingsCh <- struct{}{}:
+		default:
+		}
+	}
+	internalCAEnabled.SetOnChange(&ca.st.SV, onChange)
+	internalCARenewalWindow.SetOnChange(&ca.st.SV, onChange)
+
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(0)
+		for {
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-settingsCh:
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+			ca.maybeRenew(ctx, status)
+			timer.Reset(internalCACheckInterval)
+		}
+	})
+	return nil
+}
+
+// loadKey loads the CA key from eng. If the node was started with
+// --ca-key, the key is verified against the CA certificate and stored in
+// eng beforehand.
+func (ca *internalCA) loadKey(eng engine.Engine) error {
+	cm, err := ca.cfg.GetCertificateManager()
+	if err != nil {
+		return err
+	}
+	caCert := cm.CACert()
+	if caCert == nil || caCert.Error != nil {
+		return errors.New("CA certificate could not be loaded")
+	}
+	filename := filepath.Join(eng.GetAuxiliaryDir(), internalCAKeyFilename)
+
+	var keyPEM []byte
+	if ca.cfg.SSLCAKey != "" {
+		if keyPEM, err = ioutil.ReadFile(ca.cfg.SSLCAKey); err != nil {
+			return errors.Wrapf(err, "could not read CA key")
+		}
+		if _, err := tls.X509KeyPair(caCert.FileContents, keyPEM); err != nil {
+			return errors.Wrapf(err, "CA key %s does not match the CA certificate", ca.cfg.SSLCAKey)
+		}
+		if err := writeEngineFile(eng, filename, keyPEM); err != nil {
+			return errors.Wrapf(err, "could not store CA key")
+		}
+	} else {
+		keyPEM, err = eng.ReadFile(filename)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "could not read CA key")
+		}
+	}
+
+	pair, err := tls.X509KeyPair(caCert.FileContents, keyPEM)
+	if err != nil {
+		return errors.Wrapf(err, "stored CA key does not match the CA certificate")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return err
+	}
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.mu.caCert, ca.mu.caKey = cert, pair.PrivateKey
+	return nil
+}
+
+// writeEngineFile writes data to filename through the environment of eng.
+func writeEngineFile(eng engine.Engine, filename string, data []byte) error {
+	f, err := eng.OpenFile(filename)
+	if err != nil {
+		return err
+	}
+	err = f.Append(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// hasKey returns whether this node holds the CA key.
+func (ca *internalCA) hasKey() bool {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return ca.mu.caKey != nil
+}
+
+// sign signs the certificate signing request of a node. The new certificate
+// can only be valid for the hosts the current certificate of the node,
+// requester, is valid for. A nil requester designates this node.
+func (ca *internalCA) sign(csr []byte, requester *x509.Certificate) ([]byte, error) {
+	if !internalCAEnabled.Get(&ca.st.SV) {
+		return nil, errors.New("the built-in certificate authority is disabled")
+	}
+	ca.mu.Lock()
+	caCert, caKey := ca.mu.caCert, ca.mu.caKey
+	ca.mu.Unlock()
+	if caKey == nil {
+		return nil, errors.New("this node does not hold the CA key")
+	}
+	if requester == nil {
+		cm, err := ca.cfg.GetCertificateManager()
+		if err != nil {
+			return nil, err
+		}
+		nodeCert := cm.NodeCert()
+		if nodeCert == nil || nodeCert.Error != nil {
+			return nil, errors.New("node certificate could not be loaded")
+		}
+		requester = nodeCert.ParsedCertificates[0]
+	}
+	cert, err := security.SignNodeCSR(
+		caCert, caKey, csr, security.CertificateHosts(requester), internalCANodeCertLifetime.Get(&ca.st.SV),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ca.metrics.CertificatesSigned.Inc(1)
+	return cert, nil
+}
+
+// renewalStatus returns the time at which the node certificate is due for
+// renewal and the error of the last failed renewal.
+func (ca *internalCA) renewalStatus() (time.Time, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return ca.mu.nextRenewal, ca.mu.renewalErr
+}
+
+// maybeRenew renews the node certificate if renewal is enabled and the
+// certificate expires within the renewal window.
+func (ca *internalCA) maybeRenew(ctx context.Context, status *statusServer) {
+	var nextRenewal time.Time
+	var err error
+	defer func() {
+		ca.mu.Lock()
+		defer ca.mu.Unlock()
+		ca.mu.nextRenewal = nextRenewal
+		if err != nil {
+			ca.mu.renewalErr = err
+		}
+		if nextRenewal.IsZero() {
+			ca.metrics.NextRenewal.Update(0)
+		} else {
+			ca.metrics.NextRenewal.Update(nextRenewal.Unix())
+		}
+	}()
+
+	if !internalCAEnabled.Get(&ca.st.SV) {
+		return
+	}
+	cm, err := ca.cfg.GetCertificateManager()
+	if err != nil {
+		return
+	}
+	nodeCert := cm.NodeCert()
+	if nodeCert == nil || nodeCert.Error != nil {
+		err = errors.New("node certificate could not be loaded")
+		return
+	}
+	nextRenewal = nodeCert.ExpirationTime.Add(-internalCARenewalWindow.Get(&ca.st.SV))
+	if timeutil.Now().Before(nextRenewal) {
+		return
+	}
+
+	log.Infof(ctx, "renewing node certificate expiring at %s", nodeCert.ExpirationTime)
+	if err = ca.renew(ctx, cm, nodeCert.ParsedCertificates[0], status); err != nil {
+		log.Warningf(ctx, "could not renew node certificate: %s", err)
+		ca.metrics.RenewalErrors.Inc(1)
+		return
+	}
+	log.Infof(ctx, "renewed node certificate, now expiring at %s", cm.NodeCert().ExpirationTime)
+	ca.metrics.Renewals.Inc(1)
+	nextRenewal = cm.NodeCert().ExpirationTime.Add(-internalCARenewalWindow.Get(&ca.st.SV))
+
+	ca.mu.Lock()
+	ca.mu.renewalErr = nil
+	ca.mu.Unlock()
+}
+
+// renew replaces the node certificate with a new certificate, valid for the
+// same hosts as the current one.
+func (ca *internalCA) renew(
+	ctx context.Context, cm *security.CertificateManager, current *x509.Certificate, status *statusServer,
+) error {
+	key, err := rsa.GenerateKey(rand.Reader, internalCAKeySize)
+	if err != nil {
+		return err
+	}
+	csr, err := security.GenerateNodeCSR(key, security.CertificateHosts(current))
+	if err != nil {
+		return err
+	}
+
+	var cert []byte
+	if ca.hasKey() {
+		cert, err = ca.sign(csr, current)
+	} else {
+		cert, err = ca.signRemotely(ctx, csr, status)
+	}
+	if err != nil {
+		return err
+	}
+	return cm.InstallNodeCertificate(cert, key)
+}
+
+// signRemotely has the certificate signing request signed by one of the
+// live nodes holding the CA key.
+func (ca *internalCA) signRemotely(
+	ctx context.Context, csr []byte, status *statusServer,
+) ([]byte, error) {
+	var lastErr error
+	for nodeID, livenessStatus := range status.nodeLiveness.GetLivenessStatusMap() {
+		if livenessStatus != storage.NodeLivenessStatus_LIVE || nodeID == status.gossip.NodeID.Get() {
+			continue
+		}
+		resp, err := ca.signOnNode(ctx, nodeID, csr, status)
+		if err == nil {
+			return resp.Certificate, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no live node")
+	}
+	return nil, errors.Wrap(lastErr, "no node could sign the node certificate")
+}
+
+func (ca *internalCA) signOnNode(
+	ctx context.Context, nodeID roachpb.NodeID, csr []byte, status *statusServer,
+) (*serverpb.SignNodeCertificateResponse, error) {
+	client, err := status.dialNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return client.SignNodeCertificate(ctx, &serverpb.SignNodeCertificateRequest{CSR: csr})
+}
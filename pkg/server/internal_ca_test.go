@@ -0,0 +1,134 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TestInternalCARenewal verifies that the nodes of a cluster renew their
+// certificates with the built-in certificate authority of the node holding
+// the CA key, and serve the renewed certificates without restarting.
+func TestInternalCARenewal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetAssetLoader()
+	defer security.SetAssetLoader(securitytest.EmbeddedAssets)
+
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	caKeyPath := filepath.Join(baseDir, security.EmbeddedCAKey)
+
+	// Every node has its own certs directory with a node certificate due for
+	// renewal. Only the first node is started with the CA key.
+	const numNodes = 3
+	const nodeCertLifetime = 48 * time.Hour
+	serverArgs := make(map[int]base.TestServerArgs, numNodes)
+	var caCert []byte
+	for i := 0; i < numNodes; i++ {
+		certsDir := filepath.Join(baseDir, fmt.Sprintf("certs%d", i))
+		if i == 0 {
+			if err := security.CreateCAPair(
+				certsDir, caKeyPath, 512, 96*time.Hour, false /* allowKeyReuse */, false, /* overwrite */
+			); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			if caCert, err = ioutil.ReadFile(filepath.Join(certsDir, security.EmbeddedCACert)); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			if err := os.MkdirAll(certsDir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(certsDir, security.EmbeddedCACert), caCert, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := security.CreateNodePair(
+			certsDir, caKeyPath, 512, nodeCertLifetime, false /* overwrite */, []string{"127.0.0.1", "localhost"},
+		); err != nil {
+			t.Fatal(err)
+		}
+		serverArgs[i] = base.TestServerArgs{SSLCertsDir: certsDir}
+	}
+	args := serverArgs[0]
+	args.SSLCAKey = caKeyPath
+	serverArgs[0] = args
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, numNodes, base.TestClusterArgs{ServerArgsPerNode: serverArgs})
+	defer tc.Stopper().Stop(ctx)
+
+	// Renew the certificates expiring within 72 hours, which excludes the
+	// renewed certificates.
+	ie := tc.Server(0).InternalExecutor().(*sql.InternalExecutor)
+	for _, stmt := range []string{
+		`SET CLUSTER SETTING server.internal_ca.renewal_window = '72h'`,
+		`SET CLUSTER SETTING server.internal_ca.enabled = true`,
+	} {
+		if _, err := ie.Exec(ctx, "test", nil /* txn */, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	minExpiration := timeutil.Now().Add(nodeCertLifetime + time.Hour)
+	for i := 0; i < numNodes; i++ {
+		ts := tc.Server(i).(*server.TestServer)
+		testutils.SucceedsSoon(t, func() error {
+			cm, err := ts.Cfg.GetCertificateManager()
+			if err != nil {
+				return err
+			}
+			if expiration := cm.NodeCert().ExpirationTime; expiration.Before(minExpiration) {
+				return errors.Errorf("n%d: node certificate expiring at %s was not renewed", i+1, expiration)
+			}
+			return nil
+		})
+
+		// The server presents the renewed certificate to new connections.
+		conn, err := tls.Dial("tcp", ts.ServingAddr(), &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expiration := conn.ConnectionState().PeerCertificates[0].NotAfter
+		if err := conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if expiration.Before(minExpiration) {
+			t.Errorf("n%d: expected the renewed certificate to be served, got one expiring at %s",
+				i+1, expiration)
+		}
+	}
+}
@@ -883,6 +883,8 @@ func (n *Node) writeNodeStatus(ctx context.Context, alertTTL time.Duration) erro
 			// state (since it'll be incremented every ~10s).
 		}
 
+		n.evaluateAlerts(ctx, *nodeStatus)
+
 		err = n.recorder.WriteNodeStatus(ctx, n.storeCfg.DB, *nodeStatus)
 	}); runErr != nil {
 		err = runErr
@@ -890,6 +892,56 @@ func (n *Node) writeNodeStatus(ctx context.Context, alertTTL time.Duration) erro
 	return err
 }
 
+// evaluateAlerts evaluates the alert rules against the given node status and
+// records the alerts which started or stopped firing in the event log.
+func (n *Node) evaluateAlerts(ctx context.Context, nodeStatus status.NodeStatus) {
+	rules, err := status.ParseAlertRules(status.AlertRules.Get(&n.storeCfg.Settings.SV))
+	if err != nil {
+		log.Warningf(ctx, "invalid alert rules: %s", err)
+		return
+	}
+	fired, resolved := n.recorder.EvaluateAlerts(timeutil.Now(), rules, nodeStatus)
+	for _, alert := range fired {
+		log.Warningf(ctx, "alert firing on store %d: %s (value: %g)", alert.StoreID, alert.Rule, alert.Value)
+		n.recordAlertEvent(ctx, sql.EventLogAlertFiring, alert)
+	}
+	for _, alert := range resolved {
+		log.Infof(ctx, "alert resolved on store %d: %s", alert.StoreID, alert.Rule)
+		n.recordAlertEvent(ctx, sql.EventLogAlertResolved, alert)
+	}
+}
+
+// recordAlertEvent begins an asynchronous task which attempts to log an alert
+// event.
+func (n *Node) recordAlertEvent(
+	ctx context.Context, logEventType sql.EventLogType, alert status.Alert,
+) {
+	if !n.storeCfg.LogRangeEvents {
+		return
+	}
+	if err := n.stopper.RunAsyncTask(ctx, "record-alert-event", func(ctx context.Context) {
+		if err := n.storeCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return n.eventLogger.InsertEventRecord(
+				ctx,
+				txn,
+				logEventType,
+				int32(n.Descriptor.NodeID),
+				int32(n.Descriptor.NodeID),
+				struct {
+					Rule    string
+					StoreID roachpb.StoreID
+					Value   float64
+					Since   time.Time
+				}{alert.Rule, alert.StoreID, alert.Value, alert.Since},
+			)
+		}); err != nil {
+			log.Warningf(ctx, "%s: unable to log %s event: %s", n, logEventType, err)
+		}
+	}); err != nil {
+		log.Warningf(ctx, "%s: unable to log %s event: %s", n, logEventType, err)
+	}
+}
+
 // recordJoinEvent begins an asynchronous task which attempts to log a "node
 // join" or "node restart" event. This query will retry until it succeeds or the
 // server stops.
@@ -0,0 +1,226 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package profiler implements a continuous profiler which periodically
+// captures CPU, heap and goroutine profiles and keeps the most recent ones
+// within a disk budget.
+package profiler
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var (
+	// Enabled controls whether profiles are captured. It is exported so that
+	// SQL execution only labels its goroutines while profiles are captured.
+	Enabled = settings.RegisterBoolSetting(
+		"server.continuous_profiling.enabled",
+		"if set, CPU, heap and goroutine profiles are periodically captured "+
+			"and stored in the auxiliary directory of the first store",
+		false,
+	)
+	profileInterval = settings.RegisterValidatedDurationSetting(
+		"server.continuous_profiling.interval",
+		"interval at which profiles are captured",
+		time.Minute,
+		func(v time.Duration) error {
+			if v < time.Second {
+				return errors.Errorf("cannot set server.continuous_profiling.interval to less than 1s: %s", v)
+			}
+			return nil
+		},
+	)
+	cpuProfileDuration = settings.RegisterNonNegativeDurationSetting(
+		"server.continuous_profiling.cpu_profile_duration",
+		"duration of each CPU profile (0 to disable CPU profiles)",
+		10*time.Second,
+	)
+	maxDiskUsage = settings.RegisterByteSizeSetting(
+		"server.continuous_profiling.max_disk_usage",
+		"maximum disk space used by profiles; the oldest profiles are removed beyond it",
+		128<<20, // 128 MiB
+	)
+)
+
+// Label keys used to attribute the samples of the profiles to SQL
+// statements.
+const (
+	AppNameLabel = "appname"
+	StmtLabel    = "stmt"
+)
+
+// The prefixes of the names of the profile files, which are followed by the
+// time at which the capture of the profile started.
+const (
+	cpuProfilePrefix       = "cpuprof."
+	heapProfilePrefix      = "memprof."
+	goroutineProfilePrefix = "goroutine."
+)
+
+const timeFormat = "2006-01-02T15_04_05.000"
+
+// ContinuousProfiler periodically captures CPU, heap and goroutine profiles
+// while Enabled is set. The profiles are written to a directory in which the
+// oldest profiles are removed when their total size exceeds maxDiskUsage.
+type ContinuousProfiler struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		// dir is empty until the profiler is started.
+		dir string
+	}
+}
+
+// NewContinuousProfiler creates a new profiler which doesn't capture profiles
+// until it is started.
+func NewContinuousProfiler(st *cluster.Settings) *ContinuousProfiler {
+	return &ContinuousProfiler{st: st}
+}
+
+// Start creates the profiles directory in auxDir and starts the worker which
+// captures the profiles.
+func (p *ContinuousProfiler) Start(ctx context.Context, stopper *stop.Stopper, auxDir string) error {
+	if auxDir == "" {
+		return errors.New("directory to store profiles could not be determined")
+	}
+	dir := filepath.Join(auxDir, "profiles")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.mu.dir = dir
+	p.mu.Unlock()
+
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(0)
+		for {
+			select {
+			case <-timer.C:
+				timer.Read = true
+				if Enabled.Get(&p.st.SV) {
+					p.captureProfiles(ctx, dir, stopper.ShouldQuiesce())
+					gcProfiles(ctx, dir, maxDiskUsage.Get(&p.st.SV))
+				}
+				timer.Reset(profileInterval.Get(&p.st.SV))
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// Dir returns the directory containing the profiles, or an empty string if
+// the profiler hasn't been started.
+func (p *ContinuousProfiler) Dir() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mu.dir
+}
+
+// captureProfiles captures a CPU profile, which blocks for
+// cpuProfileDuration unless quiesce is closed, followed by heap and goroutine
+// profiles.
+func (p *ContinuousProfiler) captureProfiles(ctx context.Context, dir string, quiesce <-chan struct{}) {
+	suffix := timeutil.Now().Format(timeFormat)
+	if d := cpuProfileDuration.Get(&p.st.SV); d > 0 {
+		writeProfile(ctx, filepath.Join(dir, cpuProfilePrefix+suffix), func(w io.Writer) error {
+			// StartCPUProfile fails if a CPU profile is already being captured,
+			// e.g. through the /debug/pprof/profile endpoint.
+			if err := pprof.StartCPUProfile(w); err != nil {
+				return err
+			}
+			select {
+			case <-time.After(d):
+			case <-quiesce:
+			}
+			pprof.StopCPUProfile()
+			return nil
+		})
+	}
+	writeProfile(ctx, filepath.Join(dir, heapProfilePrefix+suffix), func(w io.Writer) error {
+		return pprof.Lookup("heap").WriteTo(w, 0)
+	})
+	writeProfile(ctx, filepath.Join(dir, goroutineProfilePrefix+suffix), func(w io.Writer) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 0)
+	})
+}
+
+// writeProfile creates the file at path and writes a profile to it. The file
+// is removed if the profile can't be written.
+func writeProfile(ctx context.Context, path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Warningf(ctx, "error creating profile %s: %s", path, err)
+		return
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Warningf(ctx, "error writing profile %s: %s", path, err)
+		if err := os.Remove(path); err != nil {
+			log.Warning(ctx, err)
+		}
+	}
+}
+
+// gcProfiles removes the oldest profiles in dir until their total size is at
+// most maxSize. The most recent profile is always kept.
+func gcProfiles(ctx context.Context, dir string, maxSize int64) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.Warning(ctx, err)
+		return
+	}
+	var size int64
+	var profiles []os.FileInfo
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		size += f.Size()
+		profiles = append(profiles, f)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ModTime().Before(profiles[j].ModTime())
+	})
+	for i := 0; size > maxSize && i < len(profiles)-1; i++ {
+		if err := os.Remove(filepath.Join(dir, profiles[i].Name())); err != nil {
+			log.Info(ctx, err)
+			continue
+		}
+		size -= profiles[i].Size()
+	}
+}
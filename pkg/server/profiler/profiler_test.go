@@ -0,0 +1,109 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package profiler
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func listProfiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCaptureProfiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	st := cluster.MakeTestingClusterSettings()
+	cpuProfileDuration.Override(&st.SV, 10*time.Millisecond)
+	p := NewContinuousProfiler(st)
+	p.captureProfiles(context.Background(), dir, nil)
+
+	names := listProfiles(t, dir)
+	if len(names) != 3 {
+		t.Fatalf("expected 3 profiles, got %v", names)
+	}
+	for i, prefix := range []string{cpuProfilePrefix, goroutineProfilePrefix, heapProfilePrefix} {
+		if !strings.HasPrefix(names[i], prefix) {
+			t.Errorf("expected profile with prefix %s, got %s", prefix, names[i])
+		}
+		fi, err := os.Stat(filepath.Join(dir, names[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() == 0 {
+			t.Errorf("expected non-empty profile %s", names[i])
+		}
+	}
+}
+
+func TestGCProfiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	start := time.Now()
+	for i, name := range []string{"c", "a", "d", "b"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, make([]byte, 10), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := start.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	gcProfiles(ctx, dir, 40)
+	if e, a := []string{"a", "b", "c", "d"}, listProfiles(t, dir); !reflect.DeepEqual(e, a) {
+		t.Fatalf("expected %v, got %v", e, a)
+	}
+	// The oldest profiles are removed first.
+	gcProfiles(ctx, dir, 25)
+	if e, a := []string{"b", "d"}, listProfiles(t, dir); !reflect.DeepEqual(e, a) {
+		t.Fatalf("expected %v, got %v", e, a)
+	}
+	// The most recent profile is kept even if it exceeds the budget.
+	gcProfiles(ctx, dir, 0)
+	if e, a := []string{"b"}, listProfiles(t, dir); !reflect.DeepEqual(e, a) {
+		t.Fatalf("expected %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+)
+
+// ProfileFiles returns the profiles captured by the continuous profiler of
+// the requested node.
+func (s *statusServer) ProfileFiles(
+	ctx context.Context, req *serverpb.ProfileFilesRequest,
+) (*serverpb.ProfileFilesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ProfileFiles(ctx, req)
+	}
+
+	dir := s.profiler.Dir()
+	if dir == "" {
+		return nil, status.Errorf(codes.Unavailable, "continuous profiler not started")
+	}
+	if req.Name != "" && filepath.Base(req.Name) != req.Name {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid profile name %q", req.Name)
+	}
+
+	var files []os.FileInfo
+	if req.Name != "" {
+		fi, err := os.Stat(filepath.Join(dir, req.Name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, status.Errorf(codes.NotFound, "profile %q not found", req.Name)
+			}
+			return nil, err
+		}
+		files = append(files, fi)
+	} else {
+		if files, err = ioutil.ReadDir(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &serverpb.ProfileFilesResponse{}
+	for _, fi := range files {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		file := serverpb.ProfileFile{Name: fi.Name(), FileSize: fi.Size()}
+		if !req.ListOnly {
+			contents, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				// The profile may have been removed since it was listed.
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			file.Contents = contents
+		}
+		response.Files = append(response.Files, file)
+	}
+	return response, nil
+}
@@ -55,6 +55,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/heapprofiler"
+	"github.com/cockroachdb/cockroach/pkg/server/profiler"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -70,6 +71,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/container"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -150,6 +152,8 @@ type Server struct {
 	registry           *metric.Registry
 	recorder           *status.MetricsRecorder
 	runtime            status.RuntimeStatSampler
+	profiler           *profiler.ContinuousProfiler
+	internalCA         *internalCA
 	admin              *adminServer
 	status             *statusServer
 	authentication     *authenticationServer
@@ -163,6 +167,7 @@ type Server struct {
 	leaseMgr           *sql.LeaseManager
 	sessionRegistry    *sql.SessionRegistry
 	jobRegistry        *jobs.Registry
+	protectedtsCache   *protectedts.PollingCache
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
 	adminMemMetrics    sql.MemoryMetrics
@@ -401,6 +406,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// Similarly for execCfg.
 	var execCfg sql.ExecutorConfig
 
+	s.protectedtsCache = protectedts.NewPollingCache(s.db, internalExecutor, st)
+
 	// TODO(bdarnell): make StoreConfig configurable.
 	storeCfg := storage.StoreConfig{
 		Settings:                st,
@@ -422,6 +429,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		SQLExecutor:             internalExecutor,
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		TimeSeriesDataStore:     s.tsDB,
+		ProtectedTimestampCache: s.protectedtsCache,
+		ContentionEvents:        storage.NewContentionEventRegistry(),
 
 		// Initialize the closed timestamp subsystem. Note that it won't
 		// be ready until it is .Start()ed, but the grpc server can be
@@ -498,6 +507,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.distSQLServer = distsqlrun.NewServer(ctx, distSQLCfg)
 	distsqlrun.RegisterDistSQLServer(s.grpc, s.distSQLServer)
 
+	s.profiler = profiler.NewContinuousProfiler(st)
+	s.internalCA = newInternalCA(s.cfg.AmbientCtx, st, s.cfg.Config)
+	s.registry.AddMetricStruct(s.internalCA.metrics)
 	s.admin = newAdminServer(s)
 	s.status = newStatusServer(
 		s.cfg.AmbientCtx,
@@ -506,12 +518,15 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.admin,
 		s.db,
 		s.gossip,
+		s.internalCA,
 		s.recorder,
+		s.profiler,
 		s.nodeLiveness,
 		s.rpcContext,
 		s.node.stores,
 		s.stopper,
 		s.sessionRegistry,
+		storeCfg.ContentionEvents,
 	)
 	s.authentication = newAuthenticationServer(s)
 	for _, gw := range []grpcGatewayServer{s.admin, s.status, s.authentication, &s.tsServer} {
@@ -557,7 +572,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		Clock:                   s.clock,
 		DistSQLSrv:              s.distSQLServer,
 		StatusServer:            s.status,
+		TimeSeriesServer:        &s.tsServer,
 		SessionRegistry:         s.sessionRegistry,
+		Notifications:           sql.NewNotificationRegistry(s.st, s.gossip, &s.nodeIDContainer),
 		JobRegistry:             s.jobRegistry,
 		VirtualSchemas:          virtualSchemas,
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
@@ -594,10 +611,13 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		AuditLogger: log.NewSecondaryLogger(
 			s.cfg.SQLAuditLogDirName, "sql-audit", true /*enableGc*/, true, /*forceSyncWrites*/
 		),
+		AuditConfig: sql.NewAuditConfig(st),
 
 		ConnResultsBufferBytes: s.cfg.ConnResultsBufferBytes,
 	}
 
+	execCfg.AuditLogger.SetRotationPolicy(s.cfg.SQLAuditLogFileMaxSize, s.cfg.SQLAuditLogDirMaxSize)
+
 	if sqlSchemaChangerTestingKnobs := s.cfg.TestingKnobs.SQLSchemaChanger; sqlSchemaChangerTestingKnobs != nil {
 		execCfg.SchemaChangerTestingKnobs = sqlSchemaChangerTestingKnobs.(*sql.SchemaChangerTestingKnobs)
 	} else {
@@ -1471,10 +1491,24 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 	// Begin recording runtime statistics.
 	s.startSampleEnvironment(DefaultMetricsSampleInterval)
 
+	// Begin capturing profiles, when enabled, in the auxiliary directory of the
+	// first store.
+	if err := s.profiler.Start(ctx, s.stopper, s.engines[0].GetAuxiliaryDir()); err != nil {
+		log.Infof(ctx, "could not start continuous profiler due to: %s", err)
+	}
+
+	// Begin renewing the node certificate with the built-in certificate
+	// authority, when enabled. The CA key, if any, is kept in the first store.
+	if err := s.internalCA.start(ctx, s.stopper, s.engines[0], s.status); err != nil {
+		return errors.Wrap(err, "could not start the built-in certificate authority")
+	}
+
 	// Begin recording time series data collected by the status monitor.
-	s.tsDB.PollSource(
-		s.cfg.AmbientCtx, s.recorder, DefaultMetricsSampleInterval, ts.Resolution10s, s.stopper,
-	)
+	if !s.cfg.DisableTimeSeriesRecording {
+		s.tsDB.PollSource(
+			s.cfg.AmbientCtx, s.recorder, DefaultMetricsSampleInterval, ts.Resolution10s, s.stopper,
+		)
+	}
 
 	// Begin recording status summaries.
 	s.node.startWriteNodeStatus(DefaultMetricsSampleInterval)
@@ -1606,6 +1640,11 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		}
 	}
 	log.Infof(ctx, "done ensuring all necessary migrations have run")
+
+	// The protected timestamp records can only be read once the migration
+	// creating their table has run. Until then, the GC queue won't advance
+	// any GC threshold.
+	s.protectedtsCache.Start(ctx, s.stopper)
 	close(serveSQL)
 
 	log.Info(ctx, "serving sql connections")
@@ -19,6 +19,7 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/rsa"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
@@ -38,7 +39,9 @@ import (
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	grpcstatus "google.golang.org/grpc/status"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -52,11 +55,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/diagnosticspb"
+	"github.com/cockroachdb/cockroach/pkg/server/profiler"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -103,6 +109,7 @@ var (
 type metricMarshaler interface {
 	json.Marshaler
 	PrintAsText(io.Writer) error
+	FiringAlerts() []status.Alert
 }
 
 func propagateGatewayMetadata(ctx context.Context) context.Context {
@@ -121,12 +128,19 @@ type statusServer struct {
 	admin           *adminServer
 	db              *client.DB
 	gossip          *gossip.Gossip
+	internalCA      *internalCA
 	metricSource    metricMarshaler
+	profiler        *profiler.ContinuousProfiler
 	nodeLiveness    *storage.NodeLiveness
 	rpcCtx          *rpc.Context
 	stores          *storage.Stores
 	stopper         *stop.Stopper
 	sessionRegistry *sql.SessionRegistry
+	// contentionEvents aggregates the contention events of the stores.
+	contentionEvents *storage.ContentionEventRegistry
+	// cancelLimiter limits the rate of the pgwire CancelRequests for the
+	// sessions of this node.
+	cancelLimiter *pgwirecancel.Limiter
 }
 
 // newStatusServer allocates and returns a statusServer.
@@ -137,27 +151,34 @@ func newStatusServer(
 	adminServer *adminServer,
 	db *client.DB,
 	gossip *gossip.Gossip,
+	internalCA *internalCA,
 	metricSource metricMarshaler,
+	profiler *profiler.ContinuousProfiler,
 	nodeLiveness *storage.NodeLiveness,
 	rpcCtx *rpc.Context,
 	stores *storage.Stores,
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
+	contentionEvents *storage.ContentionEventRegistry,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
-		AmbientContext:  ambient,
-		st:              st,
-		cfg:             cfg,
-		admin:           adminServer,
-		db:              db,
-		gossip:          gossip,
-		metricSource:    metricSource,
-		nodeLiveness:    nodeLiveness,
-		rpcCtx:          rpcCtx,
-		stores:          stores,
-		stopper:         stopper,
-		sessionRegistry: sessionRegistry,
+		AmbientContext:   ambient,
+		st:               st,
+		cfg:              cfg,
+		admin:            adminServer,
+		db:               db,
+		gossip:           gossip,
+		internalCA:       internalCA,
+		metricSource:     metricSource,
+		profiler:         profiler,
+		nodeLiveness:     nodeLiveness,
+		rpcCtx:           rpcCtx,
+		stores:           stores,
+		stopper:          stopper,
+		sessionRegistry:  sessionRegistry,
+		contentionEvents: contentionEvents,
+		cancelLimiter:    pgwirecancel.NewLimiter(),
 	}
 
 	return server
@@ -464,7 +485,14 @@ func (s *statusServer) Certificates(
 		return nil, err
 	}
 
-	cr := &serverpb.CertificatesResponse{}
+	cr := &serverpb.CertificatesResponse{CAKey: s.internalCA.hasKey()}
+	nextRenewal, renewalErr := s.internalCA.renewalStatus()
+	if !nextRenewal.IsZero() {
+		cr.NextRenewal = nextRenewal.Unix()
+	}
+	if renewalErr != nil {
+		cr.RenewalError = renewalErr.Error()
+	}
 	for _, cert := range certs {
 		details := serverpb.CertificateDetails{}
 		switch cert.FileUsage {
@@ -498,6 +526,55 @@ func (s *statusServer) Certificates(
 	return cr, nil
 }
 
+// requireNodeUser returns an error unless the RPC was sent by a node,
+// authenticated by its certificate, or locally. It returns the certificate
+// of the node, which is nil for a local request.
+func requireNodeUser(ctx context.Context) (*x509.Certificate, error) {
+	if grpcutil.IsLocalRequestContext(ctx) {
+		return nil, nil
+	}
+	peer, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("unable to get peer info from context")
+	}
+	tlsInfo, ok := peer.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, errors.New("unable to get TLS info from peer")
+	}
+	certUser, err := security.GetCertificateUser(&tlsInfo.State)
+	if err != nil {
+		return nil, err
+	}
+	if certUser != security.NodeUser {
+		return nil, errors.Errorf("user %s is not allowed", certUser)
+	}
+	return tlsInfo.State.PeerCertificates[0], nil
+}
+
+// SignNodeCertificate signs the certificate signing request of a node
+// renewing its certificate. Only nodes, authenticated by their current
+// certificate, can have their certificate signed, and only for the hosts
+// their current certificate is valid for.
+func (s *statusServer) SignNodeCertificate(
+	ctx context.Context, req *serverpb.SignNodeCertificateRequest,
+) (*serverpb.SignNodeCertificateResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if s.cfg.Insecure {
+		return nil, errors.New("server is in insecure mode, cannot sign certificates")
+	}
+	requester, err := requireNodeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := s.internalCA.sign(req.CSR, requester)
+	if err != nil {
+		log.Warningf(ctx, "could not sign node certificate: %s", err)
+		return nil, grpcstatus.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	return &serverpb.SignNodeCertificateResponse{Certificate: cert}, nil
+}
+
 func formatCertNames(p pkix.Name) string {
 	return fmt.Sprintf("CommonName=%s, Organization=%s", p.CommonName, strings.Join(p.Organization, ","))
 }
@@ -1471,6 +1548,44 @@ func (s *statusServer) CancelQuery(
 	return output, nil
 }
 
+// CancelQueryByKey responds to a pgwire CancelRequest forwarded by the node
+// which received it. The rate of the requests is limited per client on the
+// node of the sessions, and the response doesn't reveal whether the key was
+// valid.
+func (s *statusServer) CancelQueryByKey(
+	ctx context.Context, req *serverpb.CancelQueryByKeyRequest,
+) (*serverpb.CancelQueryByKeyResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if !s.cfg.Insecure {
+		if _, err := requireNodeUser(ctx); err != nil {
+			return nil, grpcstatus.Errorf(codes.PermissionDenied, err.Error())
+		}
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.CancelQueryByKey(ctx, req)
+	}
+
+	if !s.cancelLimiter.Allow(req.ClientAddr) {
+		log.Warningf(ctx, "dropping CancelRequest from %s: too many requests", req.ClientAddr)
+		return &serverpb.CancelQueryByKeyResponse{}, nil
+	}
+	if _, err := s.sessionRegistry.CancelQueryByKey(
+		pgwirecancel.BackendKeyData(req.CancelKey),
+	); err != nil && log.V(1) {
+		log.Infof(ctx, "CancelRequest from %s failed: %v", req.ClientAddr, err)
+	}
+	return &serverpb.CancelQueryByKeyResponse{}, nil
+}
+
 // SpanStats requests the total statistics stored on a node for a given key
 // span, which may include multiple ranges.
 func (s *statusServer) SpanStats(
@@ -1570,6 +1685,15 @@ func (s *statusServer) Stores(
 			storeDetails.TotalBytes = envStats.TotalBytes
 			storeDetails.ActiveKeyFiles = envStats.ActiveKeyFiles
 			storeDetails.ActiveKeyBytes = envStats.ActiveKeyBytes
+
+			for _, u := range rocksdb.GetSSTables().ByDataKey() {
+				storeDetails.DataKeyUsage = append(storeDetails.DataKeyUsage,
+					serverpb.StoreDetails_DataKeyUsage{
+						KeyID: u.KeyID,
+						Files: uint64(u.Files),
+						Bytes: uint64(u.Bytes),
+					})
+			}
 		}
 
 		resp.Stores = append(resp.Stores, storeDetails)
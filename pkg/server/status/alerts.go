@@ -0,0 +1,229 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package status
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// AlertRules is the set of alert rules evaluated by every node against its
+// node and store metrics.
+var AlertRules = settings.RegisterValidatedStringSetting(
+	"server.alerts.rules",
+	"semicolon-separated alert rules of the form '<metric> <op> <threshold> [for <duration>]', "+
+		"e.g. 'ranges.underreplicated > 0 for 5m'",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := ParseAlertRules(s)
+		return err
+	},
+)
+
+// alertOps are the comparison operators supported in alert rules.
+var alertOps = map[string]func(value, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	"=":  func(v, t float64) bool { return v == t },
+	"!=": func(v, t float64) bool { return v != t },
+}
+
+// An AlertRule fires when the value of a metric has satisfied a comparison
+// with a threshold for at least a given duration.
+type AlertRule struct {
+	Metric    string
+	Op        string
+	Threshold float64
+	For       time.Duration
+}
+
+// String returns the canonical representation of the rule, which is also how
+// it is identified in alerts.
+func (r AlertRule) String() string {
+	s := fmt.Sprintf("%s %s %s", r.Metric, r.Op, strconv.FormatFloat(r.Threshold, 'g', -1, 64))
+	if r.For > 0 {
+		s += fmt.Sprintf(" for %s", r.For)
+	}
+	return s
+}
+
+func (r AlertRule) matches(value float64) bool {
+	return alertOps[r.Op](value, r.Threshold)
+}
+
+// ParseAlertRules parses a semicolon or newline separated list of alert rules
+// of the form '<metric> <op> <threshold> [for <duration>]'.
+func ParseAlertRules(s string) ([]AlertRule, error) {
+	var rules []AlertRule
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 && !(len(fields) == 5 && fields[3] == "for") {
+			return nil, errors.Errorf(
+				"invalid alert rule %q: expected '<metric> <op> <threshold> [for <duration>]'", line)
+		}
+		rule := AlertRule{Metric: fields[0], Op: fields[1]}
+		if _, ok := alertOps[rule.Op]; !ok {
+			return nil, errors.Errorf("invalid alert rule %q: unknown operator %q", line, rule.Op)
+		}
+		var err error
+		if rule.Threshold, err = strconv.ParseFloat(fields[2], 64); err != nil {
+			return nil, errors.Wrapf(err, "invalid alert rule %q", line)
+		}
+		if len(fields) == 5 {
+			if rule.For, err = time.ParseDuration(fields[4]); err != nil {
+				return nil, errors.Wrapf(err, "invalid alert rule %q", line)
+			}
+			if rule.For < 0 {
+				return nil, errors.Errorf("invalid alert rule %q: negative duration", line)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// An Alert is an alert rule which fires for the metrics of a node or of one of
+// its stores.
+type Alert struct {
+	Rule string
+	// StoreID is zero if the alert fires for a node metric.
+	StoreID roachpb.StoreID
+	Metric  string
+	// Value is the most recently observed value of the metric.
+	Value float64
+	// Since is the time at which the metric started satisfying the rule.
+	Since time.Time
+}
+
+type alertKey struct {
+	rule    string
+	storeID roachpb.StoreID
+}
+
+// An AlertEvaluator keeps track of the alert rules which are pending or firing
+// for the metrics of a node and its stores.
+type AlertEvaluator struct {
+	mu struct {
+		syncutil.Mutex
+		// pending holds the time at which the metric started satisfying each
+		// rule which is pending or firing.
+		pending map[alertKey]time.Time
+		firing  map[alertKey]Alert
+	}
+}
+
+// NewAlertEvaluator creates a new alert evaluator with no pending or firing
+// alerts.
+func NewAlertEvaluator() *AlertEvaluator {
+	e := &AlertEvaluator{}
+	e.mu.pending = make(map[alertKey]time.Time)
+	e.mu.firing = make(map[alertKey]Alert)
+	return e
+}
+
+// EvaluateAlerts evaluates the given rules against the node and store metrics
+// in nodeStatus at time now. It returns the alerts which started firing and
+// those which stopped firing since the previous evaluation. An alert stops
+// firing when the metric no longer satisfies its rule, when the metric is no
+// longer reported or when the rule is removed.
+func (e *AlertEvaluator) EvaluateAlerts(
+	now time.Time, rules []AlertRule, nodeStatus NodeStatus,
+) (fired, resolved []Alert) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m := map[roachpb.StoreID]map[string]float64{
+		0: nodeStatus.Metrics,
+	}
+	for _, storeStatus := range nodeStatus.StoreStatuses {
+		m[storeStatus.Desc.StoreID] = storeStatus.Metrics
+	}
+
+	seen := make(map[alertKey]struct{})
+	for _, rule := range rules {
+		ruleStr := rule.String()
+		for storeID, metrics := range m {
+			value, ok := metrics[rule.Metric]
+			if !ok || !rule.matches(value) {
+				continue
+			}
+			key := alertKey{rule: ruleStr, storeID: storeID}
+			seen[key] = struct{}{}
+			since, ok := e.mu.pending[key]
+			if !ok {
+				since = now
+				e.mu.pending[key] = since
+			}
+			if now.Sub(since) < rule.For {
+				continue
+			}
+			alert := Alert{
+				Rule:    ruleStr,
+				StoreID: storeID,
+				Metric:  rule.Metric,
+				Value:   value,
+				Since:   since,
+			}
+			if _, ok := e.mu.firing[key]; !ok {
+				fired = append(fired, alert)
+			}
+			e.mu.firing[key] = alert
+		}
+	}
+
+	for key := range e.mu.pending {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		delete(e.mu.pending, key)
+		if alert, ok := e.mu.firing[key]; ok {
+			resolved = append(resolved, alert)
+			delete(e.mu.firing, key)
+		}
+	}
+	return fired, resolved
+}
+
+// FiringAlerts returns the alerts which fired during the last evaluation,
+// ordered by rule and store.
+func (e *AlertEvaluator) FiringAlerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	alerts := make([]Alert, 0, len(e.mu.firing))
+	for _, alert := range e.mu.firing {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].StoreID < alerts[j].StoreID
+	})
+	return alerts
+}
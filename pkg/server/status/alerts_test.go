@@ -0,0 +1,151 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseAlertRules(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		rules    string
+		expected []AlertRule
+		err      string
+	}{
+		{"", nil, ""},
+		{" ; \n", nil, ""},
+		{"ranges.underreplicated > 0", []AlertRule{
+			{Metric: "ranges.underreplicated", Op: ">", Threshold: 0},
+		}, ""},
+		{"ranges.underreplicated > 0 for 5m; sys.goroutines >= 5000\ncapacity.available < 1e9 for 30s", []AlertRule{
+			{Metric: "ranges.underreplicated", Op: ">", Threshold: 0, For: 5 * time.Minute},
+			{Metric: "sys.goroutines", Op: ">=", Threshold: 5000},
+			{Metric: "capacity.available", Op: "<", Threshold: 1e9, For: 30 * time.Second},
+		}, ""},
+		{"ranges.underreplicated", nil, "expected '<metric> <op> <threshold> \\[for <duration>\\]'"},
+		{"ranges.underreplicated > 0 during 5m", nil, "expected '<metric> <op> <threshold> \\[for <duration>\\]'"},
+		{"ranges.underreplicated => 0", nil, `unknown operator "=>"`},
+		{"ranges.underreplicated > zero", nil, "invalid syntax"},
+		{"ranges.underreplicated > 0 for 5", nil, "missing unit in duration"},
+		{"ranges.underreplicated > 0 for -5m", nil, "negative duration"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rules, func(t *testing.T) {
+			rules, err := ParseAlertRules(tc.rules)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(tc.expected, rules) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, rules)
+			}
+		})
+	}
+}
+
+func TestAlertRuleString(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, s := range []string{
+		"ranges.underreplicated > 0",
+		"ranges.underreplicated > 0 for 5m0s",
+		"capacity.available < 1e+09 for 30s",
+		"sys.goroutines != 0.5",
+	} {
+		rules, err := ParseAlertRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a := rules[0].String(); a != s {
+			t.Errorf("expected %q, got %q", s, a)
+		}
+	}
+}
+
+func TestAlertEvaluator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rules, err := ParseAlertRules("ranges.underreplicated > 0 for 5m; sys.goroutines > 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	underreplicated, goroutines := rules[0].String(), rules[1].String()
+
+	nodeStatus := func(goroutines, underreplicated float64) NodeStatus {
+		return NodeStatus{
+			Metrics: map[string]float64{"sys.goroutines": goroutines},
+			StoreStatuses: []StoreStatus{{
+				Desc:    roachpb.StoreDescriptor{StoreID: 1},
+				Metrics: map[string]float64{"ranges.underreplicated": underreplicated},
+			}},
+		}
+	}
+	check := func(actual, expected []Alert) {
+		t.Helper()
+		if len(actual) == 0 && len(expected) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %+v, got %+v", expected, actual)
+		}
+	}
+
+	e := NewAlertEvaluator()
+	start := time.Unix(0, 0)
+
+	// The goroutines rule fires immediately while the underreplicated rule is
+	// only pending.
+	fired, resolved := e.EvaluateAlerts(start, rules, nodeStatus(200, 3))
+	goroutineAlert := Alert{Rule: goroutines, Metric: "sys.goroutines", Value: 200, Since: start}
+	check(fired, []Alert{goroutineAlert})
+	check(resolved, nil)
+	check(e.FiringAlerts(), []Alert{goroutineAlert})
+
+	// The underreplicated rule fires after 5 minutes. Alerts which keep firing
+	// are not reported again but their value is updated.
+	fired, resolved = e.EvaluateAlerts(start.Add(5*time.Minute), rules, nodeStatus(300, 4))
+	underreplicatedAlert := Alert{
+		Rule: underreplicated, StoreID: 1, Metric: "ranges.underreplicated", Value: 4, Since: start,
+	}
+	check(fired, []Alert{underreplicatedAlert})
+	check(resolved, nil)
+	goroutineAlert.Value = 300
+	check(e.FiringAlerts(), []Alert{underreplicatedAlert, goroutineAlert})
+
+	// Both alerts resolve once the metrics no longer satisfy the rules.
+	fired, resolved = e.EvaluateAlerts(start.Add(6*time.Minute), rules, nodeStatus(50, 4))
+	check(fired, nil)
+	check(resolved, []Alert{goroutineAlert})
+	fired, resolved = e.EvaluateAlerts(start.Add(7*time.Minute), rules[1:], nodeStatus(50, 4))
+	check(fired, nil)
+	check(resolved, []Alert{underreplicatedAlert})
+	check(e.FiringAlerts(), nil)
+
+	// A rule which stops being satisfied before its duration elapses never
+	// fires.
+	fired, _ = e.EvaluateAlerts(start.Add(8*time.Minute), rules, nodeStatus(0, 1))
+	check(fired, nil)
+	fired, _ = e.EvaluateAlerts(start.Add(12*time.Minute), rules, nodeStatus(0, 0))
+	check(fired, nil)
+	fired, _ = e.EvaluateAlerts(start.Add(14*time.Minute), rules, nodeStatus(0, 1))
+	check(fired, nil)
+}
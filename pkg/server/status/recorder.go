@@ -98,6 +98,7 @@ type storeMetrics interface {
 // recorded, and they are thus kept separate.
 type MetricsRecorder struct {
 	*HealthChecker
+	*AlertEvaluator
 	gossip       *gossip.Gossip
 	nodeLiveness *storage.NodeLiveness
 	rpcContext   *rpc.Context
@@ -154,11 +155,12 @@ func NewMetricsRecorder(
 	settings *cluster.Settings,
 ) *MetricsRecorder {
 	mr := &MetricsRecorder{
-		HealthChecker: NewHealthChecker(trackedMetrics),
-		nodeLiveness:  nodeLiveness,
-		rpcContext:    rpcContext,
-		gossip:        gossip,
-		settings:      settings,
+		HealthChecker:  NewHealthChecker(trackedMetrics),
+		AlertEvaluator: NewAlertEvaluator(),
+		nodeLiveness:   nodeLiveness,
+		rpcContext:     rpcContext,
+		gossip:         gossip,
+		settings:       settings,
 	}
 	mr.mu.storeRegistries = make(map[roachpb.StoreID]*metric.Registry)
 	mr.mu.stores = make(map[roachpb.StoreID]storeMetrics)
@@ -645,6 +645,69 @@ func TestNodesGRPCResponse(t *testing.T) {
 	}
 }
 
+// TestCancelQueryByKeyGRPCResponse checks that only nodes can cancel queries
+// with a pgwire cancel key.
+func TestCancelQueryByKeyGRPCResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stopper().Stop(context.TODO())
+
+	request := serverpb.CancelQueryByKeyRequest{NodeId: "local", CancelKey: 1, ClientAddr: "127.0.0.1"}
+	for _, tc := range []struct {
+		user string
+		err  string
+	}{
+		{security.RootUser, "user root is not allowed"},
+		{security.NodeUser, ""},
+	} {
+		rpcContext := rpc.NewContext(
+			log.AmbientContext{Tracer: ts.ClusterSettings().Tracer}, testutils.NewTestBaseContext(tc.user),
+			ts.Clock(), ts.Stopper(), &ts.ClusterSettings().Version)
+		conn, err := rpcContext.GRPCDial(ts.ServingAddr()).Connect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := serverpb.NewStatusClient(conn)
+		_, err = client.CancelQueryByKey(context.Background(), &request)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.user, err)
+			}
+		} else if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.user, tc.err, err)
+		}
+	}
+}
+
+func TestSignNodeCertificateGRPCResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stopper().Stop(context.TODO())
+
+	// The test server doesn't hold the CA key: a node passes the
+	// authentication but its request cannot be signed.
+	for _, tc := range []struct {
+		user string
+		err  string
+	}{
+		{security.RootUser, "user root is not allowed"},
+		{security.NodeUser, "the built-in certificate authority is disabled"},
+	} {
+		rpcContext := rpc.NewContext(
+			log.AmbientContext{Tracer: ts.ClusterSettings().Tracer}, testutils.NewTestBaseContext(tc.user),
+			ts.Clock(), ts.Stopper(), &ts.ClusterSettings().Version)
+		conn, err := rpcContext.GRPCDial(ts.ServingAddr()).Connect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := serverpb.NewStatusClient(conn)
+		_, err = client.SignNodeCertificate(context.Background(), &serverpb.SignNodeCertificateRequest{})
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.user, tc.err, err)
+		}
+	}
+}
+
 func TestCertificatesResponse(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	ts := startServer(t)
@@ -939,3 +1002,72 @@ func TestStatusAPIStatements(t *testing.T) {
 		t.Fatalf("expected queries\n\n%v\n\ngot queries\n\n%v", expectedStatements, statementsInResponse)
 	}
 }
+
+func TestStatusAPIHotRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCluster := serverutils.StartTestCluster(t, 3, base.TestClusterArgs{})
+	defer testCluster.Stopper().Stop(context.Background())
+
+	firstServer := testCluster.Server(0)
+	db := sqlutils.MakeSQLRunner(testCluster.ServerConn(1))
+	db.Exec(t, `CREATE DATABASE hot`)
+	db.Exec(t, `CREATE TABLE hot.kv (k INT PRIMARY KEY, v INT)`)
+	db.Exec(t, `INSERT INTO hot.kv VALUES (1, 1)`)
+
+	// Every node applies the writes to the replicated system ranges, so every
+	// node reports hot ranges once the load has been measured for long
+	// enough. The reads of the table are served by its leaseholder.
+	testutils.SucceedsSoon(t, func() error {
+		for i := 0; i < 10; i++ {
+			db.Exec(t, `SELECT * FROM hot.kv`)
+		}
+
+		var resp serverpb.HotRangesResponse
+		if err := getStatusJSONProto(firstServer, "hotranges", &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors: %+v", resp.Errors)
+		}
+		nodes := make(map[roachpb.NodeID]struct{})
+		foundTable := false
+		for i, r := range resp.Ranges {
+			nodes[r.NodeID] = struct{}{}
+			if i > 0 {
+				prev := resp.Ranges[i-1]
+				if prev.NodeID > r.NodeID || (prev.NodeID == r.NodeID &&
+					(prev.StoreID > r.StoreID || (prev.StoreID == r.StoreID && prev.QueriesPerSecond < r.QueriesPerSecond))) {
+					t.Fatalf("ranges not sorted by node, store and decreasing QPS: %+v", resp.Ranges)
+				}
+			}
+			if r.DatabaseName == "hot" && r.TableName == "kv" && r.QueriesPerSecond > 0 {
+				foundTable = true
+			}
+		}
+		if len(nodes) != 3 {
+			return errors.Errorf("expected hot ranges from 3 nodes, got %v", nodes)
+		}
+		if !foundTable {
+			return errors.Errorf("table hot.kv not found in the hot ranges: %+v", resp.Ranges)
+		}
+		return nil
+	})
+
+	// The hot ranges of a single node are fetched from that node.
+	for i := 0; i < testCluster.NumServers(); i++ {
+		nodeID := testCluster.Server(i).NodeID()
+		var resp serverpb.HotRangesResponse
+		if err := getStatusJSONProto(firstServer, fmt.Sprintf("hotranges?node_id=%d", nodeID), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Ranges) == 0 {
+			t.Errorf("n%d: expected hot ranges", nodeID)
+		}
+		for _, r := range resp.Ranges {
+			if r.NodeID != nodeID {
+				t.Errorf("n%d: unexpected range from n%d: %+v", nodeID, r.NodeID, r)
+			}
+		}
+	}
+}
@@ -137,6 +137,9 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 	if params.SSLCertsDir != "" {
 		cfg.SSLCertsDir = params.SSLCertsDir
 	}
+	if params.SSLCAKey != "" {
+		cfg.SSLCAKey = params.SSLCAKey
+	}
 	if params.TimeSeriesQueryWorkerMax != 0 {
 		cfg.TimeSeriesServerConfig.QueryWorkerMax = params.TimeSeriesQueryWorkerMax
 	}
@@ -146,6 +149,9 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 	if params.DisableEventLog {
 		cfg.EventLogEnabled = false
 	}
+	if params.DisableTimeSeriesRecording {
+		cfg.DisableTimeSeriesRecording = true
+	}
 	if params.SQLMemoryPoolSize != 0 {
 		cfg.SQLMemoryPoolSize = params.SQLMemoryPoolSize
 	}
@@ -68,6 +68,9 @@ const (
 	VersionAsyncConsensus
 	VersionBatchResponse
 	VersionCreateChangefeed
+	VersionParallelCommits
+	VersionRowLevelSecurity
+	VersionColumnPrivileges
 
 	// Add new versions here (step one of two).
 
@@ -265,6 +268,21 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionCreateChangefeed,
 		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 11},
 	},
+	{
+		// VersionParallelCommits is https://github.com/cockroachdb/cockroach/pull/24194.
+		Key:     VersionParallelCommits,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 12},
+	},
+	{
+		// VersionRowLevelSecurity enables row-level security policies.
+		Key:     VersionRowLevelSecurity,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 13},
+	},
+	{
+		// VersionColumnPrivileges enables privileges on individual columns.
+		Key:     VersionColumnPrivileges,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 14},
+	},
 
 	// Add new versions here (step two of two).
 
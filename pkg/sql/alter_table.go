@@ -25,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/text/language"
 
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -393,6 +394,17 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 			}
 
+			// You can't drop a column used by a row-level security policy.
+			for i := range n.tableDesc.Policies {
+				policy := &n.tableDesc.Policies[i]
+				if used, err := policy.UsesColumn(col.Name); err != nil {
+					return err
+				} else if used {
+					return pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+						"column %q is referenced by policy %q", col.Name, policy.Name)
+				}
+			}
+
 			// Drop check constraints which reference the column.
 			validChecks := n.tableDesc.Checks[:0]
 			for _, check := range n.tableDesc.Checks {
@@ -567,6 +579,21 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return err
 			}
 
+		case *tree.AlterTableSetRowLevelSecurity:
+			if err := params.p.RequireSuperUser(
+				params.ctx, "change row-level security settings on a table",
+			); err != nil {
+				return err
+			}
+			if t.Enabled && !params.p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionRowLevelSecurity) {
+				return errors.Errorf("ENABLE ROW LEVEL SECURITY requires all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionRowLevelSecurity))
+			}
+			if n.tableDesc.RowLevelSecurity != t.Enabled {
+				n.tableDesc.RowLevelSecurity = t.Enabled
+				descriptorChanged = true
+			}
+
 		case *tree.AlterTableInjectStats:
 			sd, ok := n.statsData[i]
 			if !ok {
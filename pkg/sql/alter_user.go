@@ -23,7 +23,8 @@ import (
 	"github.com/pkg/errors"
 )
 
-// alterUserSetPasswordNode represents an ALTER USER ... WITH PASSWORD statement.
+// alterUserSetPasswordNode represents an ALTER USER ... WITH PASSWORD or
+// ALTER USER ... VALID UNTIL statement.
 type alterUserSetPasswordNode struct {
 	userAuthInfo
 	ifExists bool
@@ -31,7 +32,7 @@ type alterUserSetPasswordNode struct {
 	run alterUserSetPasswordRun
 }
 
-// AlterUserSetPassword changes a user's password.
+// AlterUserSetPassword changes a user's password and/or its expiration.
 // Privileges: UPDATE on the users table.
 func (p *planner) AlterUserSetPassword(
 	ctx context.Context, n *tree.AlterUserSetPassword,
@@ -49,6 +50,12 @@ func (p *planner) AlterUserSetPassword(
 	if err != nil {
 		return nil, err
 	}
+	if n.ValidUntil != nil {
+		ua.validUntil, err = p.TypeAsString(n.ValidUntil, "ALTER USER")
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &alterUserSetPasswordNode{
 		userAuthInfo: ua,
@@ -63,13 +70,16 @@ type alterUserSetPasswordRun struct {
 }
 
 func (n *alterUserSetPasswordNode) startExec(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(&params.extendedEvalCtx.Settings.SV)
 	if err != nil {
 		return err
 	}
 
-	// The root user is not allowed a password.
+	// The root user is not allowed a password, nor its expiration.
 	if normalizedUsername == security.RootUser {
+		if n.userAuthInfo.password == nil {
+			return errors.Errorf("user %s cannot have a password expiration", security.RootUser)
+		}
 		return errors.Errorf("user %s cannot use password authentication", security.RootUser)
 	}
 
@@ -77,21 +87,51 @@ func (n *alterUserSetPasswordNode) startExec(params runParams) error {
 		return errors.New("cluster in insecure mode; user cannot use password authentication")
 	}
 
-	n.run.rowsAffected, err = params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
-		params.ctx,
-		"update-user",
-		params.p.txn,
-		`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1 AND "isRole" = false`,
-		normalizedUsername,
-		hashedPassword,
-	)
+	validUntil, err := n.userAuthInfo.resolveValidUntil()
 	if err != nil {
 		return err
 	}
-	if n.run.rowsAffected == 0 && !n.ifExists {
-		return errors.Errorf("user %s does not exist", normalizedUsername)
+
+	if n.userAuthInfo.password != nil {
+		n.run.rowsAffected, err = params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
+			params.ctx,
+			"update-user",
+			params.p.txn,
+			`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1 AND "isRole" = false`,
+			normalizedUsername,
+			hashedPassword,
+		)
+	} else {
+		n.run.rowsAffected, err = userExists(params, normalizedUsername)
+	}
+	if err != nil {
+		return err
+	}
+	if n.run.rowsAffected == 0 {
+		if !n.ifExists {
+			return errors.Errorf("user %s does not exist", normalizedUsername)
+		}
+		return nil
+	}
+	if validUntil != nil {
+		return setUserValidUntil(params, normalizedUsername, validUntil)
+	}
+	return nil
+}
+
+// userExists returns 1 if the user exists and 0 otherwise.
+func userExists(params runParams, username string) (int, error) {
+	row, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.QueryRow(
+		params.ctx,
+		"get-user",
+		params.p.txn,
+		`SELECT 1 FROM system.users WHERE username = $1 AND "isRole" = false`,
+		username,
+	)
+	if err != nil || row == nil {
+		return 0, err
 	}
-	return err
+	return 1, nil
 }
 
 func (*alterUserSetPasswordNode) Next(runParams) (bool, error) { return false, nil }
@@ -101,3 +141,87 @@ func (*alterUserSetPasswordNode) Close(context.Context)        {}
 func (n *alterUserSetPasswordNode) FastPathResults() (int, bool) {
 	return n.run.rowsAffected, true
 }
+
+// alterUserUnlockNode represents an ALTER USER ... UNLOCK statement.
+type alterUserUnlockNode struct {
+	name     func() (string, error)
+	ifExists bool
+
+	run alterUserSetPasswordRun
+}
+
+// AlterUserUnlock forgets the failed logins of a user, which unlocks the
+// user if it was locked out.
+// Privileges: UPDATE on the users table.
+func (p *planner) AlterUserUnlock(ctx context.Context, n *tree.AlterUserUnlock) (planNode, error) {
+	tDesc, err := ResolveExistingObject(ctx, p, userTableName, true /*required*/, requireTableDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	name, err := p.TypeAsString(n.Name, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterUserUnlockNode{name: name, ifExists: n.IfExists}, nil
+}
+
+func (n *alterUserUnlockNode) startExec(params runParams) error {
+	name, err := n.name()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errNoUserNameSpecified
+	}
+	normalizedUsername, err := NormalizeAndValidateUsername(name)
+	if err != nil {
+		return err
+	}
+
+	n.run.rowsAffected, err = userExists(params, normalizedUsername)
+	if err != nil {
+		return err
+	}
+	if n.run.rowsAffected == 0 {
+		if !n.ifExists {
+			return errors.Errorf("user %s does not exist", normalizedUsername)
+		}
+		return nil
+	}
+
+	if _, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
+		params.ctx,
+		"unlock-user",
+		params.p.txn,
+		`UPDATE system.user_login_status SET failed_logins = 0 WHERE username = $1`,
+		normalizedUsername,
+	); err != nil {
+		return err
+	}
+
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogUnlockUser,
+		0, /* no target */
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			UserName string
+			User     string
+		}{normalizedUsername, params.SessionData().User},
+	)
+}
+
+func (*alterUserUnlockNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserUnlockNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterUserUnlockNode) Close(context.Context)        {}
+
+func (n *alterUserUnlockNode) FastPathResults() (int, bool) {
+	return n.run.rowsAffected, true
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -48,6 +49,16 @@ type appStats struct {
 
 	syncutil.Mutex
 	stmts map[stmtKey]*stmtStats
+
+	// unflushedStmts and unflushedTxns hold the statistics collected since
+	// they were last persisted to the system tables (see persisted_stats.go).
+	// Unlike stmts, they aren't cleared by resetStats.
+	unflushedStmts map[stmtKey]*stmtStats
+	unflushedTxns  map[string]*txnStats
+
+	// plans holds the plan history of each statement fingerprint (see
+	// plan_history.go).
+	plans map[string]*stmtPlans
 }
 
 // stmtStats holds per-statement statistics.
@@ -57,6 +68,14 @@ type stmtStats struct {
 	data roachpb.StatementStatistics
 }
 
+// txnStats holds per-transaction statistics. Transactions are keyed by their
+// fingerprint, the fingerprints of their statements.
+type txnStats struct {
+	syncutil.Mutex
+
+	data roachpb.TransactionStatistics
+}
+
 // stmtStatsEnable determines whether to collect per-statement
 // statistics.
 var stmtStatsEnable = settings.RegisterBoolSetting(
@@ -95,6 +114,8 @@ func (s stmtKey) flags() string {
 	return b.String()
 }
 
+// recordStatement records the statistics of a statement, and returns the
+// fingerprint of the statement if statistics are being collected.
 func (a *appStats) recordStatement(
 	stmt Statement,
 	distSQLUsed bool,
@@ -103,51 +124,91 @@ func (a *appStats) recordStatement(
 	numRows int,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
-) {
+) string {
 	if a == nil || !stmtStatsEnable.Get(&a.st.SV) {
-		return
-	}
-
-	if t := sqlStatsCollectionLatencyThreshold.Get(&a.st.SV); t > 0 && t.Seconds() >= svcLat {
-		return
+		return ""
 	}
 
 	// Extend the statement key with a character that indicated whether
 	// there was an error and/or whether the query was distributed, so
 	// that we use separate buckets for the different situations.
 	key := stmtKey{failed: err != nil, distSQLUsed: distSQLUsed, optUsed: optUsed}
+	key.stmt = stmtFingerprint(stmt)
 
-	if stmt.AnonymizedStr != "" {
-		// Use the cached anonymized string.
-		key.stmt = stmt.AnonymizedStr
-	} else {
-		key.stmt = anonymizeStmt(stmt)
+	if t := sqlStatsCollectionLatencyThreshold.Get(&a.st.SV); t > 0 && t.Seconds() >= svcLat {
+		return key.stmt
 	}
 
-	// Get the statistics object.
-	s := a.getStatsForStmt(key)
-
 	// Collect the per-statement statistics.
+	record := func(s *stmtStats) {
+		s.Lock()
+		s.data.Count++
+		if err != nil {
+			s.data.LastErr = err.Error()
+			s.data.LastErrRedacted = log.Redact(err)
+		}
+		if automaticRetryCount == 0 {
+			s.data.FirstAttemptCount++
+		} else if int64(automaticRetryCount) > s.data.MaxRetries {
+			s.data.MaxRetries = int64(automaticRetryCount)
+		}
+		s.data.NumRows.Record(s.data.Count, float64(numRows))
+		s.data.ParseLat.Record(s.data.Count, parseLat)
+		s.data.PlanLat.Record(s.data.Count, planLat)
+		s.data.RunLat.Record(s.data.Count, runLat)
+		s.data.ServiceLat.Record(s.data.Count, svcLat)
+		s.data.OverheadLat.Record(s.data.Count, ovhLat)
+		s.Unlock()
+	}
+	record(a.getStatsForStmt(key))
+	if statsFlushInterval.Get(&a.st.SV) > 0 {
+		record(a.getUnflushedStatsForStmt(key))
+	}
+	return key.stmt
+}
+
+// recordTransaction records the statistics of a committed transaction made
+// of the statements with the given fingerprints.
+func (a *appStats) recordTransaction(
+	stmts []string, automaticRetryCount int, numRows int, svcLat float64,
+) {
+	if a == nil || len(stmts) == 0 ||
+		!stmtStatsEnable.Get(&a.st.SV) || statsFlushInterval.Get(&a.st.SV) == 0 {
+		return
+	}
+	s := a.getUnflushedStatsForTxn(txnFingerprint(stmts))
 	s.Lock()
 	s.data.Count++
-	if err != nil {
-		s.data.LastErr = err.Error()
-		s.data.LastErrRedacted = log.Redact(err)
-	}
-	if automaticRetryCount == 0 {
-		s.data.FirstAttemptCount++
-	} else if int64(automaticRetryCount) > s.data.MaxRetries {
+	if int64(automaticRetryCount) > s.data.MaxRetries {
 		s.data.MaxRetries = int64(automaticRetryCount)
 	}
 	s.data.NumRows.Record(s.data.Count, float64(numRows))
-	s.data.ParseLat.Record(s.data.Count, parseLat)
-	s.data.PlanLat.Record(s.data.Count, planLat)
-	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
-	s.data.OverheadLat.Record(s.data.Count, ovhLat)
 	s.Unlock()
 }
 
+// recordPlan records the plan used by an execution of the statement with the
+// given fingerprint. It returns a non-nil planRegression if the plan has just
+// been found to be much slower than the plan the statement used before.
+func (a *appStats) recordPlan(stmt, plan string, svcLat float64) *planRegression {
+	if a == nil || !stmtStatsEnable.Get(&a.st.SV) {
+		return nil
+	}
+	maxPlans := maxPlansPerStmt.Get(&a.st.SV)
+	if maxPlans == 0 {
+		return nil
+	}
+	a.Lock()
+	s, ok := a.plans[stmt]
+	if !ok {
+		s = &stmtPlans{}
+		a.plans[stmt] = s
+	}
+	a.Unlock()
+	return s.recordPlan(
+		stmt, plan, svcLat, timeutil.Now(), int(maxPlans), planRegressionThreshold.Get(&a.st.SV))
+}
+
 // getStatsForStmt retrieves the per-stmt stat object.
 func (a *appStats) getStatsForStmt(key stmtKey) *stmtStats {
 	a.Lock()
@@ -162,10 +223,79 @@ func (a *appStats) getStatsForStmt(key stmtKey) *stmtStats {
 	return s
 }
 
+// getUnflushedStatsForStmt is like getStatsForStmt for the statistics that
+// haven't been persisted yet.
+func (a *appStats) getUnflushedStatsForStmt(key stmtKey) *stmtStats {
+	a.Lock()
+	s, ok := a.unflushedStmts[key]
+	if !ok {
+		s = &stmtStats{}
+		a.unflushedStmts[key] = s
+	}
+	a.Unlock()
+	return s
+}
+
+// getUnflushedStatsForTxn retrieves the statistics of the transaction with the
+// given fingerprint that haven't been persisted yet.
+func (a *appStats) getUnflushedStatsForTxn(key string) *txnStats {
+	a.Lock()
+	s, ok := a.unflushedTxns[key]
+	if !ok {
+		s = &txnStats{}
+		a.unflushedTxns[key] = s
+	}
+	a.Unlock()
+	return s
+}
+
+// restoreUnflushedStmt merges statistics of a statement which could not be
+// persisted back into the statistics that haven't been persisted yet, so that
+// they are persisted by the next flush.
+func (a *appStats) restoreUnflushedStmt(key stmtKey, data *roachpb.StatementStatistics) {
+	s := a.getUnflushedStatsForStmt(key)
+	s.Lock()
+	s.data.Add(data)
+	s.Unlock()
+}
+
+// restoreUnflushedTxn is like restoreUnflushedStmt for the statistics of a
+// transaction.
+func (a *appStats) restoreUnflushedTxn(key string, data *roachpb.TransactionStatistics) {
+	s := a.getUnflushedStatsForTxn(key)
+	s.Lock()
+	s.data.Add(data)
+	s.Unlock()
+}
+
 func anonymizeStmt(stmt Statement) string {
 	return tree.AsStringWithFlags(stmt.AST, tree.FmtHideConstants)
 }
 
+// stmtFingerprint returns the fingerprint of a statement, that is the
+// statement with its constants hidden.
+func stmtFingerprint(stmt Statement) string {
+	if stmt.AnonymizedStr != "" {
+		// Use the cached anonymized string.
+		return stmt.AnonymizedStr
+	}
+	return anonymizeStmt(stmt)
+}
+
+// maxTxnFingerprintStmts is the maximum number of statements in the
+// fingerprint of a transaction. The statements past it are elided, so that
+// long-running transactions don't produce unbounded fingerprints.
+const maxTxnFingerprintStmts = 100
+
+// txnFingerprint returns the fingerprint of a transaction made of the
+// statements with the given fingerprints.
+func txnFingerprint(stmts []string) string {
+	if len(stmts) > maxTxnFingerprintStmts {
+		return strings.Join(stmts[:maxTxnFingerprintStmts], "; ") + "; ..."
+	}
+	return strings.Join(stmts, "; ")
+}
+
 // sqlStats carries per-application statistics for all applications on
 // each node.
 type sqlStats struct {
@@ -184,7 +314,13 @@ func (s *sqlStats) getStatsForApplication(appName string) *appStats {
 	if a, ok := s.apps[appName]; ok {
 		return a
 	}
-	a := &appStats{st: s.st, stmts: make(map[stmtKey]*stmtStats)}
+	a := &appStats{
+		st:             s.st,
+		stmts:          make(map[stmtKey]*stmtStats),
+		unflushedStmts: make(map[stmtKey]*stmtStats),
+		unflushedTxns:  make(map[string]*txnStats),
+		plans:          make(map[string]*stmtPlans),
+	}
 	s.apps[appName] = a
 	return a
 }
@@ -223,6 +359,7 @@ func (s *sqlStats) resetStats(ctx context.Context) {
 		// Clear the map, to release the memory; make the new map somewhat
 		// already large for the likely future workload.
 		a.stmts = make(map[stmtKey]*stmtStats, len(a.stmts)/2)
+		a.plans = make(map[string]*stmtPlans, len(a.plans)/2)
 		a.Unlock()
 	}
 	s.lastReset = timeutil.Now()
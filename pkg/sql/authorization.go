@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -47,6 +48,14 @@ type AuthorizationAccessor interface {
 	// CheckAnyPrivilege returns nil if user has any privileges at all.
 	CheckAnyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error
 
+	// CheckColumnPrivileges verifies that the user has `privilege` on the
+	// table `desc`, or at least on some of its columns. In the latter case,
+	// it returns the error to report when each column of desc.Columns is
+	// accessed, nil for the columns on which `privilege` was granted.
+	CheckColumnPrivileges(
+		ctx context.Context, desc *sqlbase.TableDescriptor, privilege privilege.Kind,
+	) ([]error, error)
+
 	// RequiresSuperUser errors if the session user isn't a super-user (i.e. root
 	// or node). Includes the named action in the error message.
 	RequireSuperUser(ctx context.Context, action string) error
@@ -54,6 +63,13 @@ type AuthorizationAccessor interface {
 	// MemberOfWithAdminOption looks up all the roles (direct and indirect) that 'member' is a member
 	// of and returns a map of role -> isAdmin.
 	MemberOfWithAdminOption(ctx context.Context, member string) (map[string]bool, error)
+
+	// RowLevelSecurityFilter returns the filter restricting the rows of the
+	// table that statements of kind cmd issued by the session user can
+	// access, or nil if row-level security does not apply.
+	RowLevelSecurityFilter(
+		ctx context.Context, desc *sqlbase.TableDescriptor, cmd sqlbase.TableDescriptor_Policy_Command,
+	) (tree.Expr, error)
 }
 
 var _ AuthorizationAccessor = &planner{}
@@ -115,14 +131,29 @@ func (p *planner) CheckPrivilege(
 func (p *planner) CheckAnyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error {
 	user := p.SessionData().User
 	privs := descriptor.GetPrivileges()
+	anyPrivilege := privs.AnyPrivilege
+	// Privileges on individual columns of a table count as well.
+	if table, ok := descriptor.(*sqlbase.TableDescriptor); ok {
+		anyPrivilege = func(user string) bool {
+			if privs.AnyPrivilege(user) {
+				return true
+			}
+			for i := range table.Columns {
+				if colPrivs := table.Columns[i].Privileges; colPrivs != nil && colPrivs.AnyPrivilege(user) {
+					return true
+				}
+			}
+			return false
+		}
+	}
 
 	// Check if 'user' itself has privileges.
-	if privs.AnyPrivilege(user) {
+	if anyPrivilege(user) {
 		return nil
 	}
 
 	// Check if 'public' has privileges.
-	if privs.AnyPrivilege(sqlbase.PublicRole) {
+	if anyPrivilege(sqlbase.PublicRole) {
 		return nil
 	}
 
@@ -134,7 +165,7 @@ func (p *planner) CheckAnyPrivilege(ctx context.Context, descriptor sqlbase.Desc
 
 	// Iterate over the roles that 'user' is a member of. We don't care about the admin option.
 	for role := range memberOf {
-		if privs.AnyPrivilege(role) {
+		if anyPrivilege(role) {
 			return nil
 		}
 	}
@@ -143,6 +174,100 @@ func (p *planner) CheckAnyPrivilege(ctx context.Context, descriptor sqlbase.Desc
 		p.SessionData().User, descriptor.TypeName(), descriptor.GetName())
 }
 
+// CheckColumnPrivileges implements the AuthorizationAccessor interface.
+func (p *planner) CheckColumnPrivileges(
+	ctx context.Context, desc *sqlbase.TableDescriptor, privilege privilege.Kind,
+) ([]error, error) {
+	tableErr := p.CheckPrivilege(ctx, desc, privilege)
+	if tableErr == nil {
+		return nil, nil
+	}
+
+	user := p.SessionData().User
+	var memberOf map[string]bool
+	hasPrivilege := func(privs *sqlbase.PrivilegeDescriptor) (bool, error) {
+		if privs.CheckPrivilege(user, privilege) || privs.CheckPrivilege(sqlbase.PublicRole, privilege) {
+			return true, nil
+		}
+		if memberOf == nil {
+			var err error
+			if memberOf, err = p.MemberOfWithAdminOption(ctx, user); err != nil {
+				return false, err
+			}
+		}
+		for role := range memberOf {
+			if privs.CheckPrivilege(role, privilege) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	colErrs := make([]error, len(desc.Columns))
+	granted := false
+	for i := range desc.Columns {
+		col := &desc.Columns[i]
+		if col.Privileges != nil {
+			ok, err := hasPrivilege(col.Privileges)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				granted = true
+				continue
+			}
+		}
+		colErrs[i] = pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"user %s does not have %s privilege on column %q of relation %s",
+			user, privilege, col.Name, desc.Name)
+	}
+	if !granted {
+		return nil, tableErr
+	}
+	return colErrs, nil
+}
+
+// checkPrivilegeOnColumns verifies that the user has `privilege` on all
+// the columns `cols` of the table `desc`, given the result of
+// CheckColumnPrivileges for the table.
+func checkPrivilegeOnColumns(
+	desc *sqlbase.TableDescriptor, colErrs []error, cols []sqlbase.ColumnDescriptor,
+) error {
+	if colErrs == nil {
+		return nil
+	}
+	colIdxMap := desc.ColumnIdxMap()
+	for i := range cols {
+		if idx, ok := colIdxMap[cols[i].ID]; ok && colErrs[idx] != nil {
+			return colErrs[idx]
+		}
+	}
+	return nil
+}
+
+// markUnreadableColumns returns a copy of the result columns `cols` of the
+// table `desc` in which the columns that the user is not allowed to read,
+// according to the result of CheckColumnPrivileges for SELECT, report an
+// error when they are referenced.
+func markUnreadableColumns(
+	desc *sqlbase.TableDescriptor, colErrs []error, cols sqlbase.ResultColumns,
+) sqlbase.ResultColumns {
+	if colErrs == nil {
+		return cols
+	}
+	res := make(sqlbase.ResultColumns, len(cols))
+	copy(res, cols)
+	for i := range res {
+		for j := range desc.Columns {
+			if desc.Columns[j].Name == res[i].Name {
+				res[i].PrivilegeErr = colErrs[j]
+				break
+			}
+		}
+	}
+	return res
+}
+
 // RequireSuperUser implements the AuthorizationAccessor interface.
 func (p *planner) RequireSuperUser(ctx context.Context, action string) error {
 	user := p.SessionData().User
@@ -178,8 +303,15 @@ func (p *planner) MemberOfWithAdminOption(
 	if err != nil {
 		return nil, err
 	}
-	tableVersion := tableDesc.Version
+	return p.memberOfWithAdminOptionAtVersion(ctx, member, tableDesc.Version)
+}
 
+// memberOfWithAdminOptionAtVersion is the part of MemberOfWithAdminOption
+// which looks up the memberships in the cache, given the version of the
+// role_members table descriptor.
+func (p *planner) memberOfWithAdminOptionAtVersion(
+	ctx context.Context, member string, tableVersion sqlbase.DescriptorVersion,
+) (map[string]bool, error) {
 	// We loop in case the table version changes while we're looking up memberships.
 	for {
 		// Check version and maybe clear cache while holding the mutex.
@@ -188,7 +320,7 @@ func (p *planner) MemberOfWithAdminOption(
 		roleMembersCache.Lock()
 		if roleMembersCache.tableVersion != tableVersion {
 			// Update version and drop the map.
-			roleMembersCache.tableVersion = tableDesc.Version
+			roleMembersCache.tableVersion = tableVersion
 			roleMembersCache.userCache = make(map[string]userRoleMembership)
 		}
 
@@ -0,0 +1,71 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestColumnPrivilegesClusterVersion verifies that privileges cannot be
+// granted on columns until all the nodes of the cluster are upgraded.
+func TestColumnPrivilegesClusterVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	oldVersion := cluster.VersionByKey(cluster.VersionColumnPrivileges - 1)
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Settings: cluster.MakeTestingClusterSettingsWithVersion(
+			oldVersion /* minVersion */, cluster.BinaryServerVersion /* serverVersion */),
+		Knobs: base.TestingKnobs{
+			Store: &storage.StoreTestingKnobs{
+				BootstrapVersion: &cluster.ClusterVersion{
+					UseVersion:     oldVersion,
+					MinimumVersion: oldVersion,
+				},
+			},
+			Upgrade: &server.UpgradeTestingKnobs{
+				DisableUpgrade: 1,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	r := sqlutils.MakeSQLRunner(db)
+
+	r.Exec(t, `CREATE USER testuser`)
+	r.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+	const expected = `GRANT on columns requires all nodes to be upgraded to 2.0-14`
+	if _, err := db.Exec(`GRANT SELECT (k) ON t TO testuser`); !testutils.IsError(err, expected) {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+	// Privileges on whole tables can still be granted.
+	r.Exec(t, `GRANT SELECT ON t TO testuser`)
+
+	r.Exec(t, `SET CLUSTER SETTING version = $1`,
+		cluster.VersionByKey(cluster.VersionColumnPrivileges).String())
+	testutils.SucceedsSoon(t, func() error {
+		_, err := db.Exec(`GRANT INSERT (k) ON t TO testuser`)
+		return err
+	})
+}
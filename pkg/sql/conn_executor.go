@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -280,6 +281,7 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 				6*metricsSampleInterval),
 			SQLServiceLatency: metric.NewLatency(MetaSQLServiceLatency,
 				6*metricsSampleInterval),
+			PlanRegressionCount: metric.NewCounter(MetaPlanRegression),
 		},
 		StatementCounters: makeStatementCounters(),
 		// dbCache will be updated on Start().
@@ -305,6 +307,7 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearStmtStats(ctx, stopper)
+	s.PeriodicallyPersistStats(ctx, stopper)
 }
 
 // recordError takes an error and increments the corresponding count for its
@@ -408,6 +411,9 @@ func (s *Server) ServeConn(
 	ex := s.newConnExecutor(
 		ctx, sessionParams{args: &args}, stmtBuf, clientComm, s.pool, reserved, memMetrics,
 	)
+	ex.cancelKey = args.CancelKey
+	ex.notifications.stmtBuf = stmtBuf
+	ex.notifications.processID = args.CancelKey.ProcessID()
 	defer func() {
 		r := recover()
 		ex.closeWrapper(ctx, r)
@@ -530,6 +536,7 @@ func (s *Server) newConnExecutor(
 		dbCacheSubscriber: s.dbCache,
 	}
 	ex.extraTxnState.txnRewindPos = -1
+	ex.notifications.registry = s.cfg.Notifications
 
 	ex.mu.ActiveQueries = make(map[ClusterWideID]*queryMeta)
 	ex.machine = fsm.MakeMachine(TxnStateTransitions, stateNoTxn{}, &ex.state)
@@ -727,6 +734,8 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.eventLog = nil
 	}
 
+	ex.notifications.close()
+
 	if closeType != panicClose {
 		ex.state.mon.Stop(ctx)
 		ex.sessionMon.Stop(ctx)
@@ -822,6 +831,14 @@ type connExecutor struct {
 		// txnRewindPos is advanced. Prepared statements are shared between the two
 		// collections, but these collections are periodically reconciled.
 		prepStmtsNamespaceAtTxnRewindPos prepStmtNamespace
+
+		// stmtFingerprints are the fingerprints of the statements executed by the
+		// current transaction, which make up the fingerprint of the transaction
+		// for the transaction statistics. numRows is the number of rows returned
+		// or affected by these statements. The statistics of the transaction are
+		// recorded when it commits.
+		stmtFingerprints []string
+		numRows          int
 	}
 
 	// sessionData contains the user-configurable connection variables.
@@ -875,8 +892,19 @@ type connExecutor struct {
 	// curStmt is the statement that's currently being prepared or executed, if
 	// any. This is printed by high-level panic recovery.
 	curStmt tree.Statement
+	// curStmtPlanned is set once the current statement is handed to a
+	// planner, which records it in the exec and audit logs.
+	curStmtPlanned bool
 
 	sessionID ClusterWideID
+
+	// cancelKey is the key sent to the client to cancel the queries of the
+	// session. It is zero for internal sessions.
+	cancelKey pgwirecancel.BackendKeyData
+
+	// notifications holds the channels the session listens on and the
+	// notifications sent by the current transaction.
+	notifications sessionNotifications
 }
 
 // ctxHolder contains a connection's context and, while session tracing is
@@ -979,6 +1007,7 @@ func (ex *connExecutor) resetExtraTxnState(
 	ctx context.Context, ev txnEvent, dbCacheHolder *databaseCacheHolder,
 ) error {
 	ex.extraTxnState.schemaChangers.reset()
+	ex.notifications.reset()
 
 	var opt releaseOpt
 	if ev == txnCommit {
@@ -992,9 +1021,31 @@ func (ex *connExecutor) resetExtraTxnState(
 	ex.extraTxnState.tables.databaseCache = dbCacheHolder.getDatabaseCache()
 
 	ex.extraTxnState.autoRetryCounter = 0
+	ex.resetTxnStats()
 	return nil
 }
 
+// recordTxnStmt adds a statement to the statistics of the current
+// transaction.
+func (ex *connExecutor) recordTxnStmt(fingerprint string, numRows int) {
+	if fingerprint == "" {
+		return
+	}
+	// Keep one statement past the limit so that the fingerprint shows that
+	// statements were elided.
+	if len(ex.extraTxnState.stmtFingerprints) <= maxTxnFingerprintStmts {
+		ex.extraTxnState.stmtFingerprints = append(ex.extraTxnState.stmtFingerprints, fingerprint)
+	}
+	ex.extraTxnState.numRows += numRows
+}
+
+// resetTxnStats clears the statistics of the current transaction, when it
+// finishes or restarts.
+func (ex *connExecutor) resetTxnStats() {
+	ex.extraTxnState.stmtFingerprints = ex.extraTxnState.stmtFingerprints[:0]
+	ex.extraTxnState.numRows = 0
+}
+
 // Ctx returns the transaction's ctx, if we're inside a transaction, or the
 // session's context otherwise.
 func (ex *connExecutor) Ctx() context.Context {
@@ -1157,6 +1208,7 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 		case Sync:
 			// Note that the Sync result will flush results to the network connection.
 			res = ex.clientComm.CreateSyncResult(pos)
+			ex.maybeDeliverNotifications()
 			if draining {
 				// If we're draining, check whether this is a good time to finish the
 				// connection. If we're not inside a transaction, we stop processing
@@ -1191,6 +1243,10 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 		case Flush:
 			// Closing the res will flush the connection's buffer.
 			res = ex.clientComm.CreateFlushResult(pos)
+		case DeliverNotifications:
+			// Closing the res will flush the notifications to the client.
+			res = ex.clientComm.CreateFlushResult(pos)
+			ex.maybeDeliverNotifications()
 		default:
 			panic(fmt.Sprintf("unsupported command type: %T", cmd))
 		}
@@ -1347,6 +1403,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(fmt.Sprintf("unsupported cmd: %T", cmd))
 			}
@@ -1728,6 +1786,8 @@ func (ex *connExecutor) evalCtx(
 		EvalContext: tree.EvalContext{
 			Planner:       p,
 			Sequence:      p,
+			TimeSeries:    p,
+			Notifier:      &ex.notifications,
 			StmtTimestamp: stmtTS,
 
 			Txn:              txn,
@@ -1755,6 +1815,8 @@ func (ex *connExecutor) evalCtx(
 		DistSQLPlanner:  ex.server.cfg.DistSQLPlanner,
 		TxnModesSetter:  ex,
 		SchemaChangers:  &ex.extraTxnState.schemaChangers,
+		Notifications:   &ex.notifications,
+		SessionID:       ex.sessionID,
 		schemaAccessors: scInterface,
 	}
 }
@@ -1843,6 +1905,9 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 	if advInfo.code == rewind {
 		ex.extraTxnState.autoRetryCounter++
+		// The statements of the transaction are going to be executed again.
+		ex.resetTxnStats()
+		ex.notifications.reset()
 	}
 
 	// Handle transaction events which cause updates to txnState.
@@ -1850,6 +1915,11 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 	case noEvent:
 	case txnStart:
 	case txnCommit:
+		ex.appStats.recordTransaction(
+			ex.extraTxnState.stmtFingerprints, ex.extraTxnState.autoRetryCounter,
+			ex.extraTxnState.numRows, timeutil.Since(ex.state.sqlTimestamp).Seconds(),
+		)
+		ex.notifications.commit(ex.Ctx())
 		// If we have schema changers to run, release leases early so that schema
 		// changers can run.
 		if len(ex.extraTxnState.schemaChangers.schemaChangers) > 0 {
@@ -1910,6 +1980,21 @@ func (ex *connExecutor) newStatsCollector() sqlStatsCollector {
 	return newSQLStatsCollectorImpl(&ex.server.sqlStats, ex.appStats, ex.phaseTimes)
 }
 
+// maybeDeliverNotifications sends the notifications queued for the session to
+// the client. Like PostgreSQL, notifications are only delivered between
+// transactions: the notifications queued while the session is in a
+// transaction are delivered at the Sync which follows the end of the
+// transaction, as no DeliverNotifications command is pushed for them until
+// then.
+func (ex *connExecutor) maybeDeliverNotifications() {
+	if _, ok := ex.machine.CurState().(stateNoTxn); !ok {
+		return
+	}
+	for _, n := range ex.notifications.takeQueued(ex.Ctx()) {
+		ex.clientComm.BufferNotification(n)
+	}
+}
+
 // cancelQuery is part of the registrySession interface.
 func (ex *connExecutor) cancelQuery(queryID ClusterWideID) bool {
 	ex.mu.Lock()
@@ -1921,6 +2006,21 @@ func (ex *connExecutor) cancelQuery(queryID ClusterWideID) bool {
 	return false
 }
 
+// cancelActiveQueries is part of the registrySession interface.
+func (ex *connExecutor) cancelActiveQueries() bool {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	for _, queryMeta := range ex.mu.ActiveQueries {
+		queryMeta.cancel()
+	}
+	return len(ex.mu.ActiveQueries) > 0
+}
+
+// getCancelKey is part of the registrySession interface.
+func (ex *connExecutor) getCancelKey() pgwirecancel.BackendKeyData {
+	return ex.cancelKey
+}
+
 // cancelSession is part of the registrySession interface.
 func (ex *connExecutor) cancelSession() {
 	// TODO(abhimadan): figure out how to send a nice error message to the client.
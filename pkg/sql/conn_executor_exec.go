@@ -17,6 +17,7 @@ package sql
 import (
 	"context"
 	"fmt"
+	"runtime/pprof"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/profiler"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -73,16 +75,33 @@ func (ex *connExecutor) execStmt(
 		log.VEventf(ctx, 2, "executing: %s in state: %s", stmt, ex.machine.CurState())
 	}
 
+	ex.curStmtPlanned = false
+
 	// Run observer statements in a separate code path; their execution does not
 	// depend on the current transaction state.
 	if _, ok := stmt.AST.(tree.ObserverStatement); ok {
 		err := ex.runObserverStatement(ctx, stmt, res)
+		ex.maybeAuditStmt(ctx, stmt, pinfo, err)
 		return nil, nil, err
 	}
 
 	queryID := ex.generateID()
 	stmt.queryID = queryID
 
+	if profiler.Enabled.Get(&ex.server.cfg.Settings.SV) {
+		// Label the goroutine, and the goroutines it starts, so that the samples
+		// of the continuously captured profiles can be attributed to the
+		// statement. The fingerprint is kept in the statement so that it isn't
+		// computed again for the statement statistics.
+		stmt.AnonymizedStr = stmtFingerprint(stmt)
+		labels := pprof.Labels(
+			profiler.AppNameLabel, ex.sessionData.ApplicationName,
+			profiler.StmtLabel, stmt.AnonymizedStr,
+		)
+		pprof.SetGoroutineLabels(pprof.WithLabels(ctx, labels))
+		defer pprof.SetGoroutineLabels(ctx)
+	}
+
 	// Dispatch the statement for execution based on the current state.
 	var ev fsm.Event
 	var payload fsm.EventPayload
@@ -105,6 +124,17 @@ func (ex *connExecutor) execStmt(
 		panic(fmt.Sprintf("unexpected txn state: %#v", ex.machine.CurState()))
 	}
 
+	// The statements which were not handed to a planner are audited here. A
+	// statement starting an implicit transaction is executed again once the
+	// transaction is open.
+	if start, ok := ev.(eventTxnStart); !ex.curStmtPlanned && !(ok && start.ImplicitTxn.Get()) {
+		var stmtErr error
+		if perr, ok := payload.(payloadWithError); ok {
+			stmtErr = perr.errorCause()
+		}
+		ex.maybeAuditStmt(ctx, stmt, pinfo, stmtErr)
+	}
+
 	return ev, payload, err
 }
 
@@ -383,6 +413,7 @@ func (ex *connExecutor) execStmtInOpenState(
 	p.EvalContext().ActiveMemAcc = &constantMemAcc
 	defer constantMemAcc.Close(ctx)
 
+	ex.curStmtPlanned = true
 	if runInParallel {
 		cols, err := ex.execStmtInParallel(ctx, stmt, p, queryDone)
 		queryDone = nil
@@ -632,6 +663,12 @@ func (ex *connExecutor) execStmtInParallel(
 	queryMeta.isDistributed = false
 	ex.mu.Unlock()
 
+	// The statement is added to the statistics of the transaction here, since
+	// its own statistics are recorded asynchronously.
+	if !ex.stmtCounterDisabled && stmtStatsEnable.Get(&ex.server.cfg.Settings.SV) {
+		ex.recordTxnStmt(stmtFingerprint(stmt), 0 /* numRows */)
+	}
+
 	if err := ex.parallelizeQueue.Add(params, func() error {
 		res := &bufferedCommandResult{errOnly: true}
 
@@ -806,11 +843,12 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	if err != nil {
 		return err
 	}
-	ex.recordStatementSummary(
+	fingerprint := ex.recordStatementSummary(
 		planner, stmt, useDistSQL, optimizerPlanned,
 		ex.extraTxnState.autoRetryCounter, res.RowsAffected(), res.Err(),
 		&ex.server.EngineMetrics,
 	)
+	ex.recordTxnStmt(fingerprint, res.RowsAffected())
 	if ex.server.cfg.TestingKnobs.AfterExecute != nil {
 		ex.server.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res.Err())
 	}
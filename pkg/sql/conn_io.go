@@ -337,6 +337,23 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications represents a notice that asynchronous notifications
+// have been queued for the session. They are delivered to the client if the
+// session isn't in a transaction, and otherwise once the transaction ends.
+//
+// DeliverNotifications commands don't produce results other than the
+// notifications.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() {}
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -592,6 +609,10 @@ type ClientComm interface {
 	// CreateDrainResult creates a result for a Drain command.
 	CreateDrainResult(pos CmdPos) DrainResult
 
+	// BufferNotification buffers an asynchronous notification for the client.
+	// It is delivered with the next flush.
+	BufferNotification(n Notification)
+
 	// lockCommunication ensures that no further results are delivered to the
 	// client. The returned ClientLock can be queried to see what results have
 	// been already delivered to the client and to discard results that haven't
@@ -0,0 +1,110 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// CopyOutFormat is the data format of a COPY TO statement, with the defaults
+// of the unset options filled in.
+type CopyOutFormat struct {
+	// CSV is set if the data is in the CSV format rather than in the text
+	// format.
+	CSV       bool
+	Delimiter byte
+	// Null is the representation of NULL values.
+	Null string
+	// Header is set if the first line of the data holds the names of the
+	// columns. It can only be set for the CSV format.
+	Header bool
+}
+
+// MakeCopyOutFormat validates the options of a COPY TO statement and returns
+// the format of its data.
+func MakeCopyOutFormat(o *tree.CopyOptions) (CopyOutFormat, error) {
+	f := CopyOutFormat{Delimiter: '\t', Null: `\N`}
+	if o.DataFormat == tree.CopyFormatCSV {
+		f = CopyOutFormat{CSV: true, Delimiter: ',', Null: ""}
+	}
+	if o.Delimiter != nil {
+		d := o.Delimiter.RawString()
+		if len(d) != 1 {
+			return f, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"COPY delimiter must be a single one-byte character")
+		}
+		f.Delimiter = d[0]
+	}
+	if o.Null != nil {
+		f.Null = o.Null.RawString()
+	}
+	if o.Header != nil {
+		f.Header = bool(*o.Header)
+	}
+
+	switch {
+	case f.Delimiter == '\n' || f.Delimiter == '\r':
+		return f, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"COPY delimiter cannot be newline or carriage return")
+	case strings.ContainsAny(f.Null, "\r\n"):
+		return f, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"COPY null representation cannot use newline or carriage return")
+	case !f.CSV && f.Delimiter == '\\':
+		return f, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			`COPY delimiter cannot be "\"`)
+	case f.CSV && f.Delimiter == '"':
+		return f, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"COPY delimiter and quote must be different")
+	case strings.IndexByte(f.Null, f.Delimiter) >= 0:
+		return f, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"COPY delimiter must not appear in the NULL specification")
+	case !f.CSV && f.Header:
+		return f, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"COPY HEADER available only in CSV mode")
+	}
+	return f, nil
+}
+
+// CopyTo plans a COPY TO STDOUT statement. The rows of the table or query are
+// returned like the rows of a query, and the pgwire connection sends them to
+// the client with the Copy-out subprotocol.
+//
+// See: https://www.postgresql.org/docs/current/static/sql-copy.html
+func (p *planner) CopyTo(ctx context.Context, n *tree.CopyTo) (planNode, error) {
+	if _, err := MakeCopyOutFormat(&n.Options); err != nil {
+		return nil, err
+	}
+	sel := n.Statement
+	if sel == nil {
+		exprs := tree.SelectExprs{tree.StarSelectExpr()}
+		if len(n.Columns) > 0 {
+			exprs = make(tree.SelectExprs, len(n.Columns))
+			for i, col := range n.Columns {
+				exprs[i] = tree.SelectExpr{Expr: tree.NewUnresolvedName(string(col))}
+			}
+		}
+		sel = &tree.Select{
+			Select: &tree.SelectClause{
+				Exprs: exprs,
+				From:  &tree.From{Tables: tree.TableExprs{&n.Table}},
+			},
+		}
+	}
+	return p.newPlan(ctx, sel, nil /* desiredTypes */)
+}
@@ -43,10 +43,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 const crdbInternalName = "crdb_internal"
@@ -54,9 +56,11 @@ const crdbInternalName = "crdb_internal"
 var crdbInternal = virtualSchema{
 	name: crdbInternalName,
 	tables: []virtualSchemaTable{
+		crdbInternalAlertsTable,
 		crdbInternalBackwardDependenciesTable,
 		crdbInternalBuildInfoTable,
 		crdbInternalBuiltinFunctionsTable,
+		crdbInternalClusterContentionEventsTable,
 		crdbInternalClusterQueriesTable,
 		crdbInternalClusterSessionsTable,
 		crdbInternalClusterSettingsTable,
@@ -65,6 +69,7 @@ var crdbInternal = virtualSchema{
 		crdbInternalGossipNodesTable,
 		crdbInternalGossipAlertsTable,
 		crdbInternalGossipLivenessTable,
+		crdbInternalHotRangesTable,
 		crdbInternalIndexColumnsTable,
 		crdbInternalJobsTable,
 		crdbInternalKVNodeStatusTable,
@@ -74,11 +79,14 @@ var crdbInternal = virtualSchema{
 		crdbInternalLocalSessionsTable,
 		crdbInternalLocalMetricsTable,
 		crdbInternalPartitionsTable,
+		crdbInternalPersistedStmtStatsTable,
+		crdbInternalPersistedTxnStatsTable,
 		crdbInternalRangesTable,
 		crdbInternalRuntimeInfoTable,
 		crdbInternalSchemaChangesTable,
 		crdbInternalSessionTraceTable,
 		crdbInternalSessionVariablesTable,
+		crdbInternalStmtPlansTable,
 		crdbInternalStmtStatsTable,
 		crdbInternalTableColumnsTable,
 		crdbInternalTableIndexesTable,
@@ -610,6 +618,204 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+// crdbInternalStmtPlansTable exposes the plan history of the statements
+// executed on this node (see plan_history.go).
+var crdbInternalStmtPlansTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.node_statement_plans (
+  node_id          INT NOT NULL,
+  application_name STRING NOT NULL,
+  key              STRING NOT NULL,
+  plan_id          STRING NOT NULL,
+  plan             STRING NOT NULL,
+  previous_plan_id STRING,
+  first_seen       TIMESTAMP NOT NULL,
+  last_seen        TIMESTAMP NOT NULL,
+  count            INT NOT NULL,
+  service_lat_avg  FLOAT NOT NULL,
+  service_lat_var  FLOAT NOT NULL,
+  regressed        BOOL NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+			return err
+		}
+
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return errors.New("cannot access sql statistics from this context")
+		}
+
+		leaseMgr := p.LeaseMgr()
+		nodeID := tree.NewDInt(tree.DInt(int64(leaseMgr.execCfg.NodeID.Get())))
+
+		// Retrieve the application names and sort them to ensure the
+		// output is deterministic.
+		var appNames []string
+		sqlStats.Lock()
+		for n := range sqlStats.apps {
+			appNames = append(appNames, n)
+		}
+		sqlStats.Unlock()
+		sort.Strings(appNames)
+
+		for _, appName := range appNames {
+			appStats := sqlStats.getStatsForApplication(appName)
+
+			var stmts []string
+			stmtPlans := make(map[string]*stmtPlans)
+			appStats.Lock()
+			for stmt, plans := range appStats.plans {
+				stmts = append(stmts, stmt)
+				stmtPlans[stmt] = plans
+			}
+			appStats.Unlock()
+			sort.Strings(stmts)
+
+			for _, stmt := range stmts {
+				for _, plan := range stmtPlans[stmt].get() {
+					prevID := tree.DNull
+					if plan.prevID != "" {
+						prevID = tree.NewDString(plan.prevID)
+					}
+					if err := addRow(
+						nodeID,
+						tree.NewDString(appName),
+						tree.NewDString(stmt),
+						tree.NewDString(plan.id),
+						tree.NewDString(plan.plan),
+						prevID,
+						tree.MakeDTimestamp(plan.firstSeen, time.Microsecond),
+						tree.MakeDTimestamp(plan.lastSeen, time.Microsecond),
+						tree.NewDInt(tree.DInt(plan.count)),
+						tree.NewDFloat(tree.DFloat(plan.serviceLat.Mean)),
+						tree.NewDFloat(tree.DFloat(plan.serviceLat.GetVariance(plan.count))),
+						tree.MakeDBool(tree.DBool(plan.regressed)),
+					); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalPersistedStmtStatsTable exposes the statement statistics
+// persisted by all the nodes, aggregated per hourly or daily bucket.
+var crdbInternalPersistedStmtStatsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  aggregated_ts       TIMESTAMP NOT NULL,
+  application_name    STRING NOT NULL,
+  flags               STRING NOT NULL,
+  key                 STRING NOT NULL,
+  node_ids            INT[] NOT NULL,
+  count               INT NOT NULL,
+  first_attempt_count INT NOT NULL,
+  max_retries         INT NOT NULL,
+  last_error          STRING,
+  rows_avg            FLOAT NOT NULL,
+  rows_var            FLOAT NOT NULL,
+  parse_lat_avg       FLOAT NOT NULL,
+  parse_lat_var       FLOAT NOT NULL,
+  plan_lat_avg        FLOAT NOT NULL,
+  plan_lat_var        FLOAT NOT NULL,
+  run_lat_avg         FLOAT NOT NULL,
+  run_lat_var         FLOAT NOT NULL,
+  service_lat_avg     FLOAT NOT NULL,
+  service_lat_var     FLOAT NOT NULL,
+  overhead_lat_avg    FLOAT NOT NULL,
+  overhead_lat_var    FLOAT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+			return err
+		}
+		return stmtStatsTable.forEachAggregated(ctx, p,
+			func(key tree.Datums, nodeIDs *tree.DArray, stats []byte) error {
+				var s roachpb.StatementStatistics
+				if err := protoutil.Unmarshal(stats, &s); err != nil {
+					return err
+				}
+				errString := tree.DNull
+				if s.LastErr != "" {
+					errString = tree.NewDString(s.LastErr)
+				}
+				// The key columns are aggregated_ts, fingerprint, flags and app_name.
+				return addRow(
+					key[0],
+					key[3],
+					key[2],
+					key[1],
+					nodeIDs,
+					tree.NewDInt(tree.DInt(s.Count)),
+					tree.NewDInt(tree.DInt(s.FirstAttemptCount)),
+					tree.NewDInt(tree.DInt(s.MaxRetries)),
+					errString,
+					tree.NewDFloat(tree.DFloat(s.NumRows.Mean)),
+					tree.NewDFloat(tree.DFloat(s.NumRows.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.ParseLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.ParseLat.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.PlanLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.PlanLat.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.RunLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.RunLat.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.ServiceLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.ServiceLat.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.OverheadLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.OverheadLat.GetVariance(s.Count))),
+				)
+			})
+	},
+}
+
+// crdbInternalPersistedTxnStatsTable exposes the transaction statistics
+// persisted by all the nodes, aggregated per hourly or daily bucket.
+var crdbInternalPersistedTxnStatsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.transaction_statistics (
+  aggregated_ts       TIMESTAMP NOT NULL,
+  application_name    STRING NOT NULL,
+  key                 STRING NOT NULL,
+  node_ids            INT[] NOT NULL,
+  count               INT NOT NULL,
+  max_retries         INT NOT NULL,
+  rows_avg            FLOAT NOT NULL,
+  rows_var            FLOAT NOT NULL,
+  service_lat_avg     FLOAT NOT NULL,
+  service_lat_var     FLOAT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+			return err
+		}
+		return txnStatsTable.forEachAggregated(ctx, p,
+			func(key tree.Datums, nodeIDs *tree.DArray, stats []byte) error {
+				var s roachpb.TransactionStatistics
+				if err := protoutil.Unmarshal(stats, &s); err != nil {
+					return err
+				}
+				// The key columns are aggregated_ts, fingerprint and app_name.
+				return addRow(
+					key[0],
+					key[2],
+					key[1],
+					nodeIDs,
+					tree.NewDInt(tree.DInt(s.Count)),
+					tree.NewDInt(tree.DInt(s.MaxRetries)),
+					tree.NewDFloat(tree.DFloat(s.NumRows.Mean)),
+					tree.NewDFloat(tree.DFloat(s.NumRows.GetVariance(s.Count))),
+					tree.NewDFloat(tree.DFloat(s.ServiceLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.ServiceLat.GetVariance(s.Count))),
+				)
+			})
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 var crdbInternalSessionTraceTable = virtualSchemaTable{
@@ -1624,6 +1830,184 @@ CREATE TABLE crdb_internal.ranges (
 	},
 }
 
+// crdbInternalHotRangesTable exposes the ranges with the highest load on
+// each store of the cluster.
+var crdbInternalHotRangesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.hot_ranges (
+  node_id                INT NOT NULL,
+  store_id               INT NOT NULL,
+  range_id               INT NOT NULL,
+  start_pretty           STRING NOT NULL,
+  end_pretty             STRING NOT NULL,
+  database               STRING NOT NULL,
+  "table"                STRING NOT NULL,
+  "index"                STRING NOT NULL,
+  queries_per_second     FLOAT NOT NULL,
+  write_bytes_per_second FLOAT NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.hot_ranges"); err != nil {
+			return err
+		}
+		response, err := p.ExecCfg().StatusServer.HotRanges(ctx, &serverpb.HotRangesRequest{})
+		if err != nil {
+			return err
+		}
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "hot ranges of node %d: %s", rpcErr.NodeID, rpcErr.Message)
+		}
+		for _, r := range response.Ranges {
+			if err := addRow(
+				tree.NewDInt(tree.DInt(r.NodeID)),
+				tree.NewDInt(tree.DInt(r.StoreID)),
+				tree.NewDInt(tree.DInt(r.RangeID)),
+				tree.NewDString(r.StartKey),
+				tree.NewDString(r.EndKey),
+				tree.NewDString(r.DatabaseName),
+				tree.NewDString(r.TableName),
+				tree.NewDString(r.IndexName),
+				tree.NewDFloat(tree.DFloat(r.QueriesPerSecond)),
+				tree.NewDFloat(tree.DFloat(r.WriteBytesPerSecond)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalAlertsTable exposes the alert rules firing on every node of the
+// cluster.
+var crdbInternalAlertsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.alerts (
+  node_id  INT NOT NULL,
+  store_id INT,
+  rule     STRING NOT NULL,
+  metric   STRING NOT NULL,
+  value    FLOAT NOT NULL,
+  since    TIMESTAMP NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.alerts"); err != nil {
+			return err
+		}
+		response, err := p.ExecCfg().StatusServer.Alerts(ctx, &serverpb.AlertsRequest{})
+		if err != nil {
+			return err
+		}
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "alerts of node %d: %s", rpcErr.NodeID, rpcErr.Message)
+		}
+		for _, a := range response.Alerts {
+			storeID := tree.DNull
+			if a.StoreID != 0 {
+				storeID = tree.NewDInt(tree.DInt(a.StoreID))
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(a.NodeID)),
+				storeID,
+				tree.NewDString(a.Rule),
+				tree.NewDString(a.Metric),
+				tree.NewDFloat(tree.DFloat(a.Value)),
+				tree.MakeDTimestamp(a.Since, time.Microsecond),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalClusterContentionEventsTable exposes the requests blocked by
+// the write intents of other transactions, aggregated by key on every node of
+// the cluster.
+var crdbInternalClusterContentionEventsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.cluster_contention_events (
+  node_id         INT NOT NULL,
+  store_id        INT NOT NULL,
+  timestamp       TIMESTAMP NOT NULL,
+  count           INT NOT NULL,
+  duration        INTERVAL NOT NULL,
+  blocking_txn_id UUID NOT NULL,
+  blocked_txn_id  UUID,
+  key             BYTES,
+  pretty_key      STRING NOT NULL,
+  database        STRING,
+  "table"         STRING,
+  "index"         STRING
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.cluster_contention_events"); err != nil {
+			return err
+		}
+		descs, err := p.Tables().getAllDescriptors(ctx, p.txn)
+		if err != nil {
+			return err
+		}
+		dbNames := make(map[sqlbase.ID]string)
+		tables := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+		for _, desc := range descs {
+			switch desc := desc.(type) {
+			case *sqlbase.TableDescriptor:
+				tables[desc.ID] = desc
+			case *sqlbase.DatabaseDescriptor:
+				dbNames[desc.ID] = desc.Name
+			}
+		}
+
+		response, err := p.ExecCfg().StatusServer.ContentionEvents(ctx, &serverpb.ContentionEventsRequest{})
+		if err != nil {
+			return err
+		}
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "contention events of node %d: %s", rpcErr.NodeID, rpcErr.Message)
+		}
+		for _, e := range response.Events {
+			blockedTxnID := tree.DNull
+			if e.BlockedTxnID != (uuid.UUID{}) {
+				blockedTxnID = tree.NewDUuid(tree.DUuid{UUID: e.BlockedTxnID})
+			}
+			key := tree.DNull
+			if e.Key != nil {
+				key = tree.NewDBytes(tree.DBytes(e.Key))
+			}
+			dbName, tableName, indexName := tree.DNull, tree.DNull, tree.DNull
+			if table, ok := tables[sqlbase.ID(e.TableID)]; ok {
+				tableName = tree.NewDString(table.Name)
+				if name, ok := dbNames[table.ParentID]; ok {
+					dbName = tree.NewDString(name)
+				}
+				if index, err := table.FindIndexByID(sqlbase.IndexID(e.IndexID)); err == nil {
+					indexName = tree.NewDString(index.Name)
+				}
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(e.NodeID)),
+				tree.NewDInt(tree.DInt(e.StoreID)),
+				tree.MakeDTimestamp(e.Timestamp, time.Microsecond),
+				tree.NewDInt(tree.DInt(e.Count)),
+				&tree.DInterval{Duration: duration.Duration{Nanos: e.DurationNanos}},
+				tree.NewDUuid(tree.DUuid{UUID: e.BlockingTxnID}),
+				blockedTxnID,
+				key,
+				tree.NewDString(e.PrettyKey),
+				dbName,
+				tableName,
+				indexName,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalZonesTable decodes and exposes the zone configs in the
 // system.zones table.
 var crdbInternalZonesTable = virtualSchemaTable{
@@ -19,6 +19,7 @@ import (
 	"regexp"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -43,7 +44,17 @@ var userTableName = tree.NewTableName("system", "users")
 //   notes: postgres allows the creation of users with an empty password. We do
 //          as well, but disallow password authentication for these users.
 func (p *planner) CreateUser(ctx context.Context, n *tree.CreateUser) (planNode, error) {
-	return p.CreateUserNode(ctx, n.Name, n.Password, n.IfNotExists, false /* isRole */, "CREATE USER")
+	node, err := p.CreateUserNode(ctx, n.Name, n.Password, n.IfNotExists, false /* isRole */, "CREATE USER")
+	if err != nil {
+		return nil, err
+	}
+	if n.ValidUntil != nil {
+		node.validUntil, err = p.TypeAsString(n.ValidUntil, "CREATE USER")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
 }
 
 // CreateUserNode creates a "create user" plan node. This can be called from CREATE USER or CREATE ROLE.
@@ -72,7 +83,7 @@ func (p *planner) CreateUserNode(
 }
 
 func (n *CreateUserNode) startExec(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(&params.extendedEvalCtx.Settings.SV)
 	if err != nil {
 		return err
 	}
@@ -138,6 +149,13 @@ func (n *CreateUserNode) startExec(params runParams) error {
 		)
 	}
 
+	validUntil, err := n.userAuthInfo.resolveValidUntil()
+	if err != nil {
+		return err
+	}
+	if validUntil != nil {
+		return setUserValidUntil(params, normalizedUsername, validUntil)
+	}
 	return nil
 }
 
@@ -195,6 +213,8 @@ var errNoUserNameSpecified = errors.New("no username specified")
 type userAuthInfo struct {
 	name     func() (string, error)
 	password func() (string, error)
+	// validUntil is the expiration of the password; nil if it is not set.
+	validUntil func() (string, error)
 }
 
 func (p *planner) getUserAuthInfo(nameE, passwordE tree.Expr, ctx string) (userAuthInfo, error) {
@@ -213,7 +233,7 @@ func (p *planner) getUserAuthInfo(nameE, passwordE tree.Expr, ctx string) (userA
 }
 
 // resolve returns the actual user name and (hashed) password.
-func (ua *userAuthInfo) resolve() (string, []byte, error) {
+func (ua *userAuthInfo) resolve(sv *settings.Values) (string, []byte, error) {
 	name, err := ua.name()
 	if err != nil {
 		return "", nil, err
@@ -235,8 +255,11 @@ func (ua *userAuthInfo) resolve() (string, []byte, error) {
 		if resolvedPassword == "" {
 			return "", nil, security.ErrEmptyPassword
 		}
+		if err := checkPasswordPolicy(sv, resolvedPassword); err != nil {
+			return "", nil, err
+		}
 
-		hashedPassword, err = security.HashPassword(resolvedPassword)
+		hashedPassword, err = hashPassword(sv, resolvedPassword)
 		if err != nil {
 			return "", nil, err
 		}
@@ -244,3 +267,16 @@ func (ua *userAuthInfo) resolve() (string, []byte, error) {
 
 	return normalizedUsername, hashedPassword, nil
 }
+
+// resolveValidUntil returns the expiration of the password, DNull if the
+// password never expires, or nil if the expiration is not set.
+func (ua *userAuthInfo) resolveValidUntil() (tree.Datum, error) {
+	if ua.validUntil == nil {
+		return nil, nil
+	}
+	s, err := ua.validUntil()
+	if err != nil {
+		return nil, err
+	}
+	return parseValidUntil(s)
+}
@@ -340,6 +340,16 @@ func (p *planner) getPlanForDesc(
 		plan: scan,
 	}
 	ds.info.NumBackfillColumns = scan.numBackfillColumns
+
+	// Only expose the rows that the row-level security policies of the
+	// table, if any, allow the current user to see.
+	filter, err := p.RowLevelSecurityFilter(ctx, desc, sqlbase.TableDescriptor_Policy_SELECT)
+	if err != nil {
+		return planDataSource{}, err
+	}
+	if filter != nil {
+		return p.addRowLevelSecurityFilter(ctx, ds, filter)
+	}
 	return ds, nil
 }
 
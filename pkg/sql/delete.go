@@ -128,6 +128,13 @@ func (p *planner) Delete(
 
 	tracing.AnnotateTrace()
 
+	// Restrict the DELETE to the rows that the row-level security policies
+	// of the table allow the current user to delete.
+	where, err := p.rowLevelSecurityWhere(ctx, desc, sqlbase.TableDescriptor_Policy_DELETE, n.Where)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine the source for the deletion: the rows that are read,
 	// filtered, limited, ordered, etc, prior to the deletion. One would
 	// think there is only so much one wants to do with rows prior to a
@@ -136,7 +143,7 @@ func (p *planner) Delete(
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
 		Exprs: sqlbase.ColumnsSelectors(rd.FetchCols, true /* forUpdateOrDelete */),
 		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
-		Where: n.Where,
+		Where: where,
 	}, n.OrderBy, n.Limit, nil /*with*/, nil /*desiredTypes*/, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
@@ -159,7 +166,7 @@ func (p *planner) Delete(
 	}
 
 	// Finally, handle RETURNING, if any.
-	r, err := p.Returning(ctx, dn, n.Returning, desiredTypes, alias)
+	r, err := p.Returning(ctx, dn, n.Returning, desiredTypes, alias, desc)
 	if err != nil {
 		// We close explicitly here to release the node to the pool.
 		dn.Close(ctx)
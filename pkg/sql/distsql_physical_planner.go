@@ -331,6 +331,10 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *scanNode:
+		if n.lockStr != roachpb.LOCK_NONE {
+			// The table readers of DistSQL don't acquire locks.
+			return 0, newQueryNotSupportedError("locking scans not supported")
+		}
 		rec := canDistribute
 		if n.softLimit != 0 {
 			// We don't yet recommend distributing plans where soft limits propagate
@@ -426,6 +430,11 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return 0, setNotSupportedError
 
 	case *projectSetNode:
+		for _, e := range n.exprs {
+			if err := dsp.checkExpr(e); err != nil {
+				return 0, err
+			}
+		}
 		return dsp.checkSupportForNode(n.source)
 
 	case *zeroNode:
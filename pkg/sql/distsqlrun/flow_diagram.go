@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"strconv"
 
@@ -707,5 +708,52 @@ func extractStatsFromSpans(
 			stats[i] = append(stats[i], dss.StatsForQueryPlan()...)
 		}
 	}
+	for pid, contentionTime := range extractContentionTimeFromSpans(spans) {
+		processorStats[pid] = append(processorStats[pid],
+			fmt.Sprintf("KV contention time: %v", contentionTime.Round(time.Microsecond)))
+	}
 	return processorStats, streamStats
 }
+
+// extractContentionTimeFromSpans sums, for every processor, the time its KV
+// requests spent blocked on the write intents of other transactions. This time
+// is logged by the intent resolver in spans which are descendants of the
+// processor's span.
+func extractContentionTimeFromSpans(spans []tracing.RecordedSpan) map[int]time.Duration {
+	parents := make(map[uint64]uint64, len(spans))
+	processors := make(map[uint64]int)
+	for _, span := range spans {
+		parents[span.SpanID] = span.ParentSpanID
+		if pid, ok := span.Tags[processorIDTagKey]; ok {
+			if i, err := strconv.Atoi(pid); err == nil {
+				processors[span.SpanID] = i
+			}
+		}
+	}
+
+	contentionTimes := make(map[int]time.Duration)
+	for _, span := range spans {
+		var contentionTime time.Duration
+		for _, l := range span.Logs {
+			for _, f := range l.Fields {
+				if f.Key != roachpb.ContentionTimeLogKey {
+					continue
+				}
+				if nanos, err := strconv.ParseInt(f.Value, 10, 64); err == nil {
+					contentionTime += time.Duration(nanos)
+				}
+			}
+		}
+		if contentionTime == 0 {
+			continue
+		}
+		// Find the closest ancestor span which belongs to a processor.
+		for id := span.SpanID; id != 0; id = parents[id] {
+			if pid, ok := processors[id]; ok {
+				contentionTimes[pid] += contentionTime
+				break
+			}
+		}
+	}
+	return contentionTimes
+}
@@ -21,10 +21,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 // compareDiagrams verifies that two JSON strings decode to equal diagramData
@@ -400,3 +402,30 @@ func TestPlanDiagramJoin(t *testing.T) {
 
 	compareDiagrams(t, buf.String(), expected)
 }
+
+func TestExtractContentionTimeFromSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	contentionLog := func(nanos string) tracing.RecordedSpan_LogRecord {
+		return tracing.RecordedSpan_LogRecord{
+			Fields: []tracing.RecordedSpan_LogRecord_Field{
+				{Key: roachpb.ContentionTimeLogKey, Value: nanos},
+			},
+		}
+	}
+	spans := []tracing.RecordedSpan{
+		{SpanID: 1},
+		{SpanID: 2, ParentSpanID: 1, Tags: map[string]string{processorIDTagKey: "3"}},
+		{SpanID: 3, ParentSpanID: 2},
+		{SpanID: 4, ParentSpanID: 3, Logs: []tracing.RecordedSpan_LogRecord{contentionLog("1000")}},
+		{SpanID: 5, ParentSpanID: 2, Logs: []tracing.RecordedSpan_LogRecord{contentionLog("2000")}},
+		{SpanID: 6, ParentSpanID: 1, Tags: map[string]string{processorIDTagKey: "4"}},
+		// Contention outside of any processor is ignored.
+		{SpanID: 7, ParentSpanID: 1, Logs: []tracing.RecordedSpan_LogRecord{contentionLog("4000")}},
+	}
+
+	expected := map[int]time.Duration{3: 3 * time.Microsecond}
+	if actual := extractContentionTimeFromSpans(spans); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
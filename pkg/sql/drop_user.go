@@ -208,6 +208,17 @@ func (n *DropUserNode) startExec(params runParams) error {
 		}
 
 		numRoleMembershipsDeleted += rowsAffected
+
+		// Forget the password expiration and failed logins of the user.
+		if _, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
+			params.ctx,
+			"drop-user-login-status",
+			params.p.txn,
+			`DELETE FROM system.user_login_status WHERE username = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
 	}
 
 	if numRoleMembershipsDeleted > 0 {
@@ -93,6 +93,16 @@ const (
 	EventLogSetZoneConfig EventLogType = "set_zone_config"
 	// EventLogRemoveZoneConfig is recorded when a zone config is removed.
 	EventLogRemoveZoneConfig EventLogType = "remove_zone_config"
+
+	// EventLogAlertFiring is recorded when an alert rule starts firing.
+	EventLogAlertFiring EventLogType = "alert_firing"
+	// EventLogAlertResolved is recorded when an alert rule stops firing.
+	EventLogAlertResolved EventLogType = "alert_resolved"
+
+	// EventLogUserLoginFailure is recorded when a user fails to log in.
+	EventLogUserLoginFailure EventLogType = "user_login_failure"
+	// EventLogUnlockUser is recorded when a locked out user is unlocked.
+	EventLogUnlockUser EventLogType = "unlock_user"
 )
 
 // EventLogSetClusterSettingDetail is the json details for a settings change.
@@ -102,6 +112,19 @@ type EventLogSetClusterSettingDetail struct {
 	User        string
 }
 
+// EventLogUserLoginFailureDetail is the json details for a failed login.
+type EventLogUserLoginFailureDetail struct {
+	User       string
+	RemoteAddr string `json:",omitempty"`
+	Method     string
+	Error      string
+	// FailedLogins is the number of consecutive failed logins of the user,
+	// set when the failure was caused by a wrong password.
+	FailedLogins int64 `json:",omitempty"`
+	// LockedOut is set when the failure locked the user out.
+	LockedOut bool `json:",omitempty"`
+}
+
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	*InternalExecutor
@@ -17,11 +17,19 @@ package sql
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -81,6 +89,11 @@ import (
 //  - the number of rows that were produced. For troubleshooting.
 //  - the status of the query (OK for success, ERROR or full error
 //    message upon error). Needed for auditing and troubleshooting.
+//
+// Statements audited because of the role of the user (sql.audit.roles) or
+// because of their class (sql.audit.statement_classes) are recorded in the
+// audit log as structured entries instead, see auditLogEntry below:
+// I180211 07:30:48.832004 317 sql/exec_log.go:90  [client=127.0.0.1:62503,user=root,n1] 14 {"Label":"exec","Session":"1523d6b6ae1e2e300000000000000001",...}
 
 // logStatementsExecuteEnabled causes the Executor to log executed
 // statements and, if any, resulting errors.
@@ -90,6 +103,235 @@ var logStatementsExecuteEnabled = settings.RegisterBoolSetting(
 	false,
 )
 
+// auditRoles is the list of roles whose members have all their
+// statements recorded in the audit log.
+var auditRoles = settings.RegisterValidatedStringSetting(
+	"sql.audit.roles",
+	"comma-separated list of roles whose members have all their statements recorded "+
+		"in the SQL audit log",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := parseAuditRoles(s)
+		return err
+	},
+)
+
+// auditStatementClasses is the list of classes of statements which are
+// recorded in the audit log regardless of the tables they access.
+var auditStatementClasses = settings.RegisterValidatedStringSetting(
+	"sql.audit.statement_classes",
+	"comma-separated list of classes of statements recorded in the SQL audit log regardless "+
+		"of the tables they access (ddl: schema changes, dcl: GRANT, REVOKE and "+
+		"user and role management)",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := parseAuditStatementClasses(s)
+		return err
+	},
+)
+
+// The classes of statements which can be audited.
+const (
+	auditClassDDL = "ddl"
+	auditClassDCL = "dcl"
+)
+
+func parseAuditRoles(s string) ([]string, error) {
+	var roles []string
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		role, err := NormalizeAndValidateUsernameNoBlacklist(r)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func parseAuditStatementClasses(s string) ([]string, error) {
+	var classes []string
+	for _, c := range strings.Split(s, ",") {
+		switch c = strings.ToLower(strings.TrimSpace(c)); c {
+		case "":
+		case auditClassDDL, auditClassDCL:
+			classes = append(classes, c)
+		default:
+			return nil, errors.Errorf("unknown statement class %q", c)
+		}
+	}
+	return classes, nil
+}
+
+// AuditConfig holds the audited roles and statement classes. They are
+// parsed from the cluster settings when the settings change, rather than
+// for every statement.
+type AuditConfig struct {
+	// v holds an auditConfigValue.
+	v atomic.Value
+}
+
+type auditConfigValue struct {
+	roles   []string
+	classes []string
+}
+
+// NewAuditConfig creates an AuditConfig which tracks the sql.audit settings.
+func NewAuditConfig(st *cluster.Settings) *AuditConfig {
+	c := &AuditConfig{}
+	update := func() {
+		// The settings are validated when they are set.
+		var v auditConfigValue
+		v.roles, _ = parseAuditRoles(auditRoles.Get(&st.SV))
+		v.classes, _ = parseAuditStatementClasses(auditStatementClasses.Get(&st.SV))
+		c.v.Store(v)
+	}
+	update()
+	auditRoles.SetOnChange(&st.SV, update)
+	auditStatementClasses.SetOnChange(&st.SV, update)
+	return c
+}
+
+func (c *AuditConfig) get() auditConfigValue {
+	return c.v.Load().(auditConfigValue)
+}
+
+// enabled returns whether any role or statement class is audited.
+func (c *AuditConfig) enabled() bool {
+	v := c.get()
+	return len(v.roles) > 0 || len(v.classes) > 0
+}
+
+// auditStatementClass returns the class of the statement for auditing
+// purposes, or an empty string if it doesn't belong to any class.
+func auditStatementClass(stmt tree.Statement) string {
+	switch stmt.(type) {
+	case *tree.Grant, *tree.Revoke, *tree.GrantRole, *tree.RevokeRole,
+		*tree.CreateUser, *tree.DropUser, *tree.CreateRole, *tree.DropRole,
+		*tree.AlterUserSetPassword, *tree.AlterUserUnlock:
+		return auditClassDCL
+	}
+	if stmt.StatementType() == tree.DDL {
+		return auditClassDDL
+	}
+	return ""
+}
+
+// auditTriggers returns the reasons, besides the audit mode of the tables
+// it accesses, for which a statement must be recorded in the audit log:
+// "class:<class>" for the audited statement classes and "role:<role>" for
+// the audited roles the user is a member of.
+func (p *planner) auditTriggers(ctx context.Context, stmt tree.Statement) []string {
+	cfg := p.execCfg.AuditConfig.get()
+	if len(cfg.classes) == 0 && len(cfg.roles) == 0 {
+		return nil
+	}
+	// Statements issued internally by the database itself are not audited.
+	if p.SessionData().RemoteAddr == nil || stmt == nil {
+		return nil
+	}
+
+	var triggers []string
+	if class := auditStatementClass(stmt); class != "" {
+		for _, c := range cfg.classes {
+			if c == class {
+				triggers = append(triggers, "class:"+class)
+				break
+			}
+		}
+	}
+	if len(cfg.roles) > 0 {
+		user := p.SessionData().User
+		memberOf, err := p.auditMemberOf(ctx, user)
+		if err != nil {
+			// Better record too many statements than miss some.
+			log.Warningf(ctx, "unable to look up the roles of user %s for auditing: %v", user, err)
+			return append(triggers, "role:?")
+		}
+		for _, role := range cfg.roles {
+			if _, ok := memberOf[role]; ok || role == user {
+				triggers = append(triggers, "role:"+role)
+			}
+		}
+	}
+	return triggers
+}
+
+// auditMemberOf returns the roles the user is a member of. Statements may
+// be recorded outside of any transaction, so the version of the
+// role_members table which validates the cache of MemberOfWithAdminOption is
+// looked up with a lease of its own.
+func (p *planner) auditMemberOf(ctx context.Context, user string) (map[string]bool, error) {
+	leaseMgr := p.execCfg.LeaseManager
+	tableDesc, _, err := leaseMgr.AcquireByName(
+		ctx, p.execCfg.Clock.Now(), keys.SystemDatabaseID, sqlbase.RoleMembersTable.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := leaseMgr.Release(tableDesc); err != nil {
+			log.Warning(ctx, err)
+		}
+	}()
+	return p.memberOfWithAdminOptionAtVersion(ctx, user, tableDesc.Version)
+}
+
+// maybeAuditStmt records in the audit log a statement which was not handed
+// to a planner, such as a transaction control statement or a statement sent
+// in an aborted transaction, if the role of the user or the class of the
+// statement is audited.
+func (ex *connExecutor) maybeAuditStmt(
+	ctx context.Context, stmt Statement, pinfo *tree.PlaceholderInfo, err error,
+) {
+	if !ex.server.cfg.AuditConfig.enabled() {
+		return
+	}
+	p := ex.newPlanner(ctx, nil /* txn */, ex.server.cfg.Clock.PhysicalTime())
+	triggers := p.auditTriggers(ctx, stmt.AST)
+	if len(triggers) == 0 {
+		return
+	}
+	plStr := (&tree.QueryArguments{}).String()
+	if pinfo != nil {
+		plStr = pinfo.Values.String()
+	}
+	p.logAuditEntry(ctx, "exec", triggers, nil /* tables */, stmt.AST.String(), plStr,
+		ex.phaseTimes[sessionQueryReceived], 0 /* rows */, err)
+}
+
+// auditLogEntry is the structured record of a statement in the audit log.
+type auditLogEntry struct {
+	// Label indicates where the statement was executed, as in the other
+	// entries.
+	Label           string
+	Session         string
+	User            string
+	ApplicationName string
+	ClientAddress   string
+	// Triggers lists the reasons for which the statement was recorded.
+	Triggers []string
+	// Tables lists the audited tables accessed by the statement.
+	Tables       []auditLogTable `json:",omitempty"`
+	Statement    string
+	Placeholders string
+	LatencyMs    float64
+	Rows         int
+	// Outcome is OK or ERROR.
+	Outcome string
+	Error   string `json:",omitempty"`
+}
+
+// auditLogTable is an audited table accessed by a statement.
+type auditLogTable struct {
+	Name string
+	ID   sqlbase.ID
+	// Mode is READ or READWRITE.
+	Mode string
+}
+
 // maybeLogStatement conditionally records the current statement
 // (p.curPlan) to the exec / audit logs.
 func (p *planner) maybeLogStatement(ctx context.Context, lbl string, rows int, err error) {
@@ -110,8 +352,9 @@ func (p *planner) maybeLogStatementInternal(
 	logV := log.V(2)
 	logExecuteEnabled := logStatementsExecuteEnabled.Get(&s.settings.SV)
 	auditEventsDetected := len(p.curPlan.auditEvents) != 0
+	auditTriggers := p.auditTriggers(ctx, p.curPlan.AST)
 
-	if !logV && !logExecuteEnabled && !auditEventsDetected {
+	if !logV && !logExecuteEnabled && !auditEventsDetected && len(auditTriggers) == 0 {
 		return
 	}
 
@@ -154,7 +397,17 @@ func (p *planner) maybeLogStatementInternal(
 	}
 
 	// Now log!
-	if auditEventsDetected {
+	if len(auditTriggers) > 0 {
+		var tables []auditLogTable
+		for _, ev := range p.curPlan.auditEvents {
+			mode := "READ"
+			if ev.writing {
+				mode = "READWRITE"
+			}
+			tables = append(tables, auditLogTable{Name: ev.desc.GetName(), ID: ev.desc.GetID(), Mode: mode})
+		}
+		p.logAuditEntry(ctx, lbl, auditTriggers, tables, stmtStr, plStr, startTime, rows, err)
+	} else if auditEventsDetected {
 		logger := p.execCfg.AuditLogger
 		logger.Logf(ctx, "%s %q %s %q %s %.3f %d %s",
 			lbl, appName, logTrigger, stmtStr, plStr, age, rows, auditErrStr)
@@ -171,6 +424,41 @@ func (p *planner) maybeLogStatementInternal(
 	}
 }
 
+// logAuditEntry records a statement in the audit log as an auditLogEntry.
+func (p *planner) logAuditEntry(
+	ctx context.Context,
+	lbl string,
+	triggers []string,
+	tables []auditLogTable,
+	stmtStr, plStr string,
+	startTime time.Time,
+	rows int,
+	err error,
+) {
+	entry := auditLogEntry{
+		Label:           lbl,
+		Session:         p.extendedEvalCtx.SessionID.String(),
+		User:            p.SessionData().User,
+		ApplicationName: p.SessionData().ApplicationName,
+		ClientAddress:   p.SessionData().RemoteAddr.String(),
+		Triggers:        triggers,
+		Tables:          tables,
+		Statement:       stmtStr,
+		Placeholders:    plStr,
+		LatencyMs:       float64(timeutil.Now().Sub(startTime).Nanoseconds()) / 1e6,
+		Rows:            rows,
+		Outcome:         "OK",
+	}
+	if err != nil {
+		entry.Outcome = "ERROR"
+		entry.Error = err.Error()
+	}
+	// The entry only contains strings and numbers: it can always be
+	// marshaled.
+	entryJSON, _ := json.Marshal(entry)
+	p.execCfg.AuditLogger.Logf(ctx, "%s", entryJSON)
+}
+
 // maybeAudit marks the current plan being constructed as flagged
 // for auditing if the table being touched has an auditing mode set.
 // This is later picked up by maybeLogStatement() above.
@@ -0,0 +1,219 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestAuditStatementClass(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		sql   string
+		class string
+	}{
+		{`SELECT 1`, ``},
+		{`INSERT INTO t VALUES (1)`, ``},
+		{`SET application_name = 'foo'`, ``},
+		{`CREATE TABLE t (x INT)`, auditClassDDL},
+		{`ALTER TABLE t ADD COLUMN y INT`, auditClassDDL},
+		{`DROP DATABASE d`, auditClassDDL},
+		{`GRANT SELECT ON t TO foo`, auditClassDCL},
+		{`REVOKE admin FROM foo`, auditClassDCL},
+		{`CREATE USER foo`, auditClassDCL},
+		{`ALTER USER foo UNLOCK`, auditClassDCL},
+		{`DROP ROLE foo`, auditClassDCL},
+	}
+	for _, tc := range testCases {
+		stmt, err := parser.ParseOne(tc.sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if class := auditStatementClass(stmt); class != tc.class {
+			t.Errorf("%s: expected class %q, got %q", tc.sql, tc.class, class)
+		}
+	}
+}
+
+func TestParseAuditSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	roles, err := parseAuditRoles(" admin, Auditors ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := []string{"admin", "auditors"}; !reflect.DeepEqual(roles, e) {
+		t.Errorf("expected roles %v, got %v", e, roles)
+	}
+	if _, err := parseAuditRoles("admin,-foo"); !testutils.IsError(err, `username "-foo" invalid`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	classes, err := parseAuditStatementClasses("DDL,dcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := []string{auditClassDDL, auditClassDCL}; !reflect.DeepEqual(classes, e) {
+		t.Errorf("expected classes %v, got %v", e, classes)
+	}
+	if _, err := parseAuditStatementClasses("ddl,dml"); !testutils.IsError(
+		err, `unknown statement class "dml"`,
+	) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// readAuditLogEntries returns the structured entries of the audit log files
+// in dir.
+func readAuditLogEntries(dir string) ([]auditLogEntry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*sql-audit*.log"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []auditLogEntry
+	for _, name := range files {
+		// Skip the symlink to the latest file.
+		if fi, err := os.Lstat(name); err != nil {
+			return nil, err
+		} else if fi.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		decoder := log.NewEntryDecoder(f)
+		for {
+			var logEntry log.Entry
+			if err := decoder.Decode(&logEntry); err != nil {
+				break
+			}
+			// The entry follows the logging tags and the counter.
+			i := strings.Index(logEntry.Message, "{")
+			if i < 0 {
+				continue
+			}
+			var entry auditLogEntry
+			if err := json.Unmarshal([]byte(logEntry.Message[i:]), &entry); err != nil {
+				f.Close()
+				return nil, errors.Wrapf(err, "unable to decode %q", logEntry.Message)
+			}
+			entries = append(entries, entry)
+		}
+		f.Close()
+	}
+	return entries, nil
+}
+
+// TestAuditLogRolesAndClasses verifies that every statement of a member of
+// an audited role, including the transaction control statements and the
+// statements sent in an aborted transaction, and the statements of the
+// audited classes are recorded in the audit log with their outcome.
+func TestAuditLogRolesAndClasses(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	execCfg := s.ExecutorConfig().(ExecutorConfig)
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `CREATE ROLE auditors`)
+	r.Exec(t, `CREATE USER testuser`)
+	r.Exec(t, `GRANT auditors TO testuser`)
+	r.Exec(t, `SET CLUSTER SETTING sql.audit.roles = 'auditors'`)
+	r.Exec(t, `SET CLUSTER SETTING sql.audit.statement_classes = 'ddl'`)
+	testutils.SucceedsSoon(t, func() error {
+		if cfg := execCfg.AuditConfig.get(); len(cfg.roles) != 1 || len(cfg.classes) != 1 {
+			return errors.Errorf("settings not yet propagated: %+v", cfg)
+		}
+		return nil
+	})
+
+	// Statements of root are only audited because of their class.
+	r.Exec(t, `CREATE TABLE t (x INT)`)
+	r.Exec(t, `SELECT 1`)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestAuditLogRolesAndClasses", url.User("testuser"))
+	defer cleanup()
+	userDB, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer userDB.Close()
+	txn, err := userDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec(`SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec(`SELECT nonexistent`); !testutils.IsError(err, "column .* does not exist") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := txn.Exec(`SELECT 2`); !testutils.IsError(err, "current transaction is aborted") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	log.Flush()
+	entries, err := readAuditLogEntries(sc.GetDirectory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		user, stmt, triggers, outcome string
+	}
+	var results []result
+	for _, e := range entries {
+		results = append(results, result{
+			user: e.User, stmt: e.Statement, triggers: strings.Join(e.Triggers, ","), outcome: e.Outcome,
+		})
+	}
+	expected := []result{
+		{"root", `CREATE TABLE t (x INT)`, "class:ddl", "OK"},
+		{"testuser", `BEGIN TRANSACTION`, "role:auditors", "OK"},
+		{"testuser", `SELECT 1`, "role:auditors", "OK"},
+		{"testuser", `SELECT nonexistent`, "role:auditors", "ERROR"},
+		{"testuser", `SELECT 2`, "role:auditors", "ERROR"},
+		{"testuser", `ROLLBACK TRANSACTION`, "role:auditors", "OK"},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("expected audit log entries:\n%+v\ngot:\n%+v", expected, results)
+	}
+}
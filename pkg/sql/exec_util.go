@@ -46,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -187,6 +188,12 @@ var (
 		Measurement: "SQL Statements",
 		Unit:        metric.Unit_COUNT,
 	}
+	MetaPlanRegression = metric.Metadata{
+		Name:        "sql.plan.regressions",
+		Help:        "Number of new statement plans much slower than the plan they replaced",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
 	MetaDistSQLSelect = metric.Metadata{
 		Name:        "sql.distsql.select.count",
 		Help:        "Number of DistSQL SELECT statements",
@@ -275,13 +282,17 @@ type ExecutorConfig struct {
 	StatusServer     serverpb.StatusServer
 	MetricsRecorder  *status.MetricsRecorder
 	SessionRegistry  *SessionRegistry
+	Notifications    *NotificationRegistry
 	JobRegistry      *jobs.Registry
 	VirtualSchemas   *VirtualSchemaHolder
 	DistSQLPlanner   *DistSQLPlanner
 	TableStatsCache  *stats.TableStatisticsCache
 	ExecLogger       *log.SecondaryLogger
 	AuditLogger      *log.SecondaryLogger
+	AuditConfig      *AuditConfig
 	InternalExecutor *InternalExecutor
+	// TimeSeriesServer is used to query the internal time series database.
+	TimeSeriesServer tree.TimeSeriesQuerier
 
 	TestingKnobs              *ExecutorTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
@@ -740,6 +751,9 @@ type SessionArgs struct {
 	// RemoteAddr is the client's address. This is nil iff this is an internal
 	// client.
 	RemoteAddr net.Addr
+	// CancelKey is the key sent to the client to cancel the queries of the
+	// session. It is zero for internal clients.
+	CancelKey pgwirecancel.BackendKeyData
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -769,7 +783,13 @@ func (r *SessionRegistry) deregister(id ClusterWideID) {
 
 type registrySession interface {
 	user() string
+	// getCancelKey returns the key sent to the client to cancel the queries of
+	// the session, or zero if there is none.
+	getCancelKey() pgwirecancel.BackendKeyData
 	cancelQuery(queryID ClusterWideID) bool
+	// cancelActiveQueries cancels all the active queries of the session and
+	// returns whether there were any.
+	cancelActiveQueries() bool
 	cancelSession()
 	// serialize serializes a Session into a serverpb.Session
 	// that can be served over RPC.
@@ -800,6 +820,23 @@ func (r *SessionRegistry) CancelQuery(queryIDStr string, username string) (bool,
 	return false, fmt.Errorf("query ID %s not found", queryID)
 }
 
+// CancelQueryByKey looks up the session with the given cancel key in the
+// session registry and cancels its active queries. The key is its own
+// authorization, as in PostgreSQL.
+func (r *SessionRegistry) CancelQueryByKey(key pgwirecancel.BackendKeyData) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, session := range r.store {
+		if session.getCancelKey() == key {
+			return session.cancelActiveQueries(), nil
+		}
+	}
+
+	// The key isn't included in the error to avoid echoing guesses.
+	return false, fmt.Errorf("session not found")
+}
+
 // CancelSession looks up the specified session in the session registry and cancels it.
 func (r *SessionRegistry) CancelSession(sessionIDBytes []byte, username string) (bool, error) {
 	sessionID := BytesToClusterWideID(sessionIDBytes)
@@ -1630,12 +1667,17 @@ func (s *sqlStatsCollectorImpl) RecordStatement(
 	numRows int,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
-) {
-	s.appStats.recordStatement(
+) string {
+	return s.appStats.recordStatement(
 		stmt, distSQLUsed, optUsed, automaticRetryCount, numRows, err,
 		parseLat, planLat, runLat, svcLat, ovhLat)
 }
 
+// RecordPlan is part of the sqlStatsCollector interface.
+func (s *sqlStatsCollectorImpl) RecordPlan(stmt, plan string, svcLat float64) *planRegression {
+	return s.appStats.recordPlan(stmt, plan, svcLat)
+}
+
 // SQLStats is part of the sqlStatsCollector interface.
 func (s *sqlStatsCollectorImpl) SQLStats() *sqlStats {
 	return s.sqlStats
@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -75,6 +76,9 @@ type EngineMetrics struct {
 	SQLExecLatency        *metric.Histogram
 	DistSQLServiceLatency *metric.Histogram
 	SQLServiceLatency     *metric.Histogram
+	// The number of plans found to be much slower than the plan previously
+	// used by the same statement.
+	PlanRegressionCount *metric.Counter
 }
 
 // EngineMetrics implements the metric.Struct interface
@@ -85,7 +89,9 @@ func (EngineMetrics) MetricStruct() {}
 
 // recordStatementSummery gathers various details pertaining to the
 // last executed statement/query and performs the associated
-// accounting in the passed-in EngineMetrics.
+// accounting in the passed-in EngineMetrics. It returns the fingerprint
+// of the statement, or an empty string if statistics aren't being
+// collected.
 // - distSQLUsed reports whether the query was distributed.
 // - automaticRetryCount is the count of implicit txn retries
 //   so far.
@@ -100,9 +106,9 @@ func (ex *connExecutor) recordStatementSummary(
 	rowsAffected int,
 	err error,
 	m *EngineMetrics,
-) {
+) string {
 	if ex.stmtCounterDisabled {
-		return
+		return ""
 	}
 
 	phaseTimes := planner.statsCollector.PhaseTimes()
@@ -149,10 +155,20 @@ func (ex *connExecutor) recordStatementSummary(
 		}
 	}
 
-	planner.statsCollector.RecordStatement(
+	fingerprint := planner.statsCollector.RecordStatement(
 		stmt, distSQLUsed, optUsed, automaticRetryCount, rowsAffected, err,
 		parseLat, planLat, runLat, svcLat, execOverhead,
 	)
+	sv := &planner.execCfg.Settings.SV
+	if fingerprint != "" && err == nil && planner.curPlan.plan != nil &&
+		maxPlansPerStmt.Get(sv) > 0 && rand.Float64() < planSampleRate.Get(sv) {
+		ctx := planner.EvalContext().Ctx()
+		plan := planFingerprint(ctx, planner.curPlan.plan, planner.curPlan.subqueryPlans)
+		if r := planner.statsCollector.RecordPlan(fingerprint, plan, svcLat); r != nil {
+			m.PlanRegressionCount.Inc(1)
+			log.Warning(ctx, r)
+		}
+	}
 
 	if log.V(2) {
 		// ages since significant epochs
@@ -174,4 +190,5 @@ func (ex *connExecutor) recordStatementSummary(
 			sessionAge,
 		)
 	}
+	return fingerprint
 }
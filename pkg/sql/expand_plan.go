@@ -334,15 +334,18 @@ func doExpandPlan(
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserUnlockNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
@@ -835,15 +838,18 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserUnlockNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
@@ -17,6 +17,8 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -33,6 +35,20 @@ import (
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
+	if n.Columns != nil {
+		if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionColumnPrivileges) {
+			return nil, errors.Errorf("GRANT on columns requires all nodes to be upgraded to %s",
+				cluster.VersionByKey(cluster.VersionColumnPrivileges))
+		}
+		privileges, err := privilege.ExpandColumnLevel(n.Privileges)
+		if err != nil {
+			return nil, err
+		}
+		return p.changeColumnPrivileges(ctx, n.Targets, n.Grantees, n.Columns,
+			func(col *sqlbase.ColumnDescriptor, grantee string) {
+				col.GrantPrivileges(grantee, privileges)
+			})
+	}
 	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
 		privDesc.Grant(grantee, n.Privileges)
 	})
@@ -48,6 +64,16 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error) {
+	if n.Columns != nil {
+		privileges, err := privilege.ExpandColumnLevel(n.Privileges)
+		if err != nil {
+			return nil, err
+		}
+		return p.changeColumnPrivileges(ctx, n.Targets, n.Grantees, n.Columns,
+			func(col *sqlbase.ColumnDescriptor, grantee string) {
+				col.RevokePrivileges(grantee, privileges)
+			})
+	}
 	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
 		privDesc.Revoke(grantee, n.Privileges)
 	})
@@ -59,23 +85,12 @@ func (p *planner) changePrivileges(
 	grantees tree.NameList,
 	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
 ) (planNode, error) {
-	// Check whether grantees exists
-	users, err := p.GetAllUsersAndRoles(ctx)
-	if err != nil {
+	if err := p.checkGrantees(ctx, grantees); err != nil {
 		return nil, err
 	}
 
-	// We're allowed to grant/revoke privileges to/from the "public" role even though
-	// it does not exist: add it to the list of all users and roles.
-	users[sqlbase.PublicRole] = true // isRole
-
-	for _, grantee := range grantees {
-		if _, ok := users[string(grantee)]; !ok {
-			return nil, errors.Errorf("user or role %s does not exist", &grantee)
-		}
-	}
-
 	var descriptors []sqlbase.DescriptorProto
+	var err error
 	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
 	// TODO(vivek): check if the cache can be used.
 	p.runWithOptions(resolveFlags{skipCache: true}, func() {
@@ -127,3 +142,88 @@ func (p *planner) changePrivileges(
 	}
 	return newZeroNode(nil /* columns */), nil
 }
+
+// changeColumnPrivileges is the counterpart of changePrivileges for the
+// privileges on individual columns of the target tables.
+func (p *planner) changeColumnPrivileges(
+	ctx context.Context,
+	targets tree.TargetList,
+	grantees tree.NameList,
+	columns tree.NameList,
+	changePrivilege func(*sqlbase.ColumnDescriptor, string),
+) (planNode, error) {
+	if targets.Databases != nil {
+		return nil, pgerror.NewError(pgerror.CodeInvalidGrantOperationError,
+			"column privileges are only supported on tables")
+	}
+	if err := p.checkGrantees(ctx, grantees); err != nil {
+		return nil, err
+	}
+
+	var descriptors []sqlbase.DescriptorProto
+	var err error
+	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		descriptors, err = getDescriptorsFromTargetList(ctx, p, targets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := p.txn.NewBatch()
+	for _, descriptor := range descriptors {
+		d, ok := descriptor.(*sqlbase.TableDescriptor)
+		if !ok || !d.IsTable() {
+			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"%q is not a table", descriptor.GetName())
+		}
+		if err := p.CheckPrivilege(ctx, d, privilege.GRANT); err != nil {
+			return nil, err
+		}
+		for _, name := range columns {
+			c, err := d.FindActiveColumnByName(string(name))
+			if err != nil {
+				return nil, err
+			}
+			col, err := d.FindColumnByID(c.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, grantee := range grantees {
+				changePrivilege(col, string(grantee))
+			}
+		}
+
+		if !d.Dropped() {
+			if err := p.writeSchemaChangeToBatch(
+				ctx, d, sqlbase.InvalidMutationID, b); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
+
+// checkGrantees verifies that the grantees of a GRANT or REVOKE statement
+// exist.
+func (p *planner) checkGrantees(ctx context.Context, grantees tree.NameList) error {
+	users, err := p.GetAllUsersAndRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	// We're allowed to grant/revoke privileges to/from the "public" role even though
+	// it does not exist: add it to the list of all users and roles.
+	users[sqlbase.PublicRole] = true // isRole
+
+	for _, grantee := range grantees {
+		if _, ok := users[string(grantee)]; !ok {
+			return errors.Errorf("user or role %s does not exist", &grantee)
+		}
+	}
+	return nil
+}
@@ -152,6 +152,7 @@ func (p *planner) makeIndexJoin(
 	}
 	table.initOrdering(0 /* exactPrefix */, p.EvalContext())
 	table.disableBatchLimit()
+	table.lockStr, table.lockWaitPolicy = origScan.lockStr, origScan.lockWaitPolicy
 
 	primaryKeyColumns, colIDtoRowIndex := processIndexJoinColumns(table, indexScan)
 
@@ -206,8 +206,10 @@ CREATE TABLE information_schema.column_privileges (
 		return forEachTableDesc(ctx, p, dbContext, virtualMany, func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
 			dbNameStr := tree.NewDString(db.Name)
 			scNameStr := tree.NewDString(scName)
-			columndata := privilege.List{privilege.SELECT, privilege.INSERT, privilege.UPDATE} // privileges for column level granularity
+			columndata := privilege.ColumnLevel // privileges for column level granularity
+			tableBits := make(map[string]uint32, len(table.Privileges.Users))
 			for _, u := range table.Privileges.Users {
+				tableBits[u.User] = u.Privileges
 				for _, priv := range columndata {
 					if priv.Mask()&u.Privileges != 0 {
 						for _, cd := range table.Columns {
@@ -227,6 +229,31 @@ CREATE TABLE information_schema.column_privileges (
 					}
 				}
 			}
+			// Privileges granted on individual columns, unless the grantee
+			// already holds them on the whole table.
+			for _, cd := range table.Columns {
+				if cd.Privileges == nil {
+					continue
+				}
+				for _, u := range cd.Privileges.Users {
+					for _, priv := range columndata {
+						if priv.Mask()&u.Privileges != 0 && priv.Mask()&tableBits[u.User] == 0 {
+							if err := addRow(
+								tree.DNull,                     // grantor
+								tree.NewDString(u.User),        // grantee
+								dbNameStr,                      // table_catalog
+								scNameStr,                      // table_schema
+								tree.NewDString(table.Name),    // table_name
+								tree.NewDString(cd.Name),       // column_name
+								tree.NewDString(priv.String()), // privilege_type
+								tree.DNull,                     // is_grantable
+							); err != nil {
+								return err
+							}
+						}
+					}
+				}
+			}
 			return nil
 		})
 	},
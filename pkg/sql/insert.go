@@ -85,11 +85,15 @@ func (p *planner) Insert(
 	if err != nil {
 		return nil, err
 	}
-	if err := p.CheckPrivilege(ctx, desc, privilege.INSERT); err != nil {
+	// If the privileges were only granted on some of the columns, the
+	// columns inserted into and updated are checked below.
+	insertColErrs, err := p.CheckColumnPrivileges(ctx, desc, privilege.INSERT)
+	if err != nil {
 		return nil, err
 	}
+	var updateColErrs []error
 	if n.OnConflict != nil && !n.OnConflict.DoNothing {
-		if err := p.CheckPrivilege(ctx, desc, privilege.UPDATE); err != nil {
+		if updateColErrs, err = p.CheckColumnPrivileges(ctx, desc, privilege.UPDATE); err != nil {
 			return nil, err
 		}
 	}
@@ -126,6 +130,31 @@ func (p *planner) Insert(
 			return nil, err
 		}
 	}
+	if !n.DefaultValues() {
+		// DEFAULT VALUES only needs INSERT on some column of the table,
+		// which CheckColumnPrivileges has already verified.
+		if err := checkPrivilegeOnColumns(desc, insertColErrs, insertCols); err != nil {
+			return nil, err
+		}
+	}
+	if updateColErrs != nil {
+		updateExprs, _, err := upsertExprsAndIndex(desc, *n.OnConflict, insertCols)
+		if err != nil {
+			return nil, err
+		}
+		var names tree.NameList
+		for _, expr := range updateExprs {
+			names = append(names, expr.Names...)
+		}
+		updateCols, err := p.processColumns(desc, names,
+			true /* ensureColumns */, false /* allowMutations */)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPrivilegeOnColumns(desc, updateColErrs, updateCols); err != nil {
+			return nil, err
+		}
+	}
 
 	// maxInsertIdx is the highest column index we are allowed to insert into -
 	// in the presence of computed columns, when we don't explicitly specify the
@@ -241,6 +270,14 @@ func (p *planner) Insert(
 		columns = sqlbase.ResultColumnsFromColDescs(desc.Columns)
 	}
 
+	// Verify that the inserted rows satisfy the row-level security
+	// policies of the table, if any, in addition to its CHECK constraints.
+	checkHelper, err := p.makeMutationCheckHelper(
+		ctx, tn, desc, fkTables, sqlbase.TableDescriptor_Policy_INSERT)
+	if err != nil {
+		return nil, err
+	}
+
 	// At this point, everything is ready for either an insertNode or an upserNode.
 
 	var node batchedPlanNode
@@ -250,7 +287,7 @@ func (p *planner) Insert(
 		// The upsert path has a separate constructor.
 		node, err = p.newUpsertNode(
 			ctx, n, desc, ri, tn, alias, rows, rowsNeeded, columns,
-			defaultExprs, computeExprs, computedCols, fkTables, desiredTypes)
+			defaultExprs, computeExprs, computedCols, fkTables, checkHelper, desiredTypes)
 		if err != nil {
 			return nil, err
 		}
@@ -262,7 +299,7 @@ func (p *planner) Insert(
 			columns: columns,
 			run: insertRun{
 				ti:           tableInserter{ri: ri},
-				checkHelper:  fkTables[desc.ID].CheckHelper,
+				checkHelper:  checkHelper,
 				rowsNeeded:   rowsNeeded,
 				computedCols: computedCols,
 				computeExprs: computeExprs,
@@ -278,7 +315,7 @@ func (p *planner) Insert(
 	}
 
 	// Finally, handle RETURNING, if any.
-	r, err := p.Returning(ctx, node, n.Returning, desiredTypes, alias)
+	r, err := p.Returning(ctx, node, n.Returning, desiredTypes, alias, desc)
 	if err != nil {
 		// We close explicitly here to release the node to the pool.
 		node.Close(ctx)
@@ -485,7 +522,7 @@ func (n *insertNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	}
 
 	// Run the CHECK constraints, if any.
-	if len(n.run.checkHelper.Exprs) > 0 {
+	if n.run.checkHelper.HasChecks() {
 		if err := n.run.checkHelper.LoadRow(n.run.ti.ri.InsertColIDtoRowIndex, rowVals, false); err != nil {
 			return err
 		}
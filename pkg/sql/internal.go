@@ -617,6 +617,11 @@ func (icc *internalClientComm) CreateDrainResult(pos CmdPos) DrainResult {
 	panic("unimplemented")
 }
 
+// BufferNotification is part of the ClientComm interface.
+func (icc *internalClientComm) BufferNotification(n Notification) {
+	panic("unimplemented")
+}
+
 // noopClientLock is an implementation of ClientLock that says that no results
 // have been communicated to the client.
 type noopClientLock struct {
@@ -395,6 +395,9 @@ func pickUsingColumn(
 		return idx, nil, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
 			"column \"%s\" specified in USING clause does not exist in %s table", colName, context)
 	}
+	if err := cols[idx].PrivilegeErr; err != nil {
+		return idx, nil, err
+	}
 	return idx, cols[idx].Typ, nil
 }
 
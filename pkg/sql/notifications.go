@@ -0,0 +1,493 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// maxQueuedNotifications is the maximum number of notifications queued for
+// a session which hasn't received them yet. Further notifications are
+// dropped until the session catches up.
+var maxQueuedNotifications = settings.RegisterValidatedIntSetting(
+	"sql.notifications.max_queued_per_session",
+	"maximum number of asynchronous notifications queued for delivery to a session",
+	1000,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("cannot be set to a value less than 1: %d", v)
+		}
+		return nil
+	},
+)
+
+// maxNotificationsPerTxn is the maximum number of notifications a
+// transaction can send.
+var maxNotificationsPerTxn = settings.RegisterValidatedIntSetting(
+	"sql.notifications.max_per_transaction",
+	"maximum number of asynchronous notifications sent by a transaction",
+	1000,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("cannot be set to a value less than 1: %d", v)
+		}
+		return nil
+	},
+)
+
+// maxNotificationBytesPerTxn is the maximum total size of the channels and
+// payloads of the notifications sent by a transaction, which are gossiped
+// together.
+var maxNotificationBytesPerTxn = settings.RegisterByteSizeSetting(
+	"sql.notifications.max_bytes_per_transaction",
+	"maximum total size of the asynchronous notifications sent by a transaction",
+	64<<10, /* 64 KiB */
+)
+
+// maxNotificationBytesPerNode is the maximum total size of the notifications
+// published by a node which are still kept in gossip, that is, which were
+// published less than notificationTTL ago. The notifications of the
+// transactions committing beyond that are dropped.
+var maxNotificationBytesPerNode = settings.RegisterByteSizeSetting(
+	"sql.notifications.max_bytes_per_node",
+	"maximum total size of the asynchronous notifications published by a node in the last minute",
+	4<<20, /* 4 MiB */
+)
+
+// notificationPublishRate is the rate at which a node publishes the
+// notifications of its committed transactions. Transactions committing faster
+// wait for their notifications to be published.
+var notificationPublishRate = settings.RegisterValidatedFloatSetting(
+	"sql.notifications.publish_rate",
+	"maximum number of transactions per second whose asynchronous notifications are published by a node",
+	100,
+	func(v float64) error {
+		if v <= 0 {
+			return errors.Errorf("must be positive: %f", v)
+		}
+		return nil
+	},
+)
+
+// notificationPublishBurst is the number of transactions whose notifications
+// can be published at once, beyond the publish rate.
+const notificationPublishBurst = 100
+
+// maxNotificationPayloadBytes is the maximum size of the payload of a
+// notification, as in PostgreSQL.
+const maxNotificationPayloadBytes = 8000
+
+// notificationTTL is the time for which the notifications are kept in
+// gossip. It bounds the time a node can be partitioned from the gossip
+// network without missing notifications.
+const notificationTTL = time.Minute
+
+// Notification is an asynchronous notification sent with NOTIFY or
+// pg_notify().
+type Notification struct {
+	Channel string
+	Payload string
+	// ProcessID is the process ID of the cancel key of the notifying session,
+	// which is sent to the clients receiving the notification.
+	ProcessID uint32
+}
+
+// NotificationRegistry delivers notifications to the sessions listening on
+// their channels. The notifications sent by the transactions committed on a
+// node are gossiped to all the nodes, and the registry of each node delivers
+// them to its sessions.
+type NotificationRegistry struct {
+	st     *cluster.Settings
+	gossip *gossip.Gossip
+	nodeID *base.NodeIDContainer
+
+	// limiter limits the rate of the publications of the node.
+	limiter *rate.Limiter
+
+	// seq is used to make the gossip keys of the notifications of the node
+	// unique. It starts at the creation time of the registry so that the keys
+	// are not reused when the node restarts. Accessed atomically.
+	seq int64
+
+	// published tracks the publications of the node which are still kept in
+	// gossip, to bound their total size.
+	published struct {
+		syncutil.Mutex
+		// entries are ordered by expiration.
+		entries []notificationPublication
+		// bytes is the total size of the entries.
+		bytes int64
+	}
+
+	mu struct {
+		syncutil.Mutex
+		listeners map[*notificationListener]struct{}
+	}
+}
+
+// NewNotificationRegistry creates a NotificationRegistry.
+func NewNotificationRegistry(
+	st *cluster.Settings, g *gossip.Gossip, nodeID *base.NodeIDContainer,
+) *NotificationRegistry {
+	r := &NotificationRegistry{
+		st:      st,
+		gossip:  g,
+		nodeID:  nodeID,
+		limiter: rate.NewLimiter(rate.Limit(notificationPublishRate.Get(&st.SV)), notificationPublishBurst),
+		seq:     timeutil.Now().UnixNano(),
+	}
+	notificationPublishRate.SetOnChange(&st.SV, func() {
+		r.limiter.SetLimit(rate.Limit(notificationPublishRate.Get(&st.SV)))
+	})
+	r.mu.listeners = make(map[*notificationListener]struct{})
+	g.RegisterCallback(gossip.MakePrefixPattern(gossip.KeySQLNotificationPrefix), r.gossipUpdate)
+	return r
+}
+
+// notificationPublication is the size and expiration of the gossip info
+// holding the notifications of a transaction.
+type notificationPublication struct {
+	expiration time.Time
+	size       int64
+}
+
+// publish gossips the notifications sent by a committed transaction. It
+// waits if the node publishes too many notifications, and fails if the
+// notifications it published which are still in gossip are too large.
+func (r *NotificationRegistry) publish(ctx context.Context, ns []Notification) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	var buf []byte
+	for _, n := range ns {
+		buf = encoding.EncodeUvarintAscending(buf, uint64(n.ProcessID))
+		buf = encoding.EncodeBytesAscending(buf, []byte(n.Channel))
+		buf = encoding.EncodeBytesAscending(buf, []byte(n.Payload))
+	}
+	size := int64(len(buf))
+
+	r.published.Lock()
+	defer r.published.Unlock()
+	now := timeutil.Now()
+	for len(r.published.entries) > 0 && !now.Before(r.published.entries[0].expiration) {
+		r.published.bytes -= r.published.entries[0].size
+		r.published.entries = r.published.entries[1:]
+	}
+	if max := maxNotificationBytesPerNode.Get(&r.st.SV); r.published.bytes+size > max {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"notifications published by the node too large: maximum is %d bytes per %s",
+			max, notificationTTL)
+	}
+	key := gossip.MakeSQLNotificationKey(r.nodeID.Get(), atomic.AddInt64(&r.seq, 1))
+	if err := r.gossip.AddInfo(key, buf, notificationTTL); err != nil {
+		return err
+	}
+	r.published.entries = append(r.published.entries, notificationPublication{
+		expiration: now.Add(notificationTTL),
+		size:       size,
+	})
+	r.published.bytes += size
+	return nil
+}
+
+func decodeNotifications(buf []byte) ([]Notification, error) {
+	var ns []Notification
+	for len(buf) > 0 {
+		var n Notification
+		var pid uint64
+		var channel, payload []byte
+		var err error
+		if buf, pid, err = encoding.DecodeUvarintAscending(buf); err != nil {
+			return nil, err
+		}
+		if buf, channel, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		if buf, payload, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		n.ProcessID = uint32(pid)
+		n.Channel = string(channel)
+		n.Payload = string(payload)
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// gossipUpdate is the gossip callback that fires when a transaction sending
+// notifications committed on any node.
+func (r *NotificationRegistry) gossipUpdate(key string, value roachpb.Value) {
+	buf, err := value.GetBytes()
+	var ns []Notification
+	if err == nil {
+		ns, err = decodeNotifications(buf)
+	}
+	if err != nil {
+		log.Errorf(context.Background(), "unable to decode notifications %s: %v", key, err)
+		return
+	}
+	maxQueued := int(maxQueuedNotifications.Get(&r.st.SV))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for l := range r.mu.listeners {
+		l.enqueue(ns, maxQueued)
+	}
+}
+
+func (r *NotificationRegistry) register(l *notificationListener) {
+	r.mu.Lock()
+	r.mu.listeners[l] = struct{}{}
+	r.mu.Unlock()
+}
+
+func (r *NotificationRegistry) deregister(l *notificationListener) {
+	r.mu.Lock()
+	delete(r.mu.listeners, l)
+	r.mu.Unlock()
+}
+
+// notificationListener queues the notifications of the channels a session
+// listens on until the session delivers them to its client.
+type notificationListener struct {
+	// stmtBuf is the command buffer of the session, into which a
+	// DeliverNotifications command is pushed when notifications are queued.
+	stmtBuf *StmtBuf
+
+	mu struct {
+		syncutil.Mutex
+		channels map[string]struct{}
+		queue    []Notification
+		// signaled is set when a DeliverNotifications command has been pushed
+		// and the queue hasn't been taken since. While it is set, no other
+		// command is pushed: if the session is in a transaction when it
+		// processes the command, the queue is only taken at the first Sync
+		// outside of a transaction (see maybeDeliverNotifications).
+		signaled bool
+		// dropped counts the notifications dropped because the queue was full.
+		dropped int
+	}
+}
+
+func (l *notificationListener) enqueue(ns []Notification, maxQueued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queued := false
+	for _, n := range ns {
+		if _, ok := l.mu.channels[n.Channel]; !ok {
+			continue
+		}
+		if len(l.mu.queue) >= maxQueued {
+			l.mu.dropped++
+			continue
+		}
+		l.mu.queue = append(l.mu.queue, n)
+		queued = true
+	}
+	if queued && !l.mu.signaled {
+		l.mu.signaled = true
+		// The push fails if the session is closing, in which case the
+		// notifications don't matter.
+		_ = l.stmtBuf.Push(context.Background(), DeliverNotifications{})
+	}
+}
+
+// sessionNotifications holds the state of a session related to asynchronous
+// notifications.
+type sessionNotifications struct {
+	registry *NotificationRegistry
+	// stmtBuf is nil for internal sessions, which can send notifications but
+	// can't listen.
+	stmtBuf   *StmtBuf
+	processID uint32
+
+	// listener is set while the session listens on at least one channel.
+	listener *notificationListener
+
+	// pending are the notifications sent by the current transaction. They
+	// are published when it commits. Identical notifications are folded, as
+	// in PostgreSQL.
+	pending    []Notification
+	pendingSet map[Notification]struct{}
+	// pendingBytes is the total size of the channels and payloads of the
+	// pending notifications.
+	pendingBytes int64
+}
+
+var _ tree.Notifier = &sessionNotifications{}
+
+// Notify is part of the tree.Notifier interface.
+func (sn *sessionNotifications) Notify(_ context.Context, channel, payload string) error {
+	if sn.registry == nil {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"notifications are not supported in this context")
+	}
+	if channel == "" {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"channel name cannot be empty")
+	}
+	if len(payload) >= maxNotificationPayloadBytes {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"payload string too long: maximum is %d bytes", maxNotificationPayloadBytes-1)
+	}
+	n := Notification{Channel: channel, Payload: payload, ProcessID: sn.processID}
+	if _, ok := sn.pendingSet[n]; ok {
+		return nil
+	}
+	sv := &sn.registry.st.SV
+	if max := maxNotificationsPerTxn.Get(sv); int64(len(sn.pending)) >= max {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"too many notifications sent by the transaction: maximum is %d", max)
+	}
+	size := int64(len(channel) + len(payload))
+	if max := maxNotificationBytesPerTxn.Get(sv); sn.pendingBytes+size > max {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"notifications sent by the transaction too large: maximum is %d bytes", max)
+	}
+	if sn.pendingSet == nil {
+		sn.pendingSet = make(map[Notification]struct{})
+	}
+	sn.pendingBytes += size
+	sn.pendingSet[n] = struct{}{}
+	sn.pending = append(sn.pending, n)
+	return nil
+}
+
+// listen starts listening on a channel.
+func (sn *sessionNotifications) listen(channel string) error {
+	if sn.registry == nil || sn.stmtBuf == nil {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"LISTEN is not supported in this context")
+	}
+	if sn.listener == nil {
+		sn.listener = &notificationListener{stmtBuf: sn.stmtBuf}
+		sn.listener.mu.channels = make(map[string]struct{})
+		sn.registry.register(sn.listener)
+	}
+	sn.listener.mu.Lock()
+	sn.listener.mu.channels[channel] = struct{}{}
+	sn.listener.mu.Unlock()
+	return nil
+}
+
+// unlisten stops listening on a channel, or on all the channels if all is
+// set. The notifications already queued are still delivered.
+func (sn *sessionNotifications) unlisten(channel string, all bool) {
+	if sn.listener == nil {
+		return
+	}
+	sn.listener.mu.Lock()
+	defer sn.listener.mu.Unlock()
+	if all {
+		sn.listener.mu.channels = make(map[string]struct{})
+	} else {
+		delete(sn.listener.mu.channels, channel)
+	}
+}
+
+// commit publishes the notifications sent by the transaction which just
+// committed.
+func (sn *sessionNotifications) commit(ctx context.Context) {
+	if len(sn.pending) > 0 {
+		if err := sn.registry.publish(ctx, sn.pending); err != nil {
+			// The transaction is committed regardless.
+			log.Warningf(ctx, "unable to publish notifications: %v", err)
+		}
+	}
+	sn.reset()
+}
+
+// reset discards the notifications sent by the current transaction, when it
+// ends or restarts.
+func (sn *sessionNotifications) reset() {
+	sn.pending = nil
+	sn.pendingSet = nil
+	sn.pendingBytes = 0
+}
+
+// takeQueued returns the notifications queued for the session, which are to
+// be delivered to the client.
+func (sn *sessionNotifications) takeQueued(ctx context.Context) []Notification {
+	if sn.listener == nil {
+		return nil
+	}
+	l := sn.listener
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.dropped > 0 {
+		log.Warningf(ctx, "dropped %d notifications: the session had too many queued notifications",
+			l.mu.dropped)
+		l.mu.dropped = 0
+	}
+	queue := l.mu.queue
+	l.mu.queue = nil
+	l.mu.signaled = false
+	return queue
+}
+
+// close stops listening on all the channels when the session is closed.
+func (sn *sessionNotifications) close() {
+	if sn.listener != nil {
+		sn.registry.deregister(sn.listener)
+		sn.listener = nil
+	}
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/static/sql-listen.html for
+// details. Unlike in PostgreSQL, the session starts listening immediately
+// rather than when the transaction commits.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	if err := p.extendedEvalCtx.Notifications.listen(string(n.Channel)); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/static/sql-unlisten.html for
+// details.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	p.extendedEvalCtx.Notifications.unlisten(string(n.Channel), n.All)
+	return newZeroNode(nil /* columns */), nil
+}
+
+// Notify implements the NOTIFY statement. The notification is delivered to
+// the listening sessions once the transaction commits.
+// See https://www.postgresql.org/docs/current/static/sql-notify.html for
+// details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	if err := p.extendedEvalCtx.Notifications.Notify(ctx, string(n.Channel), n.Payload); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
@@ -211,6 +211,15 @@ type Table interface {
 
 	// Statistic returns the ith statistic, where i < StatisticCount.
 	Statistic(i int) TableStatistic
+
+	// RowLevelSecurityFilter returns the boolean expression restricting the
+	// rows of the table visible to the current user, or nil if all the rows
+	// are visible. Column references in the expression are unqualified.
+	RowLevelSecurityFilter() tree.Expr
+
+	// CheckColumnPrivilege returns the error to report when the ith column
+	// is read, or nil if the current user is allowed to read it.
+	CheckColumnPrivilege(i int) error
 }
 
 // Catalog is an interface to a database catalog, exposing only the information
@@ -76,6 +76,11 @@ type Builder struct {
 
 	// Skip index 0 in order to reserve it to indicate the "unknown" column.
 	colMap []scopeColumn
+
+	// skipColumnPrivileges is set while building expressions that may refer
+	// to table columns regardless of the column privileges of the current
+	// user.
+	skipColumnPrivileges bool
 }
 
 // New creates a new Builder structure initialized with the given
@@ -252,6 +252,8 @@ func (b *Builder) buildUsingJoinPredicate(
 		// For every adjacent pair of tables, add an equality predicate.
 		leftCol := findUsingColumn(leftCols, name, "left")
 		rightCol := findUsingColumn(rightCols, name, "right")
+		b.checkColumnPrivilege(leftCol)
+		b.checkColumnPrivilege(rightCol)
 
 		if !leftCol.typ.Equivalent(rightCol.typ) {
 			// First, check if the comparison would even be valid.
@@ -652,7 +652,9 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		if err != nil {
 			panic(builderError{err})
 		}
-		return false, colI.(*scopeColumn)
+		col := colI.(*scopeColumn)
+		s.builder.checkColumnPrivilege(col)
+		return false, col
 
 	case *tree.FuncExpr:
 		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
@@ -55,6 +55,10 @@ type scopeColumn struct {
 	// exprStr contains a stringified representation of expr, or the original
 	// column name if expr is nil. It is populated lazily inside getExprStr().
 	exprStr string
+
+	// selectErr is the error to report when this table column is referenced
+	// because the current user is not allowed to read it, if any.
+	selectErr error
 }
 
 // getExprStr gets a stringified representation of the expression that this
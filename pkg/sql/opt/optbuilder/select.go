@@ -155,12 +155,13 @@ func (b *Builder) buildScan(tab opt.Table, tn *tree.TableName, inScope *scope) (
 		colID := b.factory.Metadata().TableColumn(tabID, i)
 		name := tree.Name(col.ColName())
 		colProps := scopeColumn{
-			id:       colID,
-			origName: name,
-			name:     name,
-			table:    *tn,
-			typ:      col.DatumType(),
-			hidden:   col.IsHidden(),
+			id:        colID,
+			origName:  name,
+			name:      name,
+			table:     *tn,
+			typ:       col.DatumType(),
+			hidden:    col.IsHidden(),
+			selectErr: tab.CheckColumnPrivilege(i),
 		}
 
 		tabCols.Add(int(colID))
@@ -174,10 +175,34 @@ func (b *Builder) buildScan(tab opt.Table, tn *tree.TableName, inScope *scope) (
 	} else {
 		def := memo.ScanOpDef{Table: tabID, Cols: tabCols}
 		outScope.group = b.factory.ConstructScan(b.factory.InternScanOpDef(&def))
+
+		if filter := tab.RowLevelSecurityFilter(); filter != nil {
+			b.buildRowLevelSecurityFilter(filter, outScope)
+		}
 	}
 	return outScope
 }
 
+// buildRowLevelSecurityFilter restricts the rows produced by a table scan to
+// those that the row-level security policies of the table allow the current
+// user to see.
+func (b *Builder) buildRowLevelSecurityFilter(filter tree.Expr, outScope *scope) {
+	// We need to save and restore the previous value of the field in
+	// semaCtx in case we are recursively called within a subquery
+	// context.
+	defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+	b.semaCtx.Properties.Require("row-level security policy", tree.RejectSpecial)
+
+	// The policies may refer to columns the current user cannot read.
+	b.skipColumnPrivileges = true
+	defer func() { b.skipColumnPrivileges = false }()
+
+	texpr := outScope.resolveAndRequireType(filter, types.Bool, "row-level security policy")
+	group := b.buildScalar(texpr, outScope)
+	group = b.factory.ConstructFilters(b.factory.InternList([]memo.GroupID{group}))
+	outScope.group = b.factory.ConstructSelect(outScope.group, group)
+}
+
 // buildWithOrdinality builds a group which appends an increasing integer column to
 // the output. colName optionally denotes the name this column is given, or can
 // be blank for none.
@@ -210,6 +235,9 @@ func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scop
 	if stmt.With != nil {
 		panic(unimplementedf("with clause not supported"))
 	}
+	if stmt.Locking != nil {
+		panic(unimplementedf("locking clause not supported"))
+	}
 
 	wrapped := stmt.Select
 	orderBy := stmt.OrderBy
@@ -218,6 +246,9 @@ func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scop
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		stmt = s.Select
 		wrapped = stmt.Select
+		if stmt.Locking != nil {
+			panic(unimplementedf("locking clause not supported"))
+		}
 		if stmt.OrderBy != nil {
 			if orderBy != nil {
 				panic(builderError{pgerror.NewErrorf(
@@ -89,6 +89,7 @@ func (b *Builder) expandStar(
 		for i := range inScope.cols {
 			col := inScope.cols[i]
 			if col.table == *src && !col.hidden {
+				b.checkColumnPrivilege(&col)
 				exprs = append(exprs, &col)
 				labels = append(labels, string(col.name))
 			}
@@ -99,6 +100,7 @@ func (b *Builder) expandStar(
 		for i := range inScope.cols {
 			col := inScope.cols[i]
 			if !col.hidden {
+				b.checkColumnPrivilege(&col)
 				exprs = append(exprs, &col)
 				labels = append(labels, string(col.name))
 			}
@@ -111,6 +113,14 @@ func (b *Builder) expandStar(
 	return labels, exprs
 }
 
+// checkColumnPrivilege reports an error if the current user is not allowed
+// to read the given column.
+func (b *Builder) checkColumnPrivilege(col *scopeColumn) {
+	if col.selectErr != nil && !b.skipColumnPrivileges {
+		panic(builderError{col.selectErr})
+	}
+}
+
 // expandStarAndResolveType expands expr into a list of columns if
 // expr corresponds to a "*", "<table>.*" or "(Expr).*". Otherwise,
 // expandStarAndResolveType resolves the type of expr and returns it
@@ -29,6 +29,7 @@ import (
 //
 // Supported commands:
 //  - INJECT STATISTICS: imports table statistics from a JSON object.
+//  - {ENABLE|DISABLE} ROW LEVEL SECURITY: toggles the policies of the table.
 //
 func (tc *Catalog) AlterTable(stmt *tree.AlterTable) {
 	tn, err := stmt.Table.Normalize()
@@ -49,6 +50,9 @@ func (tc *Catalog) AlterTable(stmt *tree.AlterTable) {
 		case *tree.AlterTableInjectStats:
 			injectTableStats(table, t.Stats)
 
+		case *tree.AlterTableSetRowLevelSecurity:
+			table.RowLevelSecurity = t.Enabled
+
 		default:
 			panic(fmt.Sprintf("unsupported ALTER TABLE command %T", t))
 		}
@@ -0,0 +1,46 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcat
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// CreatePolicy is a partial implementation of the CREATE POLICY statement.
+// The test catalog has no users, so every policy applies; only the USING
+// expressions of the policies governing SELECT statements are recorded.
+func (tc *Catalog) CreatePolicy(stmt *tree.CreatePolicy) {
+	tn, err := stmt.Table.Normalize()
+	if err != nil {
+		panic(err)
+	}
+
+	// Update the table name to include catalog and schema if not provided.
+	tc.qualifyTableName(tn)
+
+	table, ok := tc.tables[tn.FQString()]
+	if !ok {
+		panic(fmt.Sprintf("cannot find table %q", tree.ErrString(tn)))
+	}
+
+	if stmt.Command != tree.PolicyAll && stmt.Command != tree.PolicySelect {
+		return
+	}
+	if stmt.Using != nil {
+		table.Policies = append(table.Policies, stmt.Using)
+	}
+}
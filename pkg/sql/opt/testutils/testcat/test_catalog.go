@@ -131,6 +131,10 @@ func (tc *Catalog) ExecuteDDL(sql string) (string, error) {
 		tc.DropTable(stmt)
 		return "", nil
 
+	case *tree.CreatePolicy:
+		tc.CreatePolicy(stmt)
+		return "", nil
+
 	default:
 		return "", fmt.Errorf("expected CREATE TABLE or ALTER TABLE statement but found: %v", stmt)
 	}
@@ -174,6 +178,11 @@ type Table struct {
 	Indexes   []*Index
 	Stats     TableStats
 	IsVirtual bool
+
+	// RowLevelSecurity is set when the USING expressions in Policies
+	// restrict the visible rows of the table.
+	RowLevelSecurity bool
+	Policies         []tree.Expr
 }
 
 var _ opt.Table = &Table{}
@@ -224,6 +233,26 @@ func (tt *Table) Statistic(i int) opt.TableStatistic {
 	return tt.Stats[i]
 }
 
+// RowLevelSecurityFilter is part of the opt.Table interface.
+func (tt *Table) RowLevelSecurityFilter() tree.Expr {
+	if !tt.RowLevelSecurity {
+		return nil
+	}
+	if len(tt.Policies) == 0 {
+		return tree.DBoolFalse
+	}
+	filter := tt.Policies[0]
+	for _, expr := range tt.Policies[1:] {
+		filter = &tree.OrExpr{Left: filter, Right: expr}
+	}
+	return filter
+}
+
+// CheckColumnPrivilege is part of the opt.Table interface.
+func (tt *Table) CheckColumnPrivilege(i int) error {
+	return nil
+}
+
 // FindOrdinal returns the ordinal of the column with the given name.
 func (tt *Table) FindOrdinal(name string) int {
 	for i, col := range tt.Columns {
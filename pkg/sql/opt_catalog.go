@@ -56,7 +56,8 @@ func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.
 		return nil, err
 	}
 
-	if err := oc.resolver.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
+	colPrivErrs, err := oc.resolver.CheckColumnPrivileges(ctx, desc, privilege.SELECT)
+	if err != nil {
 		return nil, err
 	}
 
@@ -69,6 +70,16 @@ func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.
 		wrapper = newOptTable(name, oc.statsCache, desc)
 		oc.wrappers[desc] = wrapper
 	}
+
+	// The rows and columns visible through the table depend on the current
+	// user, so the row-level security filter and the column privileges are
+	// refreshed on every lookup.
+	wrapper.colPrivErrs = colPrivErrs
+	wrapper.rlsFilter, err = oc.resolver.RowLevelSecurityFilter(
+		ctx, desc, sqlbase.TableDescriptor_Policy_SELECT)
+	if err != nil {
+		return nil, err
+	}
 	return wrapper, nil
 }
 
@@ -97,6 +108,15 @@ type optTable struct {
 	// wrappers is a cache of index wrappers that's used to satisfy repeated
 	// calls to the SecondaryIndex method for the same index.
 	wrappers map[*sqlbase.IndexDescriptor]*optIndex
+
+	// rlsFilter is the row-level security filter for the current user, or
+	// nil if all the rows are visible.
+	rlsFilter tree.Expr
+
+	// colPrivErrs holds the errors to report when the columns of the table
+	// are read, if the current user was only granted SELECT on some of
+	// them. It is nil if all the columns can be read.
+	colPrivErrs []error
 }
 
 var _ opt.Table = &optTable{}
@@ -203,6 +223,19 @@ func (ot *optTable) Statistic(i int) opt.TableStatistic {
 	return &ot.stats[i]
 }
 
+// RowLevelSecurityFilter is part of the opt.Table interface.
+func (ot *optTable) RowLevelSecurityFilter() tree.Expr {
+	return ot.rlsFilter
+}
+
+// CheckColumnPrivilege is part of the opt.Table interface.
+func (ot *optTable) CheckColumnPrivilege(i int) error {
+	if ot.colPrivErrs == nil {
+		return nil
+	}
+	return ot.colPrivErrs[i]
+}
+
 func (ot *optTable) ensureColMap() {
 	if ot.colMap == nil {
 		ot.colMap = make(map[sqlbase.ColumnID]int, len(ot.desc.Columns))
@@ -202,15 +202,18 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserUnlockNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
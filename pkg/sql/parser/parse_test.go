@@ -278,6 +278,13 @@ func TestParse(t *testing.T) {
 
 		{`DISCARD ALL`},
 
+		{`LISTEN foo`},
+		{`UNLISTEN foo`},
+		{`UNLISTEN *`},
+		{`NOTIFY foo`},
+		{`NOTIFY foo, 'bar'`},
+		{`NOTIFY "Foo", 'bar'`},
+
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
 		{`DROP DATABASE a CASCADE`},
@@ -455,6 +462,9 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT SELECT (a, b) ON TABLE foo TO root`},
+		{`GRANT SELECT, UPDATE (a) ON TABLE foo, db.foo TO root, bar`},
+		{`GRANT ALL (a) ON TABLE foo TO root`},
 		{`GRANT rolea, roleb TO usera, userb`},
 		{`GRANT rolea, roleb TO usera, userb WITH ADMIN OPTION`},
 
@@ -466,6 +476,8 @@ func TestParse(t *testing.T) {
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
+		{`REVOKE SELECT (a, b) ON TABLE foo FROM root`},
+		{`REVOKE INSERT, UPDATE (a) ON TABLE foo, db.foo FROM root, bar`},
 		{`REVOKE rolea, roleb FROM usera, userb`},
 		{`REVOKE ADMIN OPTION FOR rolea, roleb FROM usera, userb`},
 
@@ -821,6 +833,15 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t LIMIT a`},
 		{`SELECT a FROM t OFFSET b`},
 		{`SELECT a FROM t LIMIT a OFFSET b`},
+		{`SELECT a FROM t FOR UPDATE`},
+		{`SELECT a FROM t FOR NO KEY UPDATE`},
+		{`SELECT a FROM t FOR SHARE`},
+		{`SELECT a FROM t FOR KEY SHARE`},
+		{`SELECT a FROM t FOR UPDATE NOWAIT`},
+		{`SELECT a FROM t FOR UPDATE SKIP LOCKED`},
+		{`SELECT a FROM t, u FOR UPDATE OF t FOR SHARE OF u, v SKIP LOCKED`},
+		{`SELECT a FROM t ORDER BY a LIMIT 1 FOR UPDATE SKIP LOCKED`},
+		{`WITH a AS (SELECT 1) SELECT * FROM t FOR UPDATE`},
 		{`SELECT DISTINCT * FROM t`},
 		{`SELECT DISTINCT a, b FROM t`},
 		{`SELECT DISTINCT ON (a, b) c FROM t`},
@@ -941,6 +962,11 @@ func TestParse(t *testing.T) {
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
+		{`COPY t TO STDOUT`},
+		{`COPY t (a, b, c) TO STDOUT`},
+		{`COPY (SELECT * FROM t WHERE a > 1) TO STDOUT`},
+		{`COPY t TO STDOUT WITH (FORMAT csv, DELIMITER ';', NULL 'n', HEADER true)`},
+		{`COPY (VALUES (1)) TO STDOUT WITH (FORMAT text, HEADER false)`},
 
 		{`ALTER TABLE a SPLIT AT VALUES (1)`},
 		{`ALTER TABLE a SPLIT AT SELECT * FROM t`},
@@ -960,6 +986,9 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE d.a EXPERIMENTAL_RELOCATE LEASE VALUES (1, 'b', 2)`},
 		{`ALTER INDEX d.i EXPERIMENTAL_RELOCATE LEASE VALUES (1, 2)`},
 
+		{`ALTER TABLE a EXPERIMENTAL REVERT TO SYSTEM TIME '2018-01-01 00:00:00'`},
+		{`ALTER TABLE d.a EXPERIMENTAL REVERT TO SYSTEM TIME '-10m'`},
+
 		{`ALTER TABLE a SCATTER`},
 		{`ALTER TABLE a SCATTER FROM (1, 2, 3) TO (4, 5, 6)`},
 		{`ALTER TABLE d.a SCATTER`},
@@ -980,6 +1009,14 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE a || b`},
 		{`ALTER TABLE t EXPERIMENTAL_AUDIT SET READ WRITE`},
 		{`ALTER TABLE t EXPERIMENTAL_AUDIT SET OFF`},
+		{`ALTER TABLE t ENABLE ROW LEVEL SECURITY`},
+		{`ALTER TABLE t DISABLE ROW LEVEL SECURITY`},
+
+		{`CREATE POLICY p ON t FOR SELECT TO foo USING (a > 1)`},
+		{`CREATE POLICY p ON db.t FOR ALL TO foo, bar USING (a = current_user()) WITH CHECK (b IS NOT NULL)`},
+		{`CREATE POLICY p ON t FOR INSERT WITH CHECK (a = 1)`},
+		{`DROP POLICY p ON t`},
+		{`DROP POLICY IF EXISTS p ON db.t`},
 
 		{`ALTER SEQUENCE a RENAME TO b`},
 		{`ALTER SEQUENCE IF EXISTS a RENAME TO b`},
@@ -1064,6 +1101,12 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+		{`COPY t TO STDOUT CSV HEADER`,
+			`COPY t TO STDOUT WITH (FORMAT csv, HEADER true)`},
+		{`COPY t TO STDOUT WITH DELIMITER AS '|' NULL AS ''`,
+			`COPY t TO STDOUT WITH (DELIMITER '|', NULL '')`},
+		{`COPY t TO STDOUT (format 'CSV', header)`,
+			`COPY t TO STDOUT WITH (FORMAT csv, HEADER true)`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
@@ -1210,6 +1253,14 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
 		{`SELECT a FROM t FETCH FIRST (2 * a) ROWS ONLY OFFSET b`,
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
+		// The locking clause may appear before or after LIMIT/OFFSET, but is
+		// always output last.
+		{`SELECT a FROM t FOR UPDATE LIMIT 1`,
+			`SELECT a FROM t LIMIT 1 FOR UPDATE`},
+		{`SELECT a FROM t ORDER BY a FOR SHARE NOWAIT OFFSET 2`,
+			`SELECT a FROM t ORDER BY a OFFSET 2 FOR SHARE NOWAIT`},
+		{`SELECT a FROM t FOR READ ONLY`,
+			`SELECT a FROM t`},
 		// Double negation. See #1800.
 		{`SELECT *,-/* comment */-5`,
 			`SELECT *, -(-5)`},
@@ -1456,6 +1507,18 @@ func TestParse2(t *testing.T) {
 			`DROP USER IF EXISTS 'foo', 'bar'`},
 		{`ALTER USER foo WITH PASSWORD bar`,
 			`ALTER USER 'foo' WITH PASSWORD 'bar'`},
+		{`CREATE USER foo VALID UNTIL '2020-01-01'`,
+			`CREATE USER 'foo' VALID UNTIL '2020-01-01'`},
+		{`CREATE USER foo PASSWORD bar VALID UNTIL 'infinity'`,
+			`CREATE USER 'foo' WITH PASSWORD 'bar' VALID UNTIL 'infinity'`},
+		{`ALTER USER foo WITH PASSWORD bar VALID UNTIL '2020-01-01'`,
+			`ALTER USER 'foo' WITH PASSWORD 'bar' VALID UNTIL '2020-01-01'`},
+		{`ALTER USER foo WITH VALID UNTIL '2020-01-01'`,
+			`ALTER USER 'foo' VALID UNTIL '2020-01-01'`},
+		{`ALTER USER IF EXISTS foo VALID UNTIL '2020-01-01'`,
+			`ALTER USER IF EXISTS 'foo' VALID UNTIL '2020-01-01'`},
+		{`ALTER USER foo UNLOCK`,
+			`ALTER USER 'foo' UNLOCK`},
 
 		// Alternative forms for table patterns.
 
@@ -1494,6 +1557,8 @@ func TestParse2(t *testing.T) {
 
 		{`GRANT SELECT ON foo TO root`,
 			`GRANT SELECT ON TABLE foo TO root`},
+		{`GRANT SELECT (a) ON foo TO root`,
+			`GRANT SELECT (a) ON TABLE foo TO root`},
 		{`GRANT SELECT, DELETE, UPDATE ON foo, db.foo TO root, bar`,
 			`GRANT SELECT, DELETE, UPDATE ON TABLE foo, db.foo TO root, bar`},
 		// Tables named "role" are handled specially to support SHOW GRANTS ON ROLE,
@@ -1516,6 +1581,9 @@ func TestParse2(t *testing.T) {
 		{`DROP ROLE IF EXISTS foo, bar`,
 			`DROP ROLE IF EXISTS 'foo', 'bar'`},
 
+		{`CREATE POLICY p ON t USING (a = 1)`,
+			`CREATE POLICY p ON t FOR ALL USING (a = 1)`},
+
 		// Clarify the ambiguity between "ON ROLE" (RBAC) and "ON ROLE"
 		// (regular table named "role").
 		{`SHOW GRANTS ON role`, `SHOW GRANTS ON ROLE`},
@@ -1861,6 +1929,13 @@ SELECT a FROM foo@{FORCE_INDEX=bar,FORCE_INDEX=baz}
 			`FORCE_INDEX specified multiple times at or near "baz"
 SELECT a FROM foo@{FORCE_INDEX=bar,NO_INDEX_JOIN,FORCE_INDEX=baz}
                                                              ^
+`,
+		},
+		{
+			`COPY t TO STDOUT WITH (FORMAT csv, FORMAT csv)`,
+			`COPY format specified multiple times at or near "csv"
+COPY t TO STDOUT WITH (FORMAT csv, FORMAT csv)
+                                          ^
 `,
 		},
 		{
@@ -110,6 +110,9 @@ func (u *sqlSymUnion) strVal() *tree.StrVal {
 func (u *sqlSymUnion) auditMode() tree.AuditMode {
     return u.val.(tree.AuditMode)
 }
+func (u *sqlSymUnion) policyCommand() tree.PolicyCommand {
+    return u.val.(tree.PolicyCommand)
+}
 func (u *sqlSymUnion) bool() bool {
     return u.val.(bool)
 }
@@ -147,6 +150,9 @@ func (u *sqlSymUnion) normalizableTableNames() tree.NormalizableTableNames {
 func (u *sqlSymUnion) indexHints() *tree.IndexHints {
     return u.val.(*tree.IndexHints)
 }
+func (u *sqlSymUnion) copyOptions() *tree.CopyOptions {
+    return u.val.(*tree.CopyOptions)
+}
 func (u *sqlSymUnion) arraySubscript() *tree.ArraySubscript {
     return u.val.(*tree.ArraySubscript)
 }
@@ -288,6 +294,18 @@ func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
+func (u *sqlSymUnion) lockingClause() tree.LockingClause {
+    return u.val.(tree.LockingClause)
+}
+func (u *sqlSymUnion) lockingItem() *tree.LockingItem {
+    return u.val.(*tree.LockingItem)
+}
+func (u *sqlSymUnion) lockingStrength() tree.LockingStrength {
+    return u.val.(tree.LockingStrength)
+}
+func (u *sqlSymUnion) lockingWaitPolicy() tree.LockingWaitPolicy {
+    return u.val.(tree.LockingWaitPolicy)
+}
 func (u *sqlSymUnion) targetList() tree.TargetList {
     return u.val.(tree.TargetList)
 }
@@ -479,9 +497,9 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT
 %token <str> DEALLOCATE DEFERRABLE DELETE DESC
-%token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
+%token <str> DISABLE DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING END ENUM ESCAPE EXCEPT
+%token <str> ELSE ENABLE ENCODING END ENUM ESCAPE EXCEPT
 %token <str> EXISTS EXECUTE EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT
@@ -506,34 +524,35 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> KEY KEYS KV
 
 %token <str> LATERAL LC_CTYPE LC_COLLATE
-%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str> LOCALTIME LOCALTIMESTAMP LOW LSHIFT
+%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LISTEN LOCAL
+%token <str> LOCALTIME LOCALTIMESTAMP LOCKED LOW LSHIFT
 
 %token <str> MATCH MINVALUE MAXVALUE MINUTE MONTH
 
 %token <str> NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str> NOT NOTHING NOTNULL NULL NULLIF NUMERIC
+%token <str> NOWAIT
+%token <str> NOT NOTHING NOTIFY NOTNULL NULL NULLIF NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDVECTOR ON ONLY OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY OWNED
 
 %token <str> PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
-%token <str> PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
+%token <str> PLANS POLICY POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
 
 %token <str> QUERIES QUERY
 
 %token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVERT REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SECURITY SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
-%token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
+%token <str> SHARE SHOW SIMILAR SIMPLE SKIP SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
+%token <str> START STATISTICS STATUS STDIN STDOUT STRICT STRING STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM
 
 %token <str> TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
@@ -541,7 +560,7 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> TRUNCATE TYPE
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOCK UNTIL
 %token <str> UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING VIRTUAL
@@ -591,6 +610,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> alter_split_stmt
 %type <tree.Statement> alter_rename_table_stmt
 %type <tree.Statement> alter_scatter_stmt
+%type <tree.Statement> alter_revert_stmt
 %type <tree.Statement> alter_relocate_stmt
 %type <tree.Statement> alter_relocate_lease_stmt
 %type <tree.Statement> alter_zone_table_stmt
@@ -637,12 +657,14 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> comment_stmt
 %type <tree.Statement> commit_stmt
 %type <tree.Statement> copy_from_stmt
+%type <tree.Statement> copy_to_stmt
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_changefeed_stmt
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_index_stmt
+%type <tree.Statement> create_policy_stmt
 %type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
@@ -653,11 +675,15 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_type_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
+%type <tree.Statement> unlisten_stmt
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
+%type <tree.Statement> drop_policy_stmt
 %type <tree.Statement> drop_role_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
@@ -753,6 +779,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <str> opt_template_clause opt_encoding_clause opt_lc_collate_clause opt_lc_ctype_clause
 %type <tree.Expr> opt_password
+%type <tree.Expr> opt_valid_until
 
 %type <tree.IsolationLevel> transaction_iso_level
 %type <tree.UserPriority> transaction_user_priority
@@ -810,7 +837,12 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UpdateExpr> set_clause multiple_set_clause
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
-%type <*tree.Limit> select_limit
+%type <*tree.Limit> select_limit opt_select_limit
+%type <tree.LockingClause> for_locking_clause opt_for_locking_clause for_locking_items
+%type <*tree.LockingItem> for_locking_item
+%type <tree.LockingStrength> for_locking_strength
+%type <tree.LockingWaitPolicy> opt_nowait_or_skip
+%type <tree.NormalizableTableNames> opt_locked_rels
 %type <tree.NormalizableTableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
 
@@ -856,6 +888,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.IndexHints> opt_index_hints
 %type <*tree.IndexHints> index_hints_param
 %type <*tree.IndexHints> index_hints_param_list
+%type <*tree.CopyOptions> opt_copy_options copy_generic_option copy_generic_option_list
+%type <*tree.CopyOptions> copy_legacy_option copy_legacy_option_list
 %type <tree.Expr> a_expr b_expr c_expr d_expr
 %type <tree.Expr> substr_from substr_for
 %type <tree.Expr> in_expr
@@ -950,6 +984,9 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.NameList> for_grantee_clause
 %type <privilege.List> privileges
 %type <tree.AuditMode> audit_mode
+%type <tree.PolicyCommand> opt_policy_command
+%type <tree.NameList> opt_policy_roles
+%type <tree.Expr> opt_policy_using opt_policy_with_check
 
 %type <str> relocate_kw ranges_kw
 
@@ -1052,6 +1089,7 @@ stmt:
 | backup_stmt     // EXTEND WITH HELP: BACKUP
 | cancel_stmt     // help texts in sub-rule
 | copy_from_stmt
+| copy_to_stmt
 | comment_stmt
 | create_stmt     // help texts in sub-rule
 | deallocate_stmt // EXTEND WITH HELP: DEALLOCATE
@@ -1064,6 +1102,8 @@ stmt:
 | grant_stmt      // EXTEND WITH HELP: GRANT
 | insert_stmt     // EXTEND WITH HELP: INSERT
 | import_stmt     // EXTEND WITH HELP: IMPORT
+| listen_stmt     // EXTEND WITH HELP: LISTEN
+| notify_stmt     // EXTEND WITH HELP: NOTIFY
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOBS
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
 | restore_stmt    // EXTEND WITH HELP: RESTORE
@@ -1081,6 +1121,7 @@ stmt:
 | show_stmt         // help texts in sub-rule
 | transaction_stmt  // help texts in sub-rule
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
+| unlisten_stmt     // EXTEND WITH HELP: UNLISTEN
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
 | /* EMPTY */
@@ -1137,6 +1178,7 @@ alter_table_stmt:
 | alter_relocate_lease_stmt
 | alter_split_stmt
 | alter_scatter_stmt
+| alter_revert_stmt
 | alter_zone_table_stmt
 | alter_rename_table_stmt
 // ALTER TABLE has its error help token here because the ALTER TABLE
@@ -1182,7 +1224,9 @@ alter_sequence_options_stmt:
 // %Help: ALTER USER - change user properties
 // %Category: Priv
 // %Text:
-// ALTER USER [IF EXISTS] <name> WITH PASSWORD <password>
+// ALTER USER [IF EXISTS] <name> WITH PASSWORD <password> [VALID UNTIL <timestamp>]
+// ALTER USER [IF EXISTS] <name> [WITH] VALID UNTIL <timestamp>
+// ALTER USER [IF EXISTS] <name> UNLOCK
 // %SeeAlso: CREATE USER
 alter_user_stmt:
   alter_user_password_stmt
@@ -1355,6 +1399,13 @@ alter_scatter_stmt:
     $$.val = &tree.Scatter{Table: $3.newNormalizableTableNameFromUnresolvedName(), From: $7.exprs(), To: $11.exprs()}
   }
 
+alter_revert_stmt:
+  ALTER TABLE table_name EXPERIMENTAL REVERT TO SYSTEM TIME a_expr
+  {
+    /* SKIP DOC */
+    $$.val = &tree.Revert{Table: $3.newNormalizableTableNameFromUnresolvedName(), AsOf: tree.AsOfClause{Expr: $9.expr()}}
+  }
+
 alter_scatter_index_stmt:
   ALTER INDEX table_name_with_index SCATTER
   {
@@ -1484,6 +1535,16 @@ alter_table_cmd:
   {
     $$.val = &tree.AlterTableSetAudit{Mode: $3.auditMode()}
   }
+  // ALTER TABLE <name> ENABLE ROW LEVEL SECURITY
+| ENABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTableSetRowLevelSecurity{Enabled: true}
+  }
+  // ALTER TABLE <name> DISABLE ROW LEVEL SECURITY
+| DISABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTableSetRowLevelSecurity{Enabled: false}
+  }
   // ALTER TABLE <name> PARTITION BY ...
 | partition_by
   {
@@ -1772,6 +1833,159 @@ copy_from_stmt:
     }
   }
 
+copy_to_stmt:
+  COPY table_name opt_column_list TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{
+       Table: $2.normalizableTableNameFromUnresolvedName(),
+       Columns: $3.nameList(),
+       Options: *$6.copyOptions(),
+    }
+  }
+| COPY select_with_parens TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{
+       Statement: &tree.Select{Select: $2.selectStmt()},
+       Options: *$5.copyOptions(),
+    }
+  }
+
+opt_copy_options:
+  opt_with '(' copy_generic_option_list ')'
+  {
+    $$.val = $3.copyOptions()
+  }
+| opt_with copy_legacy_option_list
+  {
+    $$.val = $2.copyOptions()
+  }
+| /* EMPTY */
+  {
+    $$.val = &tree.CopyOptions{}
+  }
+
+copy_generic_option_list:
+  copy_generic_option
+  {
+    $$.val = $1.copyOptions()
+  }
+| copy_generic_option_list ',' copy_generic_option
+  {
+    o := $1.copyOptions()
+    if err := o.CombineWith($3.copyOptions()); err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+
+// copy_generic_option is an option of the form used since PostgreSQL 9.0.
+copy_generic_option:
+  name
+  {
+    o, err := tree.MakeCopyOption($1, nil)
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| name name
+  {
+    o, err := tree.MakeCopyOption($1, tree.NewStrVal($2))
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| name SCONST
+  {
+    o, err := tree.MakeCopyOption($1, tree.NewStrVal($2))
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| name TRUE
+  {
+    o, err := tree.MakeCopyOption($1, tree.DBoolTrue)
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| name FALSE
+  {
+    o, err := tree.MakeCopyOption($1, tree.DBoolFalse)
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| NULL SCONST
+  {
+    o, err := tree.MakeCopyOption("null", tree.NewStrVal($2))
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+
+copy_legacy_option_list:
+  copy_legacy_option
+  {
+    $$.val = $1.copyOptions()
+  }
+| copy_legacy_option_list copy_legacy_option
+  {
+    o := $1.copyOptions()
+    if err := o.CombineWith($2.copyOptions()); err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+
+// copy_legacy_option is an option of the form used before PostgreSQL 9.0,
+// which is still used by some clients.
+copy_legacy_option:
+  name
+  {
+    o, err := tree.MakeCopyOption($1, nil)
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| name opt_as SCONST
+  {
+    o, err := tree.MakeCopyOption($1, tree.NewStrVal($3))
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+| NULL opt_as SCONST
+  {
+    o, err := tree.MakeCopyOption("null", tree.NewStrVal($3))
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = o
+  }
+
+opt_as:
+  AS {}
+| /* EMPTY */ {}
+
 // %Help: CANCEL
 // %Category: Group
 // %Text: CANCEL JOBS, CANCEL QUERIES, CANCEL SESSIONS
@@ -1897,7 +2111,7 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE
+// CREATE ROLE, CREATE POLICY
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
@@ -1909,6 +2123,7 @@ create_ddl_stmt:
   create_changefeed_stmt
 | create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
 | create_index_stmt    // EXTEND WITH HELP: CREATE INDEX
+| create_policy_stmt   // EXTEND WITH HELP: CREATE POLICY
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
@@ -2010,11 +2225,52 @@ discard_stmt:
 | DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
 | DISCARD error // SHOW HELP: DISCARD
 
+// %Help: LISTEN - listen for notifications on a channel
+// %Category: Misc
+// %Text: LISTEN <channel>
+// %SeeAlso: UNLISTEN, NOTIFY
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{Channel: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: NOTIFY - send a notification on a channel
+// %Category: Misc
+// %Text: NOTIFY <channel> [, <payload>]
+// %SeeAlso: LISTEN, UNLISTEN
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2), Payload: $4}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
+// %Help: UNLISTEN - stop listening for notifications
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{Channel: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{All: true}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
 // %Help: DROP
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE
+// DROP USER, DROP ROLE, DROP POLICY
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
@@ -2024,6 +2280,7 @@ drop_stmt:
 drop_ddl_stmt:
   drop_database_stmt // EXTEND WITH HELP: DROP DATABASE
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
+| drop_policy_stmt   // EXTEND WITH HELP: DROP POLICY
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
@@ -2134,6 +2391,29 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP POLICY - remove a row-level security policy
+// %Category: Priv
+// %Text: DROP POLICY [IF EXISTS] <name> ON <tablename>
+// %SeeAlso: CREATE POLICY, ALTER TABLE
+drop_policy_stmt:
+  DROP POLICY name ON table_name
+  {
+    $$.val = &tree.DropPolicy{
+      Name: tree.Name($3),
+      Table: $5.normalizableTableNameFromUnresolvedName(),
+      IfExists: false,
+    }
+  }
+| DROP POLICY IF EXISTS name ON table_name
+  {
+    $$.val = &tree.DropPolicy{
+      Name: tree.Name($5),
+      Table: $7.normalizableTableNameFromUnresolvedName(),
+      IfExists: true,
+    }
+  }
+| DROP POLICY error // SHOW HELP: DROP POLICY
+
 // %Help: DROP ROLE - remove a role
 // %Category: Priv
 // %Text: DROP ROLE [IF EXISTS] <role> [, ...]
@@ -2317,6 +2597,8 @@ deallocate_stmt:
 // %Text:
 // Grant privileges:
 //   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant column privileges:
+//   GRANT {ALL | <privileges...> } (<columns...>) ON [TABLE] <tablename> [, ...] TO <grantees...>
 // Grant role membership (CCL only):
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
@@ -2333,6 +2615,10 @@ grant_stmt:
   {
     $$.val = &tree.Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privileges '(' name_list ')' ON targets TO name_list
+  {
+    $$.val = &tree.Grant{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
 | GRANT privilege_list TO name_list
   {
     $$.val = &tree.GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
@@ -2348,6 +2634,8 @@ grant_stmt:
 // %Text:
 // Revoke privileges:
 //   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke column privileges:
+//   REVOKE {ALL | <privileges...> } (<columns...>) ON [TABLE] <tablename> [, ...] FROM <grantees...>
 // Revoke role membership (CCL only):
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
@@ -2364,6 +2652,10 @@ revoke_stmt:
   {
     $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privileges '(' name_list ')' ON targets FROM name_list
+  {
+    $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
 | REVOKE privilege_list FROM name_list
   {
     $$.val = &tree.RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false }
@@ -4143,16 +4435,16 @@ truncate_stmt:
 
 // %Help: CREATE USER - define a new user
 // %Category: Priv
-// %Text: CREATE USER [IF NOT EXISTS] <name> [ [WITH] PASSWORD <passwd> ]
+// %Text: CREATE USER [IF NOT EXISTS] <name> [ [WITH] PASSWORD <passwd> ] [VALID UNTIL <timestamp>]
 // %SeeAlso: DROP USER, SHOW USERS, WEBDOCS/create-user.html
 create_user_stmt:
-  CREATE USER string_or_placeholder opt_password
+  CREATE USER string_or_placeholder opt_password opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $4.expr()}
+    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $4.expr(), ValidUntil: $5.expr()}
   }
-| CREATE USER IF NOT EXISTS string_or_placeholder opt_password
+| CREATE USER IF NOT EXISTS string_or_placeholder opt_password opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $7.expr(), IfNotExists: true}
+    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $7.expr(), ValidUntil: $8.expr(), IfNotExists: true}
   }
 | CREATE USER error // SHOW HELP: CREATE USER
 
@@ -4166,6 +4458,16 @@ opt_password:
     $$.val = nil
   }
 
+opt_valid_until:
+  VALID UNTIL string_or_placeholder
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
 // %Help: CREATE ROLE - define a new role
 // %Category: Priv
 // %Text: CREATE ROLE [IF NOT EXISTS] <name>
@@ -4181,6 +4483,73 @@ create_role_stmt:
   }
 | CREATE ROLE error // SHOW HELP: CREATE ROLE
 
+// %Help: CREATE POLICY - define a row-level security policy
+// %Category: Priv
+// %Text:
+// CREATE POLICY <name> ON <tablename>
+//    [FOR { ALL | SELECT | INSERT | UPDATE | DELETE }]
+//    [TO <role> [, ...]]
+//    [USING ( <expr> )]
+//    [WITH CHECK ( <expr> )]
+//
+// Policies only take effect once row-level security has been enabled
+// on the table with ALTER TABLE ... ENABLE ROW LEVEL SECURITY.
+// %SeeAlso: DROP POLICY, ALTER TABLE
+create_policy_stmt:
+  CREATE POLICY name ON table_name opt_policy_command opt_policy_roles opt_policy_using opt_policy_with_check
+  {
+    $$.val = &tree.CreatePolicy{
+      Name: tree.Name($3),
+      Table: $5.normalizableTableNameFromUnresolvedName(),
+      Command: $6.policyCommand(),
+      Roles: $7.nameList(),
+      Using: $8.expr(),
+      WithCheck: $9.expr(),
+    }
+  }
+| CREATE POLICY error // SHOW HELP: CREATE POLICY
+
+opt_policy_command:
+  FOR ALL    { $$.val = tree.PolicyAll }
+| FOR SELECT { $$.val = tree.PolicySelect }
+| FOR INSERT { $$.val = tree.PolicyInsert }
+| FOR UPDATE { $$.val = tree.PolicyUpdate }
+| FOR DELETE { $$.val = tree.PolicyDelete }
+| /* EMPTY */
+  {
+    $$.val = tree.PolicyAll
+  }
+
+opt_policy_roles:
+  TO name_list
+  {
+    $$.val = $2.nameList()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.NameList(nil)
+  }
+
+opt_policy_using:
+  USING '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+opt_policy_with_check:
+  WITH CHECK '(' a_expr ')'
+  {
+    $$.val = $4.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
 // %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
@@ -4351,13 +4720,29 @@ alter_rename_database_stmt:
 
 // https://www.postgresql.org/docs/10/static/sql-alteruser.html
 alter_user_password_stmt:
-  ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder
+  ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder opt_valid_until
+  {
+    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), Password: $6.expr(), ValidUntil: $7.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder WITH PASSWORD string_or_placeholder opt_valid_until
   {
-    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), Password: $6.expr()}
+    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), Password: $8.expr(), ValidUntil: $9.expr(), IfExists: true}
   }
-| ALTER USER IF EXISTS string_or_placeholder WITH PASSWORD string_or_placeholder
+| ALTER USER string_or_placeholder opt_with VALID UNTIL string_or_placeholder
   {
-    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), Password: $8.expr(), IfExists: true}
+    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), ValidUntil: $7.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with VALID UNTIL string_or_placeholder
+  {
+    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), ValidUntil: $9.expr(), IfExists: true}
+  }
+| ALTER USER string_or_placeholder UNLOCK
+  {
+    $$.val = &tree.AlterUserUnlock{Name: $3.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder UNLOCK
+  {
+    $$.val = &tree.AlterUserUnlock{Name: $5.expr(), IfExists: true}
   }
 
 alter_rename_table_stmt:
@@ -4980,9 +5365,13 @@ select_no_parens:
   {
     $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy()}
   }
-| select_clause opt_sort_clause select_limit
+| select_clause opt_sort_clause for_locking_clause opt_select_limit
+  {
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $4.limit(), Locking: $3.lockingClause()}
+  }
+| select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit(), Locking: $4.lockingClause()}
   }
 | with_clause select_clause
   {
@@ -4992,9 +5381,13 @@ select_no_parens:
   {
     $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
   }
-| with_clause select_clause opt_sort_clause select_limit
+| with_clause select_clause opt_sort_clause for_locking_clause opt_select_limit
+  {
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $5.limit(), Locking: $4.lockingClause()}
+  }
+| with_clause select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), Locking: $5.lockingClause()}
   }
 
 select_clause:
@@ -5054,6 +5447,8 @@ simple_select:
 //        [ ORDER BY <expr> [ ASC | DESC ] [, ...] ]
 //        [ LIMIT { <expr> | ALL } ]
 //        [ OFFSET <expr> [ ROW | ROWS ] ]
+//        [ FOR { UPDATE | NO KEY UPDATE | SHARE | KEY SHARE }
+//          [ OF <tablename> [, ...] ] [ NOWAIT | SKIP LOCKED ] [...] ]
 // %SeeAlso: WEBDOCS/select-clause.html
 simple_select_clause:
   SELECT opt_all_clause target_list
@@ -5271,6 +5666,82 @@ sortby:
 // TODO(pmattis): Support ordering using arbitrary math ops?
 // | a_expr USING math_op {}
 
+for_locking_clause:
+  for_locking_items
+| FOR READ ONLY
+  {
+    $$.val = tree.LockingClause(nil)
+  }
+
+opt_for_locking_clause:
+  for_locking_clause
+| /* EMPTY */
+  {
+    $$.val = tree.LockingClause(nil)
+  }
+
+for_locking_items:
+  for_locking_item
+  {
+    $$.val = tree.LockingClause{$1.lockingItem()}
+  }
+| for_locking_items for_locking_item
+  {
+    $$.val = append($1.lockingClause(), $2.lockingItem())
+  }
+
+for_locking_item:
+  for_locking_strength opt_locked_rels opt_nowait_or_skip
+  {
+    $$.val = &tree.LockingItem{
+      Strength:   $1.lockingStrength(),
+      Targets:    $2.normalizableTableNames(),
+      WaitPolicy: $3.lockingWaitPolicy(),
+    }
+  }
+
+for_locking_strength:
+  FOR UPDATE
+  {
+    $$.val = tree.ForUpdate
+  }
+| FOR NO KEY UPDATE
+  {
+    $$.val = tree.ForNoKeyUpdate
+  }
+| FOR SHARE
+  {
+    $$.val = tree.ForShare
+  }
+| FOR KEY SHARE
+  {
+    $$.val = tree.ForKeyShare
+  }
+
+opt_locked_rels:
+  /* EMPTY */
+  {
+    $$.val = tree.NormalizableTableNames(nil)
+  }
+| OF table_name_list
+  {
+    $$.val = $2.normalizableTableNames()
+  }
+
+opt_nowait_or_skip:
+  /* EMPTY */
+  {
+    $$.val = tree.LockWaitBlock
+  }
+| SKIP LOCKED
+  {
+    $$.val = tree.LockWaitSkip
+  }
+| NOWAIT
+  {
+    $$.val = tree.LockWaitError
+  }
+
 select_limit:
   limit_clause offset_clause
   {
@@ -5291,6 +5762,10 @@ select_limit:
 | limit_clause
 | offset_clause
 
+opt_select_limit:
+  select_limit
+| /* EMPTY */ { $$.val = (*tree.Limit)(nil) }
+
 opt_limit_clause:
   limit_clause
 | /* EMPTY */ { $$.val = (*tree.Limit)(nil) }
@@ -8179,10 +8654,12 @@ unreserved_keyword:
 | DAY
 | DEALLOCATE
 | DELETE
+| DISABLE
 | DISCARD
 | DOMAIN
 | DOUBLE
 | DROP
+| ENABLE
 | ENCODING
 | ENUM
 | ESCAPE
@@ -8235,7 +8712,9 @@ unreserved_keyword:
 | LESS
 | LEVEL
 | LIST
+| LISTEN
 | LOCAL
+| LOCKED
 | LOW
 | MATCH
 | MINUTE
@@ -8246,7 +8725,9 @@ unreserved_keyword:
 | NEXT
 | NO
 | NORMAL
+| NOTIFY
 | NO_INDEX_JOIN
+| NOWAIT
 | OF
 | OFF
 | OID
@@ -8263,6 +8744,7 @@ unreserved_keyword:
 | PAUSE
 | PHYSICAL
 | PLANS
+| POLICY
 | PRECEDING
 | PREPARE
 | PRIORITY
@@ -8285,6 +8767,7 @@ unreserved_keyword:
 | RESTORE
 | RESTRICT
 | RESUME
+| REVERT
 | REVOKE
 | ROLE
 | ROLES
@@ -8301,6 +8784,7 @@ unreserved_keyword:
 | SCRUB
 | SEARCH
 | SECOND
+| SECURITY
 | SERIAL
 | SERIALIZABLE
 | SERIAL2
@@ -8311,14 +8795,17 @@ unreserved_keyword:
 | SESSION
 | SESSIONS
 | SET
+| SHARE
 | SHOW
 | SIMPLE
+| SKIP
 | SMALLSERIAL
 | SNAPSHOT
 | SQL
 | START
 | STATISTICS
 | STDIN
+| STDOUT
 | STORE
 | STORED
 | STORING
@@ -8342,6 +8829,9 @@ unreserved_keyword:
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
+| UNLOCK
+| UNTIL
 | UPDATE
 | UPSERT
 | UUID
@@ -0,0 +1,597 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The statement and transaction statistics collected by each node are
+// periodically persisted to the system.statement_statistics and
+// system.transaction_statistics tables, so that they survive restarts and the
+// resets of the in-memory statistics, and can be compared over time. The
+// statistics are aggregated in hourly buckets per node: every flush merges
+// the statistics collected since the previous one into the rows of the
+// current hour. A job deletes the statistics once they are older than the
+// retention period, and compacts the older hourly buckets into daily ones;
+// each node periodically makes sure that the job exists. The
+// crdb_internal.statement_statistics and crdb_internal.transaction_statistics
+// tables aggregate the persisted statistics of all the nodes.
+
+var statsFlushInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.metrics.statement_details.flush_interval",
+	"interval at which the collected statement and transaction statistics are persisted "+
+		"to the system tables (0 disables persistence)",
+	10*time.Minute,
+)
+
+var persistedStatsRetention = settings.RegisterNonNegativeDurationSetting(
+	"sql.metrics.statement_details.retention",
+	"age after which the persisted statement and transaction statistics are deleted "+
+		"(0 keeps them forever)",
+	7*24*time.Hour,
+)
+
+var persistedStatsCompactionThreshold = settings.RegisterNonNegativeDurationSetting(
+	"sql.metrics.statement_details.compaction_threshold",
+	"age after which the hourly persisted statement and transaction statistics are "+
+		"compacted into daily statistics (0 disables compaction)",
+	24*time.Hour,
+)
+
+// persistedStatsCleanupInterval is the interval at which the cleanup job
+// deletes and compacts the old persisted statistics, and at which each node
+// makes sure that the job exists.
+const persistedStatsCleanupInterval = time.Hour
+
+// persistedStatsBatchSize is the maximum number of rows written or deleted by
+// each of the transactions which flush and clean up the persisted statistics.
+const persistedStatsBatchSize = 100
+
+// persistedStatsReadBatchSize is the maximum number of rows read at once when
+// aggregating the persisted statistics.
+const persistedStatsReadBatchSize = 1000
+
+// persistedStatsTable describes one of the system tables holding persisted
+// statistics. The primary key of the tables is made of the aggregated_ts
+// column, the key columns and the node_id column, and the encoded statistics
+// are stored in the statistics column.
+type persistedStatsTable struct {
+	name    string
+	keyCols []string
+	// merge returns the encoded sum of the encoded statistics a and b.
+	merge func(a, b []byte) ([]byte, error)
+}
+
+var stmtStatsTable = persistedStatsTable{
+	name:    "system.statement_statistics",
+	keyCols: []string{"fingerprint", "flags", "app_name"},
+	merge: func(a, b []byte) ([]byte, error) {
+		var sa, sb roachpb.StatementStatistics
+		if err := protoutil.Unmarshal(a, &sa); err != nil {
+			return nil, err
+		}
+		if err := protoutil.Unmarshal(b, &sb); err != nil {
+			return nil, err
+		}
+		sa.Add(&sb)
+		return protoutil.Marshal(&sa)
+	},
+}
+
+var txnStatsTable = persistedStatsTable{
+	name:    "system.transaction_statistics",
+	keyCols: []string{"fingerprint", "app_name"},
+	merge: func(a, b []byte) ([]byte, error) {
+		var sa, sb roachpb.TransactionStatistics
+		if err := protoutil.Unmarshal(a, &sa); err != nil {
+			return nil, err
+		}
+		if err := protoutil.Unmarshal(b, &sb); err != nil {
+			return nil, err
+		}
+		sa.Add(&sb)
+		return protoutil.Marshal(&sa)
+	},
+}
+
+// pkCols returns the columns of the primary key of the table.
+func (t *persistedStatsTable) pkCols() []string {
+	cols := append([]string{"aggregated_ts"}, t.keyCols...)
+	return append(cols, "node_id")
+}
+
+// pkPredicate returns a predicate selecting a row by its primary key, given
+// as the placeholders $1 to $n.
+func (t *persistedStatsTable) pkPredicate() string {
+	var buf bytes.Buffer
+	for i, col := range t.pkCols() {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		fmt.Fprintf(&buf, "%s = $%d", col, i+1)
+	}
+	return buf.String()
+}
+
+// persistedStatsRow is a row of a persisted statistics table.
+type persistedStatsRow struct {
+	pk    []interface{}
+	stats []byte
+	// restore, if set, merges the statistics back into the in-memory
+	// statistics which haven't been persisted yet. It is called if the row
+	// could not be written.
+	restore func()
+}
+
+// add merges the statistics of the row into the row with the same primary
+// key in the table, or inserts the row if there is none.
+func (t *persistedStatsTable) add(
+	ctx context.Context, ie *InternalExecutor, txn *client.Txn, row persistedStatsRow,
+) error {
+	existing, err := ie.QueryRow(
+		ctx, "read-persisted-stats", txn,
+		fmt.Sprintf(`SELECT statistics FROM %s WHERE %s`, t.name, t.pkPredicate()),
+		row.pk...,
+	)
+	if err != nil {
+		return err
+	}
+	stats := row.stats
+	if existing != nil {
+		if stats, err = t.merge([]byte(tree.MustBeDBytes(existing[0])), stats); err != nil {
+			return err
+		}
+	}
+	cols := t.pkCols()
+	placeholders := make([]string, len(cols)+1)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	args := append(append([]interface{}(nil), row.pk...), stats)
+	_, err = ie.Exec(
+		ctx, "upsert-persisted-stats", txn,
+		fmt.Sprintf(`UPSERT INTO %s (%s, statistics) VALUES (%s)`,
+			t.name, strings.Join(cols, ", "), strings.Join(placeholders, ", ")),
+		args...,
+	)
+	return err
+}
+
+// addAll adds the rows to the table, in batches. It returns the number of
+// rows which were added, which is less than len(rows) only if an error is
+// returned.
+func (t *persistedStatsTable) addAll(
+	ctx context.Context, db *client.DB, ie *InternalExecutor, rows []persistedStatsRow,
+) (int, error) {
+	added := 0
+	for added < len(rows) {
+		batch := rows[added:]
+		if len(batch) > persistedStatsBatchSize {
+			batch = batch[:persistedStatsBatchSize]
+		}
+		if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			for _, row := range batch {
+				if err := t.add(ctx, ie, txn, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return added, err
+		}
+		added += len(batch)
+	}
+	return added, nil
+}
+
+// deleteExpired deletes the statistics persisted for the buckets before the
+// given time.
+func (t *persistedStatsTable) deleteExpired(
+	ctx context.Context, ie *InternalExecutor, before time.Time,
+) error {
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE aggregated_ts < $1 LIMIT %d`,
+		t.name, persistedStatsBatchSize)
+	for {
+		n, err := ie.Exec(ctx, "delete-expired-stats", nil /* txn */, stmt, before)
+		if err != nil {
+			return err
+		}
+		if n < persistedStatsBatchSize {
+			return nil
+		}
+	}
+}
+
+// compact merges the hourly statistics persisted for the buckets before the
+// given time into daily statistics. The statistics of a day are stored in the
+// bucket of its first hour.
+func (t *persistedStatsTable) compact(
+	ctx context.Context, db *client.DB, ie *InternalExecutor, before time.Time,
+) error {
+	query := fmt.Sprintf(
+		`SELECT %s, statistics FROM %s
+		  WHERE aggregated_ts < $1 AND extract('hour', aggregated_ts) != 0
+		  LIMIT %d`,
+		strings.Join(t.pkCols(), ", "), t.name, persistedStatsBatchSize)
+	deleteStmt := fmt.Sprintf(`DELETE FROM %s WHERE %s`, t.name, t.pkPredicate())
+	for {
+		var n int
+		if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			rows, _ /* cols */, err := ie.Query(ctx, "select-stats-to-compact", txn, query, before)
+			if err != nil {
+				return err
+			}
+			n = len(rows)
+			for _, r := range rows {
+				pk := make([]interface{}, len(r)-1)
+				for i, d := range r[:len(r)-1] {
+					pk[i] = d
+				}
+				if _, err := ie.Exec(ctx, "delete-compacted-stats", txn, deleteStmt, pk...); err != nil {
+					return err
+				}
+				pk[0] = r[0].(*tree.DTimestamp).Time.Truncate(24 * time.Hour)
+				row := persistedStatsRow{pk: pk, stats: []byte(tree.MustBeDBytes(r[len(r)-1]))}
+				if err := t.add(ctx, ie, txn, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if n < persistedStatsBatchSize {
+			return nil
+		}
+	}
+}
+
+// forEachAggregated calls fn, in primary key order, for each group of rows of
+// the table with the same bucket and key, that is for the statistics persisted
+// by all the nodes for a statement or transaction. fn is passed the bucket and
+// key, the IDs of the nodes and the merged statistics. The table is read in
+// batches of persistedStatsReadBatchSize rows.
+func (t *persistedStatsTable) forEachAggregated(
+	ctx context.Context,
+	p *planner,
+	fn func(key tree.Datums, nodeIDs *tree.DArray, stats []byte) error,
+) error {
+	cols := t.pkCols()
+	pkCols := strings.Join(cols, ", ")
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	firstBatch := fmt.Sprintf(`SELECT %s, statistics FROM %s ORDER BY %s LIMIT %d`,
+		pkCols, t.name, pkCols, persistedStatsReadBatchSize)
+	nextBatch := fmt.Sprintf(`SELECT %s, statistics FROM %s WHERE (%s) > (%s) ORDER BY %s LIMIT %d`,
+		pkCols, t.name, pkCols, strings.Join(placeholders, ", "), pkCols, persistedStatsReadBatchSize)
+
+	ie := p.ExtendedEvalContext().ExecCfg.InternalExecutor
+	keyLen := 1 + len(t.keyCols)
+	var key tree.Datums
+	var nodeIDs *tree.DArray
+	var stats []byte
+	var lastPK []interface{}
+	for {
+		var rows []tree.Datums
+		var err error
+		if lastPK == nil {
+			rows, _ /* cols */, err = ie.Query(ctx, "read-persisted-stats", p.txn, firstBatch)
+		} else {
+			rows, _ /* cols */, err = ie.Query(ctx, "read-persisted-stats", p.txn, nextBatch, lastPK...)
+		}
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if key != nil && !sameDatums(p.EvalContext(), key, r[:keyLen]) {
+				if err := fn(key, nodeIDs, stats); err != nil {
+					return err
+				}
+				key = nil
+			}
+			if key == nil {
+				key = r[:keyLen]
+				nodeIDs = tree.NewDArray(types.Int)
+				stats = []byte(tree.MustBeDBytes(r[keyLen+1]))
+			} else if stats, err = t.merge(stats, []byte(tree.MustBeDBytes(r[keyLen+1]))); err != nil {
+				return err
+			}
+			if err := nodeIDs.Append(r[keyLen]); err != nil {
+				return err
+			}
+		}
+		if len(rows) < persistedStatsReadBatchSize {
+			break
+		}
+		last := rows[len(rows)-1]
+		lastPK = make([]interface{}, len(cols))
+		for i := range lastPK {
+			lastPK[i] = last[i]
+		}
+	}
+	if key != nil {
+		return fn(key, nodeIDs, stats)
+	}
+	return nil
+}
+
+func sameDatums(evalCtx *tree.EvalContext, a, b tree.Datums) bool {
+	for i := range a {
+		if a[i].Compare(evalCtx, b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// drainUnflushed returns the statement and transaction statistics collected
+// since the last call as rows of the persisted statistics tables for the given
+// bucket and node, and clears them. The restore function of each row merges
+// its statistics back if they could not be persisted; they are then persisted
+// by the next flush, in its bucket.
+func (s *sqlStats) drainUnflushed(
+	aggregatedTs time.Time, nodeID roachpb.NodeID,
+) (stmtRows, txnRows []persistedStatsRow) {
+	s.Lock()
+	defer s.Unlock()
+	for appName, a := range s.apps {
+		a.Lock()
+		stmts, txns := a.unflushedStmts, a.unflushedTxns
+		a.unflushedStmts = make(map[stmtKey]*stmtStats, len(stmts)/2)
+		a.unflushedTxns = make(map[string]*txnStats, len(txns)/2)
+		a.Unlock()
+
+		for key, stats := range stmts {
+			a, key, stats := a, key, stats
+			stats.Lock()
+			buf, err := protoutil.Marshal(&stats.data)
+			stats.Unlock()
+			restore := func() { a.restoreUnflushedStmt(key, &stats.data) }
+			if err != nil {
+				restore()
+				continue
+			}
+			stmtRows = append(stmtRows, persistedStatsRow{
+				pk:      []interface{}{aggregatedTs, key.stmt, key.flags(), appName, nodeID},
+				stats:   buf,
+				restore: restore,
+			})
+		}
+		for fingerprint, stats := range txns {
+			a, fingerprint, stats := a, fingerprint, stats
+			stats.Lock()
+			buf, err := protoutil.Marshal(&stats.data)
+			stats.Unlock()
+			restore := func() { a.restoreUnflushedTxn(fingerprint, &stats.data) }
+			if err != nil {
+				restore()
+				continue
+			}
+			txnRows = append(txnRows, persistedStatsRow{
+				pk:      []interface{}{aggregatedTs, fingerprint, appName, nodeID},
+				stats:   buf,
+				restore: restore,
+			})
+		}
+	}
+	return stmtRows, txnRows
+}
+
+// PeriodicallyPersistStats runs a loop which persists the statement and
+// transaction statistics collected by the node every
+// sql.metrics.statement_details.flush_interval, and periodically makes sure
+// that the job which deletes and compacts the old persisted statistics exists.
+func (s *Server) PeriodicallyPersistStats(ctx context.Context, stopper *stop.Stopper) {
+	intervalChangedCh := make(chan struct{}, 1)
+	statsFlushInterval.SetOnChange(&s.cfg.Settings.SV, func() {
+		select {
+		case intervalChangedCh <- struct{}{}:
+		default:
+		}
+	})
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		lastCleanupCheck := timeutil.Now()
+		for {
+			wait := statsFlushInterval.Get(&s.cfg.Settings.SV)
+			if wait == 0 {
+				// Persistence is disabled; still wake up periodically to flush
+				// the statistics collected before it was, and to check on the
+				// cleanup job.
+				wait = persistedStatsCleanupInterval
+			}
+			timer.Reset(wait)
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-intervalChangedCh:
+				continue
+			case <-timer.C:
+				timer.Read = true
+			}
+
+			if err := s.persistStats(ctx); err != nil {
+				log.Warningf(ctx, "error persisting statement statistics: %v", err)
+			}
+			if timeutil.Since(lastCleanupCheck) >= persistedStatsCleanupInterval {
+				if err := ensureStatsCleanupJob(ctx, s.cfg); err != nil {
+					log.Warningf(ctx, "error creating the statement statistics cleanup job: %v", err)
+				}
+				lastCleanupCheck = timeutil.Now()
+			}
+		}
+	})
+}
+
+// persistStats merges the statistics collected since the last call into the
+// persisted statistics of the current hour. The statistics which could not be
+// persisted are kept in memory for the next call.
+func (s *Server) persistStats(ctx context.Context) error {
+	stmtRows, txnRows := s.sqlStats.drainUnflushed(
+		timeutil.Now().Truncate(time.Hour), s.cfg.NodeID.Get(),
+	)
+	n, err := stmtStatsTable.addAll(ctx, s.cfg.DB, s.cfg.InternalExecutor, stmtRows)
+	if err != nil {
+		restorePersistedStatsRows(stmtRows[n:])
+		restorePersistedStatsRows(txnRows)
+		return err
+	}
+	n, err = txnStatsTable.addAll(ctx, s.cfg.DB, s.cfg.InternalExecutor, txnRows)
+	if err != nil {
+		restorePersistedStatsRows(txnRows[n:])
+	}
+	return err
+}
+
+func restorePersistedStatsRows(rows []persistedStatsRow) {
+	for _, row := range rows {
+		row.restore()
+	}
+}
+
+// cleanupPersistedStats deletes the persisted statistics which are older than
+// sql.metrics.statement_details.retention, and compacts those older than
+// sql.metrics.statement_details.compaction_threshold.
+func cleanupPersistedStats(ctx context.Context, execCfg *ExecutorConfig) error {
+	now := timeutil.Now()
+	retention := persistedStatsRetention.Get(&execCfg.Settings.SV)
+	threshold := persistedStatsCompactionThreshold.Get(&execCfg.Settings.SV)
+	for _, t := range []*persistedStatsTable{&stmtStatsTable, &txnStatsTable} {
+		if retention > 0 {
+			if err := t.deleteExpired(ctx, execCfg.InternalExecutor, now.Add(-retention)); err != nil {
+				return err
+			}
+		}
+		if threshold > 0 {
+			if err := t.compact(ctx, execCfg.DB, execCfg.InternalExecutor, now.Add(-threshold)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureStatsCleanupJob creates the job which cleans up the persisted
+// statistics, unless it already exists. A paused job is left alone, which is
+// how the cleanup is disabled.
+func ensureStatsCleanupJob(ctx context.Context, execCfg *ExecutorConfig) error {
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, _ /* cols */, err := execCfg.InternalExecutor.Query(
+			ctx, "find-stats-cleanup-job", txn,
+			`SELECT payload FROM system.jobs WHERE status IN ($1, $2, $3)`,
+			string(jobs.StatusPending), string(jobs.StatusRunning), string(jobs.StatusPaused),
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			payload, err := jobs.UnmarshalPayload(row[0])
+			if err != nil {
+				return err
+			}
+			if payload.Type() == jobspb.TypeStatsCleanup {
+				return nil
+			}
+		}
+		_, err = execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+			Description: "persisted statement statistics cleanup",
+			Username:    security.RootUser,
+			Details:     jobspb.StatsCleanupDetails{},
+			Progress:    jobspb.StatsCleanupProgress{},
+		}, txn)
+		return err
+	})
+}
+
+// statsCleanupResumer runs the job which cleans up the persisted statistics
+// every persistedStatsCleanupInterval. The job never completes; it stops
+// when it is paused or canceled.
+type statsCleanupResumer struct{}
+
+var _ jobs.Resumer = &statsCleanupResumer{}
+
+// Resume implements the jobs.Resumer interface.
+func (r *statsCleanupResumer) Resume(
+	ctx context.Context, job *jobs.Job, phs interface{}, _ chan<- tree.Datums,
+) error {
+	execCfg := phs.(PlanHookState).ExecCfg()
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		if err := cleanupPersistedStats(ctx, execCfg); err != nil {
+			log.Warningf(ctx, "error cleaning up persisted statement statistics: %v", err)
+		}
+		// Updating the progress fails once the job is paused or canceled.
+		if err := job.FractionProgressed(ctx, jobs.FractionUpdater(0)); err != nil {
+			return err
+		}
+		timer.Reset(persistedStatsCleanupInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.Read = true
+		}
+	}
+}
+
+// OnSuccess implements the jobs.Resumer interface.
+func (r *statsCleanupResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+// OnTerminal implements the jobs.Resumer interface.
+func (r *statsCleanupResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+// OnFailOrCancel implements the jobs.Resumer interface.
+func (r *statsCleanupResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+func statsCleanupResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeStatsCleanup {
+		return nil
+	}
+	return &statsCleanupResumer{}
+}
+
+func init() {
+	jobs.AddResumeHook(statsCleanupResumeHook)
+}
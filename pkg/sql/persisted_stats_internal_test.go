@@ -0,0 +1,173 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestPersistedStatsCleanup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(ExecutorConfig)
+	ie := execCfg.InternalExecutor
+	r := sqlutils.MakeSQLRunner(db)
+
+	stats, err := protoutil.Marshal(&roachpb.TransactionStatistics{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := timeutil.Now()
+	day := now.Truncate(24 * time.Hour).Add(-3 * 24 * time.Hour)
+	expired := now.Add(-10 * 24 * time.Hour)
+
+	// Persist the hourly statistics of three transactions on two nodes for a
+	// whole day, which takes several batches to compact, and an expired row.
+	var rows []persistedStatsRow
+	for h := 0; h < 24; h++ {
+		for _, nodeID := range []roachpb.NodeID{1, 2} {
+			for i := 0; i < 3; i++ {
+				rows = append(rows, persistedStatsRow{
+					pk:    []interface{}{day.Add(time.Duration(h) * time.Hour), fmt.Sprintf("txn%d", i), "app", nodeID},
+					stats: stats,
+				})
+			}
+		}
+	}
+	rows = append(rows, persistedStatsRow{
+		pk:    []interface{}{expired, "txn0", "app", roachpb.NodeID(3)},
+		stats: stats,
+	})
+	if n, err := txnStatsTable.addAll(ctx, kvDB, ie, rows); err != nil {
+		t.Fatal(err)
+	} else if n != len(rows) {
+		t.Fatalf("expected %d rows to be added, got %d", len(rows), n)
+	}
+
+	if err := txnStatsTable.deleteExpired(ctx, ie, now.Add(-7*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(t,
+		`SELECT count(*) FROM system.transaction_statistics WHERE node_id = 3`,
+		[][]string{{"0"}},
+	)
+
+	if err := txnStatsTable.compact(ctx, kvDB, ie, now.Add(-24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	res, _ /* cols */, err := ie.Query(ctx, "test", nil, /* txn */
+		`SELECT aggregated_ts, fingerprint, node_id, statistics FROM system.transaction_statistics
+		  ORDER BY fingerprint, node_id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 6 {
+		t.Fatalf("expected 6 compacted rows, got %d", len(res))
+	}
+	for _, row := range res {
+		ts := tree.MustBeDTimestampTZ(row[0]).Time
+		if !ts.Equal(day) {
+			t.Errorf("%s: expected bucket %s, got %s", row[1], day, ts)
+		}
+		var compacted roachpb.TransactionStatistics
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[3])), &compacted); err != nil {
+			t.Fatal(err)
+		}
+		if compacted.Count != 24 {
+			t.Errorf("%s on node %s: expected a count of 24, got %d", row[1], row[2], compacted.Count)
+		}
+	}
+}
+
+func TestPersistedStatsRestore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := &sqlStats{st: cluster.MakeTestingClusterSettings(), apps: make(map[string]*appStats)}
+	a := s.getStatsForApplication("app")
+	key := stmtKey{stmt: "SELECT _"}
+	a.restoreUnflushedStmt(key, &roachpb.StatementStatistics{Count: 2})
+	a.restoreUnflushedTxn("txn", &roachpb.TransactionStatistics{Count: 3})
+
+	aggregatedTs := timeutil.Now().Truncate(time.Hour)
+	stmtRows, txnRows := s.drainUnflushed(aggregatedTs, 1 /* nodeID */)
+	if len(stmtRows) != 1 || len(txnRows) != 1 {
+		t.Fatalf("expected 1 statement and 1 transaction row, got %d and %d",
+			len(stmtRows), len(txnRows))
+	}
+	if again, _ := s.drainUnflushed(aggregatedTs, 1 /* nodeID */); len(again) != 0 {
+		t.Fatalf("expected the statistics to be drained, got %d rows", len(again))
+	}
+
+	// Simulate a failure to persist the rows: the statistics are merged with
+	// those collected in the meantime.
+	a.restoreUnflushedTxn("txn", &roachpb.TransactionStatistics{Count: 1})
+	restorePersistedStatsRows(stmtRows)
+	restorePersistedStatsRows(txnRows)
+
+	if count := a.getUnflushedStatsForStmt(key).data.Count; count != 2 {
+		t.Errorf("expected a statement count of 2, got %d", count)
+	}
+	if count := a.getUnflushedStatsForTxn("txn").data.Count; count != 4 {
+		t.Errorf("expected a transaction count of 4, got %d", count)
+	}
+}
+
+func TestEnsureStatsCleanupJob(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(ExecutorConfig)
+	r := sqlutils.MakeSQLRunner(db)
+
+	for i := 0; i < 2; i++ {
+		if err := ensureStatsCleanupJob(ctx, &execCfg); err != nil {
+			t.Fatal(err)
+		}
+		r.CheckQueryResults(t,
+			`SELECT count(*) FROM crdb_internal.jobs WHERE job_type = 'STATS CLEANUP'`,
+			[][]string{{"1"}},
+		)
+	}
+
+	// A paused job is not replaced, so that pausing it disables the cleanup.
+	var jobID int64
+	r.QueryRow(t, `SELECT job_id FROM crdb_internal.jobs WHERE job_type = 'STATS CLEANUP'`).Scan(&jobID)
+	r.Exec(t, `PAUSE JOB $1`, jobID)
+	if err := ensureStatsCleanupJob(ctx, &execCfg); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(t,
+		`SELECT count(*) FROM crdb_internal.jobs WHERE job_type = 'STATS CLEANUP'`,
+		[][]string{{"1"}},
+	)
+}
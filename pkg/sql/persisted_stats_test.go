@@ -0,0 +1,63 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPersistedStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Use a single connection so that the application name applies to all the
+	// statements.
+	db.SetMaxOpenConns(1)
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `SET CLUSTER SETTING sql.metrics.statement_details.flush_interval = '10ms'`)
+	r.Exec(t, `SET application_name = 'persisted_stats_test'`)
+	r.Exec(t, `CREATE TABLE t (x INT PRIMARY KEY)`)
+	r.Exec(t, `BEGIN; INSERT INTO t VALUES (1); INSERT INTO t VALUES (2); COMMIT`)
+
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		r.QueryRow(t, `
+SELECT coalesce(sum(count), 0) FROM crdb_internal.statement_statistics
+ WHERE application_name = 'persisted_stats_test' AND key LIKE '%INSERT INTO t VALUES%'`,
+		).Scan(&count)
+		if count != 2 {
+			return errors.Errorf("expected 2 persisted INSERT statements, got %d", count)
+		}
+		r.QueryRow(t, `
+SELECT coalesce(sum(count), 0) FROM crdb_internal.transaction_statistics
+ WHERE application_name = 'persisted_stats_test' AND key LIKE '%INSERT INTO t VALUES%'`,
+		).Scan(&count)
+		if count != 1 {
+			return errors.Errorf("expected 1 persisted transaction, got %d", count)
+		}
+		return nil
+	})
+}
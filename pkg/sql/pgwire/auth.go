@@ -0,0 +1,357 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// connAuthConf is the host-based authentication configuration of SQL
+// connections. When it is empty, clients connecting over TLS authenticate
+// with a certificate or a password and other clients, which can only connect
+// to insecure servers, are trusted.
+var connAuthConf = settings.RegisterValidatedStringSetting(
+	"server.host_based_authentication.configuration",
+	"host-based authentication configuration to use during connection authentication, "+
+		"in pg_hba.conf syntax",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := hba.Parse(s)
+		return err
+	},
+)
+
+// rootEntry is implicitly prepended to non-empty configurations so that a
+// configuration can't lock the root user out of the cluster.
+var rootEntry = hba.Entry{
+	ConnType: hba.ConnHost,
+	Users:    []string{security.RootUser},
+	Method:   hba.MethodCertPassword,
+}
+
+// authConf is the parsed connection authentication configuration, as of the
+// last change of connAuthConf.
+type authConf struct {
+	conf *hba.Conf
+	err  error
+}
+
+// parseAuthConf parses the current value of connAuthConf.
+func parseAuthConf(sv *settings.Values) authConf {
+	conf, err := hba.Parse(connAuthConf.Get(sv))
+	return authConf{conf: conf, err: err}
+}
+
+// authMethod returns the authentication method to use for the connection.
+// entry is nil if the connection authentication configuration is empty.
+func (c *conn) authMethod() (method hba.Method, entry *hba.Entry, err error) {
+	_, isTLS := c.conn.(*tls.Conn)
+	if c.authConf.err != nil {
+		return "", nil, c.authConf.err
+	}
+	if c.authConf.conf == nil || len(c.authConf.conf.Entries) == 0 {
+		if isTLS {
+			return hba.MethodCertPassword, nil, nil
+		}
+		return hba.MethodTrust, nil, nil
+	}
+	// The entries are shared by all the connections: copy them instead of
+	// prepending rootEntry in place.
+	conf := hba.Conf{Entries: make([]hba.Entry, 0, len(c.authConf.conf.Entries)+1)}
+	conf.Entries = append(conf.Entries, rootEntry)
+	conf.Entries = append(conf.Entries, c.authConf.conf.Entries...)
+
+	connType := hba.ConnHost
+	var ip net.IP
+	if addr := c.sessionArgs.RemoteAddr; addr != nil && addr.Network() == "unix" {
+		connType = hba.ConnLocal
+	} else {
+		if isTLS {
+			connType = hba.ConnHostSSL
+		}
+		if addr != nil {
+			if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+				ip = net.ParseIP(host)
+			}
+		}
+	}
+	e, ok := conf.Find(connType, c.sessionArgs.Database, c.sessionArgs.User, ip)
+	if !ok {
+		return "", nil, errors.Errorf(
+			"no host-based authentication rule for user %s, database %q and address %s",
+			c.sessionArgs.User, c.sessionArgs.Database, c.sessionArgs.RemoteAddr)
+	}
+	return e.Method, &e, nil
+}
+
+// authenticate authenticates the user of the connection with the given
+// method.
+func (c *conn) authenticate(
+	ctx context.Context, method hba.Method, insecure bool, hashedPassword []byte,
+) error {
+	var authenticationHook security.UserAuthHook
+	switch method {
+	case hba.MethodTrust:
+		return nil
+
+	case hba.MethodReject:
+		return errors.Errorf("authentication rejected for user %s", c.sessionArgs.User)
+
+	case hba.MethodCert, hba.MethodPassword, hba.MethodCertPassword:
+		var tlsState *tls.ConnectionState
+		if tlsConn, ok := c.conn.(*tls.Conn); ok {
+			state := tlsConn.ConnectionState()
+			if len(state.PeerCertificates) > 0 {
+				tlsState = &state
+			}
+		}
+		if method == hba.MethodCert && tlsState == nil {
+			return errors.Errorf("user %s must authenticate with a client certificate",
+				c.sessionArgs.User)
+		}
+		// If no certificates are provided, or certificates are not accepted,
+		// default to password authentication.
+		if tlsState == nil || method == hba.MethodPassword {
+			return c.authenticatePassword(ctx, insecure, hashedPassword)
+		}
+		// Normalize the username contained in the certificate.
+		tlsState.PeerCertificates[0].Subject.CommonName = tree.Name(
+			tlsState.PeerCertificates[0].Subject.CommonName,
+		).Normalize()
+		var err error
+		authenticationHook, err = security.UserAuthCertHook(insecure, tlsState)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return errors.Errorf("unknown authentication method %q", method)
+	}
+	return authenticationHook(c.sessionArgs.User, true /* public */)
+}
+
+// authenticatePassword authenticates the user of the connection with their
+// password. The SCRAM-SHA-256 exchange is used if it is accepted and the
+// password of the user is stored as a SCRAM verifier. Otherwise the password
+// is requested in cleartext, and its bcrypt hash is upgraded to a SCRAM
+// verifier if SCRAM is accepted.
+func (c *conn) authenticatePassword(
+	ctx context.Context, insecure bool, hashedPassword []byte,
+) error {
+	user := c.sessionArgs.User
+	if insecure {
+		password, err := c.sendAuthPasswordRequest()
+		if err != nil {
+			return err
+		}
+		hook := security.UserAuthPasswordHook(insecure, password, hashedPassword)
+		return hook(user, true /* public */)
+	}
+
+	if err := sql.CheckUserLoginStatus(ctx, c.execCfg, user); err != nil {
+		return err
+	}
+
+	methods := sql.GetPasswordMethods(&c.execCfg.Settings.SV)
+	isSCRAM := security.IsSCRAMHash(hashedPassword)
+	if methods.SCRAM && isSCRAM {
+		if user == security.RootUser {
+			return errors.Errorf(
+				"user %s must use certificate authentication instead of password authentication", user)
+		}
+		if err := c.authenticateSCRAM(hashedPassword); err != nil {
+			return err
+		}
+		c.resetFailedLogins(ctx)
+		return nil
+	}
+	if !methods.Cleartext {
+		if methods.SCRAM && len(hashedPassword) > 0 {
+			return errors.Errorf("the password of user %s must be reset to authenticate with %s",
+				user, security.SCRAMMechanism)
+		}
+		return errors.New("cleartext password authentication is disabled")
+	}
+
+	password, err := c.sendAuthPasswordRequest()
+	if err != nil {
+		return err
+	}
+	hook := security.UserAuthPasswordHook(insecure, password, hashedPassword)
+	if err := hook(user, true /* public */); err != nil {
+		return wrongPasswordError{err}
+	}
+	c.resetFailedLogins(ctx)
+	if methods.SCRAM && !isSCRAM {
+		newHashedPassword, err := security.HashPasswordSCRAM(password)
+		if err == nil {
+			err = sql.UpgradeUserHashedPassword(ctx, c.execCfg, user, hashedPassword, newHashedPassword)
+		}
+		if err != nil {
+			// The user is authenticated regardless.
+			log.Warningf(ctx, "%v", err)
+		}
+	}
+	return nil
+}
+
+// authenticateSCRAM runs the SASL SCRAM-SHA-256 exchange with the client.
+func (c *conn) authenticateSCRAM(hashedPassword []byte) error {
+	scram, err := security.NewSCRAMServer(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	// Advertise the supported mechanisms, as a list of strings terminated by
+	// an empty string.
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgAuth)
+	c.msgBuilder.putInt32(authSASL)
+	c.msgBuilder.writeTerminatedString(security.SCRAMMechanism)
+	c.msgBuilder.writeTerminatedString("")
+	if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+		return err
+	}
+
+	// The SASLInitialResponse message contains the selected mechanism and the
+	// client-first-message.
+	if err := c.readSASLResponse(); err != nil {
+		return err
+	}
+	mechanism, err := c.readBuf.GetString()
+	if err != nil {
+		return err
+	}
+	if mechanism != security.SCRAMMechanism {
+		return errors.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	n, err := c.readBuf.GetUint32()
+	if err != nil {
+		return err
+	}
+	// The length of the client-first-message is -1 if there is none.
+	if int32(n) < 0 {
+		return errors.New("missing SCRAM client-first-message")
+	}
+	clientFirst, err := c.readBuf.GetBytes(int(n))
+	if err != nil {
+		return err
+	}
+	serverFirst, err := scram.ServerFirst(clientFirst)
+	if err != nil {
+		return err
+	}
+	if err := c.sendSASLMessage(authSASLContinue, serverFirst); err != nil {
+		return err
+	}
+
+	// The SASLResponse message contains the client-final-message.
+	if err := c.readSASLResponse(); err != nil {
+		return err
+	}
+	serverFinal, err := scram.ServerFinal(c.readBuf.Msg)
+	if err != nil {
+		return wrongPasswordError{err}
+	}
+	return c.sendSASLMessage(authSASLFinal, serverFinal)
+}
+
+// readSASLResponse reads a SASLInitialResponse or SASLResponse message from
+// the client into c.readBuf.
+func (c *conn) readSASLResponse() error {
+	typ, n, err := c.readBuf.ReadTypedMsg(&c.rd)
+	c.metrics.BytesInCount.Inc(int64(n))
+	if err != nil {
+		return err
+	}
+	if typ != pgwirebase.ClientMsgPassword {
+		return errors.Errorf("invalid response to authentication request: %s", typ)
+	}
+	return nil
+}
+
+func (c *conn) sendSASLMessage(authType int32, data []byte) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgAuth)
+	c.msgBuilder.putInt32(authType)
+	c.msgBuilder.write(data)
+	return c.msgBuilder.finishMsg(c.conn)
+}
+
+// wrongPasswordError is returned when a client fails to authenticate because
+// of a wrong password. These failures count towards the lockout of the user.
+type wrongPasswordError struct {
+	error
+}
+
+// recordLoginFailure records a failed authentication in the event log.
+func (c *conn) recordLoginFailure(ctx context.Context, method hba.Method, err error) {
+	_, wrongPassword := err.(wrongPasswordError)
+	info := sql.EventLogUserLoginFailureDetail{
+		User:   c.sessionArgs.User,
+		Method: string(method),
+		Error:  err.Error(),
+	}
+	if addr := c.sessionArgs.RemoteAddr; addr != nil {
+		info.RemoteAddr = addr.String()
+	}
+	if err := sql.RecordUserLoginFailure(ctx, c.execCfg, &info, wrongPassword); err != nil {
+		log.Warningf(ctx, "unable to record the failed login of user %s: %v", info.User, err)
+	}
+}
+
+// resetFailedLogins forgets the failed logins of the user of the connection
+// after it authenticated with a password.
+func (c *conn) resetFailedLogins(ctx context.Context) {
+	if err := sql.ResetUserFailedLogins(ctx, c.execCfg, c.sessionArgs.User); err != nil {
+		// The user is authenticated regardless.
+		log.Warningf(ctx, "%v", err)
+	}
+}
+
+// logAuthentication records an authentication decision in the audit log.
+// entry is the host-based authentication rule which selected the method, if
+// any.
+func (c *conn) logAuthentication(
+	ctx context.Context, method hba.Method, entry *hba.Entry, err error,
+) {
+	logger := c.execCfg.AuditLogger
+	if logger == nil {
+		return
+	}
+	rule := "(none)"
+	if entry != nil {
+		rule = entry.String()
+	}
+	if method == "" {
+		method = "(none)"
+	}
+	result := "OK"
+	if err != nil {
+		result = "ERROR: " + err.Error()
+	}
+	logger.Logf(ctx, "authentication user=%q database=%q remote=%s rule=%q method=%s %s",
+		c.sessionArgs.User, c.sessionArgs.Database, c.sessionArgs.RemoteAddr, rule, method, result)
+}
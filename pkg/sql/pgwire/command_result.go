@@ -76,6 +76,10 @@ type commandResult struct {
 	// case for queries executed through the simple protocol). Otherwise, it needs
 	// to have an entry for every column.
 	formatCodes []pgwirebase.FormatCode
+
+	// copyOut is set for COPY TO statements, whose rows are sent with the
+	// Copy-out subprotocol in this format.
+	copyOut *sql.CopyOutFormat
 }
 
 func (c *conn) makeCommandResult(
@@ -85,7 +89,7 @@ func (c *conn) makeCommandResult(
 	formatCodes []pgwirebase.FormatCode,
 	conv sessiondata.DataConversionConfig,
 ) commandResult {
-	return commandResult{
+	r := commandResult{
 		conn:           c,
 		pos:            pos,
 		descOpt:        descOpt,
@@ -95,6 +99,20 @@ func (c *conn) makeCommandResult(
 		cmdCompleteTag: stmt.StatementTag(),
 		conv:           conv,
 	}
+	r.setCopyOut(stmt)
+	return r
+}
+
+// setCopyOut sets copyOut if stmt is a COPY TO statement.
+func (r *commandResult) setCopyOut(stmt tree.Statement) {
+	r.copyOut = nil
+	if cp, ok := stmt.(*tree.CopyTo); ok {
+		// The options were validated when planning the statement, which fails
+		// before any row is produced otherwise.
+		if f, err := sql.MakeCopyOutFormat(&cp.Options); err == nil {
+			r.copyOut = &f
+		}
+	}
 }
 
 func (c *conn) makeMiscResult(pos sql.CmdPos, typ completionMsgType) commandResult {
@@ -132,6 +150,9 @@ func (r *commandResult) Close(t sql.TransactionStatusIndicator) {
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
+		if r.copyOut != nil {
+			r.conn.bufferCopyDone()
+		}
 		tag := cookTag(
 			r.cmdCompleteTag, r.conn.writerState.tagBuf[:0], r.stmtType, r.rowsAffected,
 		)
@@ -210,7 +231,11 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 	}
 	r.rowsAffected++
 
-	r.conn.bufferRow(ctx, row, r.formatCodes, r.conv)
+	if r.copyOut != nil {
+		r.conn.bufferCopyData(ctx, row, r.copyOut, r.conv)
+	} else {
+		r.conn.bufferRow(ctx, row, r.formatCodes, r.conv)
+	}
 	_ /* flushed */, err := r.conn.maybeFlush(r.pos)
 	return err
 }
@@ -218,6 +243,10 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 // SetColumns is part of the CommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols sqlbase.ResultColumns) {
 	r.conn.writerState.fi.registerCmd(r.pos)
+	if r.copyOut != nil {
+		r.conn.bufferCopyOutResponse(ctx, cols, r.copyOut)
+		return
+	}
 	if r.descOpt == sql.NeedRowDesc {
 		_ /* err */ = r.conn.writeRowDescription(ctx, cols, r.formatCodes, &r.conn.writerState.buf)
 	}
@@ -268,4 +297,5 @@ func (r *commandResult) SetLimit(n int) {
 func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.stmtType = stmt.StatementType()
 	r.cmdCompleteTag = stmt.StatementTag()
+	r.setCopyOut(stmt)
 }
@@ -18,7 +18,6 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -32,7 +31,6 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -51,6 +49,9 @@ import (
 const (
 	authOK                int32 = 0
 	authCleartextPassword int32 = 3
+	authSASL              int32 = 10
+	authSASLContinue      int32 = 11
+	authSASLFinal         int32 = 12
 )
 
 // conn implements a pgwire network connection (version 3 of the protocol,
@@ -66,6 +67,9 @@ type conn struct {
 	sessionArgs sql.SessionArgs
 	execCfg     *sql.ExecutorConfig
 	metrics     *ServerMetrics
+	// authConf is the connection authentication configuration in effect when
+	// the connection was established.
+	authConf authConf
 
 	// rd is a buffered reader consuming conn. All reads from conn go through
 	// this.
@@ -92,6 +96,9 @@ type conn struct {
 
 	readBuf    pgwirebase.ReadBuffer
 	msgBuilder *writeBuffer
+	// copyOutBuf is used to encode the values of the rows of COPY TO
+	// statements.
+	copyOutBuf *writeBuffer
 }
 
 // serveConn creates a conn that will serve the netConn. It returns once the
@@ -143,6 +150,7 @@ func serveConn(
 	execCfg *sql.ExecutorConfig,
 	stopper *stop.Stopper,
 	insecure bool,
+	authConf authConf,
 ) error {
 	sArgs.RemoteAddr = netConn.RemoteAddr()
 
@@ -151,6 +159,7 @@ func serveConn(
 	}
 
 	c := newConn(netConn, sArgs, metrics, execCfg)
+	c.authConf = authConf
 
 	if err := c.handleAuthentication(ctx, insecure); err != nil {
 		_ = c.conn.Close()
@@ -171,6 +180,7 @@ func newConn(
 		stmtBuf:     sql.NewStmtBuf(),
 		sessionArgs: sArgs,
 		msgBuilder:  newWriteBuffer(metrics.BytesOutCount),
+		copyOutBuf:  newWriteBuffer(nil /* bytecount */),
 		metrics:     metrics,
 		rd:          *bufio.NewReader(netConn),
 		execCfg:     execCfg,
@@ -227,6 +237,16 @@ func (c *conn) serveImpl(
 		}
 	}
 
+	// The key to cancel the queries of the session.
+	if key := c.sessionArgs.CancelKey; key != 0 {
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgBackendKeyData)
+		c.msgBuilder.putInt32(int32(key.ProcessID()))
+		c.msgBuilder.putInt32(int32(key.SecretKey()))
+		if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+			return err
+		}
+	}
+
 	// An initial readyForQuery message is part of the handshake.
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgReady)
 	c.msgBuilder.writeByte(byte(sql.IdleTxnBlock))
@@ -526,6 +546,12 @@ func (c *conn) handleParse(ctx context.Context, buf *pgwirebase.ReadBuffer) erro
 		// https://www.postgresql.org/message-id/flat/CAMsr%2BYGvp2wRx9pPSxaKFdaObxX8DzWse%2BOkWk2xpXSvT0rq-g%40mail.gmail.com#CAMsr+YGvp2wRx9pPSxaKFdaObxX8DzWse+OkWk2xpXSvT0rq-g@mail.gmail.com
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: fmt.Errorf("CopyFrom not supported in extended protocol mode")})
 	}
+	if _, ok := stmt.(*tree.CopyTo); ok {
+		// COPY TO is not supported in extended protocol either: describing the
+		// statement would return the description of its rows, which are sent as
+		// Copy-out data instead.
+		return c.stmtBuf.Push(ctx, sql.SendError{Err: fmt.Errorf("CopyTo not supported in extended protocol mode")})
+	}
 
 	return c.stmtBuf.Push(
 		ctx,
@@ -903,6 +929,18 @@ func (c *conn) bufferErr(err error) {
 		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
 	}
 }
+
+// BufferNotification is part of the sql.ClientComm interface.
+func (c *conn) BufferNotification(n sql.Notification) {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(int32(n.ProcessID))
+	c.msgBuilder.writeTerminatedString(n.Channel)
+	c.msgBuilder.writeTerminatedString(n.Payload)
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
 func (c *conn) bufferEmptyQueryResponse() {
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgEmptyQuery)
 	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
@@ -1236,38 +1274,26 @@ func (c *conn) handleAuthentication(ctx context.Context, insecure bool) error {
 		return sendError(err)
 	}
 	if !exists {
-		return sendError(errors.Errorf("user %s does not exist", c.sessionArgs.User))
+		err := errors.Errorf("user %s does not exist", c.sessionArgs.User)
+		c.logAuthentication(ctx, "" /* method */, nil /* entry */, err)
+		c.recordLoginFailure(ctx, "", err)
+		return sendError(err)
 	}
 
-	if tlsConn, ok := c.conn.(*tls.Conn); ok {
-		var authenticationHook security.UserAuthHook
-
-		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
-			password, err := c.sendAuthPasswordRequest()
-			if err != nil {
-				return sendError(err)
-			}
-			authenticationHook = security.UserAuthPasswordHook(
-				insecure, password, hashedPassword,
-			)
-		} else {
-			// Normalize the username contained in the certificate.
-			tlsState.PeerCertificates[0].Subject.CommonName = tree.Name(
-				tlsState.PeerCertificates[0].Subject.CommonName,
-			).Normalize()
-			var err error
-			authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
-			if err != nil {
-				return sendError(err)
-			}
-		}
-
-		if err := authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
-			return sendError(err)
+	method, entry, err := c.authMethod()
+	if err != nil {
+		c.logAuthentication(ctx, "" /* method */, nil /* entry */, err)
+		c.recordLoginFailure(ctx, "", err)
+		return sendError(err)
+	}
+	err = c.authenticate(ctx, method, insecure, hashedPassword)
+	c.logAuthentication(ctx, method, entry, err)
+	if err != nil {
+		c.recordLoginFailure(ctx, method, err)
+		if wrongPassword, ok := err.(wrongPasswordError); ok {
+			err = wrongPassword.error
 		}
+		return sendError(err)
 	}
 
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgAuth)
@@ -0,0 +1,159 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// The rows of COPY TO statements are sent with the Copy-out subprotocol: a
+// CopyOutResponse message, followed by a CopyData message per row and a
+// CopyDone message. Each row is a line of text in the text or CSV format of
+// the statement.
+//
+// See: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-COPY
+
+// bufferCopyOutResponse buffers the CopyOutResponse message and, if the
+// format has a header, the line with the names of the columns.
+func (c *conn) bufferCopyOutResponse(
+	ctx context.Context, cols sqlbase.ResultColumns, f *sql.CopyOutFormat,
+) {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyOutResponse)
+	c.msgBuilder.writeByte(byte(pgwirebase.FormatText))
+	c.msgBuilder.putInt16(int16(len(cols)))
+	for range cols {
+		c.msgBuilder.putInt16(int16(pgwirebase.FormatText))
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+
+	if !f.Header {
+		return
+	}
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+	for i, col := range cols {
+		if i > 0 {
+			c.msgBuilder.writeByte(f.Delimiter)
+		}
+		writeCopyValue(c.msgBuilder, []byte(col.Name), f)
+	}
+	c.msgBuilder.writeByte('\n')
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// bufferCopyData buffers a CopyData message containing a row.
+func (c *conn) bufferCopyData(
+	ctx context.Context, row tree.Datums, f *sql.CopyOutFormat, conv sessiondata.DataConversionConfig,
+) {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+	for i, d := range row {
+		if i > 0 {
+			c.msgBuilder.writeByte(f.Delimiter)
+		}
+		if d == tree.DNull {
+			c.msgBuilder.writeString(f.Null)
+			continue
+		}
+		// Values are in the text format of the protocol. copyOutBuf receives
+		// the encoded value after its 4-byte length prefix.
+		c.copyOutBuf.reset()
+		c.copyOutBuf.writeTextDatum(ctx, d, conv)
+		if c.copyOutBuf.err != nil {
+			c.msgBuilder.setError(c.copyOutBuf.err)
+			break
+		}
+		writeCopyValue(c.msgBuilder, c.copyOutBuf.wrapped.Bytes()[4:], f)
+	}
+	c.msgBuilder.writeByte('\n')
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// bufferCopyDone buffers the CopyDone message ending the Copy-out
+// subprotocol.
+func (c *conn) bufferCopyDone() {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyDone)
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// writeCopyValue writes a non-NULL value, escaped or quoted as required by
+// the format.
+func writeCopyValue(b *writeBuffer, v []byte, f *sql.CopyOutFormat) {
+	if f.CSV {
+		writeCSVValue(b, v, f)
+		return
+	}
+	for _, ch := range v {
+		switch ch {
+		case '\\':
+			b.writeString(`\\`)
+		case '\b':
+			b.writeString(`\b`)
+		case '\f':
+			b.writeString(`\f`)
+		case '\n':
+			b.writeString(`\n`)
+		case '\r':
+			b.writeString(`\r`)
+		case '\t':
+			b.writeString(`\t`)
+		case '\v':
+			b.writeString(`\v`)
+		default:
+			if ch == f.Delimiter {
+				b.writeByte('\\')
+			}
+			b.writeByte(ch)
+		}
+	}
+}
+
+// writeCSVValue writes a non-NULL value in the CSV format. The value is
+// quoted if it contains special characters or if it could be mistaken for
+// the representation of NULL.
+func writeCSVValue(b *writeBuffer, v []byte, f *sql.CopyOutFormat) {
+	quote := string(v) == f.Null
+	for _, ch := range v {
+		if ch == f.Delimiter || ch == '"' || ch == '\n' || ch == '\r' {
+			quote = true
+			break
+		}
+	}
+	if !quote {
+		b.write(v)
+		return
+	}
+	b.writeByte('"')
+	for _, ch := range v {
+		if ch == '"' {
+			b.writeByte('"')
+		}
+		b.writeByte(ch)
+	}
+	b.writeByte('"')
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hba parses host-based authentication configurations in the syntax
+// of PostgreSQL's pg_hba.conf and matches connections against them.
+//
+// Each non-empty line of a configuration, after removing comments which
+// start with '#', is a rule of one of the forms:
+//
+//	local   DATABASE USER         METHOD
+//	host    DATABASE USER ADDRESS METHOD
+//	hostssl DATABASE USER ADDRESS METHOD
+//
+// DATABASE and USER are comma-separated lists of names, or "all". ADDRESS is
+// a CIDR range, or "all". METHOD is one of cert, password, cert-password,
+// trust and reject. The first rule matching a connection determines how it
+// is authenticated.
+package hba
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConnType is the type of connection matched by a rule.
+type ConnType int
+
+const (
+	// ConnLocal matches connections over a unix socket.
+	ConnLocal ConnType = iota
+	// ConnHost matches TCP connections, with or without TLS.
+	ConnHost
+	// ConnHostSSL matches TCP connections using TLS.
+	ConnHostSSL
+)
+
+var connTypeNames = map[string]ConnType{
+	"local":   ConnLocal,
+	"host":    ConnHost,
+	"hostssl": ConnHostSSL,
+}
+
+func (t ConnType) String() string {
+	switch t {
+	case ConnLocal:
+		return "local"
+	case ConnHost:
+		return "host"
+	case ConnHostSSL:
+		return "hostssl"
+	default:
+		return fmt.Sprintf("ConnType(%d)", int(t))
+	}
+}
+
+// Method is an authentication method.
+type Method string
+
+// The supported authentication methods.
+const (
+	// MethodCert requires a valid client certificate for the user.
+	MethodCert Method = "cert"
+	// MethodPassword requires the password of the user.
+	MethodPassword Method = "password"
+	// MethodCertPassword requires a valid client certificate for the user if
+	// the client presents one, and the password of the user otherwise.
+	MethodCertPassword Method = "cert-password"
+	// MethodTrust accepts the connection unconditionally.
+	MethodTrust Method = "trust"
+	// MethodReject rejects the connection unconditionally.
+	MethodReject Method = "reject"
+)
+
+var methods = map[Method]struct{}{
+	MethodCert:         {},
+	MethodPassword:     {},
+	MethodCertPassword: {},
+	MethodTrust:        {},
+	MethodReject:       {},
+}
+
+const keywordAll = "all"
+
+// Entry is a rule of a configuration.
+type Entry struct {
+	ConnType ConnType
+	// Databases and Users are nil if the rule matches all the databases or
+	// users.
+	Databases []string
+	Users     []string
+	// Address is nil if the rule matches all the addresses, and for local
+	// rules.
+	Address *net.IPNet
+	Method  Method
+	// Line is the line of the rule in the configuration, starting at 1.
+	Line int
+}
+
+// String returns the rule in configuration syntax.
+func (e Entry) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s %s", e.ConnType, formatNames(e.Databases), formatNames(e.Users))
+	if e.ConnType != ConnLocal {
+		if e.Address == nil {
+			buf.WriteString(" " + keywordAll)
+		} else {
+			buf.WriteString(" " + e.Address.String())
+		}
+	}
+	buf.WriteString(" " + string(e.Method))
+	return buf.String()
+}
+
+func formatNames(names []string) string {
+	if names == nil {
+		return keywordAll
+	}
+	return strings.Join(names, ",")
+}
+
+// Conf is a parsed configuration.
+type Conf struct {
+	Entries []Entry
+}
+
+// Parse parses a configuration. An empty configuration has no rules.
+func Parse(input string) (*Conf, error) {
+	conf := &Conf{}
+	for i, line := range strings.Split(input, "\n") {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		entry, err := parseEntry(fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		entry.Line = i + 1
+		conf.Entries = append(conf.Entries, entry)
+	}
+	return conf, nil
+}
+
+func parseEntry(fields []string) (Entry, error) {
+	var entry Entry
+	connType, ok := connTypeNames[fields[0]]
+	if !ok {
+		return entry, errors.Errorf("unknown connection type %q", fields[0])
+	}
+	entry.ConnType = connType
+	expected := 5
+	if connType == ConnLocal {
+		expected = 4
+	}
+	if len(fields) != expected {
+		if connType == ConnLocal {
+			return entry, errors.New("expected 'local DATABASE USER METHOD'")
+		}
+		return entry, errors.Errorf("expected '%s DATABASE USER ADDRESS METHOD'", fields[0])
+	}
+	entry.Databases = parseNames(fields[1])
+	entry.Users = parseNames(fields[2])
+	if connType != ConnLocal && fields[3] != keywordAll {
+		_, ipNet, err := net.ParseCIDR(fields[3])
+		if err != nil {
+			return entry, errors.Errorf("invalid address %q: expected a CIDR range or %q",
+				fields[3], keywordAll)
+		}
+		entry.Address = ipNet
+	}
+	entry.Method = Method(fields[len(fields)-1])
+	if _, ok := methods[entry.Method]; !ok {
+		return entry, errors.Errorf("unknown authentication method %q", entry.Method)
+	}
+	return entry, nil
+}
+
+func parseNames(s string) []string {
+	if s == keywordAll {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func matchName(names []string, name string) bool {
+	if names == nil {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the first rule which matches a connection of the given type,
+// for the given database and user, from the given address. addr is ignored
+// for local connections. ok is false if no rule matches.
+func (c *Conf) Find(
+	connType ConnType, database, user string, addr net.IP,
+) (entry Entry, ok bool) {
+	for _, e := range c.Entries {
+		switch e.ConnType {
+		case ConnLocal:
+			if connType != ConnLocal {
+				continue
+			}
+		case ConnHost:
+			if connType == ConnLocal {
+				continue
+			}
+		case ConnHostSSL:
+			if connType != ConnHostSSL {
+				continue
+			}
+		}
+		if !matchName(e.Databases, database) || !matchName(e.Users, user) {
+			continue
+		}
+		if e.ConnType != ConnLocal && e.Address != nil && (addr == nil || !e.Address.Contains(addr)) {
+			continue
+		}
+		return e, true
+	}
+	return Entry{}, false
+}
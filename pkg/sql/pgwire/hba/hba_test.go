@@ -0,0 +1,130 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package hba
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		input    string
+		expected []string
+		err      string
+	}{
+		{"", nil, ""},
+		{"# comment\n\n   ", nil, ""},
+		{
+			`local all all trust
+host all root all cert # root only uses certs
+hostssl db1,db2 alice,bob 10.0.0.0/8 password
+host all all 192.168.1.7/32 cert-password
+host all all ::1/128 reject`,
+			[]string{
+				"local all all trust",
+				"host all root all cert",
+				"hostssl db1,db2 alice,bob 10.0.0.0/8 password",
+				"host all all 192.168.1.7/32 cert-password",
+				"host all all ::1/128 reject",
+			},
+			"",
+		},
+		{"hostnossl all all all trust", nil, `line 1: unknown connection type "hostnossl"`},
+		{"\nlocal all all 10.0.0.0/8 trust", nil, "line 2: expected 'local DATABASE USER METHOD'"},
+		{"host all all trust", nil, "line 1: expected 'host DATABASE USER ADDRESS METHOD'"},
+		{"host all all 10.0.0.1 trust", nil, `line 1: invalid address "10.0.0.1"`},
+		{"host all all all md5", nil, `line 1: unknown authentication method "md5"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			conf, err := Parse(tc.input)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(conf.Entries) != len(tc.expected) {
+				t.Fatalf("expected %d entries, got %+v", len(tc.expected), conf.Entries)
+			}
+			for i, e := range conf.Entries {
+				if s := e.String(); s != tc.expected[i] {
+					t.Errorf("%d: expected %q, got %q", i, tc.expected[i], s)
+				}
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conf, err := Parse(`
+local all all trust
+hostssl all root all cert
+host db1 alice 10.0.0.0/8 password
+hostssl all alice all cert-password
+host all all all reject
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		connType ConnType
+		database string
+		user     string
+		addr     string
+		line     int
+	}{
+		{ConnLocal, "db1", "alice", "", 2},
+		{ConnHostSSL, "db2", "root", "127.0.0.1", 3},
+		{ConnHost, "db2", "root", "127.0.0.1", 6},
+		{ConnHost, "db1", "alice", "10.1.2.3", 4},
+		{ConnHostSSL, "db1", "alice", "10.1.2.3", 4},
+		{ConnHostSSL, "db1", "alice", "192.168.0.1", 5},
+		{ConnHost, "db1", "alice", "192.168.0.1", 6},
+		{ConnHost, "db2", "alice", "10.1.2.3", 6},
+	}
+	for _, tc := range testCases {
+		entry, ok := conf.Find(tc.connType, tc.database, tc.user, net.ParseIP(tc.addr))
+		if !ok {
+			t.Errorf("%+v: expected a match", tc)
+			continue
+		}
+		if entry.Line != tc.line {
+			t.Errorf("%+v: expected rule on line %d, got %d: %s", tc, tc.line, entry.Line, entry)
+		}
+	}
+
+	conf, err = Parse("host all all 10.0.0.0/8 trust")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"11.0.0.1", ""} {
+		if entry, ok := conf.Find(ConnHost, "db1", "alice", net.ParseIP(addr)); ok {
+			t.Errorf("%q: unexpected match %s", addr, entry)
+		}
+	}
+	if entry, ok := conf.Find(ConnLocal, "db1", "alice", nil); ok {
+		t.Errorf("unexpected match %s", entry)
+	}
+}
@@ -17,21 +17,30 @@ package pgwire_test
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
 	gosql "database/sql"
 	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/pgproto3"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
@@ -40,13 +49,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // https://golang.org/cl/38533 and https://golang.org/cl/91115 changed the
@@ -1811,6 +1823,787 @@ func TestPGWireAuth(t *testing.T) {
 	})
 }
 
+func TestPGWireHostBasedAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	rootPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+	db, err := gosql.Open("postgres", rootPgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(fmt.Sprintf("CREATE USER %s WITH PASSWORD 'abc'", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+
+	setConf := func(conf string) {
+		t.Helper()
+		if _, err := db.Exec(
+			"SET CLUSTER SETTING server.host_based_authentication.configuration = $1", conf,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Exec(
+		"SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all md5'",
+	); !testutils.IsError(err, `unknown authentication method "md5"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testUserCertURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupFn()
+	host, port, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testUserPasswordURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(server.TestUser, "abc"),
+		Host:     net.JoinHostPort(host, port),
+		RawQuery: "sslmode=require",
+	}
+
+	testCases := []struct {
+		conf        string
+		certErr     string
+		passwordErr string
+	}{
+		{"", "", ""},
+		{"host all all all cert", "", "must authenticate with a client certificate"},
+		{"hostssl all all all password", "invalid password", ""},
+		{"host all all 10.0.0.0/8 trust\nhost all all all reject", "authentication rejected", "authentication rejected"},
+		{"host otherdb all all trust", "no host-based authentication rule", "no host-based authentication rule"},
+		{"local all all trust", "no host-based authentication rule", "no host-based authentication rule"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.conf, func(t *testing.T) {
+			setConf(tc.conf)
+			// Settings are propagated asynchronously.
+			testutils.SucceedsSoon(t, func() error {
+				if err := trivialQuery(testUserCertURL); !testutils.IsError(err, tc.certErr) {
+					return errors.Errorf("certificate: expected error %q, got %v", tc.certErr, err)
+				}
+				if err := trivialQuery(testUserPasswordURL); !testutils.IsError(err, tc.passwordErr) {
+					return errors.Errorf("password: expected error %q, got %v", tc.passwordErr, err)
+				}
+				return nil
+			})
+			// Root can always connect.
+			if err := trivialQuery(rootPgURL); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// Every authentication decision is audited, including the rejection of
+	// users which don't exist.
+	nonexistentURL := testUserPasswordURL
+	nonexistentURL.User = url.UserPassword("nonexistent", "abc")
+	if err := trivialQuery(nonexistentURL); !testutils.IsError(err, "user nonexistent does not exist") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log.Flush()
+	files, err := filepath.Glob(filepath.Join(sc.GetDirectory(), "*sql-audit*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auditLog []byte
+	for _, name := range files {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auditLog = append(auditLog, b...)
+	}
+	for _, expected := range []string{
+		`authentication user="root" .* OK`,
+		`authentication user="testuser" .* rule="host all all all reject" method=reject ERROR: authentication rejected`,
+		`authentication user="nonexistent" .* rule="\(none\)" method=\(none\) ERROR: user nonexistent does not exist`,
+	} {
+		if !regexp.MustCompile(expected).Match(auditLog) {
+			t.Errorf("expected the audit log to match %q:\n%s", expected, auditLog)
+		}
+	}
+}
+
+func TestPGWirePasswordMethods(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	// The password of testuser is hashed with bcrypt.
+	sqlDB.Exec(t, fmt.Sprintf("CREATE USER %s WITH PASSWORD 'abc'", server.TestUser))
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.password_methods = 'scram-sha-256,cleartext'")
+
+	hashedPassword := func() string {
+		var hashed []byte
+		sqlDB.QueryRow(t, `SELECT "hashedPassword" FROM system.users WHERE username = $1`,
+			server.TestUser).Scan(&hashed)
+		return string(hashed)
+	}
+	if h := hashedPassword(); strings.HasPrefix(h, security.SCRAMMechanism) {
+		t.Fatalf("unexpected SCRAM verifier %s", h)
+	}
+
+	host, port, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(server.TestUser, "abc"),
+		Host:     net.JoinHostPort(host, port),
+		RawQuery: "sslmode=require",
+	}
+
+	// The bcrypt hash is upgraded on the next cleartext login.
+	testutils.SucceedsSoon(t, func() error {
+		if err := trivialQuery(pgURL); err != nil {
+			return err
+		}
+		if h := hashedPassword(); !strings.HasPrefix(h, security.SCRAMMechanism+"$") {
+			return errors.Errorf("expected a SCRAM verifier, got %s", h)
+		}
+		return nil
+	})
+
+	// Cleartext passwords are still checked against the SCRAM verifier when
+	// the client doesn't support SCRAM.
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.password_methods = 'cleartext'")
+	testutils.SucceedsSoon(t, func() error {
+		return trivialQuery(pgURL)
+	})
+	pgURL.User = url.UserPassword(server.TestUser, "abd")
+	if err := trivialQuery(pgURL); !testutils.IsError(err, "invalid password") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// New passwords are stored as SCRAM verifiers.
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.password_methods = 'scram-sha-256'")
+	sqlDB.Exec(t, fmt.Sprintf("ALTER USER %s WITH PASSWORD 'def'", server.TestUser))
+	if h := hashedPassword(); !strings.HasPrefix(h, security.SCRAMMechanism+"$") {
+		t.Fatalf("expected a SCRAM verifier, got %s", h)
+	}
+
+	if _, err := db.Exec(
+		"SET CLUSTER SETTING server.user_login.password_methods = 'md5'",
+	); !testutils.IsError(err, `unknown password authentication method "md5"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// scramClient runs the client side of a SCRAM-SHA-256 exchange over a TLS
+// pgwire connection to addr, and returns the error sent by the server if the
+// authentication fails.
+func scramClient(addr, user, password string) error {
+	netConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer netConn.Close()
+
+	// SSLRequest.
+	var req [8]byte
+	binary.BigEndian.PutUint32(req[0:], 8)
+	binary.BigEndian.PutUint32(req[4:], 80877103)
+	if _, err := netConn.Write(req[:]); err != nil {
+		return err
+	}
+	var ok [1]byte
+	if _, err := io.ReadFull(netConn, ok[:]); err != nil {
+		return err
+	}
+	if ok[0] != 'S' {
+		return errors.Errorf("expected the server to accept TLS, got %q", ok[0])
+	}
+	conn := tls.Client(netConn, &tls.Config{InsecureSkipVerify: true})
+
+	send := func(typ byte, body []byte) error {
+		msg := []byte{typ, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(msg[1:], uint32(4+len(body)))
+		_, err := conn.Write(append(msg, body...))
+		return err
+	}
+	// receive reads the next message and returns its type and body. An
+	// ErrorResponse is returned as an error.
+	receive := func() (byte, []byte, error) {
+		var header [5]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return 0, nil, err
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return 0, nil, err
+		}
+		if header[0] == 'E' {
+			var e pgproto3.ErrorResponse
+			if err := e.Decode(body); err != nil {
+				return 0, nil, err
+			}
+			return 0, nil, errors.New(e.Message)
+		}
+		return header[0], body, nil
+	}
+	// receiveAuth reads an authentication request of the given type and
+	// returns its data.
+	receiveAuth := func(authType uint32) ([]byte, error) {
+		typ, body, err := receive()
+		if err != nil {
+			return nil, err
+		}
+		if typ != 'R' || len(body) < 4 || binary.BigEndian.Uint32(body) != authType {
+			return nil, errors.Errorf("expected authentication request %d, got %q %v", authType, typ, body)
+		}
+		return body[4:], nil
+	}
+
+	startup := &pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": user},
+	}
+	if _, err := conn.Write(startup.Encode(nil)); err != nil {
+		return err
+	}
+
+	// AuthenticationSASL lists the mechanisms supported by the server.
+	mechanisms, err := receiveAuth(10)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(mechanisms, []byte(security.SCRAMMechanism+"\x00\x00")) {
+		return errors.Errorf("unexpected SASL mechanisms %q", mechanisms)
+	}
+
+	// SASLInitialResponse.
+	const clientNonce = "rOprNGfwEbeRWgbNEkqO"
+	clientFirstBare := "n=,r=" + clientNonce
+	clientFirst := "n,," + clientFirstBare
+	initial := append([]byte(security.SCRAMMechanism), 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(initial[len(initial)-4:], uint32(len(clientFirst)))
+	if err := send('p', append(initial, clientFirst...)); err != nil {
+		return err
+	}
+
+	// AuthenticationSASLContinue contains the server-first-message.
+	serverFirst, err := receiveAuth(11)
+	if err != nil {
+		return err
+	}
+	attrs := strings.Split(string(serverFirst), ",")
+	if len(attrs) != 3 || !strings.HasPrefix(attrs[0], "r="+clientNonce) ||
+		!strings.HasPrefix(attrs[1], "s=") || !strings.HasPrefix(attrs[2], "i=") {
+		return errors.Errorf("malformed server-first-message %q", serverFirst)
+	}
+	nonce := attrs[0][2:]
+	salt, err := base64.StdEncoding.DecodeString(attrs[1][2:])
+	if err != nil {
+		return err
+	}
+	iterations, err := strconv.Atoi(attrs[2][2:])
+	if err != nil {
+		return err
+	}
+
+	// SASLResponse contains the client-final-message with the proof.
+	hmacSHA256 := func(key []byte, msg string) []byte {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte(msg))
+		return mac.Sum(nil)
+	}
+	u := hmacSHA256([]byte(password), string(salt)+"\x00\x00\x00\x01")
+	saltedPassword := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256([]byte(password), string(u))
+		for j := range saltedPassword {
+			saltedPassword[j] ^= u[j]
+		}
+	}
+	clientKey := hmacSHA256(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	clientFinalWithoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte("n,,")) + ",r=" + nonce
+	authMessage := clientFirstBare + "," + string(serverFirst) + "," + clientFinalWithoutProof
+	proof := hmacSHA256(storedKey[:], authMessage)
+	for j := range proof {
+		proof[j] ^= clientKey[j]
+	}
+	clientFinal := clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)
+	if err := send('p', []byte(clientFinal)); err != nil {
+		return err
+	}
+
+	// AuthenticationSASLFinal contains the signature of the server.
+	serverFinal, err := receiveAuth(12)
+	if err != nil {
+		return err
+	}
+	serverSignature := hmacSHA256(hmacSHA256(saltedPassword, "Server Key"), authMessage)
+	if e := "v=" + base64.StdEncoding.EncodeToString(serverSignature); string(serverFinal) != e {
+		return errors.Errorf("expected server-final-message %q, got %q", e, serverFinal)
+	}
+	if _, err := receiveAuth(0 /* AuthenticationOk */); err != nil {
+		return err
+	}
+	for {
+		typ, _, err := receive()
+		if err != nil {
+			return err
+		}
+		if typ == 'Z' {
+			return nil
+		}
+	}
+}
+
+// TestPGWireSCRAM runs SCRAM-SHA-256 exchanges with the server.
+func TestPGWireSCRAM(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.password_methods = 'scram-sha-256'")
+	sqlDB.Exec(t, fmt.Sprintf("CREATE USER %s WITH PASSWORD 'abc'", server.TestUser))
+
+	testutils.SucceedsSoon(t, func() error {
+		return scramClient(s.ServingAddr(), server.TestUser, "abc")
+	})
+	if err := scramClient(
+		s.ServingAddr(), server.TestUser, "abd",
+	); !testutils.IsError(err, "invalid password") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestPGWireLoginPolicy checks that new passwords must satisfy the password
+// policy, that users are locked out after too many failed password logins
+// and that expired passwords are rejected.
+func TestPGWireLoginPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.min_password_length = 3")
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.min_password_character_classes = 2")
+	testutils.SucceedsSoon(t, func() error {
+		_, err := db.Exec(fmt.Sprintf("ALTER USER IF EXISTS %s WITH PASSWORD 'a1'", server.TestUser))
+		if !testutils.IsError(err, "password must contain at least 3 characters") {
+			return errors.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if _, err := db.Exec(
+		fmt.Sprintf("CREATE USER %s WITH PASSWORD 'abc'", server.TestUser),
+	); !testutils.IsError(err, "password must contain characters of at least 2 of the following classes") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqlDB.Exec(t, "RESET CLUSTER SETTING server.user_login.min_password_length")
+	sqlDB.Exec(t, "RESET CLUSTER SETTING server.user_login.min_password_character_classes")
+	testutils.SucceedsSoon(t, func() error {
+		_, err := db.Exec(fmt.Sprintf("CREATE USER %s WITH PASSWORD 'abc'", server.TestUser))
+		return err
+	})
+	sqlDB.Exec(t, "SET CLUSTER SETTING server.user_login.lockout.max_failed_attempts = 2")
+	testutils.SucceedsSoon(t, func() error {
+		var v string
+		sqlDB.QueryRow(t, "SHOW CLUSTER SETTING server.user_login.lockout.max_failed_attempts").Scan(&v)
+		if v != "2" {
+			return errors.Errorf("setting not yet propagated: %s", v)
+		}
+		return nil
+	})
+
+	host, port, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(server.TestUser, "abd"),
+		Host:     net.JoinHostPort(host, port),
+		RawQuery: "sslmode=require",
+	}
+	for i := 0; i < 2; i++ {
+		if err := trivialQuery(pgURL); !testutils.IsError(err, "invalid password") {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+	}
+
+	// The right password is rejected once the user is locked out.
+	pgURL.User = url.UserPassword(server.TestUser, "abc")
+	if err := trivialQuery(pgURL); !testutils.IsError(
+		err, "user testuser is locked out after 2 failed login attempts",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Every failure is recorded in the event log.
+	var failures, lockouts int
+	sqlDB.QueryRow(t, `SELECT count(*), count(*) FILTER (WHERE info::JSONB->>'LockedOut' = 'true') `+
+		`FROM system.eventlog WHERE "eventType" = $1`, string(sql.EventLogUserLoginFailure),
+	).Scan(&failures, &lockouts)
+	if failures != 3 || lockouts != 1 {
+		t.Fatalf("expected 3 failures and 1 lockout in the event log, got %d and %d", failures, lockouts)
+	}
+
+	sqlDB.Exec(t, fmt.Sprintf("ALTER USER %s UNLOCK", server.TestUser))
+	if err := trivialQuery(pgURL); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired passwords are rejected.
+	sqlDB.Exec(t, fmt.Sprintf("ALTER USER %s VALID UNTIL '2000-01-01'", server.TestUser))
+	if err := trivialQuery(pgURL); !testutils.IsError(err, "the password of user testuser has expired") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqlDB.Exec(t, fmt.Sprintf("ALTER USER %s VALID UNTIL 'infinity'", server.TestUser))
+	if err := trivialQuery(pgURL); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPGWireCancelRequest checks that a CancelRequest cancels the active
+// queries of the session with the given key, even when it is received by
+// another node than the one of the session.
+func TestPGWireCancelRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := serverutils.StartTestCluster(t, 2, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{Insecure: true},
+	})
+	defer tc.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+
+	// Open a session on the second node and read its key.
+	conn, err := net.Dial("tcp", tc.Server(1).ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fe, err := pgproto3.NewFrontend(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startup := &pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": security.RootUser},
+	}
+	if _, err := conn.Write(startup.Encode(nil)); err != nil {
+		t.Fatal(err)
+	}
+	var key pgproto3.BackendKeyData
+	for {
+		msg, err := fe.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m, ok := msg.(*pgproto3.BackendKeyData); ok {
+			key = *m
+		}
+		if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+			break
+		}
+	}
+	cancelKey := pgwirecancel.FromParts(key.ProcessID, key.SecretKey)
+	if nodeID, ok := cancelKey.NodeID(); !ok || nodeID != tc.Server(1).NodeID() {
+		t.Fatalf("expected node ID %d in key %s", tc.Server(1).NodeID(), cancelKey)
+	}
+
+	// sendCancel sends a CancelRequest to the first node, and waits until the
+	// node closes the connection after handling it.
+	sendCancel := func(processID, secretKey uint32) {
+		t.Helper()
+		c, err := net.Dial("tcp", tc.Server(0).ServingAddr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		var req [16]byte
+		binary.BigEndian.PutUint32(req[0:], 16)
+		binary.BigEndian.PutUint32(req[4:], 80877102)
+		binary.BigEndian.PutUint32(req[8:], processID)
+		binary.BigEndian.PutUint32(req[12:], secretKey)
+		if _, err := c.Write(req[:]); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ioutil.ReadAll(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const query = "SELECT pg_sleep(1000)"
+	if err := fe.Send(&pgproto3.Query{String: query}); err != nil {
+		t.Fatal(err)
+	}
+	countQueries := func() int {
+		var n int
+		sqlDB.QueryRow(t,
+			`SELECT count(*) FROM [SHOW CLUSTER QUERIES] WHERE query = $1`, query,
+		).Scan(&n)
+		return n
+	}
+	testutils.SucceedsSoon(t, func() error {
+		if n := countQueries(); n != 1 {
+			return errors.Errorf("expected the query to be running, found %d", n)
+		}
+		return nil
+	})
+
+	// A wrong key doesn't cancel the query.
+	sendCancel(key.ProcessID, key.SecretKey^1)
+	if n := countQueries(); n != 1 {
+		t.Fatalf("expected the query to be running after a wrong CancelRequest, found %d", n)
+	}
+
+	sendCancel(key.ProcessID, key.SecretKey)
+	msg, err := fe.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errMsg, ok := msg.(*pgproto3.ErrorResponse)
+	if !ok {
+		t.Fatalf("expected an error, got %#v", msg)
+	}
+	if !strings.Contains(errMsg.Message, "query execution canceled") {
+		t.Fatalf("unexpected error %q", errMsg.Message)
+	}
+}
+
+func TestPGWireNotifications(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := serverutils.StartTestCluster(t, 2, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{Insecure: true},
+	})
+	defer tc.Stopper().Stop(context.TODO())
+
+	// Listen on the second node.
+	conn, err := net.Dial("tcp", tc.Server(1).ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetReadDeadline(timeutil.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	fe, err := pgproto3.NewFrontend(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startup := &pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": security.RootUser},
+	}
+	if _, err := conn.Write(startup.Encode(nil)); err != nil {
+		t.Fatal(err)
+	}
+	waitForReady := func() {
+		t.Helper()
+		for {
+			msg, err := fe.Receive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m, ok := msg.(*pgproto3.ErrorResponse); ok {
+				t.Fatal(m.Message)
+			}
+			if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+				return
+			}
+		}
+	}
+	waitForReady()
+	if err := fe.Send(&pgproto3.Query{String: "LISTEN foo"}); err != nil {
+		t.Fatal(err)
+	}
+	waitForReady()
+
+	// waitForNotification returns the next notification received by the
+	// listening session, which is idle.
+	waitForNotification := func() *pgproto3.NotificationResponse {
+		t.Helper()
+		for {
+			msg, err := fe.Receive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m, ok := msg.(*pgproto3.NotificationResponse); ok {
+				return m
+			}
+		}
+	}
+
+	// Notifications sent by an aborted transaction or on other channels are
+	// not delivered, so the first notification is the committed one.
+	db0 := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	db0.Exec(t, "BEGIN; NOTIFY foo, 'aborted'; ROLLBACK")
+	db0.Exec(t, "NOTIFY bar, 'other channel'")
+	db0.Exec(t, "BEGIN; NOTIFY foo, 'remote'; SELECT pg_notify('foo', 'remote'); COMMIT")
+	if n := waitForNotification(); n.Channel != "foo" || n.Payload != "remote" || n.PID == 0 {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+
+	db1 := sqlutils.MakeSQLRunner(tc.ServerConn(1))
+	db1.Exec(t, "SELECT pg_notify('foo', 'local')")
+	if n := waitForNotification(); n.Channel != "foo" || n.Payload != "local" {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+
+	// The notifications of a transaction are dropped if they don't fit in the
+	// budget of the node for the notifications it published in the last
+	// minute.
+	db0.Exec(t, "SET CLUSTER SETTING sql.notifications.max_bytes_per_node = '200B'")
+	testutils.SucceedsSoon(t, func() error {
+		var max string
+		db0.QueryRow(t, "SHOW CLUSTER SETTING sql.notifications.max_bytes_per_node").Scan(&max)
+		if max != "200 B" {
+			return errors.Errorf("setting not updated yet: %s", max)
+		}
+		return nil
+	})
+	db0.Exec(t, "SELECT pg_notify('foo', repeat('a', 200))")
+	db0.Exec(t, "SELECT pg_notify('foo', 'within budget')")
+	if n := waitForNotification(); n.Channel != "foo" || n.Payload != "within budget" {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+}
+
+func TestPGWireCopyTo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, NULL), (2, e'tab\there'), (3, 'say "hi", bye'), (4, '')`)
+
+	conn, err := net.Dial("tcp", s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fe, err := pgproto3.NewFrontend(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startup := &pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": security.RootUser, "database": "defaultdb"},
+	}
+	if _, err := conn.Write(startup.Encode(nil)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		msg, err := fe.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+			break
+		}
+	}
+
+	// copyTo runs a COPY TO statement and returns the data copied and the
+	// command tag, or the error message.
+	copyTo := func(query string, expectedCols int) (string, string, error) {
+		t.Helper()
+		if err := fe.Send(&pgproto3.Query{String: query}); err != nil {
+			t.Fatal(err)
+		}
+		var data bytes.Buffer
+		var tag string
+		var resErr error
+		var done bool
+		for {
+			msg, err := fe.Receive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch m := msg.(type) {
+			case *pgproto3.CopyOutResponse:
+				if len(m.ColumnFormatCodes) != expectedCols {
+					t.Fatalf("%s: expected %d columns, got %d", query, expectedCols, len(m.ColumnFormatCodes))
+				}
+			case *pgproto3.CopyData:
+				data.Write(m.Data)
+			case *pgproto3.CopyDone:
+				done = true
+			case *pgproto3.CommandComplete:
+				if !done {
+					t.Fatalf("%s: CommandComplete received before CopyDone", query)
+				}
+				tag = string(m.CommandTag)
+			case *pgproto3.ErrorResponse:
+				resErr = errors.New(m.Message)
+			case *pgproto3.ReadyForQuery:
+				return data.String(), tag, resErr
+			default:
+				t.Fatalf("%s: unexpected message %#v", query, msg)
+			}
+		}
+	}
+
+	testCases := []struct {
+		query    string
+		cols     int
+		rows     int
+		expected string
+	}{
+		{
+			`COPY t TO STDOUT`,
+			2, 4,
+			"1\t\\N\n2\ttab\\there\n3\tsay \"hi\", bye\n4\t\n",
+		},
+		{
+			`COPY t (b, a) TO STDOUT WITH CSV HEADER`,
+			2, 4,
+			"b,a\n,1\ntab\there,2\n\"say \"\"hi\"\", bye\",3\n\"\",4\n",
+		},
+		{
+			`COPY (SELECT b, a FROM t WHERE a != 2 ORDER BY a) TO STDOUT ` +
+				`WITH (FORMAT csv, DELIMITER '|', NULL 'NULL')`,
+			2, 3,
+			"NULL|1\n\"say \"\"hi\"\", bye\"|3\n|4\n",
+		},
+		{
+			`COPY (SELECT a FROM t) TO STDOUT WITH DELIMITER ','`,
+			1, 4,
+			"1\n2\n3\n4\n",
+		},
+	}
+	for _, tc := range testCases {
+		data, tag, err := copyTo(tc.query, tc.cols)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		if expectedTag := fmt.Sprintf("COPY %d", tc.rows); tag != expectedTag {
+			t.Errorf("%s: expected tag %q, got %q", tc.query, expectedTag, tag)
+		}
+		if data != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.query, tc.expected, data)
+		}
+	}
+
+	if _, _, err := copyTo(`COPY t TO STDOUT WITH (HEADER)`, 0); !testutils.IsError(
+		err, "COPY HEADER available only in CSV mode",
+	) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 func TestPGWireResultChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
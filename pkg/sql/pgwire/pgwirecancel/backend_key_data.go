@@ -0,0 +1,95 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package pgwirecancel implements the keys that PostgreSQL clients use to
+// cancel the queries of their sessions.
+package pgwirecancel
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// BackendKeyData is the key sent to a client in the BackendKeyData message
+// at the start of a session. Its high 32 bits are sent as the process ID and
+// its low 32 bits as the secret key. The client sends them back in a
+// CancelRequest, on a new connection, to cancel the queries of the session.
+//
+// If the leading bit of the key is set, the next 11 bits are the ID of the
+// node of the session and the remaining 52 bits are random, so that a
+// CancelRequest received by any node can be forwarded to the node of the
+// session. Otherwise, which happens when the node ID doesn't fit in 11 bits,
+// the remaining 63 bits are random and the CancelRequest has to be received
+// by the node of the session. The random bits make the key hard to guess.
+type BackendKeyData uint64
+
+const (
+	leadingBit = 1 << 63
+	nodeIDBits = 11
+	// randomBits is the number of random bits of a key with a node ID.
+	randomBits = 63 - nodeIDBits
+	maxNodeID  = 1<<nodeIDBits - 1
+)
+
+// MakeBackendKeyData returns a new random key for a session on the given
+// node.
+func MakeBackendKeyData(nodeID roachpb.NodeID) (BackendKeyData, error) {
+	var buf [8]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, err
+		}
+		k := binary.BigEndian.Uint64(buf[:])
+		if nodeID > 0 && nodeID <= maxNodeID {
+			k = leadingBit | uint64(nodeID)<<randomBits | k&(1<<randomBits-1)
+		} else {
+			k &^= leadingBit
+		}
+		// A zero key is used to indicate the lack of a key.
+		if k != 0 {
+			return BackendKeyData(k), nil
+		}
+	}
+}
+
+// FromParts returns the key made of the process ID and secret key of a
+// CancelRequest.
+func FromParts(processID, secretKey uint32) BackendKeyData {
+	return BackendKeyData(uint64(processID)<<32 | uint64(secretKey))
+}
+
+// ProcessID returns the process ID sent in the BackendKeyData message.
+func (k BackendKeyData) ProcessID() uint32 {
+	return uint32(k >> 32)
+}
+
+// SecretKey returns the secret key sent in the BackendKeyData message.
+func (k BackendKeyData) SecretKey() uint32 {
+	return uint32(k)
+}
+
+// NodeID returns the ID of the node of the session, if the key contains it.
+func (k BackendKeyData) NodeID() (roachpb.NodeID, bool) {
+	if k&leadingBit == 0 {
+		return 0, false
+	}
+	return roachpb.NodeID((k &^ leadingBit) >> randomBits), true
+}
+
+func (k BackendKeyData) String() string {
+	return fmt.Sprintf("%016x", uint64(k))
+}
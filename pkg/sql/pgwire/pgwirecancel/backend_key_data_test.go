@@ -0,0 +1,68 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwirecancel
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestBackendKeyData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		nodeID  roachpb.NodeID
+		encoded bool
+	}{
+		{1, true},
+		{7, true},
+		{maxNodeID, true},
+		{maxNodeID + 1, false},
+		{0, false},
+	} {
+		k, err := MakeBackendKeyData(tc.nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k == 0 {
+			t.Fatalf("%d: unexpected zero key", tc.nodeID)
+		}
+		if rt := FromParts(k.ProcessID(), k.SecretKey()); rt != k {
+			t.Fatalf("%d: expected %s after round trip, got %s", tc.nodeID, k, rt)
+		}
+		nodeID, ok := k.NodeID()
+		if ok != tc.encoded {
+			t.Fatalf("%d: expected node ID encoded %t in %s", tc.nodeID, tc.encoded, k)
+		}
+		if ok && nodeID != tc.nodeID {
+			t.Fatalf("expected node ID %d in %s, got %d", tc.nodeID, k, nodeID)
+		}
+	}
+
+	// The random bits of two keys should differ.
+	k1, err := MakeBackendKeyData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := MakeBackendKeyData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1 == k2 {
+		t.Fatalf("expected different keys, got %s twice", k1)
+	}
+}
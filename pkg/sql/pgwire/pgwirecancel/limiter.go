@@ -0,0 +1,66 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwirecancel
+
+import (
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+const (
+	// requestRate is the maximum number of CancelRequests per second handled
+	// for a client address. It bounds the rate at which a client can guess the
+	// random bits of the keys, without letting it starve other clients.
+	requestRate = 16
+	// maxClients is the number of client addresses whose rate is tracked.
+	maxClients = 1024
+)
+
+// Limiter limits the rate of CancelRequests per client address. It is safe
+// for concurrent use.
+type Limiter struct {
+	mu struct {
+		syncutil.Mutex
+		// limiters maps client addresses to their *rate.Limiter.
+		limiters *cache.UnorderedCache
+	}
+}
+
+// NewLimiter returns a new Limiter.
+func NewLimiter() *Limiter {
+	l := &Limiter{}
+	l.mu.limiters = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return size > maxClients
+		},
+	})
+	return l
+}
+
+// Allow returns whether a CancelRequest from the client with the given
+// address may be handled now.
+func (l *Limiter) Allow(clientAddr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	v, ok := l.mu.limiters.Get(clientAddr)
+	if !ok {
+		v = rate.NewLimiter(requestRate, requestRate)
+		l.mu.limiters.Add(clientAddr, v)
+	}
+	return v.(*rate.Limiter).Allow()
+}
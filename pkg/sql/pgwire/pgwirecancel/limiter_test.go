@@ -0,0 +1,39 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwirecancel
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l := NewLimiter()
+	for i := 0; i < requestRate; i++ {
+		if !l.Allow("10.0.0.1") {
+			t.Fatalf("request %d: expected to be allowed", i)
+		}
+	}
+	if l.Allow("10.0.0.1") {
+		t.Fatal("expected the requests of 10.0.0.1 to be limited")
+	}
+	// Other clients are not affected.
+	if !l.Allow("10.0.0.2") {
+		t.Fatal("expected the requests of 10.0.0.2 to be allowed")
+	}
+}
@@ -20,18 +20,22 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -72,8 +76,9 @@ var (
 )
 
 const (
-	version30  = 196608
-	versionSSL = 80877103
+	version30     = 196608
+	versionCancel = 80877102
+	versionSSL    = 80877103
 )
 
 // cancelMaxWait is the amount of time a draining server gives to sessions to
@@ -116,6 +121,13 @@ type Server struct {
 		draining      bool
 	}
 
+	auth struct {
+		syncutil.RWMutex
+		// conf is the parsed connection authentication configuration. It is
+		// parsed whenever the setting changes rather than for every connection.
+		conf authConf
+	}
+
 	sqlMemoryPool mon.BytesMonitor
 	connMonitor   mon.BytesMonitor
 
@@ -190,6 +202,14 @@ func MakeServer(
 	server.mu.connCancelMap = make(cancelChanMap)
 	server.mu.Unlock()
 
+	server.auth.conf = parseAuthConf(&st.SV)
+	connAuthConf.SetOnChange(&st.SV, func() {
+		conf := parseAuthConf(&st.SV)
+		server.auth.Lock()
+		server.auth.conf = conf
+		server.auth.Unlock()
+	})
+
 	return server
 }
 
@@ -204,7 +224,7 @@ func Match(rd io.Reader) bool {
 	if err != nil {
 		return false
 	}
-	return version == version30 || version == versionSSL
+	return version == version30 || version == versionSSL || version == versionCancel
 }
 
 // Start makes the Server ready for serving connections.
@@ -418,6 +438,14 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		errSSLRequired = true
 	}
 
+	// A CancelRequest is sent on a new connection, which isn't used for
+	// anything else. The key it contains is its only authorization, as in
+	// PostgreSQL, so it is accepted without TLS.
+	if version == versionCancel {
+		s.handleCancel(ctx, conn, &buf)
+		return nil
+	}
+
 	sendErr := func(err error) error {
 		msgBuilder := newWriteBuffer(s.metrics.BytesOutCount)
 		_ /* err */ = writeErr(err, msgBuilder, conn)
@@ -440,6 +468,9 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		return sendErr(pgerror.NewError(pgerror.CodeProtocolViolationError, err.Error()))
 	}
 	sArgs.User = tree.Name(sArgs.User).Normalize()
+	if sArgs.CancelKey, err = pgwirecancel.MakeBackendKeyData(s.execCfg.NodeID.Get()); err != nil {
+		return sendErr(err)
+	}
 
 	// Reserve some memory for this connection using the server's monitor. This
 	// reduces pressure on the shared pool because the server monitor allocates in
@@ -451,7 +482,56 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 			baseSQLMemoryBudget, err)
 	}
 	return serveConn(ctx, conn, sArgs, &s.metrics, reserved, s.SQLServer,
-		s.IsDraining, s.execCfg, s.stopper, s.cfg.Insecure)
+		s.IsDraining, s.execCfg, s.stopper, s.cfg.Insecure, s.getAuthConf())
+}
+
+// getAuthConf returns the current connection authentication configuration.
+func (s *Server) getAuthConf() authConf {
+	s.auth.RLock()
+	defer s.auth.RUnlock()
+	return s.auth.conf
+}
+
+// handleCancel handles a CancelRequest by canceling the active queries of the
+// session with the key contained in the request. The request is forwarded to
+// the node of the session if the key contains its ID, which limits the rate
+// of the requests of each client. As in PostgreSQL, nothing is sent to the
+// client, which can't tell whether the request succeeded.
+func (s *Server) handleCancel(
+	ctx context.Context, conn net.Conn, buf *pgwirebase.ReadBuffer,
+) {
+	defer func() { _ = conn.Close() }()
+
+	processID, err := buf.GetUint32()
+	if err != nil {
+		log.Warningf(ctx, "malformed CancelRequest from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	secretKey, err := buf.GetUint32()
+	if err != nil {
+		log.Warningf(ctx, "malformed CancelRequest from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	key := pgwirecancel.FromParts(processID, secretKey)
+
+	nodeID := "local"
+	if id, ok := key.NodeID(); ok {
+		nodeID = strconv.Itoa(int(id))
+	}
+	clientAddr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(clientAddr); err == nil {
+		clientAddr = host
+	}
+	if _, err := s.execCfg.StatusServer.CancelQueryByKey(
+		grpcutil.NewLocalRequestContext(ctx),
+		&serverpb.CancelQueryByKeyRequest{
+			NodeId:     nodeID,
+			CancelKey:  uint64(key),
+			ClientAddr: clientAddr,
+		},
+	); err != nil && log.V(1) {
+		log.Infof(ctx, "CancelRequest from %s failed: %v", conn.RemoteAddr(), err)
+	}
 }
 
 func parseOptions(ctx context.Context, data []byte) (sql.SessionArgs, error) {
@@ -163,6 +163,7 @@ var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createPolicyNode{}
 var _ planNode = &createSequenceNode{}
 var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
@@ -173,6 +174,7 @@ var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropPolicyNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &DropUserNode{}
@@ -210,6 +212,7 @@ var _ planNode = &zeroNode{}
 var _ planNodeFastPath = &CreateUserNode{}
 var _ planNodeFastPath = &DropUserNode{}
 var _ planNodeFastPath = &alterUserSetPasswordNode{}
+var _ planNodeFastPath = &alterUserUnlockNode{}
 var _ planNodeFastPath = &createTableNode{}
 var _ planNodeFastPath = &deleteNode{}
 var _ planNodeFastPath = &rowCountNode{}
@@ -693,18 +696,24 @@ func (p *planner) newPlan(
 		return p.AlterSequence(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
+	case *tree.AlterUserUnlock:
+		return p.AlterUserUnlock(ctx, n)
 	case *tree.CancelQueries:
 		return p.CancelQueries(ctx, n)
 	case *tree.CancelSessions:
 		return p.CancelSessions(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.CopyTo:
+		return p.CopyTo(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case *tree.CreateDatabase:
 		return p.CreateDatabase(ctx, n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreatePolicy:
+		return p.CreatePolicy(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
 	case *tree.CreateUser:
@@ -725,6 +734,8 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *tree.DropPolicy:
+		return p.DropPolicy(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
 	case *tree.DropView:
@@ -741,6 +752,10 @@ func (p *planner) newPlan(
 		return p.Grant(ctx, n)
 	case *tree.Insert:
 		return p.Insert(ctx, n, desiredTypes)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
+	case *tree.Notify:
+		return p.Notify(ctx, n)
 	case *tree.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
 	case *tree.Relocate:
@@ -753,6 +768,8 @@ func (p *planner) newPlan(
 		return p.RenameIndex(ctx, n)
 	case *tree.RenameTable:
 		return p.RenameTable(ctx, n)
+	case *tree.Revert:
+		return p.Revert(ctx, n)
 	case *tree.Revoke:
 		return p.Revoke(ctx, n)
 	case *tree.Scatter:
@@ -829,6 +846,8 @@ func (p *planner) newPlan(
 		return p.Truncate(ctx, n)
 	case *tree.UnionClause:
 		return p.Union(ctx, n, desiredTypes)
+	case *tree.Unlisten:
+		return p.Unlisten(ctx, n)
 	case *tree.Update:
 		return p.Update(ctx, n, desiredTypes)
 	case *tree.ValuesClause:
@@ -868,6 +887,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 	switch n := stmt.(type) {
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
+	case *tree.AlterUserUnlock:
+		return p.AlterUserUnlock(ctx, n)
 	case *tree.CancelQueries:
 		return p.CancelQueries(ctx, n)
 	case *tree.CancelSessions:
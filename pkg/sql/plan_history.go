@@ -0,0 +1,249 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxPlansPerStmt is the number of distinct plans kept in the plan history
+// of each statement fingerprint.
+var maxPlansPerStmt = settings.RegisterValidatedIntSetting(
+	"sql.metrics.statement_details.max_plans",
+	"number of distinct plans kept per statement fingerprint (0 to disable plan history)",
+	5,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.metrics.statement_details.max_plans to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// planSampleRate is the fraction of the executions of a statement whose plan
+// is recorded in the plan history. Fingerprinting a plan isn't free, so it
+// isn't done for every execution.
+var planSampleRate = settings.RegisterValidatedFloatSetting(
+	"sql.metrics.statement_details.plan_sample_rate",
+	"fraction of statement executions whose plan is recorded in the plan history",
+	0.1,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("cannot set sql.metrics.statement_details.plan_sample_rate "+
+				"to a value outside [0, 1]: %f", v)
+		}
+		return nil
+	},
+)
+
+// planRegressionThreshold is the ratio of the mean latency of a new plan over
+// the mean latency of the plan it replaced beyond which the new plan is
+// reported as a regression.
+var planRegressionThreshold = settings.RegisterValidatedFloatSetting(
+	"sql.metrics.statement_details.plan_regression_threshold",
+	"ratio of the mean service latency of a new plan for a statement over that of the previous plan "+
+		"beyond which the new plan is reported as a regression",
+	2,
+	func(v float64) error {
+		if v <= 1 {
+			return errors.Errorf("cannot set sql.metrics.statement_details.plan_regression_threshold "+
+				"to a value less than or equal to 1: %f", v)
+		}
+		return nil
+	},
+)
+
+// planRegressionMinCount is the number of executions of both a new plan and
+// the plan it replaced required before their latencies are compared.
+const planRegressionMinCount = 10
+
+// planStats holds the statistics of the sampled executions of a statement
+// which used a given plan.
+type planStats struct {
+	id   string
+	plan string
+	// prevID is the ID of the plan the statement used before this plan, if
+	// any.
+	prevID string
+
+	firstSeen, lastSeen time.Time
+	count               int64
+	serviceLat          roachpb.NumericStat
+
+	// checked is set once the latency of the plan has been compared to the
+	// latency of the previous plan, and regressed if the plan was found to be
+	// a regression.
+	checked   bool
+	regressed bool
+}
+
+// stmtPlans holds the plan history of a statement fingerprint.
+type stmtPlans struct {
+	syncutil.Mutex
+
+	// plans is ordered by time of first use.
+	plans    []*planStats
+	lastUsed *planStats
+}
+
+// planRegression describes a plan whose mean latency is much worse than the
+// mean latency of the plan the statement used before.
+type planRegression struct {
+	stmt                 string
+	prevPlanID, planID   string
+	prevMeanLat, meanLat float64
+}
+
+func (r *planRegression) String() string {
+	return fmt.Sprintf("plan regression for statement %q: plan %s has a mean service latency of %s, "+
+		"previous plan %s had %s", r.stmt,
+		r.planID, time.Duration(r.meanLat*float64(time.Second)),
+		r.prevPlanID, time.Duration(r.prevMeanLat*float64(time.Second)))
+}
+
+// recordPlan records an execution of a statement which used the given plan.
+// It returns a non-nil planRegression if the plan has just been found to be a
+// regression over the previous plan of the statement.
+func (s *stmtPlans) recordPlan(
+	stmt, plan string, svcLat float64, now time.Time, maxPlans int, threshold float64,
+) *planRegression {
+	s.Lock()
+	defer s.Unlock()
+
+	var p *planStats
+	for _, candidate := range s.plans {
+		if candidate.plan == plan {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		p = &planStats{id: planID(plan), plan: plan, firstSeen: now}
+		if s.lastUsed != nil {
+			p.prevID = s.lastUsed.id
+		}
+		s.plans = append(s.plans, p)
+		s.evictLocked(maxPlans)
+	}
+	p.count++
+	p.serviceLat.Record(p.count, svcLat)
+	p.lastSeen = now
+	s.lastUsed = p
+
+	if p.checked || p.prevID == "" || p.count < planRegressionMinCount {
+		return nil
+	}
+	p.checked = true
+	for _, prev := range s.plans {
+		if prev.id != p.prevID {
+			continue
+		}
+		if prev.count >= planRegressionMinCount && p.serviceLat.Mean > threshold*prev.serviceLat.Mean {
+			p.regressed = true
+			return &planRegression{
+				stmt:        stmt,
+				prevPlanID:  prev.id,
+				planID:      p.id,
+				prevMeanLat: prev.serviceLat.Mean,
+				meanLat:     p.serviceLat.Mean,
+			}
+		}
+		break
+	}
+	return nil
+}
+
+// evictLocked removes the least recently used plans until at most maxPlans
+// remain.
+func (s *stmtPlans) evictLocked(maxPlans int) {
+	for len(s.plans) > maxPlans && len(s.plans) > 1 {
+		lru := 0
+		for i, p := range s.plans {
+			if p.lastSeen.Before(s.plans[lru].lastSeen) {
+				lru = i
+			}
+		}
+		if s.plans[lru] == s.lastUsed {
+			s.lastUsed = nil
+		}
+		s.plans = append(s.plans[:lru], s.plans[lru+1:]...)
+	}
+}
+
+// get returns a copy of the plan history.
+func (s *stmtPlans) get() []planStats {
+	s.Lock()
+	defer s.Unlock()
+	plans := make([]planStats, len(s.plans))
+	for i, p := range s.plans {
+		plans[i] = *p
+	}
+	return plans
+}
+
+// planID returns a short identifier of a plan.
+func planID(plan string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(plan))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// planFingerprintIgnoredFields are the EXPLAIN fields which depend on the
+// values of the constants and placeholders of a statement and are thus
+// omitted from plan fingerprints.
+var planFingerprintIgnoredFields = map[string]struct{}{
+	"spans": {},
+	"limit": {},
+	"size":  {},
+}
+
+// planFingerprint returns a representation of the shape of a plan, that is
+// its EXPLAIN tree without the fields which depend on the values of the
+// constants of the statement. The SQL of the subqueries is formatted without
+// its constants, like statement fingerprints.
+func planFingerprint(ctx context.Context, plan planNode, subqueryPlans []subquery) string {
+	var e explainer
+	e.populateEntries(ctx, plan, subqueryPlans)
+	var buf bytes.Buffer
+	subqueryIdx := 0
+	for _, entry := range e.entries {
+		if entry.plan != nil {
+			fmt.Fprintf(&buf, "%s%s\n", strings.Repeat("  ", entry.level), entry.node)
+			continue
+		}
+		if _, ok := planFingerprintIgnoredFields[entry.field]; ok {
+			continue
+		}
+		val := entry.fieldVal
+		if entry.field == "sql" && subqueryIdx < len(subqueryPlans) {
+			val = tree.AsStringWithFlags(subqueryPlans[subqueryIdx].subquery, tree.FmtHideConstants)
+			subqueryIdx++
+		}
+		fmt.Fprintf(&buf, "%s  %s: %s\n", strings.Repeat("  ", entry.level), entry.field, val)
+	}
+	return buf.String()
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestStmtPlansRegression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const maxPlans = 2
+	const threshold = 2
+	var s stmtPlans
+	now := time.Unix(0, 0)
+	record := func(plan string, svcLat float64) *planRegression {
+		now = now.Add(time.Second)
+		return s.recordPlan("SELECT _", plan, svcLat, now, maxPlans, threshold)
+	}
+
+	// The first plan of a statement is never a regression.
+	for i := 0; i < planRegressionMinCount; i++ {
+		if r := record("a", 1); r != nil {
+			t.Fatalf("unexpected regression %s", r)
+		}
+	}
+
+	// A plan which is slightly slower than the previous plan is not a
+	// regression.
+	for i := 0; i < planRegressionMinCount; i++ {
+		if r := record("b", 1.5); r != nil {
+			t.Fatalf("unexpected regression %s", r)
+		}
+	}
+
+	// A plan which is much slower than the previous plan is reported once,
+	// after enough executions.
+	for i := 0; i < planRegressionMinCount-1; i++ {
+		if r := record("c", 4); r != nil {
+			t.Fatalf("%d: unexpected regression %s", i, r)
+		}
+	}
+	r := record("c", 4)
+	if r == nil {
+		t.Fatal("expected a regression")
+	}
+	if e := planID("b"); r.prevPlanID != e {
+		t.Errorf("expected previous plan %s, got %s", e, r.prevPlanID)
+	}
+	if r := record("c", 4); r != nil {
+		t.Fatalf("unexpected regression %s", r)
+	}
+
+	// Only the most recently used plans are kept.
+	plans := s.get()
+	if len(plans) != maxPlans {
+		t.Fatalf("expected %d plans, got %d", maxPlans, len(plans))
+	}
+	for i, e := range []string{"b", "c"} {
+		if plans[i].plan != e {
+			t.Errorf("%d: expected plan %q, got %q", i, e, plans[i].plan)
+		}
+	}
+	if !plans[1].regressed || plans[0].regressed {
+		t.Errorf("expected only plan c to be marked as regressed: %+v", plans)
+	}
+}
@@ -68,6 +68,14 @@ type extendedEvalContext struct {
 
 	SchemaChangers *schemaChangerCollection
 
+	// Notifications holds the state of the session related to asynchronous
+	// notifications.
+	Notifications *sessionNotifications
+
+	// SessionID identifies the session in the audit log. It is zero for
+	// internal planners.
+	SessionID ClusterWideID
+
 	schemaAccessors *schemaInterface
 }
 
@@ -257,6 +265,8 @@ func newInternalPlanner(
 	p.extendedEvalCtx.ExecCfg = execCfg
 	p.extendedEvalCtx.Placeholders = &p.semaCtx.Placeholders
 	p.extendedEvalCtx.Tables = tables
+	// Internal planners can't send or receive notifications.
+	p.extendedEvalCtx.Notifications = &sessionNotifications{}
 
 	acc := plannerMon.MakeBoundAccount()
 	p.extendedEvalCtx.ActiveMemAcc = &acc
@@ -558,7 +568,9 @@ type sqlStatsCollector interface {
 	// See executor_statement_metrics.go for details.
 	PhaseTimes() *phaseTimes
 
-	// RecordStatement record stats for one statement.
+	// RecordStatement record stats for one statement. It returns the
+	// fingerprint of the statement, or an empty string if statistics aren't
+	// being collected.
 	RecordStatement(
 		stmt Statement,
 		distSQLUsed bool,
@@ -567,7 +579,13 @@ type sqlStatsCollector interface {
 		numRows int,
 		err error,
 		parseLat, planLat, runLat, svcLat, ovhLat float64,
-	)
+	) string
+
+	// RecordPlan records the plan used by an execution of the statement with
+	// the given fingerprint. It returns a non-nil planRegression if the plan
+	// has just been found to be much slower than the plan the statement used
+	// before.
+	RecordPlan(stmt, plan string, svcLat float64) *planRegression
 
 	// SQLStats provides access to the global sqlStats object.
 	SQLStats() *sqlStats
@@ -46,6 +46,9 @@ const (
 var (
 	ReadData      = List{GRANT, SELECT}
 	ReadWriteData = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
+	// ColumnLevel are the privileges that can be granted on individual
+	// columns of a table.
+	ColumnLevel = List{SELECT, INSERT, UPDATE}
 )
 
 // Mask returns the bitmask for a given privilege.
@@ -165,3 +168,19 @@ func ListFromStrings(strs []string) (List, error) {
 	}
 	return ret, nil
 }
+
+// ExpandColumnLevel returns the privileges to grant or revoke on individual
+// columns for the list, replacing ALL with ColumnLevel. An error is returned
+// if the list contains privileges that only apply to whole objects.
+func ExpandColumnLevel(pl List) (List, error) {
+	allowed := ColumnLevel.ToBitField()
+	for _, p := range pl {
+		if p == ALL {
+			return ColumnLevel, nil
+		}
+		if allowed&p.Mask() == 0 {
+			return nil, errors.Errorf("invalid privilege type %s for column", p)
+		}
+	}
+	return pl, nil
+}
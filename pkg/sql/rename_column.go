@@ -125,6 +125,21 @@ func (p *planner) RenameColumn(ctx context.Context, n *tree.RenameColumn) (planN
 		}
 	}
 
+	// Rename the column in row-level security policies.
+	for i := range tableDesc.Policies {
+		policy := &tableDesc.Policies[i]
+		if policy.UsingExpr != "" {
+			if policy.UsingExpr, err = renameIn(policy.UsingExpr); err != nil {
+				return nil, err
+			}
+		}
+		if policy.WithCheckExpr != "" {
+			if policy.WithCheckExpr, err = renameIn(policy.WithCheckExpr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Rename the column in computed columns.
 	for i := range tableDesc.Columns {
 		if tableDesc.Columns[i].IsComputed() {
@@ -98,9 +98,11 @@ func (p *planner) Select(
 	limit := n.Limit
 	orderBy := n.OrderBy
 	with := n.With
+	locking := n.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
+		locking = append(locking, s.Select.Locking...)
 		if s.Select.OrderBy != nil {
 			if orderBy != nil {
 				return nil, pgerror.NewErrorf(
@@ -119,11 +121,24 @@ func (p *planner) Select(
 		}
 	}
 
+	if len(locking) > 0 {
+		if err := checkLockingClause(locking, wrapped); err != nil {
+			return nil, err
+		}
+	}
+
 	switch s := wrapped.(type) {
 	case *tree.SelectClause:
 		// Select can potentially optimize index selection if it's being ordered,
 		// so we allow it to do its own sorting.
-		return p.SelectClause(ctx, s, orderBy, limit, with, desiredTypes, publicColumns)
+		plan, err := p.SelectClause(ctx, s, orderBy, limit, with, desiredTypes, publicColumns)
+		if err != nil || len(locking) == 0 {
+			return plan, err
+		}
+		if err := p.applyLockingClause(ctx, locking, s, plan); err != nil {
+			return nil, err
+		}
+		return plan, nil
 
 	// TODO(dan): Union can also do optimizations when it has an ORDER BY, but
 	// currently expects the ordering to be done externally, so we let it fall
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -39,13 +40,16 @@ func resultsNeeded(r tree.ReturningClause) bool {
 }
 
 // Returning wraps the given source node in a way suitable for the
-// given RETURNING specification.
+// given RETURNING specification. The columns referenced by the RETURNING
+// expressions are read from the table desc, so they require the SELECT
+// privilege.
 func (p *planner) Returning(
 	ctx context.Context,
 	source batchedPlanNode,
 	r tree.ReturningClause,
 	desiredTypes []types.T,
 	tn *tree.TableName,
+	desc *sqlbase.TableDescriptor,
 ) (planNode, error) {
 	// serialize the data-modifying plan to ensure that no data is
 	// observed that hasn't been validated first. See the comments
@@ -58,8 +62,20 @@ func (p *planner) Returning(
 		return &rowCountNode{source: source}, nil
 
 	case *tree.ReturningExprs:
+		colErrs, err := p.CheckColumnPrivileges(ctx, desc, privilege.SELECT)
+		if err != nil {
+			// Without any SELECT privilege, RETURNING can still produce
+			// values that do not come from the table.
+			colErrs = make([]error, len(desc.Columns))
+			for i := range colErrs {
+				colErrs[i] = err
+			}
+		}
+
 		serialized := &serializeNode{source: source}
-		info := sqlbase.NewSourceInfoForSingleTable(*tn, planColumns(source))
+		info := sqlbase.NewSourceInfoForSingleTable(
+			*tn, markUnreadableColumns(desc, colErrs, planColumns(source)),
+		)
 		r := &renderNode{
 			source:     planDataSource{info: info, plan: serialized},
 			sourceInfo: sqlbase.MultiSourceInfo{info},
@@ -74,7 +90,7 @@ func (p *planner) Returning(
 		p.semaCtx.Properties.Require("RETURNING", tree.RejectSpecial)
 
 		r.ivarHelper = tree.MakeIndexedVarHelper(r, len(r.source.info.SourceColumns))
-		err := p.initTargets(ctx, r, tree.SelectExprs(*t), desiredTypes)
+		err = p.initTargets(ctx, r, tree.SelectExprs(*t), desiredTypes)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,175 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// revertBatchSize is the maximum number of keys reverted by a RevertRange
+// batch.
+const revertBatchSize = 10000
+
+var revertHeader = sqlbase.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+}
+
+// Revert rewrites the data of a table to its state at a past timestamp
+// (`ALTER TABLE ... EXPERIMENTAL REVERT TO SYSTEM TIME ...` statement).
+// The revert is performed by a job using RevertRange requests.
+// Privileges: DROP on table.
+func (p *planner) Revert(ctx context.Context, n *tree.Revert) (planNode, error) {
+	tableDesc, _, err := p.getTableAndIndex(ctx, n.Table, nil /* tableWithIndex */, privilege.DROP)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc.IsView() || tableDesc.IsSequence() {
+		return nil, errors.Errorf("%q is not a table", tableDesc.Name)
+	}
+	targetTime, err := p.EvalAsOfTimestamp(n.AsOf, hlc.MaxTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := func(ctx context.Context, _ []planNode, resultsCh chan<- tree.Datums) error {
+		if !p.ExtendedEvalContext().TxnImplicit {
+			return errors.Errorf("%s cannot be used inside a transaction", n.StatementTag())
+		}
+		if now := p.ExecCfg().Clock.Now(); !targetTime.Less(now) {
+			return errors.Errorf("revert target time %s must be in the past", targetTime)
+		}
+		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
+			Description:   tree.AsStringWithFlags(n, tree.FmtAlwaysQualifyTableNames),
+			Username:      p.User(),
+			DescriptorIDs: sqlbase.IDs{tableDesc.ID},
+			Details: jobspb.RevertDetails{
+				TableID:    tableDesc.ID,
+				TargetTime: targetTime,
+			},
+			Progress: jobspb.RevertProgress{},
+		})
+		if err != nil {
+			return err
+		}
+		return <-errCh
+	}
+	return &hookFnNode{f: fn, header: revertHeader}, nil
+}
+
+type revertResumer struct{}
+
+var _ jobs.Resumer = &revertResumer{}
+
+// Resume implements the jobs.Resumer interface.
+func (r *revertResumer) Resume(
+	ctx context.Context, job *jobs.Job, phs interface{}, resultsCh chan<- tree.Datums,
+) error {
+	details := job.Details().(jobspb.RevertDetails)
+	db := phs.(PlanHookState).ExecCfg().DB
+
+	var tableDesc, oldDesc *sqlbase.TableDescriptor
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		tableDesc, err = sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, details.TargetTime)
+		var err error
+		oldDesc, err = sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "reading table descriptor as of %s", details.TargetTime)
+	}
+	// Rows written under another version of the schema may not be valid
+	// under the current one (e.g. they may lack a NOT NULL column that was
+	// added since), so refuse to revert across a schema change.
+	if oldDesc.Version != tableDesc.Version {
+		return errors.Errorf("cannot revert table %q: its schema changed after %s",
+			tableDesc.Name, details.TargetTime)
+	}
+
+	span := tableDesc.TableSpan()
+	log.Infof(ctx, "reverting table %q (span %s) to %s", tableDesc.Name, span, details.TargetTime)
+	// Each batch reverts a limited number of keys, so that no single Raft
+	// command grows with the size of the table.
+	for resumeSpan := &span; resumeSpan != nil; {
+		var b client.Batch
+		b.Header.MaxSpanRequestKeys = revertBatchSize
+		b.AddRawRequest(&roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key:    resumeSpan.Key,
+				EndKey: resumeSpan.EndKey,
+			},
+			TargetTime: details.TargetTime,
+		})
+		if err := db.Run(ctx, &b); err != nil {
+			return err
+		}
+		resumeSpan = b.RawResponse().Responses[0].GetInner().Header().ResumeSpan
+	}
+	return nil
+}
+
+// OnSuccess implements the jobs.Resumer interface.
+func (r *revertResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+// OnTerminal implements the jobs.Resumer interface.
+func (r *revertResumer) OnTerminal(
+	ctx context.Context, job *jobs.Job, status jobs.Status, resultsCh chan<- tree.Datums,
+) {
+	if status == jobs.StatusSucceeded {
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(*job.ID())),
+			tree.NewDString(string(status)),
+		}
+	}
+}
+
+// OnFailOrCancel implements the jobs.Resumer interface. A partially applied
+// revert is left in place; the statement can be run again.
+func (r *revertResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+func revertResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeRevert {
+		return nil
+	}
+	return &revertResumer{}
+}
+
+func init() {
+	jobs.AddResumeHook(revertResumeHook)
+}
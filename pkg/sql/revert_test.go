@@ -0,0 +1,75 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRevertTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	// Use a single connection so that the transaction below stays on it.
+	db.SetMaxOpenConns(1)
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `CREATE DATABASE d`)
+	r.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, v INT)`)
+	r.Exec(t, `INSERT INTO d.t SELECT i, i FROM generate_series(1, 100) AS g(i)`)
+	r.Exec(t, `ALTER TABLE d.t SPLIT AT VALUES (50)`)
+
+	var before string
+	r.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&before)
+	expected := r.QueryStr(t, `SELECT * FROM d.t ORDER BY k`)
+
+	// A bad bulk update, a deletion and an insert of a new row.
+	r.Exec(t, `UPDATE d.t SET v = v * 10`)
+	r.Exec(t, `DELETE FROM d.t WHERE k > 90`)
+	r.Exec(t, `INSERT INTO d.t VALUES (1000, 1000)`)
+	var after string
+	r.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&after)
+
+	r.Exec(t, fmt.Sprintf(`ALTER TABLE d.t EXPERIMENTAL REVERT TO SYSTEM TIME '%s'`, before))
+	r.CheckQueryResults(t, `SELECT * FROM d.t ORDER BY k`, expected)
+
+	// The revert wrote new versions, so the bad update is still visible in
+	// the past.
+	var v int
+	r.QueryRow(t, fmt.Sprintf(`SELECT max(v) FROM d.t AS OF SYSTEM TIME '%s'`, after)).Scan(&v)
+	if v != 1000 {
+		t.Fatalf("expected max(v) = 1000, got %d", v)
+	}
+
+	t.Run("in txn", func(t *testing.T) {
+		r.ExpectErr(t, "cannot be used inside a transaction",
+			fmt.Sprintf(`BEGIN; ALTER TABLE d.t EXPERIMENTAL REVERT TO SYSTEM TIME '%s'`, before))
+		r.Exec(t, `ROLLBACK`)
+	})
+
+	t.Run("schema change", func(t *testing.T) {
+		r.Exec(t, `ALTER TABLE d.t ADD COLUMN w INT`)
+		r.ExpectErr(t, "its schema changed",
+			fmt.Sprintf(`ALTER TABLE d.t EXPERIMENTAL REVERT TO SYSTEM TIME '%s'`, before))
+	})
+}
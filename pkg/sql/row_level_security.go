@@ -0,0 +1,433 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type createPolicyNode struct {
+	n         *tree.CreatePolicy
+	tableDesc *sqlbase.TableDescriptor
+	policy    sqlbase.TableDescriptor_Policy
+}
+
+// CreatePolicy adds a row-level security policy to a table.
+// Privileges: superuser.
+//   Notes: postgres requires the table owner.
+func (p *planner) CreatePolicy(ctx context.Context, n *tree.CreatePolicy) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "create row-level security policies"); err != nil {
+		return nil, err
+	}
+	if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionRowLevelSecurity) {
+		return nil, errors.Errorf("CREATE POLICY requires all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionRowLevelSecurity))
+	}
+
+	tn, tableDesc, err := p.resolvePolicyTable(ctx, &n.Table, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+
+	if tableDesc.FindPolicyByName(string(n.Name)) != -1 {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"policy %q for table %q already exists", string(n.Name), tableDesc.Name)
+	}
+
+	switch n.Command {
+	case tree.PolicyInsert:
+		if n.Using != nil {
+			return nil, pgerror.NewError(pgerror.CodeSyntaxError,
+				"only WITH CHECK expression allowed for INSERT")
+		}
+	case tree.PolicySelect, tree.PolicyDelete:
+		if n.WithCheck != nil {
+			return nil, pgerror.NewError(pgerror.CodeSyntaxError,
+				"WITH CHECK cannot be applied to SELECT or DELETE")
+		}
+	}
+
+	cmd, err := sqlbase.PolicyCommandFromTree(n.Command)
+	if err != nil {
+		return nil, err
+	}
+	policy := sqlbase.TableDescriptor_Policy{Name: string(n.Name), Command: cmd}
+
+	if len(n.Roles) > 0 {
+		users, err := p.GetAllUsersAndRoles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Like GRANT, policies may target the "public" pseudo-role.
+		users[sqlbase.PublicRole] = true
+		for i := range n.Roles {
+			role := &n.Roles[i]
+			if _, ok := users[string(*role)]; !ok {
+				return nil, errors.Errorf("user or role %s does not exist", role)
+			}
+			policy.Roles = append(policy.Roles, string(*role))
+		}
+	}
+
+	if n.Using != nil {
+		if policy.UsingExpr, err = p.makePolicyExpr(ctx, tableDesc, tn, n.Using); err != nil {
+			return nil, err
+		}
+	}
+	if n.WithCheck != nil {
+		if policy.WithCheckExpr, err = p.makePolicyExpr(ctx, tableDesc, tn, n.WithCheck); err != nil {
+			return nil, err
+		}
+	}
+
+	return &createPolicyNode{n: n, tableDesc: tableDesc, policy: policy}, nil
+}
+
+func (n *createPolicyNode) startExec(params runParams) error {
+	n.tableDesc.Policies = append(n.tableDesc.Policies, n.policy)
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, sqlbase.InvalidMutationID,
+	); err != nil {
+		return err
+	}
+	return logPolicyChange(params, n.tableDesc, n.n.Table.TableName(), n.n)
+}
+
+func (n *createPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createPolicyNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createPolicyNode) Close(context.Context)        {}
+
+type dropPolicyNode struct {
+	n         *tree.DropPolicy
+	tableDesc *sqlbase.TableDescriptor
+	idx       int
+}
+
+// DropPolicy removes a row-level security policy from a table.
+// Privileges: superuser.
+//   Notes: postgres requires the table owner.
+func (p *planner) DropPolicy(ctx context.Context, n *tree.DropPolicy) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "drop row-level security policies"); err != nil {
+		return nil, err
+	}
+
+	_, tableDesc, err := p.resolvePolicyTable(ctx, &n.Table, !n.IfExists)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+
+	idx := tableDesc.FindPolicyByName(string(n.Name))
+	if idx == -1 {
+		if n.IfExists {
+			return newZeroNode(nil /* columns */), nil
+		}
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"policy %q for table %q does not exist", string(n.Name), tableDesc.Name)
+	}
+
+	return &dropPolicyNode{n: n, tableDesc: tableDesc, idx: idx}, nil
+}
+
+func (n *dropPolicyNode) startExec(params runParams) error {
+	n.tableDesc.Policies = append(n.tableDesc.Policies[:n.idx], n.tableDesc.Policies[n.idx+1:]...)
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, sqlbase.InvalidMutationID,
+	); err != nil {
+		return err
+	}
+	return logPolicyChange(params, n.tableDesc, n.n.Table.TableName(), n.n)
+}
+
+func (n *dropPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropPolicyNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropPolicyNode) Close(context.Context)        {}
+
+// resolvePolicyTable resolves the table targeted by a CREATE or DROP
+// POLICY statement.
+func (p *planner) resolvePolicyTable(
+	ctx context.Context, name *tree.NormalizableTableName, required bool,
+) (*tree.TableName, *sqlbase.TableDescriptor, error) {
+	tn, err := name.Normalize()
+	if err != nil {
+		return nil, nil, err
+	}
+	var tableDesc *TableDescriptor
+	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		tableDesc, err = ResolveExistingObject(ctx, p, tn, required, requireTableDesc)
+	})
+	return tn, tableDesc, err
+}
+
+// logPolicyChange records a policy change in the event log. This is an
+// auditable log event and is recorded in the same transaction as the
+// table descriptor update.
+func logPolicyChange(
+	params runParams, tableDesc *sqlbase.TableDescriptor, tn *tree.TableName, stmt tree.Statement,
+) error {
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterTable,
+		int32(tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			TableName string
+			Statement string
+			User      string
+		}{tn.FQString(), stmt.String(), params.SessionData().User},
+	)
+}
+
+// makePolicyExpr validates a USING or WITH CHECK expression against the
+// columns of the table and returns its serialized form, ready to be
+// stored in the table descriptor.
+func (p *planner) makePolicyExpr(
+	ctx context.Context, desc *sqlbase.TableDescriptor, tn *tree.TableName, expr tree.Expr,
+) (string, error) {
+	// The policy expressions are evaluated for every row read or
+	// written, so they cannot refer to other tables.
+	if _, err := tree.SimpleVisit(expr, func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if _, ok := expr.(*tree.Subquery); ok {
+			return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in row-level security policies"), false, expr
+		}
+		return nil, true, expr
+	}); err != nil {
+		return "", err
+	}
+
+	replaced, _, err := replaceVars(*desc, expr)
+	if err != nil {
+		return "", err
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replaced, types.Bool, "POLICY", &p.semaCtx, p.EvalContext(), true, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sources := sqlbase.MakeMultiSourceInfo(sqlbase.NewSourceInfoForSingleTable(
+		*tn, sqlbase.ResultColumnsFromColDescs(desc.Columns),
+	))
+	expr, err = dequalifyColumnRefs(ctx, sources, expr)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
+// RowLevelSecurityFilter implements the AuthorizationAccessor interface.
+func (p *planner) RowLevelSecurityFilter(
+	ctx context.Context, desc *sqlbase.TableDescriptor, cmd sqlbase.TableDescriptor_Policy_Command,
+) (tree.Expr, error) {
+	return p.rowLevelSecurityExpr(ctx, desc, cmd, false /* withCheck */)
+}
+
+// rowLevelSecurityExpr combines the expressions of the policies that
+// apply to the current user for statements of kind cmd. If withCheck is
+// set, the WITH CHECK expressions are used, falling back to the USING
+// expression of the policies that have none, as in PostgreSQL. A row
+// passes if any of the applicable policies accepts it; when no policy
+// applies, no row passes. The returned expression is nil if row-level
+// security does not apply at all.
+func (p *planner) rowLevelSecurityExpr(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	cmd sqlbase.TableDescriptor_Policy_Command,
+	withCheck bool,
+) (tree.Expr, error) {
+	if !desc.RowLevelSecurity {
+		return nil, nil
+	}
+
+	// Superusers bypass row-level security.
+	user := p.SessionData().User
+	if user == security.RootUser || user == security.NodeUser {
+		return nil, nil
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := memberOf[sqlbase.AdminRole]; ok {
+		return nil, nil
+	}
+
+	var exprs []string
+	for i := range desc.Policies {
+		policy := &desc.Policies[i]
+		if !policy.AppliesTo(cmd) || !policyAppliesToUser(policy, user, memberOf) {
+			continue
+		}
+		expr := policy.UsingExpr
+		if withCheck && policy.WithCheckExpr != "" {
+			expr = policy.WithCheckExpr
+		}
+		if expr != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	if len(exprs) == 0 {
+		return tree.DBoolFalse, nil
+	}
+
+	parsed, err := parser.ParseExprs(exprs)
+	if err != nil {
+		return nil, err
+	}
+	res := parsed[0]
+	for _, expr := range parsed[1:] {
+		res = &tree.OrExpr{Left: res, Right: expr}
+	}
+	return res, nil
+}
+
+// policyAppliesToUser returns whether the policy targets the given user,
+// either directly or through one of its roles.
+func policyAppliesToUser(
+	policy *sqlbase.TableDescriptor_Policy, user string, memberOf map[string]bool,
+) bool {
+	if len(policy.Roles) == 0 {
+		return true
+	}
+	for _, role := range policy.Roles {
+		if role == sqlbase.PublicRole || role == user {
+			return true
+		}
+		if _, ok := memberOf[role]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addRowLevelSecurityFilter restricts the rows produced by the given
+// table data source to those that the row-level security policies allow
+// the current user to see.
+func (p *planner) addRowLevelSecurityFilter(
+	ctx context.Context, ds planDataSource, filter tree.Expr,
+) (planDataSource, error) {
+	f := &filterNode{source: ds}
+	f.ivarHelper = tree.MakeIndexedVarHelper(f, len(ds.info.SourceColumns))
+
+	defer p.semaCtx.Properties.Restore(p.semaCtx.Properties)
+	p.semaCtx.Properties.Require("row-level security policy", tree.RejectSpecial)
+
+	filter, err := policyColumnRefs(filter)
+	if err != nil {
+		return planDataSource{}, err
+	}
+	f.filter, err = p.analyzeExpr(ctx, filter, sqlbase.MakeMultiSourceInfo(ds.info),
+		f.ivarHelper, types.Bool, true, "row-level security policy")
+	if err != nil {
+		return planDataSource{}, err
+	}
+	return planDataSource{info: ds.info, plan: f}, nil
+}
+
+// rowLevelSecurityWhere restricts the WHERE clause of an UPDATE or DELETE
+// statement to the rows that the row-level security policies allow the
+// current user to modify with a statement of kind cmd.
+func (p *planner) rowLevelSecurityWhere(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	cmd sqlbase.TableDescriptor_Policy_Command,
+	where *tree.Where,
+) (*tree.Where, error) {
+	filter, err := p.RowLevelSecurityFilter(ctx, desc, cmd)
+	if err != nil || filter == nil {
+		return where, err
+	}
+	if filter, err = policyColumnRefs(filter); err != nil {
+		return nil, err
+	}
+	if where == nil {
+		return tree.NewWhere(tree.AstWhere, filter), nil
+	}
+	return tree.NewWhere(where.Type, &tree.AndExpr{
+		Left:  &tree.ParenExpr{Expr: where.Expr},
+		Right: &tree.ParenExpr{Expr: filter},
+	}), nil
+}
+
+// policyColumnRefs turns the column references of a policy expression
+// into column items that are exempt from the column privilege checks:
+// the policies may refer to columns the current user cannot read.
+func policyColumnRefs(expr tree.Expr) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if t, ok := expr.(*tree.UnresolvedName); ok && t.NumParts == 1 && !t.Star {
+			return nil, false, &tree.ColumnItem{
+				ColumnName:        tree.Name(t.Parts[0]),
+				ForUpdateOrDelete: true,
+			}
+		}
+		return nil, true, expr
+	})
+}
+
+// makeMutationCheckHelper returns the helper that validates the rows
+// written to the table by a statement of kind cmd. When row-level
+// security applies to the current user, a dedicated helper is built that
+// also enforces the WITH CHECK expressions of the policies. Otherwise, the
+// helper shared with the foreign key machinery is returned.
+func (p *planner) makeMutationCheckHelper(
+	ctx context.Context,
+	tn *tree.TableName,
+	desc *sqlbase.TableDescriptor,
+	fkTables sqlbase.TableLookupsByID,
+	cmd sqlbase.TableDescriptor_Policy_Command,
+) (*sqlbase.CheckHelper, error) {
+	policyExpr, err := p.rowLevelSecurityExpr(ctx, desc, cmd, true /* withCheck */)
+	if err != nil || policyExpr == nil {
+		return fkTables[desc.ID].CheckHelper, err
+	}
+	return p.makePolicyCheckHelper(ctx, tn, desc, policyExpr, true /* withConstraints */)
+}
+
+// makePolicyCheckHelper builds a CheckHelper verifying the given policy
+// expression and, if withConstraints is set, the CHECK constraints of the
+// table.
+func (p *planner) makePolicyCheckHelper(
+	ctx context.Context,
+	tn *tree.TableName,
+	desc *sqlbase.TableDescriptor,
+	policyExpr tree.Expr,
+	withConstraints bool,
+) (*sqlbase.CheckHelper, error) {
+	h := &sqlbase.CheckHelper{}
+	if withConstraints {
+		if err := h.Init(ctx, p.analyzeExpr, tn, desc); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.AddPolicyCheck(ctx, p.analyzeExpr, tn, desc, policyExpr); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
@@ -97,6 +97,11 @@ type scanNode struct {
 
 	disableBatchLimits bool
 
+	// lockStr and lockWaitPolicy, if lockStr is set, cause the scan to lock
+	// the rows it reads (SELECT ... FOR UPDATE). See applyLockingClause.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
+
 	run scanRun
 
 	// This struct must be allocated on the heap and its location stay
@@ -190,8 +195,12 @@ func (n *scanNode) startExec(params runParams) error {
 		Cols:             n.cols,
 		ValNeededForCol:  n.valNeededForCol.Copy(),
 	}
-	return n.run.fetcher.Init(n.reverse, false, /* returnRangeInfo */
-		false /* isCheck */, &params.p.alloc, tableArgs)
+	if err := n.run.fetcher.Init(n.reverse, false, /* returnRangeInfo */
+		false /* isCheck */, &params.p.alloc, tableArgs); err != nil {
+		return err
+	}
+	n.run.fetcher.SetLocking(n.lockStr, n.lockWaitPolicy)
+	return nil
 }
 
 func (n *scanNode) Close(context.Context) {
@@ -284,8 +293,10 @@ func (n *scanNode) initTable(
 ) error {
 	n.desc = desc
 
+	var colErrs []error
 	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(ctx, n.desc, privilege.SELECT); err != nil {
+		var err error
+		if colErrs, err = p.CheckColumnPrivileges(ctx, n.desc, privilege.SELECT); err != nil {
 			return err
 		}
 	}
@@ -297,7 +308,14 @@ func (n *scanNode) initTable(
 	}
 
 	n.noIndexJoin = (indexHints != nil && indexHints.NoIndexJoin)
-	return n.initDescDefaults(p.curPlan.deps, colCfg)
+	if err := n.initDescDefaults(p.curPlan.deps, colCfg); err != nil {
+		return err
+	}
+
+	// If SELECT was only granted on some of the columns, the other ones
+	// cannot be referenced.
+	n.resultColumns = markUnreadableColumns(n.desc, colErrs, n.resultColumns)
+	return nil
 }
 
 func (n *scanNode) lookupSpecifiedIndex(indexHints *tree.IndexHints) error {
@@ -0,0 +1,185 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// lockingStrength maps the strength of a locking clause to the strength of
+// the locks acquired by KV. KV doesn't distinguish the key and non-key
+// variants of the clauses: FOR KEY SHARE is treated like FOR SHARE and FOR NO
+// KEY UPDATE like FOR UPDATE.
+func lockingStrength(s tree.LockingStrength) roachpb.KeyLockingStrength {
+	switch s {
+	case tree.ForKeyShare, tree.ForShare:
+		return roachpb.LOCK_SHARED
+	case tree.ForNoKeyUpdate, tree.ForUpdate:
+		return roachpb.LOCK_EXCLUSIVE
+	default:
+		return roachpb.LOCK_NONE
+	}
+}
+
+// lockingWaitPolicy maps the wait policy of a locking clause to the wait
+// policy of KV.
+func lockingWaitPolicy(p tree.LockingWaitPolicy) roachpb.LockWaitPolicy {
+	switch p {
+	case tree.LockWaitSkip:
+		return roachpb.LOCK_WAIT_SKIP
+	case tree.LockWaitError:
+		return roachpb.LOCK_WAIT_ERROR
+	default:
+		return roachpb.LOCK_WAIT_BLOCK
+	}
+}
+
+// checkLockingClause returns an error if the locking clause can't be applied
+// to the SELECT clause. Locking is only possible for queries whose result
+// rows can be traced back to the table rows they came from.
+func checkLockingClause(locking tree.LockingClause, sel tree.SelectStatement) error {
+	s, ok := sel.(*tree.SelectClause)
+	if !ok {
+		switch sel.(type) {
+		case *tree.UnionClause:
+			return lockingNotAllowedError(locking, "UNION/INTERSECT/EXCEPT")
+		default:
+			return lockingNotAllowedError(locking, "VALUES")
+		}
+	}
+	switch {
+	case s.Distinct:
+		return lockingNotAllowedError(locking, "DISTINCT clause")
+	case len(s.GroupBy) > 0:
+		return lockingNotAllowedError(locking, "GROUP BY clause")
+	case s.Having != nil:
+		return lockingNotAllowedError(locking, "HAVING clause")
+	case len(s.Window) > 0:
+		return lockingNotAllowedError(locking, "window functions")
+	}
+	return nil
+}
+
+func lockingNotAllowedError(locking tree.LockingClause, what string) error {
+	return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+		"%s is not allowed with %s", locking[0].Strength, what)
+}
+
+// applyLockingClause configures the scans of the tables in the plan of a
+// SELECT clause to lock the rows they read, according to the locking clause.
+// Each item of the clause applies to the tables it names, or to all tables
+// if it doesn't name any. If several items apply to a table, the strongest
+// strength and wait policy win.
+func (p *planner) applyLockingClause(
+	ctx context.Context, locking tree.LockingClause, sel *tree.SelectClause, plan planNode,
+) error {
+	// Resolve the names of the targets, which can be aliases, to the names of
+	// the tables in the FROM clause.
+	tableNames := make(map[tree.Name]tree.Name)
+	if sel.From != nil {
+		for _, t := range sel.From.Tables {
+			collectLockingTableNames(t, tableNames)
+		}
+	}
+	type tableLocking struct {
+		str    tree.LockingStrength
+		policy tree.LockingWaitPolicy
+	}
+	var all tableLocking
+	byTable := make(map[tree.Name]tableLocking)
+	for _, item := range locking {
+		if len(item.Targets) == 0 {
+			all.str = maxLockingStrength(all.str, item.Strength)
+			all.policy = maxLockingWaitPolicy(all.policy, item.WaitPolicy)
+			continue
+		}
+		for i := range item.Targets {
+			tn, err := item.Targets[i].Normalize()
+			if err != nil {
+				return err
+			}
+			name, ok := tableNames[tn.TableName]
+			if !ok {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedTableError,
+					"relation %q in %s clause not found in FROM clause", tn.TableName, item.Strength)
+			}
+			l := byTable[name]
+			l.str = maxLockingStrength(l.str, item.Strength)
+			l.policy = maxLockingWaitPolicy(l.policy, item.WaitPolicy)
+			byTable[name] = l
+		}
+	}
+
+	return walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *groupNode:
+				return false, lockingNotAllowedError(locking, "aggregate functions")
+			case *windowNode:
+				return false, lockingNotAllowedError(locking, "window functions")
+			case *scanNode:
+				l := byTable[tree.Name(n.desc.Name)]
+				l.str = maxLockingStrength(l.str, all.str)
+				l.policy = maxLockingWaitPolicy(l.policy, all.policy)
+				n.lockStr = lockingStrength(l.str)
+				n.lockWaitPolicy = lockingWaitPolicy(l.policy)
+			}
+			return true, nil
+		},
+	})
+}
+
+// collectLockingTableNames adds the names and aliases of the tables in a FROM
+// clause expression to the map, which maps them to the table names.
+func collectLockingTableNames(expr tree.TableExpr, names map[tree.Name]tree.Name) {
+	switch t := expr.(type) {
+	case *tree.AliasedTableExpr:
+		if tn, ok := t.Expr.(*tree.NormalizableTableName); ok {
+			if name, err := tn.Normalize(); err == nil {
+				names[name.TableName] = name.TableName
+				if t.As.Alias != "" {
+					names[t.As.Alias] = name.TableName
+				}
+			}
+			return
+		}
+		collectLockingTableNames(t.Expr, names)
+	case *tree.JoinTableExpr:
+		collectLockingTableNames(t.Left, names)
+		collectLockingTableNames(t.Right, names)
+	case *tree.ParenTableExpr:
+		collectLockingTableNames(t.Expr, names)
+	}
+}
+
+func maxLockingStrength(a, b tree.LockingStrength) tree.LockingStrength {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// maxLockingWaitPolicy returns the stricter of the wait policies: NOWAIT
+// wins over SKIP LOCKED, which wins over waiting.
+func maxLockingWaitPolicy(a, b tree.LockingWaitPolicy) tree.LockingWaitPolicy {
+	if a > b {
+		return a
+	}
+	return b
+}
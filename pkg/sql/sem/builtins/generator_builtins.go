@@ -15,16 +15,20 @@
 package builtins
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/arith"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // See the comments at the start of generators.go for details about
@@ -141,6 +145,43 @@ var generators = map[string]builtinDefinition{
 		),
 	),
 
+	"crdb_internal.timeseries": makeBuiltin(
+		tree.FunctionProperties{
+			Impure:           true,
+			Class:            tree.GeneratorClass,
+			Category:         categoryGenerator,
+			ReturnLabels:     timeSeriesValueGeneratorLabels,
+			DistsqlBlacklist: true,
+		},
+		makeGeneratorOverload(
+			tree.ArgTypes{{"name", types.String}, {"start", types.TimestampTZ}, {"end", types.TimestampTZ}},
+			timeSeriesValueGeneratorType,
+			makeTimeSeriesGenerator,
+			"Produces a virtual table containing the values of the internal time series `name` "+
+				"for each of its sources between `start` and `end`, averaged over 10 second intervals.\n\n"+
+				"This function requires superuser privileges.",
+		),
+		makeGeneratorOverload(
+			tree.ArgTypes{
+				{"name", types.String},
+				{"start", types.TimestampTZ},
+				{"end", types.TimestampTZ},
+				{"resolution", types.Interval},
+				{"aggregator", types.String},
+				{"downsampler", types.String},
+			},
+			timeSeriesValueGeneratorType,
+			makeTimeSeriesGenerator,
+			"Produces a virtual table containing the values of the internal time series `name` "+
+				"between `start` and `end`. The values within each `resolution` interval, which must "+
+				"be a multiple of 10 seconds, are combined using `downsampler`. Unless `aggregator` "+
+				"is empty, the values of the different sources are combined using `aggregator` and "+
+				"the source column is NULL.\n\n"+
+				"The aggregator and downsampler can be one of AVG, SUM, MAX, MIN, FIRST, LAST or VARIANCE.\n\n"+
+				"This function requires superuser privileges.",
+		),
+	),
+
 	"generate_subscripts": makeBuiltin(genProps(subscriptsValueGeneratorLabels),
 		// See https://www.postgresql.org/docs/current/static/functions-srf.html#FUNCTIONS-SRF-SUBSCRIPTS
 		makeGeneratorOverload(
@@ -594,6 +635,170 @@ var noDatums tree.Datums
 // Values implements the tree.ValueGenerator interface.
 func (s *unaryValueGenerator) Values() tree.Datums { return noDatums }
 
+// timeSeriesValueGenerator supports the execution of
+// crdb_internal.timeseries().
+type timeSeriesValueGenerator struct {
+	evalCtx     *tree.EvalContext
+	query       tspb.Query
+	perSource   bool
+	startNanos  int64
+	endNanos    int64
+	sampleNanos int64
+
+	// sources and datapoints hold the results of the queries; the datapoints
+	// at index i belong to the source at index i.
+	sources    tree.Datums
+	datapoints [][]tspb.TimeSeriesDatapoint
+	sourceIdx  int
+	pointIdx   int
+	// release releases the memory accounted for the results of the queries.
+	release func(context.Context)
+}
+
+var timeSeriesValueGeneratorLabels = []string{"timestamp", "source", "value"}
+
+var timeSeriesValueGeneratorType = types.TTuple{
+	Types:  []types.T{types.TimestampTZ, types.String, types.Float},
+	Labels: timeSeriesValueGeneratorLabels,
+}
+
+func parseTimeSeriesAggregator(kind, s string) (*tspb.TimeSeriesQueryAggregator, error) {
+	agg, ok := tspb.TimeSeriesQueryAggregator_value[strings.ToUpper(s)]
+	if !ok {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"unknown %s %q", kind, s)
+	}
+	return tspb.TimeSeriesQueryAggregator(agg).Enum(), nil
+}
+
+func makeTimeSeriesGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums,
+) (tree.ValueGenerator, error) {
+	if evalCtx.TimeSeries == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"crdb_internal.timeseries() is not available in this context")
+	}
+	g := &timeSeriesValueGenerator{
+		evalCtx:    evalCtx,
+		query:      tspb.Query{Name: string(tree.MustBeDString(args[0]))},
+		perSource:  true,
+		startNanos: args[1].(*tree.DTimestampTZ).UnixNano(),
+		endNanos:   args[2].(*tree.DTimestampTZ).UnixNano(),
+	}
+	if len(args) > 3 {
+		sampleNanos, _, _, err := args[3].(*tree.DInterval).Encode()
+		if err != nil {
+			return nil, err
+		}
+		if sampleNanos <= 0 {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"resolution must be positive")
+		}
+		g.sampleNanos = sampleNanos
+		if aggregator := string(tree.MustBeDString(args[4])); aggregator != "" {
+			if g.query.SourceAggregator, err = parseTimeSeriesAggregator("aggregator", aggregator); err != nil {
+				return nil, err
+			}
+			g.perSource = false
+		}
+		if g.query.Downsampler, err = parseTimeSeriesAggregator(
+			"downsampler", string(tree.MustBeDString(args[5])),
+		); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (*timeSeriesValueGenerator) ResolvedType() types.T { return timeSeriesValueGeneratorType }
+
+// runQueries runs the given queries over the time span of the generator. The
+// memory accounted for the results of the previous queries is released.
+func (g *timeSeriesValueGenerator) runQueries(
+	queries []tspb.Query,
+) (*tspb.TimeSeriesQueryResponse, error) {
+	g.Close()
+	resp, release, err := g.evalCtx.TimeSeries.QueryTimeSeries(g.evalCtx.Ctx(), &tspb.TimeSeriesQueryRequest{
+		StartNanos:  g.startNanos,
+		EndNanos:    g.endNanos,
+		Queries:     queries,
+		SampleNanos: g.sampleNanos,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.release = release
+	return resp, nil
+}
+
+// Start implements the tree.ValueGenerator interface.
+func (g *timeSeriesValueGenerator) Start() error {
+	g.sources, g.datapoints = nil, nil
+	g.sourceIdx, g.pointIdx = 0, -1
+
+	resp, err := g.runQueries([]tspb.Query{g.query})
+	if err != nil {
+		return err
+	}
+	if !g.perSource {
+		g.sources = tree.Datums{tree.DNull}
+		g.datapoints = [][]tspb.TimeSeriesDatapoint{resp.Results[0].Datapoints}
+		return nil
+	}
+	// The datapoints of the first query are aggregated across all the sources;
+	// query each of the sources it found separately, in a single request.
+	sources := resp.Results[0].Sources
+	if len(sources) == 0 {
+		return nil
+	}
+	sort.Strings(sources)
+	queries := make([]tspb.Query, len(sources))
+	for i, source := range sources {
+		queries[i] = g.query
+		queries[i].Sources = []string{source}
+	}
+	if resp, err = g.runQueries(queries); err != nil {
+		return err
+	}
+	for i, source := range sources {
+		g.sources = append(g.sources, tree.NewDString(source))
+		g.datapoints = append(g.datapoints, resp.Results[i].Datapoints)
+	}
+	return nil
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (g *timeSeriesValueGenerator) Close() {
+	if g.release != nil {
+		g.release(g.evalCtx.Ctx())
+		g.release = nil
+	}
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (g *timeSeriesValueGenerator) Next() (bool, error) {
+	g.pointIdx++
+	for g.sourceIdx < len(g.datapoints) {
+		if g.pointIdx < len(g.datapoints[g.sourceIdx]) {
+			return true, nil
+		}
+		g.sourceIdx++
+		g.pointIdx = 0
+	}
+	return false, nil
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (g *timeSeriesValueGenerator) Values() tree.Datums {
+	point := g.datapoints[g.sourceIdx][g.pointIdx]
+	return tree.Datums{
+		tree.MakeDTimestampTZ(timeutil.Unix(0, point.TimestampNanos), time.Microsecond),
+		g.sources[g.sourceIdx],
+		tree.NewDFloat(tree.DFloat(point.Value)),
+	}
+}
+
 func jsonAsText(j json.JSON) (tree.Datum, error) {
 	text, err := j.AsText()
 	if err != nil {
@@ -843,6 +843,31 @@ var pgBuiltins = map[string]builtinDefinition{
 		},
 	),
 
+	"pg_notify": makeBuiltin(
+		tree.FunctionProperties{
+			Impure:           true,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"channel", types.String}, {"payload", types.String}},
+			ReturnType: tree.FixedReturnType(types.Unknown),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if ctx.Notifier == nil {
+					return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"pg_notify() is not available in this context")
+				}
+				channel := string(tree.MustBeDString(args[0]))
+				payload := string(tree.MustBeDString(args[1]))
+				if err := ctx.Notifier.Notify(ctx.Ctx(), channel, payload); err != nil {
+					return nil, err
+				}
+				return tree.DNull, nil
+			},
+			Info: "pg_notify sends a notification with the given payload on the given channel " +
+				"when the current transaction commits, like the NOTIFY statement.",
+		},
+	),
+
 	// pg_is_in_recovery returns true if the Postgres database is currently in
 	// recovery.  This is not applicable so this can always return false.
 	// https://www.postgresql.org/docs/current/static/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
@@ -14,6 +14,13 @@
 
 package tree
 
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 // CopyFrom represents a COPY FROM statement.
 type CopyFrom struct {
 	Table   NormalizableTableName
@@ -35,3 +42,192 @@ func (node *CopyFrom) Format(ctx *FmtCtx) {
 		ctx.WriteString("STDIN")
 	}
 }
+
+// CopyTo represents a COPY TO statement.
+type CopyTo struct {
+	Table   NormalizableTableName
+	Columns NameList
+	// Statement is the query whose results are copied, for statements of the
+	// form COPY (query) TO. Table and Columns are not set in that case.
+	Statement *Select
+	Options   CopyOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CopyTo) Format(ctx *FmtCtx) {
+	ctx.WriteString("COPY ")
+	if node.Statement != nil {
+		ctx.FormatNode(node.Statement)
+	} else {
+		ctx.FormatNode(&node.Table)
+		if len(node.Columns) > 0 {
+			ctx.WriteString(" (")
+			ctx.FormatNode(&node.Columns)
+			ctx.WriteString(")")
+		}
+	}
+	ctx.WriteString(" TO STDOUT")
+	if !node.Options.IsDefault() {
+		ctx.WriteString(" WITH (")
+		ctx.FormatNode(&node.Options)
+		ctx.WriteString(")")
+	}
+}
+
+// CopyFormat is the data format of a COPY statement.
+type CopyFormat int
+
+const (
+	// CopyFormatDefault is used when no format is specified. It is
+	// equivalent to CopyFormatText.
+	CopyFormatDefault CopyFormat = iota
+	// CopyFormatText is PostgreSQL's tab-separated text format.
+	CopyFormatText
+	// CopyFormatCSV is the comma-separated values format.
+	CopyFormatCSV
+)
+
+var copyFormatNames = [...]string{
+	CopyFormatDefault: "",
+	CopyFormatText:    "text",
+	CopyFormatCSV:     "csv",
+}
+
+func (f CopyFormat) String() string {
+	return copyFormatNames[f]
+}
+
+// CopyOptions are the options of a COPY statement describing its data
+// format. Unset options have their zero value.
+type CopyOptions struct {
+	DataFormat CopyFormat
+	Delimiter  *StrVal
+	Null       *StrVal
+	Header     *DBool
+}
+
+// MakeCopyOption returns the CopyOptions with the option of the given name
+// set to the given value. value is nil if the option was specified without a
+// value.
+func MakeCopyOption(name string, value Expr) (*CopyOptions, error) {
+	var o CopyOptions
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case *StrVal:
+		s = v.RawString()
+	case *DBool:
+		s = strconv.FormatBool(bool(*v))
+	default:
+		return nil, errors.Errorf("invalid value for COPY option %q: %s", name, value)
+	}
+	requireValue := func() error {
+		if value == nil {
+			return errors.Errorf("COPY option %q requires a value", name)
+		}
+		return nil
+	}
+	switch strings.ToLower(name) {
+	case "format":
+		if err := requireValue(); err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(s) {
+		case "text":
+			o.DataFormat = CopyFormatText
+		case "csv":
+			o.DataFormat = CopyFormatCSV
+		default:
+			return nil, errors.Errorf("COPY format %q not supported", s)
+		}
+	case "csv":
+		if value != nil {
+			return nil, errors.Errorf("COPY option %q does not take a value", name)
+		}
+		o.DataFormat = CopyFormatCSV
+	case "delimiter":
+		if err := requireValue(); err != nil {
+			return nil, err
+		}
+		o.Delimiter = NewStrVal(s)
+	case "null":
+		if err := requireValue(); err != nil {
+			return nil, err
+		}
+		o.Null = NewStrVal(s)
+	case "header":
+		o.Header = DBoolTrue
+		if value != nil {
+			b, err := ParseDBool(s)
+			if err != nil {
+				return nil, errors.Errorf("COPY option %q requires a Boolean value", name)
+			}
+			o.Header = b
+		}
+	default:
+		return nil, errors.Errorf("COPY option %q not recognized", name)
+	}
+	return &o, nil
+}
+
+// CombineWith merges the options set in other into o. An error is returned
+// if an option is set in both.
+func (o *CopyOptions) CombineWith(other *CopyOptions) error {
+	if other.DataFormat != CopyFormatDefault {
+		if o.DataFormat != CopyFormatDefault {
+			return errors.New("COPY format specified multiple times")
+		}
+		o.DataFormat = other.DataFormat
+	}
+	if other.Delimiter != nil {
+		if o.Delimiter != nil {
+			return errors.New("COPY delimiter specified multiple times")
+		}
+		o.Delimiter = other.Delimiter
+	}
+	if other.Null != nil {
+		if o.Null != nil {
+			return errors.New("COPY null specified multiple times")
+		}
+		o.Null = other.Null
+	}
+	if other.Header != nil {
+		if o.Header != nil {
+			return errors.New("COPY header specified multiple times")
+		}
+		o.Header = other.Header
+	}
+	return nil
+}
+
+// IsDefault returns true if no option is set.
+func (o *CopyOptions) IsDefault() bool {
+	return *o == CopyOptions{}
+}
+
+// Format implements the NodeFormatter interface.
+func (o *CopyOptions) Format(ctx *FmtCtx) {
+	sep := ""
+	if o.DataFormat != CopyFormatDefault {
+		ctx.WriteString("FORMAT ")
+		ctx.WriteString(o.DataFormat.String())
+		sep = ", "
+	}
+	if o.Delimiter != nil {
+		ctx.WriteString(sep)
+		ctx.WriteString("DELIMITER ")
+		ctx.FormatNode(o.Delimiter)
+		sep = ", "
+	}
+	if o.Null != nil {
+		ctx.WriteString(sep)
+		ctx.WriteString("NULL ")
+		ctx.FormatNode(o.Null)
+		sep = ", "
+	}
+	if o.Header != nil {
+		ctx.WriteString(sep)
+		ctx.WriteString("HEADER ")
+		ctx.FormatNode(o.Header)
+	}
+}
@@ -971,6 +971,7 @@ const (
 type CreateUser struct {
 	Name        Expr
 	Password    Expr // nil if no password specified
+	ValidUntil  Expr // nil if no expiration specified
 	IfNotExists bool
 }
 
@@ -994,13 +995,19 @@ func (node *CreateUser) Format(ctx *FmtCtx) {
 			ctx.WriteString("*****")
 		}
 	}
+	if node.ValidUntil != nil {
+		ctx.WriteString(" VALID UNTIL ")
+		ctx.FormatNode(node.ValidUntil)
+	}
 }
 
-// AlterUserSetPassword represents an ALTER USER ... WITH PASSWORD statement.
+// AlterUserSetPassword represents an ALTER USER ... WITH PASSWORD or
+// ALTER USER ... VALID UNTIL statement.
 type AlterUserSetPassword struct {
-	Name     Expr
-	Password Expr
-	IfExists bool
+	Name       Expr
+	Password   Expr // nil if the password is not changed
+	ValidUntil Expr // nil if the expiration is not changed
+	IfExists   bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -1010,14 +1017,36 @@ func (node *AlterUserSetPassword) Format(ctx *FmtCtx) {
 		ctx.WriteString("IF EXISTS ")
 	}
 	ctx.FormatNode(node.Name)
-	ctx.WriteString(" WITH PASSWORD ")
-	if ctx.flags.HasFlags(FmtShowPasswords) {
-		ctx.FormatNode(node.Password)
-	} else {
-		ctx.WriteString("*****")
+	if node.Password != nil {
+		ctx.WriteString(" WITH PASSWORD ")
+		if ctx.flags.HasFlags(FmtShowPasswords) {
+			ctx.FormatNode(node.Password)
+		} else {
+			ctx.WriteString("*****")
+		}
+	}
+	if node.ValidUntil != nil {
+		ctx.WriteString(" VALID UNTIL ")
+		ctx.FormatNode(node.ValidUntil)
 	}
 }
 
+// AlterUserUnlock represents an ALTER USER ... UNLOCK statement.
+type AlterUserUnlock struct {
+	Name     Expr
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUserUnlock) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER USER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(node.Name)
+	ctx.WriteString(" UNLOCK")
+}
+
 // CreateRole represents a CREATE ROLE statement.
 type CreateRole struct {
 	Name        Expr
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/arith"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -2384,6 +2385,25 @@ type SequenceOperators interface {
 	SetSequenceValue(ctx context.Context, seqName *TableName, newVal int64, isCalled bool) error
 }
 
+// TimeSeriesQuerier is used by the crdb_internal.timeseries() generator to
+// query the internal time series database.
+type TimeSeriesQuerier interface {
+	// QueryTimeSeries runs the queries of the request in parallel. The memory
+	// used by the results remains accounted for until the returned function
+	// is called.
+	QueryTimeSeries(
+		ctx context.Context, request *tspb.TimeSeriesQueryRequest,
+	) (*tspb.TimeSeriesQueryResponse, func(context.Context), error)
+}
+
+// Notifier is used by the pg_notify() builtin to send asynchronous
+// notifications.
+type Notifier interface {
+	// Notify sends a notification on the given channel to the sessions
+	// listening on it once the current transaction commits.
+	Notify(ctx context.Context, channel, payload string) error
+}
+
 // CtxProvider is anything that can return a Context.
 //
 // TODO(andrei): I think this whole CtxProvider business might not be needed any
@@ -2500,6 +2520,14 @@ type EvalContext struct {
 
 	Sequence SequenceOperators
 
+	// TimeSeries gives access to the internal time series database. It is not
+	// available in DistSQL flows.
+	TimeSeries TimeSeriesQuerier
+
+	// Notifier sends asynchronous notifications. It is not available in
+	// DistSQL flows.
+	Notifier Notifier
+
 	// Ths transaction in which the statement is executing.
 	Txn *client.Txn
 
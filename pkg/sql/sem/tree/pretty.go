@@ -487,6 +487,9 @@ func (node *Select) docTable(p *PrettyCfg) []pretty.RLTableRow {
 	}
 	items = append(items, node.OrderBy.docRow(p))
 	items = append(items, node.Limit.docTable(p)...)
+	if len(node.Locking) > 0 {
+		items = append(items, p.row("", p.Doc(&node.Locking)))
+	}
 	return items
 }
 
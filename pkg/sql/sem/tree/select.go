@@ -44,6 +44,7 @@ type Select struct {
 	Select  SelectStatement
 	OrderBy OrderBy
 	Limit   *Limit
+	Locking LockingClause
 }
 
 // Format implements the NodeFormatter interface.
@@ -58,6 +59,10 @@ func (node *Select) Format(ctx *FmtCtx) {
 		ctx.WriteByte(' ')
 		ctx.FormatNode(node.Limit)
 	}
+	if len(node.Locking) > 0 {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.Locking)
+	}
 }
 
 // ParenSelect represents a parenthesized SELECT/UNION/VALUES statement.
@@ -571,6 +576,107 @@ func (node *Limit) Format(ctx *FmtCtx) {
 	}
 }
 
+// LockingClause represents the locking clause of a SELECT statement, e.g.
+// FOR UPDATE or FOR SHARE OF t NOWAIT. A clause can consist of multiple
+// items, each of which applies to the tables it names (or to all tables if it
+// doesn't name any).
+type LockingClause []*LockingItem
+
+// Format implements the NodeFormatter interface.
+func (node *LockingClause) Format(ctx *FmtCtx) {
+	for i, n := range *node {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(n)
+	}
+}
+
+// LockingItem represents a single item of a locking clause.
+type LockingItem struct {
+	Strength   LockingStrength
+	Targets    NormalizableTableNames
+	WaitPolicy LockingWaitPolicy
+}
+
+// Format implements the NodeFormatter interface.
+func (node *LockingItem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.Strength)
+	if len(node.Targets) > 0 {
+		ctx.WriteString(" OF ")
+		ctx.FormatNode(&node.Targets)
+	}
+	ctx.FormatNode(node.WaitPolicy)
+}
+
+// LockingStrength is the strength of the row locks acquired by a locking
+// clause. The strengths are ordered from weakest to strongest.
+type LockingStrength byte
+
+// The ordering of the strengths is relied upon when combining the items of a
+// locking clause.
+const (
+	// ForNone is the absence of a locking clause.
+	ForNone LockingStrength = iota
+	// ForKeyShare is FOR KEY SHARE.
+	ForKeyShare
+	// ForShare is FOR SHARE.
+	ForShare
+	// ForNoKeyUpdate is FOR NO KEY UPDATE.
+	ForNoKeyUpdate
+	// ForUpdate is FOR UPDATE.
+	ForUpdate
+)
+
+var lockingStrengthName = [...]string{
+	ForNone:        "",
+	ForKeyShare:    "FOR KEY SHARE",
+	ForShare:       "FOR SHARE",
+	ForNoKeyUpdate: "FOR NO KEY UPDATE",
+	ForUpdate:      "FOR UPDATE",
+}
+
+func (s LockingStrength) String() string {
+	return lockingStrengthName[s]
+}
+
+// Format implements the NodeFormatter interface.
+func (s LockingStrength) Format(ctx *FmtCtx) {
+	ctx.WriteString(s.String())
+}
+
+// LockingWaitPolicy is the policy of a locking clause for rows which are
+// locked by other transactions.
+type LockingWaitPolicy byte
+
+const (
+	// LockWaitBlock waits for the locks to be released. This is the default.
+	LockWaitBlock LockingWaitPolicy = iota
+	// LockWaitSkip skips locked rows (SKIP LOCKED).
+	LockWaitSkip
+	// LockWaitError returns an error when it encounters a locked row
+	// (NOWAIT).
+	LockWaitError
+)
+
+var lockingWaitPolicyName = [...]string{
+	LockWaitBlock: "",
+	LockWaitSkip:  "SKIP LOCKED",
+	LockWaitError: "NOWAIT",
+}
+
+func (p LockingWaitPolicy) String() string {
+	return lockingWaitPolicyName[p]
+}
+
+// Format implements the NodeFormatter interface.
+func (p LockingWaitPolicy) Format(ctx *FmtCtx) {
+	if p != LockWaitBlock {
+		ctx.WriteByte(' ')
+		ctx.WriteString(p.String())
+	}
+}
+
 // RowsFromExpr represents a ROWS FROM(...) expression.
 type RowsFromExpr struct {
 	Items Exprs
@@ -101,3 +101,19 @@ func (node *Scatter) Format(ctx *FmtCtx) {
 		ctx.WriteString(")")
 	}
 }
+
+// Revert represents an `ALTER TABLE .. EXPERIMENTAL REVERT TO SYSTEM TIME ..`
+// statement.
+type Revert struct {
+	Table *NormalizableTableName
+	// AsOf is the time the table's data is reverted to.
+	AsOf AsOfClause
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Revert) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER TABLE ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" EXPERIMENTAL REVERT TO SYSTEM TIME ")
+	ctx.FormatNode(node.AsOf.Expr)
+}
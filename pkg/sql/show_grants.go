@@ -45,6 +45,18 @@ SELECT table_catalog AS database_name,
        grantee,
        privilege_type
 FROM "".information_schema.table_privileges`
+	// Column privileges are reported with the name of the column, unless
+	// the grantee holds the privilege on the whole table.
+	const columnPrivQuery = `
+SELECT table_catalog AS database_name,
+       table_schema AS schema_name,
+       table_name,
+       grantee,
+       privilege_type || ' (' || column_name || ')' AS privilege_type
+FROM "".information_schema.column_privileges
+WHERE (table_catalog, table_schema, table_name, grantee, privilege_type) NOT IN (
+  SELECT table_catalog, table_schema, table_name, grantee, privilege_type
+  FROM "".information_schema.table_privileges)`
 
 	var source bytes.Buffer
 	var cond bytes.Buffer
@@ -79,6 +91,8 @@ FROM "".information_schema.table_privileges`
 		}
 	} else {
 		fmt.Fprint(&source, tablePrivQuery)
+		source.WriteString(` UNION ALL`)
+		source.WriteString(columnPrivQuery)
 		orderBy = "1,2,3,4,5"
 
 		if n.Targets != nil {
@@ -26,8 +26,14 @@ import (
 )
 
 // CheckHelper validates check constraints on rows, on INSERT and UPDATE.
+// It also validates the row-level security policy expressions added with
+// AddPolicyCheck.
 type CheckHelper struct {
-	Exprs        []tree.TypedExpr
+	Exprs []tree.TypedExpr
+	// PolicyExprs are the WITH CHECK expressions of the row-level security
+	// policies that the written rows must satisfy.
+	PolicyExprs  []tree.TypedExpr
+	tableName    string
 	cols         []ColumnDescriptor
 	sourceInfo   *DataSourceInfo
 	ivarHelper   *tree.IndexedVarHelper
@@ -58,10 +64,7 @@ func (c *CheckHelper) Init(
 		return nil
 	}
 
-	c.cols = tableDesc.Columns
-	c.sourceInfo = NewSourceInfoForSingleTable(
-		*tn, ResultColumnsFromColDescs(tableDesc.Columns),
-	)
+	c.initSource(tn, tableDesc)
 
 	c.Exprs = make([]tree.TypedExpr, len(tableDesc.Checks))
 	exprStrings := make([]string, len(tableDesc.Checks))
@@ -73,13 +76,12 @@ func (c *CheckHelper) Init(
 		return err
 	}
 
-	ivarHelper := tree.MakeIndexedVarHelper(c, len(c.cols))
 	for i, raw := range exprs {
 		typedExpr, err := analyzeExpr(
 			ctx,
 			raw,
 			MakeMultiSourceInfo(c.sourceInfo),
-			ivarHelper,
+			*c.ivarHelper,
 			types.Bool,
 			false, /* requireType */
 			"",    /* typingContext */
@@ -89,16 +91,61 @@ func (c *CheckHelper) Init(
 		}
 		c.Exprs[i] = typedExpr
 	}
+	return nil
+}
+
+// AddPolicyCheck adds a row-level security policy expression that rows
+// written to the table must satisfy. Unlike CHECK constraints, a NULL
+// result is a violation. This step should be done during planning, after
+// Init.
+func (c *CheckHelper) AddPolicyCheck(
+	ctx context.Context,
+	analyzeExpr AnalyzeExprFunction,
+	tn *tree.TableName,
+	tableDesc *TableDescriptor,
+	expr tree.Expr,
+) error {
+	if c.ivarHelper == nil {
+		c.initSource(tn, tableDesc)
+	}
+	c.tableName = tableDesc.Name
+	typedExpr, err := analyzeExpr(
+		ctx,
+		expr,
+		MakeMultiSourceInfo(c.sourceInfo),
+		*c.ivarHelper,
+		types.Bool,
+		true, /* requireType */
+		"row-level security policy",
+	)
+	if err != nil {
+		return err
+	}
+	c.PolicyExprs = append(c.PolicyExprs, typedExpr)
+	return nil
+}
+
+func (c *CheckHelper) initSource(tn *tree.TableName, tableDesc *TableDescriptor) {
+	c.cols = tableDesc.Columns
+	c.sourceInfo = NewSourceInfoForSingleTable(
+		*tn, ResultColumnsFromColDescs(tableDesc.Columns),
+	)
+	ivarHelper := tree.MakeIndexedVarHelper(c, len(c.cols))
 	c.ivarHelper = &ivarHelper
 	c.curSourceRow = make(tree.Datums, len(c.cols))
-	return nil
+}
+
+// HasChecks returns whether there is anything to validate on the rows
+// written to the table.
+func (c *CheckHelper) HasChecks() bool {
+	return len(c.Exprs) > 0 || len(c.PolicyExprs) > 0
 }
 
 // LoadRow sets values in the IndexedVars used by the CHECK exprs.
 // Any value not passed is set to NULL, unless `merge` is true, in which
 // case it is left unchanged (allowing updating a subset of a row's values).
 func (c *CheckHelper) LoadRow(colIdx map[ColumnID]int, row tree.Datums, merge bool) error {
-	if len(c.Exprs) == 0 {
+	if !c.HasChecks() {
 		return nil
 	}
 	// Populate IndexedVars.
@@ -153,5 +200,15 @@ func (c *CheckHelper) Check(ctx *tree.EvalContext) error {
 				"failed to satisfy CHECK constraint (%s)", expr)
 		}
 	}
+	for _, expr := range c.PolicyExprs {
+		if d, err := expr.Eval(ctx); err != nil {
+			return err
+		} else if res, err := tree.GetBool(d); err != nil {
+			return err
+		} else if !res {
+			return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+				"new row violates row-level security policy for table %q", c.tableName)
+		}
+	}
 	return nil
 }
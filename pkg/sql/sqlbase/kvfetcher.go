@@ -24,6 +24,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -153,6 +154,10 @@ type txnKVFetcher struct {
 	// returnRangeInfo, if set, causes the kvFetcher to populate rangeInfos.
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
+	// lockStr and lockWaitPolicy are the locking strength and wait policy of
+	// the scans. See RowFetcher.SetLocking.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
 
 	fetchEnd  bool
 	batchIdx  int
@@ -230,6 +235,8 @@ func (f *txnKVFetcher) getBatchSizeForIdx(batchIdx int) int64 {
 // Subsequent batches are larger, up to kvBatchSize.
 //
 // Batch limits can only be used if the spans are ordered.
+//
+// If lockStr is not LOCK_NONE, the scans lock the keys they return.
 func makeKVFetcher(
 	txn *client.Txn,
 	spans roachpb.Spans,
@@ -237,6 +244,8 @@ func makeKVFetcher(
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
+	lockStr roachpb.KeyLockingStrength,
+	lockWaitPolicy roachpb.LockWaitPolicy,
 ) (txnKVFetcher, error) {
 	if firstBatchLimit < 0 || (!useBatchLimit && firstBatchLimit != 0) {
 		return txnKVFetcher{}, errors.Errorf("invalid batch limit %d (useBatchLimit: %t)",
@@ -270,6 +279,8 @@ func makeKVFetcher(
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
+		lockStr:         lockStr,
+		lockWaitPolicy:  lockWaitPolicy,
 	}, nil
 }
 
@@ -283,6 +294,8 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 		scans := make([]roachpb.ReverseScanRequest, len(f.spans))
 		for i := range f.spans {
 			scans[i].ScanFormat = roachpb.BATCH_RESPONSE
+			scans[i].KeyLocking = f.lockStr
+			scans[i].LockWaitPolicy = f.lockWaitPolicy
 			scans[i].SetSpan(f.spans[i])
 			ba.Requests[i].MustSetInner(&scans[i])
 		}
@@ -290,6 +303,8 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 		scans := make([]roachpb.ScanRequest, len(f.spans))
 		for i := range f.spans {
 			scans[i].ScanFormat = roachpb.BATCH_RESPONSE
+			scans[i].KeyLocking = f.lockStr
+			scans[i].LockWaitPolicy = f.lockWaitPolicy
 			scans[i].SetSpan(f.spans[i])
 			ba.Requests[i].MustSetInner(&scans[i])
 		}
@@ -311,6 +326,13 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 
 	br, err := f.txn.Send(ctx, ba)
 	if err != nil {
+		if _, ok := err.GetDetail().(*roachpb.WriteIntentError); ok &&
+			f.lockWaitPolicy == roachpb.LOCK_WAIT_ERROR {
+			// The scan ran into a row locked by another transaction and was told
+			// not to wait for it (SELECT ... FOR UPDATE NOWAIT).
+			return pgerror.NewErrorf(pgerror.CodeLockNotAvailableError,
+				"could not obtain lock on row: %s", err)
+		}
 		return err.GoError()
 	}
 	if br != nil {
@@ -334,3 +334,45 @@ func (p PrivilegeDescriptor) AnyPrivilege(user string) bool {
 	}
 	return userPriv.Privileges != 0
 }
+
+// validateColumn checks that the privileges granted on a column are among
+// the privileges that can be granted at the column level.
+func (p PrivilegeDescriptor) validateColumn(colName string) error {
+	allowedPrivilegesBits := privilege.ColumnLevel.ToBitField()
+	for _, u := range p.Users {
+		if remaining := u.Privileges &^ allowedPrivilegesBits; remaining != 0 {
+			return fmt.Errorf("user %s must not have %s privileges on column %q",
+				u.User, privilege.ListFromBitField(remaining), colName)
+		}
+	}
+	return nil
+}
+
+// GrantPrivileges adds new privileges on the column for a given user. The
+// privileges must be among privilege.ColumnLevel.
+func (desc *ColumnDescriptor) GrantPrivileges(user string, privList privilege.List) {
+	if desc.Privileges == nil {
+		desc.Privileges = &PrivilegeDescriptor{}
+	}
+	desc.Privileges.Grant(user, privList)
+}
+
+// RevokePrivileges removes privileges on the column from a given user.
+func (desc *ColumnDescriptor) RevokePrivileges(user string, privList privilege.List) {
+	if desc.Privileges == nil {
+		return
+	}
+	desc.Privileges.Revoke(user, privList)
+	if len(desc.Privileges.Users) == 0 {
+		desc.Privileges = nil
+	}
+}
+
+// CheckPrivilege returns true if 'user' was granted 'privilege' on the
+// column itself. Privileges granted on the table are not considered.
+func (desc *ColumnDescriptor) CheckPrivilege(user string, priv privilege.Kind) bool {
+	if desc.Privileges == nil {
+		return false
+	}
+	return desc.Privileges.CheckPrivilege(user, priv)
+}
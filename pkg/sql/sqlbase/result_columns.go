@@ -30,6 +30,10 @@ type ResultColumn struct {
 
 	// If set, a value won't be produced for this column; used internally.
 	Omitted bool
+
+	// If set, the current user is not allowed to read this column of a
+	// table, and references to it report this error; used internally.
+	PrivilegeErr error
 }
 
 // ResultColumns is the type used throughout the sql module to
@@ -201,6 +201,12 @@ type RowFetcher struct {
 	// If set, GetRangeInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// lockStr and lockWaitPolicy, if lockStr is set, cause the scans of the
+	// RowFetcher to lock the rows they return (SELECT ... FOR UPDATE). See
+	// SetLocking.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -428,13 +434,25 @@ func (rf *RowFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVFetcher(
+		txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo,
+		rf.lockStr, rf.lockWaitPolicy,
+	)
 	if err != nil {
 		return err
 	}
 	return rf.StartScanFrom(ctx, &f)
 }
 
+// SetLocking configures the RowFetcher to lock the rows returned by the scans
+// started by StartScan with the given strength, which is LOCK_NONE by default.
+// The wait policy determines what happens when a row is locked by another
+// transaction. It must be called after Init.
+func (rf *RowFetcher) SetLocking(str roachpb.KeyLockingStrength, policy roachpb.LockWaitPolicy) {
+	rf.lockStr = str
+	rf.lockWaitPolicy = policy
+}
+
 // StartScanFrom initializes and starts a scan from the given kvFetcher. Can be
 // used multiple times.
 func (rf *RowFetcher) StartScanFrom(ctx context.Context, f kvFetcher) error {
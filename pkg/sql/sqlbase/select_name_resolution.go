@@ -115,6 +115,15 @@ func (v *NameResolutionVisitor) VisitPre(expr tree.Expr) (recurse bool, newNode
 		if v.err != nil {
 			return false, expr
 		}
+		for _, src := range v.sources {
+			if idx := t.Idx - src.ColOffset; idx >= 0 && idx < len(src.SourceColumns) {
+				if err := src.SourceColumns[idx].PrivilegeErr; err != nil {
+					v.err = err
+					return false, expr
+				}
+				break
+			}
+		}
 
 		v.foundDependentVars = true
 		return false, t
@@ -137,6 +146,14 @@ func (v *NameResolutionVisitor) VisitPre(expr tree.Expr) (recurse bool, newNode
 
 		srcIdx := v.resolver.ResolverState.SrcIdx
 		colIdx := v.resolver.ResolverState.ColIdx
+		// The references generated internally to fetch the rows to update
+		// or delete do not read the values on behalf of the user.
+		if !t.ForUpdateOrDelete {
+			if err := v.sources[srcIdx].SourceColumns[colIdx].PrivilegeErr; err != nil {
+				v.err = err
+				return false, expr
+			}
+		}
 		ivar := v.iVarHelper.IndexedVar(v.sources[srcIdx].ColOffset + colIdx)
 		v.foundDependentVars = true
 		return true, ivar
@@ -264,6 +281,9 @@ func expandStar(
 	colSel := func(src *DataSourceInfo, idx int) {
 		col := src.SourceColumns[idx]
 		if !col.Hidden {
+			if col.PrivilegeErr != nil && err == nil {
+				err = col.PrivilegeErr
+			}
 			ivar := ivarHelper.IndexedVar(idx + src.ColOffset)
 			columns = append(columns, ResultColumn{Name: col.Name, Typ: ivar.ResolvedType()})
 			exprs = append(exprs, ivar)
@@ -290,6 +310,9 @@ func expandStar(
 			colSel(ds, i)
 		}
 	}
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return columns, exprs, nil
 }
@@ -1113,6 +1113,12 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 			return fmt.Errorf("column %q invalid ID (%d) >= next column ID (%d)",
 				column.Name, column.ID, desc.NextColumnID)
 		}
+
+		if column.Privileges != nil {
+			if err := column.Privileges.validateColumn(column.Name); err != nil {
+				return err
+			}
+		}
 	}
 
 	if st != nil && st.Version.HasBeenInitialized() {
@@ -2719,6 +2725,75 @@ func (desc *TableDescriptor) SetAuditMode(mode tree.AuditMode) (bool, error) {
 	return prev != desc.AuditMode, nil
 }
 
+// PolicyCommandFromTree converts the statement kind of a CREATE POLICY
+// statement into its descriptor representation.
+func PolicyCommandFromTree(cmd tree.PolicyCommand) (TableDescriptor_Policy_Command, error) {
+	switch cmd {
+	case tree.PolicyAll:
+		return TableDescriptor_Policy_ALL, nil
+	case tree.PolicySelect:
+		return TableDescriptor_Policy_SELECT, nil
+	case tree.PolicyInsert:
+		return TableDescriptor_Policy_INSERT, nil
+	case tree.PolicyUpdate:
+		return TableDescriptor_Policy_UPDATE, nil
+	case tree.PolicyDelete:
+		return TableDescriptor_Policy_DELETE, nil
+	default:
+		return 0, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"unknown policy command: %s (%d)", cmd, cmd)
+	}
+}
+
+// FindPolicyByName returns the ordinal of the row-level security policy
+// with the given name, or -1 if the table has no such policy.
+func (desc *TableDescriptor) FindPolicyByName(name string) int {
+	for i := range desc.Policies {
+		if desc.Policies[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AppliesTo returns whether the policy governs statements of the given
+// kind.
+func (p *TableDescriptor_Policy) AppliesTo(cmd TableDescriptor_Policy_Command) bool {
+	return p.Command == TableDescriptor_Policy_ALL || p.Command == cmd
+}
+
+// UsesColumn returns whether the USING or WITH CHECK expression of the
+// policy refers to the named column.
+func (p *TableDescriptor_Policy) UsesColumn(colName string) (bool, error) {
+	used := false
+	visitFn := func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if vBase, ok := expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			if c, ok := v.(*tree.ColumnItem); ok && string(c.ColumnName) == colName {
+				used = true
+			}
+			return nil, false, v
+		}
+		return nil, true, expr
+	}
+	for _, expr := range []string{p.UsingExpr, p.WithCheckExpr} {
+		if expr == "" {
+			continue
+		}
+		parsed, err := parser.ParseExpr(expr)
+		if err != nil {
+			return false, errors.Wrapf(err, "could not parse policy expression %s", expr)
+		}
+		if _, err := tree.SimpleVisit(parsed, visitFn); err != nil {
+			return false, err
+		}
+	}
+	return used, nil
+}
+
 // GetAuditMode is part of the DescriptorProto interface.
 // This is a stub until per-database auditing is enabled.
 func (desc *DatabaseDescriptor) GetAuditMode() TableDescriptor_AuditMode {
@@ -184,6 +184,61 @@ CREATE TABLE system.role_members (
   INDEX ("role"),
   INDEX ("member")
 );`
+
+	// protected_ts stores protected timestamp records. The GC queue will not
+	// advance the GC threshold of any range overlapping the spans of a record
+	// past the record's timestamp. Records created on behalf of a job are
+	// removed once the job finishes.
+	ProtectedTsTableSchema = `
+CREATE TABLE system.protected_ts (
+	id     INT     DEFAULT unique_rowid() PRIMARY KEY,
+	ts     DECIMAL NOT NULL,
+	job_id INT,
+	spans  BYTES   NOT NULL,
+	FAMILY (id, ts, job_id, spans)
+);`
+
+	// statement_statistics stores the statement statistics collected by each
+	// node, aggregated by the hour, or by the day once they are older than
+	// sql.metrics.statement_details.compaction_threshold. The statistics are an
+	// encoded roachpb.StatementStatistics.
+	StatementStatsTableSchema = `
+CREATE TABLE system.statement_statistics (
+	aggregated_ts TIMESTAMP NOT NULL,
+	fingerprint   STRING    NOT NULL,
+	flags         STRING    NOT NULL,
+	app_name      STRING    NOT NULL,
+	node_id       INT       NOT NULL,
+	statistics    BYTES     NOT NULL,
+	PRIMARY KEY (aggregated_ts, fingerprint, flags, app_name, node_id),
+	FAMILY (aggregated_ts, fingerprint, flags, app_name, node_id, statistics)
+);`
+
+	// transaction_statistics is like statement_statistics for transactions.
+	// The fingerprint of a transaction is made of the fingerprints of its
+	// statements. The statistics are an encoded roachpb.TransactionStatistics.
+	TxnStatsTableSchema = `
+CREATE TABLE system.transaction_statistics (
+	aggregated_ts TIMESTAMP NOT NULL,
+	fingerprint   STRING    NOT NULL,
+	app_name      STRING    NOT NULL,
+	node_id       INT       NOT NULL,
+	statistics    BYTES     NOT NULL,
+	PRIMARY KEY (aggregated_ts, fingerprint, app_name, node_id),
+	FAMILY (aggregated_ts, fingerprint, app_name, node_id, statistics)
+);`
+
+	// user_login_status stores the time after which the password of a user
+	// is no longer valid, set with VALID UNTIL, and the number of consecutive
+	// failed password logins of the user, used to lock users out.
+	UserLoginStatusTableSchema = `
+CREATE TABLE system.user_login_status (
+	username      STRING    PRIMARY KEY,
+	valid_until   TIMESTAMP,
+	failed_logins INT       NOT NULL,
+	last_failure  TIMESTAMP,
+	FAMILY (username, valid_until, failed_logins, last_failure)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -223,6 +278,10 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.TableStatisticsTableID: privilege.ReadWriteData,
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
+	keys.ProtectedTsTableID:     privilege.ReadWriteData,
+	keys.StatementStatsTableID:  privilege.ReadWriteData,
+	keys.TxnStatsTableID:        privilege.ReadWriteData,
+	keys.UserLoginStatusTableID: privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -232,6 +291,7 @@ var (
 	colTypeString    = ColumnType{SemanticType: ColumnType_STRING}
 	colTypeBytes     = ColumnType{SemanticType: ColumnType_BYTES}
 	colTypeTimestamp = ColumnType{SemanticType: ColumnType_TIMESTAMP}
+	colTypeDecimal   = ColumnType{SemanticType: ColumnType_DECIMAL}
 	colTypeIntArray  = ColumnType{SemanticType: ColumnType_ARRAY, ArrayContents: &colTypeInt.SemanticType,
 		ArrayDimensions: []int32{-1}}
 	singleASC = []IndexDescriptor_Direction{IndexDescriptor_ASC}
@@ -792,6 +852,143 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ProtectedTsTable is the descriptor for the protected_ts table.
+	ProtectedTsTable = TableDescriptor{
+		Name:     "protected_ts",
+		ID:       keys.ProtectedTsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "ts", ID: 2, Type: colTypeDecimal},
+			{Name: "job_id", ID: 3, Type: colTypeInt, Nullable: true},
+			{Name: "spans", ID: 4, Type: colTypeBytes},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_id_ts_job_id_spans",
+				ID:          0,
+				ColumnNames: []string{"id", "ts", "job_id", "spans"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ProtectedTsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementStatsTable is the descriptor for the statement_statistics table.
+	StatementStatsTable = TableDescriptor{
+		Name:     "statement_statistics",
+		ID:       keys.StatementStatsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: colTypeTimestamp},
+			{Name: "fingerprint", ID: 2, Type: colTypeString},
+			{Name: "flags", ID: 3, Type: colTypeString},
+			{Name: "app_name", ID: 4, Type: colTypeString},
+			{Name: "node_id", ID: 5, Type: colTypeInt},
+			{Name: "statistics", ID: 6, Type: colTypeBytes},
+		},
+		NextColumnID: 7,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_aggregated_ts_fingerprint_flags_app_name_node_id_statistics",
+				ID:          0,
+				ColumnNames: []string{"aggregated_ts", "fingerprint", "flags", "app_name", "node_id", "statistics"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5, 6},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "fingerprint", "flags", "app_name", "node_id"},
+			ColumnDirections: []IndexDescriptor_Direction{
+				IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC,
+			},
+			ColumnIDs: []ColumnID{1, 2, 3, 4, 5},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementStatsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// TxnStatsTable is the descriptor for the transaction_statistics table.
+	TxnStatsTable = TableDescriptor{
+		Name:     "transaction_statistics",
+		ID:       keys.TxnStatsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: colTypeTimestamp},
+			{Name: "fingerprint", ID: 2, Type: colTypeString},
+			{Name: "app_name", ID: 3, Type: colTypeString},
+			{Name: "node_id", ID: 4, Type: colTypeInt},
+			{Name: "statistics", ID: 5, Type: colTypeBytes},
+		},
+		NextColumnID: 6,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_aggregated_ts_fingerprint_app_name_node_id_statistics",
+				ID:          0,
+				ColumnNames: []string{"aggregated_ts", "fingerprint", "app_name", "node_id", "statistics"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "fingerprint", "app_name", "node_id"},
+			ColumnDirections: []IndexDescriptor_Direction{
+				IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC,
+			},
+			ColumnIDs: []ColumnID{1, 2, 3, 4},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.TxnStatsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// UserLoginStatusTable is the descriptor for the user_login_status table.
+	UserLoginStatusTable = TableDescriptor{
+		Name:     "user_login_status",
+		ID:       keys.UserLoginStatusTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "username", ID: 1, Type: colTypeString},
+			{Name: "valid_until", ID: 2, Type: colTypeTimestamp, Nullable: true},
+			{Name: "failed_logins", ID: 3, Type: colTypeInt},
+			{Name: "last_failure", ID: 4, Type: colTypeTimestamp, Nullable: true},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_username_valid_until_failed_logins_last_failure",
+				ID:          0,
+				ColumnNames: []string{"username", "valid_until", "failed_logins", "last_failure"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("username"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.UserLoginStatusTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -232,6 +232,13 @@ type tableUpserter struct {
 	// allocations.
 	updateValues tree.Datums
 
+	// policyUsing and policyCheck are set when row-level security applies
+	// to ON CONFLICT DO UPDATE. The conflicting row must satisfy the USING
+	// expressions of the UPDATE policies, and the updated row their WITH
+	// CHECK expressions.
+	policyUsing *sqlbase.CheckHelper
+	policyCheck *sqlbase.CheckHelper
+
 	// Set by init.
 	fkTables              sqlbase.TableLookupsByID // for fk checks in update case
 	ru                    sqlbase.RowUpdater
@@ -477,6 +484,18 @@ func (tu *tableUpserter) updateConflictingRow(
 	tableDesc *sqlbase.TableDescriptor,
 	traceKV bool,
 ) (resultRow tree.Datums, newExistingRows []tree.Datums, err error) {
+	// Verify that the row-level security policies, if any, allow the
+	// conflicting row to be updated.
+	if tu.policyUsing != nil {
+		if err := tu.policyUsing.LoadRow(
+			tu.fetchColIDtoRowIndex, conflictingRowValues, false); err != nil {
+			return nil, nil, err
+		}
+		if err := tu.policyUsing.Check(tu.evalCtx); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// First compute all the updates via SET (or the pseudo-SET generated
 	// for UPSERT statements).
 
@@ -523,6 +542,21 @@ func (tu *tableUpserter) updateConflictingRow(
 		}
 	}
 
+	// Verify that the updated row satisfies the row-level security
+	// policies, if any.
+	if tu.policyCheck != nil {
+		if err := tu.policyCheck.LoadRow(
+			tu.fetchColIDtoRowIndex, conflictingRowValues, false); err != nil {
+			return nil, nil, err
+		}
+		if err := tu.policyCheck.LoadRow(tu.updateColIDtoRowIndex, updateValues, true); err != nil {
+			return nil, nil, err
+		}
+		if err := tu.policyCheck.Check(tu.evalCtx); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Queue the update in KV. This also returns an "update row"
 	// containing the updated values for every column in the
 	// table. This is useful for RETURNING, which we collect below.
@@ -120,6 +120,10 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.TableStatisticsTableID, sqlbase.TableStatisticsTableSchema, sqlbase.TableStatisticsTable},
 		{keys.LocationsTableID, sqlbase.LocationsTableSchema, sqlbase.LocationsTable},
 		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
+		{keys.ProtectedTsTableID, sqlbase.ProtectedTsTableSchema, sqlbase.ProtectedTsTable},
+		{keys.StatementStatsTableID, sqlbase.StatementStatsTableSchema, sqlbase.StatementStatsTable},
+		{keys.TxnStatsTableID, sqlbase.TxnStatsTableSchema, sqlbase.TxnStatsTable},
+		{keys.UserLoginStatusTableID, sqlbase.UserLoginStatusTableSchema, sqlbase.UserLoginStatusTable},
 	} {
 		// Always create tables with "admin" privileges included, or CreateTestTableDescriptor fails.
 		privs := sqlbase.NewCustomSuperuserPrivilegeDescriptor(sqlbase.SystemAllowedPrivileges[test.id])
@@ -0,0 +1,40 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+)
+
+var _ tree.TimeSeriesQuerier = &planner{}
+
+// QueryTimeSeries is part of the tree.TimeSeriesQuerier interface.
+func (p *planner) QueryTimeSeries(
+	ctx context.Context, request *tspb.TimeSeriesQueryRequest,
+) (*tspb.TimeSeriesQueryResponse, func(context.Context), error) {
+	if err := p.RequireSuperUser(ctx, "query time series"); err != nil {
+		return nil, nil, err
+	}
+	tsServer := p.ExecCfg().TimeSeriesServer
+	if tsServer == nil {
+		return nil, nil, errors.New("time series are not available")
+	}
+	return tsServer.QueryTimeSeries(ctx, request)
+}
@@ -84,7 +84,10 @@ func (p *planner) Update(
 	if err != nil {
 		return nil, err
 	}
-	if err := p.CheckPrivilege(ctx, desc, privilege.UPDATE); err != nil {
+	// If UPDATE was only granted on some of the columns, the columns
+	// assigned to are checked below.
+	updateColErrs, err := p.CheckColumnPrivileges(ctx, desc, privilege.UPDATE)
+	if err != nil {
 		return nil, err
 	}
 
@@ -153,6 +156,9 @@ func (p *planner) Update(
 	if err != nil {
 		return nil, err
 	}
+	if err := checkPrivilegeOnColumns(desc, updateColErrs, updateCols); err != nil {
+		return nil, err
+	}
 
 	// Ensure that the columns being updated are not computed.
 	// We do this check as early as possible to avoid doing
@@ -186,8 +192,21 @@ func (p *planner) Update(
 	// rowsContainer.
 	rowsNeeded := resultsNeeded(n.Returning)
 
+	// Restrict the UPDATE to the rows that the row-level security policies
+	// of the table allow the current user to update, and verify that the
+	// updated rows satisfy them.
+	where, err := p.rowLevelSecurityWhere(ctx, desc, sqlbase.TableDescriptor_Policy_UPDATE, n.Where)
+	if err != nil {
+		return nil, err
+	}
+	checkHelper, err := p.makeMutationCheckHelper(
+		ctx, tn, desc, fkTables, sqlbase.TableDescriptor_Policy_UPDATE)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestedCols []sqlbase.ColumnDescriptor
-	if rowsNeeded || len(desc.Checks) > 0 {
+	if rowsNeeded || checkHelper.HasChecks() {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		// TODO(nvanbenschoten): This could be made tighter, just the rows needed for
@@ -221,7 +240,7 @@ func (p *planner) Update(
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
 		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols, true /* forUpdateOrDelete */),
 		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
-		Where: n.Where,
+		Where: where,
 	}, n.OrderBy, n.Limit, nil /* with */, nil /*desiredTypes*/, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
@@ -344,7 +363,7 @@ func (p *planner) Update(
 		columns: columns,
 		run: updateRun{
 			tu:           tableUpdater{ru: ru},
-			checkHelper:  fkTables[desc.ID].CheckHelper,
+			checkHelper:  checkHelper,
 			rowsNeeded:   rowsNeeded,
 			computedCols: computedCols,
 			computeExprs: computeExprs,
@@ -360,7 +379,7 @@ func (p *planner) Update(
 	}
 
 	// Finally, handle RETURNING, if any.
-	r, err := p.Returning(ctx, un, n.Returning, desiredTypes, alias)
+	r, err := p.Returning(ctx, un, n.Returning, desiredTypes, alias, desc)
 	if err != nil {
 		// We close explicitly here to release the node to the pool.
 		un.Close(ctx)
@@ -610,7 +629,7 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	// Run the CHECK constraints, if any.
 	// TODO(justin): we have actually constructed the whole row at this point and
 	// thus should be able to avoid loading it separately like this now.
-	if len(u.run.checkHelper.Exprs) > 0 {
+	if u.run.checkHelper.HasChecks() {
 		if err := u.run.checkHelper.LoadRow(
 			u.run.tu.ru.FetchColIDtoRowIndex, oldValues, false); err != nil {
 			return err
@@ -59,6 +59,7 @@ func (p *planner) newUpsertNode(
 	computeExprs []tree.TypedExpr,
 	computedCols []sqlbase.ColumnDescriptor,
 	fkTables sqlbase.TableLookupsByID,
+	checkHelper *sqlbase.CheckHelper,
 	desiredTypes []types.T,
 ) (res batchedPlanNode, err error) {
 	// Extract the index that will detect upsert conflicts
@@ -75,7 +76,7 @@ func (p *planner) newUpsertNode(
 		source:  sourceRows,
 		columns: resultCols,
 		run: upsertRun{
-			checkHelper:  fkTables[desc.ID].CheckHelper,
+			checkHelper:  checkHelper,
 			insertCols:   ri.InsertCols,
 			defaultExprs: defaultExprs,
 			computedCols: computedCols,
@@ -161,6 +162,32 @@ func (p *planner) newUpsertNode(
 			return nil, err
 		}
 
+		// When row-level security applies, the rows updated on conflict
+		// must be allowed by the UPDATE policies of the table, both before
+		// and after the update.
+		var policyUsing, policyCheck *sqlbase.CheckHelper
+		usingExpr, err := p.RowLevelSecurityFilter(ctx, desc, sqlbase.TableDescriptor_Policy_UPDATE)
+		if err != nil {
+			return nil, err
+		}
+		if usingExpr != nil {
+			policyUsing, err = p.makePolicyCheckHelper(
+				ctx, tn, desc, usingExpr, false /* withConstraints */)
+			if err != nil {
+				return nil, err
+			}
+			checkExpr, err := p.rowLevelSecurityExpr(
+				ctx, desc, sqlbase.TableDescriptor_Policy_UPDATE, true /* withCheck */)
+			if err != nil {
+				return nil, err
+			}
+			policyCheck, err = p.makePolicyCheckHelper(
+				ctx, tn, desc, checkExpr, false /* withConstraints */)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// Determine whether to use the fast path or the slow path.
 		// TODO(dan): The fast path is currently only enabled when the UPSERT alias
 		// is explicitly selected by the user. It's possible to fast path some
@@ -182,7 +209,10 @@ func (p *planner) newUpsertNode(
 			len(ri.InsertCols) == len(desc.Columns) &&
 			// We cannot use the fast path if we also have a RETURNING clause, because
 			// RETURNING wants to see only the updated rows.
-			!needRows
+			!needRows &&
+			// The fast path blindly overwrites the existing rows, so it cannot
+			// enforce the row-level security policies.
+			policyUsing == nil
 
 		if enableFastPath {
 			// We then use the super-simple, super-fast writer. There's not
@@ -205,6 +235,8 @@ func (p *planner) newUpsertNode(
 				updateCols:    updateCols,
 				conflictIndex: *conflictIndex,
 				evaler:        helper,
+				policyUsing:   policyUsing,
+				policyCheck:   policyCheck,
 			}
 		}
 	}
@@ -362,7 +394,7 @@ func (n *upsertNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	}
 
 	// Run the CHECK constraints, if any.
-	if len(n.run.checkHelper.Exprs) > 0 {
+	if n.run.checkHelper.HasChecks() {
 		insertColIDtoRowIndex := n.run.iVarContainerForComputedCols.Mapping
 		if err := n.run.checkHelper.LoadRow(insertColIDtoRowIndex, rowVals, false); err != nil {
 			return err
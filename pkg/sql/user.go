@@ -16,13 +16,76 @@ package sql
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
 
+// The methods which clients can use to authenticate with a password.
+const (
+	// PasswordMethodSCRAM is the SASL SCRAM-SHA-256 exchange, in which the
+	// password doesn't travel to the server.
+	PasswordMethodSCRAM = "scram-sha-256"
+	// PasswordMethodCleartext is the exchange in which the client sends its
+	// password to the server.
+	PasswordMethodCleartext = "cleartext"
+)
+
+// passwordMethods is the list of the methods which clients can use to
+// authenticate with a password.
+var passwordMethods = settings.RegisterValidatedStringSetting(
+	"server.user_login.password_methods",
+	"comma-separated list of the methods clients can use to authenticate with a password "+
+		"(scram-sha-256, cleartext); when scram-sha-256 is listed, passwords are stored as "+
+		"SCRAM-SHA-256 verifiers and bcrypt hashes are upgraded on the next cleartext login",
+	PasswordMethodCleartext,
+	func(_ *settings.Values, s string) error {
+		_, err := parsePasswordMethods(s)
+		return err
+	},
+)
+
+// PasswordMethods is the set of accepted password authentication methods.
+type PasswordMethods struct {
+	SCRAM     bool
+	Cleartext bool
+}
+
+func parsePasswordMethods(s string) (PasswordMethods, error) {
+	var m PasswordMethods
+	for _, method := range strings.Split(s, ",") {
+		switch strings.TrimSpace(method) {
+		case PasswordMethodSCRAM:
+			m.SCRAM = true
+		case PasswordMethodCleartext:
+			m.Cleartext = true
+		default:
+			return m, errors.Errorf("unknown password authentication method %q", method)
+		}
+	}
+	return m, nil
+}
+
+// GetPasswordMethods returns the accepted password authentication methods.
+func GetPasswordMethods(sv *settings.Values) PasswordMethods {
+	// The setting is validated when it is set.
+	m, _ := parsePasswordMethods(passwordMethods.Get(sv))
+	return m
+}
+
+// hashPassword hashes a new password, as a SCRAM-SHA-256 verifier if SCRAM
+// authentication is accepted and with bcrypt otherwise.
+func hashPassword(sv *settings.Values, password string) ([]byte, error) {
+	if GetPasswordMethods(sv).SCRAM {
+		return security.HashPasswordSCRAM(password)
+	}
+	return security.HashPassword(password)
+}
+
 // GetUserHashedPassword returns the hashedPassword for the given username if
 // found in system.users.
 func GetUserHashedPassword(
@@ -48,6 +111,23 @@ func GetUserHashedPassword(
 	return true, hashedPassword, nil
 }
 
+// UpgradeUserHashedPassword replaces the hashed password of a user with
+// newHashedPassword, unless the password was changed since oldHashedPassword
+// was read.
+func UpgradeUserHashedPassword(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	username string,
+	oldHashedPassword, newHashedPassword []byte,
+) error {
+	const upgradeHashedPassword = `UPDATE system.users SET "hashedPassword" = $3 ` +
+		`WHERE username = $1 AND "hashedPassword" = $2 AND "isRole" = false`
+	_, err := execCfg.InternalExecutor.Exec(
+		ctx, "upgrade-hashed-pwd", nil /* txn */, upgradeHashedPassword,
+		tree.Name(username).Normalize(), oldHashedPassword, newHashedPassword)
+	return errors.Wrapf(err, "error upgrading the password of user %s", username)
+}
+
 // The map value is true if the map key is a role, false if it is a user.
 func (p *planner) GetAllUsersAndRoles(ctx context.Context) (map[string]bool, error) {
 	query := `SELECT username,"isRole"  FROM system.users`
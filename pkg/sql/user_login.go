@@ -0,0 +1,267 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The password policy is enforced when a password is set with CREATE USER or
+// ALTER USER. Passwords which were set before the policy was changed remain
+// valid.
+var minPasswordLength = settings.RegisterNonNegativeIntSetting(
+	"server.user_login.min_password_length",
+	"the minimum number of characters of user passwords",
+	1,
+)
+
+var minPasswordCharClasses = settings.RegisterValidatedIntSetting(
+	"server.user_login.min_password_character_classes",
+	"the minimum number of character classes (lowercase letters, uppercase letters, digits, "+
+		"other characters) user passwords must contain",
+	0,
+	func(v int64) error {
+		if v < 0 || v > 4 {
+			return errors.Errorf("cannot set server.user_login.min_password_character_classes to %d, "+
+				"must be between 0 and 4", v)
+		}
+		return nil
+	},
+)
+
+var maxFailedLogins = settings.RegisterNonNegativeIntSetting(
+	"server.user_login.lockout.max_failed_attempts",
+	"the number of consecutive failed password logins after which a user is locked out "+
+		"(0 disables the lockout)",
+	0,
+)
+
+var lockoutDuration = settings.RegisterNonNegativeDurationSetting(
+	"server.user_login.lockout.duration",
+	"the duration of the lockout of a user after too many failed password logins "+
+		"(0 locks the user out until ALTER USER ... UNLOCK)",
+	0,
+)
+
+// checkPasswordPolicy returns an error if the password does not satisfy the
+// password policy configured by the cluster settings.
+func checkPasswordPolicy(sv *settings.Values, password string) error {
+	err := security.CheckPasswordPolicy(
+		password, int(minPasswordLength.Get(sv)), int(minPasswordCharClasses.Get(sv)),
+	)
+	if err != nil {
+		return pgerror.NewError(pgerror.CodeInvalidPasswordError, err.Error())
+	}
+	return nil
+}
+
+// parseValidUntil interprets the timestamp of a VALID UNTIL clause. The
+// password never expires if the timestamp is 'infinity', in which case
+// DNull is returned.
+func parseValidUntil(s string) (tree.Datum, error) {
+	if strings.EqualFold(s, "infinity") {
+		return tree.DNull, nil
+	}
+	d, err := tree.ParseDTimestamp(s, time.Microsecond)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid VALID UNTIL timestamp")
+	}
+	return d, nil
+}
+
+// setUserValidUntil sets the expiration of the password of a user.
+func setUserValidUntil(params runParams, username string, validUntil tree.Datum) error {
+	_, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
+		params.ctx,
+		"set-user-valid-until",
+		params.p.txn,
+		`INSERT INTO system.user_login_status (username, valid_until, failed_logins) VALUES ($1, $2, 0) `+
+			`ON CONFLICT (username) DO UPDATE SET valid_until = excluded.valid_until`,
+		username,
+		validUntil,
+	)
+	return err
+}
+
+// userLoginStatus is the content of the system.user_login_status row of a
+// user.
+type userLoginStatus struct {
+	// validUntil is the expiration of the password; zero if it never
+	// expires.
+	validUntil   time.Time
+	failedLogins int64
+	lastFailure  time.Time
+}
+
+// lockedOut returns whether the user cannot log in with a password because
+// of too many failed logins.
+func (s userLoginStatus) lockedOut(sv *settings.Values, now time.Time) bool {
+	max := maxFailedLogins.Get(sv)
+	if max == 0 || s.failedLogins < max {
+		return false
+	}
+	d := lockoutDuration.Get(sv)
+	return d == 0 || now.Sub(s.lastFailure) < d
+}
+
+func getUserLoginStatus(
+	ctx context.Context, execCfg *ExecutorConfig, txn *client.Txn, username string,
+) (userLoginStatus, error) {
+	var status userLoginStatus
+	row, err := execCfg.InternalExecutor.QueryRow(
+		ctx, "get-user-login-status", txn,
+		`SELECT valid_until, failed_logins, last_failure FROM system.user_login_status WHERE username = $1`,
+		username,
+	)
+	if err != nil {
+		return status, errors.Wrapf(err, "error looking up the login status of user %s", username)
+	}
+	if row == nil {
+		return status, nil
+	}
+	if t, ok := row[0].(*tree.DTimestamp); ok {
+		status.validUntil = t.Time
+	}
+	status.failedLogins = int64(tree.MustBeDInt(row[1]))
+	if t, ok := row[2].(*tree.DTimestamp); ok {
+		status.lastFailure = t.Time
+	}
+	return status, nil
+}
+
+// CheckUserLoginStatus returns an error if the user is not allowed to log in
+// with a password, because the password has expired or because the user is
+// locked out after too many failed logins.
+//
+// Otherwise, if the lockout is enabled, the login attempt is counted as a
+// failure in the same transaction as the check, until ResetUserFailedLogins
+// records its success. Concurrent or abandoned attempts thus cannot exceed the
+// number of failed logins allowed before the lockout.
+func CheckUserLoginStatus(ctx context.Context, execCfg *ExecutorConfig, username string) error {
+	username = tree.Name(username).Normalize()
+	if username == security.RootUser {
+		return nil
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		status, err := getUserLoginStatus(ctx, execCfg, txn, username)
+		if err != nil {
+			return err
+		}
+		sv := &execCfg.Settings.SV
+		now := timeutil.Now()
+		if status.lockedOut(sv, now) {
+			return pgerror.NewErrorf(pgerror.CodeInvalidAuthorizationSpecificationError,
+				"user %s is locked out after %d failed login attempts", username, status.failedLogins)
+		}
+		if !status.validUntil.IsZero() && !now.Before(status.validUntil) {
+			return pgerror.NewErrorf(pgerror.CodeInvalidPasswordError,
+				"the password of user %s has expired", username)
+		}
+		if maxFailedLogins.Get(sv) == 0 {
+			return nil
+		}
+		return countUserLoginAttempt(ctx, execCfg, txn, username, status, now)
+	})
+}
+
+// RecordUserLoginFailure records a failed login in the event log. The login
+// attempt was already counted towards the lockout of the user by
+// CheckUserLoginStatus. If wrongPassword is set, info is updated with the
+// number of consecutive failures.
+func RecordUserLoginFailure(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	info *EventLogUserLoginFailureDetail,
+	wrongPassword bool,
+) error {
+	username := tree.Name(info.User).Normalize()
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if wrongPassword && username != security.RootUser {
+			status, err := getUserLoginStatus(ctx, execCfg, txn, username)
+			if err != nil {
+				return err
+			}
+			info.FailedLogins = status.failedLogins
+			info.LockedOut = status.lockedOut(&execCfg.Settings.SV, timeutil.Now())
+		}
+		return MakeEventLogger(execCfg).InsertEventRecord(
+			ctx,
+			txn,
+			EventLogUserLoginFailure,
+			0, /* no target */
+			int32(execCfg.NodeID.Get()),
+			info,
+		)
+	})
+}
+
+// countUserLoginAttempt counts a login attempt of a user who is not locked
+// out as a failure.
+func countUserLoginAttempt(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	txn *client.Txn,
+	username string,
+	status userLoginStatus,
+	now time.Time,
+) error {
+	// Only existing users are tracked.
+	row, err := execCfg.InternalExecutor.QueryRow(
+		ctx, "get-user", txn,
+		`SELECT 1 FROM system.users WHERE username = $1 AND "isRole" = false`, username,
+	)
+	if err != nil || row == nil {
+		return err
+	}
+
+	// Failures start over when a lockout expires.
+	if max := maxFailedLogins.Get(&execCfg.Settings.SV); max > 0 && status.failedLogins >= max {
+		status.failedLogins = 0
+	}
+	_, err = execCfg.InternalExecutor.Exec(
+		ctx, "count-login-attempt", txn,
+		`INSERT INTO system.user_login_status (username, failed_logins, last_failure) VALUES ($1, $2, $3) `+
+			`ON CONFLICT (username) DO UPDATE `+
+			`SET failed_logins = excluded.failed_logins, last_failure = excluded.last_failure`,
+		username, status.failedLogins+1, now,
+	)
+	return err
+}
+
+// ResetUserFailedLogins forgets the failed logins of a user after a
+// successful login. The failed logins are not counted, and thus not reset,
+// while the lockout is disabled.
+func ResetUserFailedLogins(ctx context.Context, execCfg *ExecutorConfig, username string) error {
+	if maxFailedLogins.Get(&execCfg.Settings.SV) == 0 {
+		return nil
+	}
+	_, err := execCfg.InternalExecutor.Exec(
+		ctx, "reset-failed-logins", nil, /* txn */
+		`UPDATE system.user_login_status SET failed_logins = 0 WHERE username = $1 AND failed_logins > 0`,
+		tree.Name(username).Normalize(),
+	)
+	return errors.Wrapf(err, "error resetting the failed logins of user %s", username)
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestCheckUserLoginStatusLockoutDisabled verifies that login attempts don't
+// write to system.user_login_status while the lockout is disabled.
+func TestCheckUserLoginStatusLockoutDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(sql.ExecutorConfig)
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `CREATE USER testuser WITH PASSWORD 'abc'`)
+	for i := 0; i < 3; i++ {
+		if err := sql.CheckUserLoginStatus(ctx, &execCfg, "testuser"); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+	}
+	if err := sql.ResetUserFailedLogins(ctx, &execCfg, "testuser"); err != nil {
+		t.Fatal(err)
+	}
+	r.CheckQueryResults(t,
+		`SELECT count(*) FROM system.user_login_status WHERE username = 'testuser'`,
+		[][]string{{"0"}},
+	)
+}
+
+// TestCheckUserLoginStatusConcurrent verifies that concurrent login attempts
+// cannot exceed the number of failed logins allowed before the lockout, and
+// that successful logins do not count towards it.
+func TestCheckUserLoginStatusConcurrent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(sql.ExecutorConfig)
+
+	const maxFailedLogins = 3
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `CREATE USER testuser WITH PASSWORD 'abc'`)
+	r.Exec(t, `SET CLUSTER SETTING server.user_login.lockout.max_failed_attempts = $1`, maxFailedLogins)
+	testutils.SucceedsSoon(t, func() error {
+		var v string
+		r.QueryRow(t, `SHOW CLUSTER SETTING server.user_login.lockout.max_failed_attempts`).Scan(&v)
+		if v != "3" {
+			return errors.Errorf("setting not yet propagated: %s", v)
+		}
+		return nil
+	})
+
+	// Attempts which are not followed by a successful login count as
+	// failures, however concurrent they are.
+	const numAttempts = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, numAttempts)
+	for i := 0; i < numAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- sql.CheckUserLoginStatus(ctx, &execCfg, "testuser")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	allowed := 0
+	for err := range errs {
+		if err == nil {
+			allowed++
+		} else if !testutils.IsError(err, "user testuser is locked out after 3 failed login attempts") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if allowed != maxFailedLogins {
+		t.Fatalf("expected %d login attempts to be allowed, got %d", maxFailedLogins, allowed)
+	}
+
+	// Once unlocked, the attempts which succeed are forgotten.
+	r.Exec(t, `ALTER USER testuser UNLOCK`)
+	for i := 0; i < 2*maxFailedLogins; i++ {
+		if err := sql.CheckUserLoginStatus(ctx, &execCfg, "testuser"); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if err := sql.ResetUserFailedLogins(ctx, &execCfg, "testuser"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -370,6 +370,9 @@ func (v *planVisitor) visit(plan planNode) {
 			for i, cexpr := range n.run.checkHelper.Exprs {
 				v.expr(name, "check", i, cexpr)
 			}
+			for i, pexpr := range n.run.checkHelper.PolicyExprs {
+				v.expr(name, "policy", i, pexpr)
+			}
 		}
 		v.visit(n.source)
 
@@ -395,6 +398,9 @@ func (v *planVisitor) visit(plan planNode) {
 			for i, cexpr := range n.run.checkHelper.Exprs {
 				v.expr(name, "check", i, cexpr)
 			}
+			for i, pexpr := range n.run.checkHelper.PolicyExprs {
+				v.expr(name, "policy", i, pexpr)
+			}
 			n.run.tw.walkExprs(func(d string, i int, e tree.TypedExpr) {
 				v.expr(name, d, i, e)
 			})
@@ -422,6 +428,9 @@ func (v *planVisitor) visit(plan planNode) {
 			for i, cexpr := range n.run.checkHelper.Exprs {
 				v.expr(name, "check", i, cexpr)
 			}
+			for i, pexpr := range n.run.checkHelper.PolicyExprs {
+				v.expr(name, "policy", i, pexpr)
+			}
 		}
 		// An updater has no sub-expressions, so nothing special to do here.
 		v.visit(n.source)
@@ -578,11 +587,13 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
 	reflect.TypeOf(&alterTableNode{}):           "alter table",
 	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
+	reflect.TypeOf(&alterUserUnlockNode{}):      "alter user",
 	reflect.TypeOf(&cancelQueriesNode{}):        "cancel queries",
 	reflect.TypeOf(&cancelSessionsNode{}):       "cancel sessions",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
+	reflect.TypeOf(&createPolicyNode{}):         "create policy",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
 	reflect.TypeOf(&createStatsNode{}):          "create statistics",
 	reflect.TypeOf(&createTableNode{}):          "create table",
@@ -593,6 +604,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&distinctNode{}):             "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
 	reflect.TypeOf(&dropIndexNode{}):            "drop index",
+	reflect.TypeOf(&dropPolicyNode{}):           "drop policy",
 	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
 	reflect.TypeOf(&dropTableNode{}):            "drop table",
 	reflect.TypeOf(&DropUserNode{}):             "drop user/role",
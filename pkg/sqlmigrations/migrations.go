@@ -190,6 +190,24 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "add progress to system.jobs",
 		workFn: addJobsProgress,
 	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.protected_ts table",
+		workFn:           createProtectedTsTable,
+		newDescriptorIDs: staticIDs(keys.ProtectedTsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.statement_statistics and system.transaction_statistics tables",
+		workFn:           createStatsTables,
+		newDescriptorIDs: staticIDs(keys.StatementStatsTableID, keys.TxnStatsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.user_login_status table",
+		workFn:           createUserLoginStatusTable,
+		newDescriptorIDs: staticIDs(keys.UserLoginStatusTableID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -503,6 +521,21 @@ func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescript
 	return err
 }
 
+func createProtectedTsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ProtectedTsTable)
+}
+
+func createStatsTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, sqlbase.StatementStatsTable); err != nil {
+		return err
+	}
+	return createSystemTable(ctx, r, sqlbase.TxnStatsTable)
+}
+
+func createUserLoginStatusTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.UserLoginStatusTable)
+}
+
 var reportingOptOut = envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
 
 func runStmtAsRootWithRetry(
@@ -208,7 +208,7 @@ func evalEndTransaction(
 		reply.Txn.Intents = args.IntentSpans
 		return result.FromEndTxn(reply.Txn, true /* alwaysReturn */, args.Poison), roachpb.NewTransactionAbortedError()
 
-	case roachpb.PENDING:
+	case roachpb.PENDING, roachpb.STAGING:
 		if h.Txn.Epoch < reply.Txn.Epoch {
 			// TODO(tschottdorf): this leaves the Txn record (and more
 			// importantly, intents) dangling; we can't currently write on
@@ -262,6 +262,28 @@ func evalEndTransaction(
 				"transaction deadline exceeded")
 		}
 
+		// If the coordinator sent this EndTransaction in parallel with some of
+		// the transaction's writes, we can't tell whether those writes will
+		// succeed. Move the transaction record to STAGING instead of committing
+		// it; the transaction is committed once all of the in-flight writes
+		// are proven to have succeeded at or below the record's timestamp,
+		// either by the coordinator or by a pusher running RecoverTxn.
+		if len(args.InFlightWrites) > 0 {
+			if args.InternalCommitTrigger != nil {
+				return result.Result{}, roachpb.NewTransactionStatusError(
+					"cannot stage a transaction with a commit trigger")
+			}
+			reply.Txn.Status = roachpb.STAGING
+			reply.Txn.InFlightWrites = args.InFlightWrites
+			reply.Txn.Intents = args.IntentSpans
+			if err := engine.MVCCPutProto(
+				ctx, batch, ms, key, hlc.Timestamp{}, nil /* txn */, reply.Txn,
+			); err != nil {
+				return result.Result{}, err
+			}
+			return result.Result{}, nil
+		}
+
 		reply.Txn.Status = roachpb.COMMITTED
 
 		// Merge triggers must run before intent resolution as the merge trigger
@@ -287,6 +309,7 @@ func evalEndTransaction(
 	} else {
 		reply.Txn.Status = roachpb.ABORTED
 	}
+	reply.Txn.InFlightWrites = nil
 
 	desc := cArgs.EvalCtx.Desc()
 	externalIntents, err := resolveLocalIntents(ctx, desc, batch, ms, *args, reply.Txn, cArgs.EvalCtx)
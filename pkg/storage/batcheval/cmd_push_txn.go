@@ -57,6 +57,11 @@ func declareKeysPushTransaction(
 // Txn already committed/aborted: If pushee txn is committed or
 // aborted return success.
 //
+// Txn staging: If pushee txn is STAGING, it can't be pushed. If it
+// has expired, return success with the unchanged STAGING txn; the
+// pusher must then recover the txn's outcome with RecoverTxn.
+// Otherwise return TransactionPushError.
+//
 // Txn Timeout: If pushee txn entry isn't present or its LastHeartbeat
 // timestamp isn't set, use its as LastHeartbeat. If current time -
 // LastHeartbeat > 2 * DefaultHeartbeatInterval, then the pushee txn
@@ -154,7 +159,7 @@ func PushTxn(
 	reply.PusheeTxn = existTxn.Clone()
 
 	// If already committed or aborted, return success.
-	if reply.PusheeTxn.Status != roachpb.PENDING {
+	if reply.PusheeTxn.Status.IsFinalized() {
 		// Trivial noop.
 		return result.Result{}, nil
 	}
@@ -166,6 +171,19 @@ func PushTxn(
 		return result.Result{}, nil
 	}
 
+	// A STAGING transaction may already be implicitly committed, so neither
+	// its timestamp nor its status can be changed by a push. If its
+	// coordinator stopped heartbeating it, return the record unchanged and
+	// leave it to the pusher to recover the transaction's outcome using
+	// RecoverTxn. Otherwise, the pusher must wait for the coordinator to
+	// finalize the transaction, regardless of its priority.
+	if reply.PusheeTxn.Status == roachpb.STAGING {
+		if txnwait.IsExpired(args.Now, &reply.PusheeTxn) {
+			return result.Result{}, nil
+		}
+		return result.Result{}, roachpb.NewTransactionPushError(reply.PusheeTxn)
+	}
+
 	// The pusher might be aware of a newer version of the pushee.
 	reply.PusheeTxn.Timestamp.Forward(args.PusheeTxn.Timestamp)
 	if reply.PusheeTxn.Epoch < args.PusheeTxn.Epoch {
@@ -0,0 +1,99 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterCommand(roachpb.RecoverTxn, declareKeysRecoverTransaction, RecoverTxn)
+}
+
+func declareKeysRecoverTransaction(
+	_ roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	rr := req.(*roachpb.RecoverTxnRequest)
+	spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.TransactionKey(rr.Txn.Key, rr.Txn.ID)})
+}
+
+// RecoverTxn finalizes the record of a STAGING transaction whose
+// coordinator stopped heartbeating it. The caller is expected to have
+// queried all of the transaction's in-flight writes using QueryIntent
+// requests with IfMissing set to PREVENT, at the timestamp of the
+// staging record. If all of them were found, the transaction is
+// implicitly committed and its record is moved to COMMITTED. Otherwise,
+// the missing write can never succeed at the staging timestamp, so the
+// transaction can't have committed and its record is moved to ABORTED.
+//
+// If the record was finalized or restaged (at a new epoch or timestamp)
+// in the meantime, the outcome of the queries no longer applies and the
+// record is returned unchanged.
+func RecoverTxn(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.RecoverTxnRequest)
+	reply := resp.(*roachpb.RecoverTxnResponse)
+
+	if cArgs.Header.Txn != nil {
+		return result.Result{}, ErrTransactionUnsupported
+	}
+	if !bytes.Equal(args.Key, args.Txn.Key) {
+		return result.Result{}, errors.Errorf("request key %s does not match txn key %s", args.Key, args.Txn.Key)
+	}
+	key := keys.TransactionKey(args.Txn.Key, args.Txn.ID)
+
+	ok, err := engine.MVCCGetProto(ctx, batch, key, hlc.Timestamp{},
+		true /* consistent */, nil /* txn */, &reply.RecoveredTxn)
+	if err != nil {
+		return result.Result{}, err
+	} else if !ok {
+		// A STAGING record is never removed before it is finalized, so the
+		// transaction must have been committed explicitly and cleaned up.
+		return result.Result{}, roachpb.NewTransactionNotFoundStatusError()
+	}
+	txn := &reply.RecoveredTxn
+	if txn.Status != roachpb.STAGING ||
+		txn.Epoch != args.Txn.Epoch || args.Txn.Timestamp.Less(txn.Timestamp) {
+		return result.Result{}, nil
+	}
+
+	if args.ImplicitlyCommitted {
+		txn.Status = roachpb.COMMITTED
+	} else {
+		txn.Status = roachpb.ABORTED
+	}
+	txn.InFlightWrites = nil
+	if log.V(1) {
+		log.Infof(ctx, "recovered staging transaction %s as %s", txn.Short(), txn.Status)
+	}
+	if err := engine.MVCCPutProto(ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil, txn); err != nil {
+		return result.Result{}, err
+	}
+	// The coordinator is gone, so resolve all of the transaction's intents.
+	res := result.FromEndTxn(txn, false /* alwaysReturn */, false /* poison */)
+	res.Local.UpdatedTxns = &[]*roachpb.Transaction{txn}
+	return res, nil
+}
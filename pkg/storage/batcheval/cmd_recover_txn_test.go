@@ -0,0 +1,149 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestStagingTxn verifies that a STAGING transaction can't be pushed and
+// that RecoverTxn finalizes its record according to the outcome of the
+// queries of its in-flight writes.
+func TestStagingTxn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	ts := hlc.Timestamp{WallTime: 10}
+	key := roachpb.Key("a")
+	stage := func(t *testing.T, eng engine.Engine) roachpb.Transaction {
+		t.Helper()
+		txn := roachpb.MakeTransaction("test", key, 0, enginepb.SERIALIZABLE, ts, 0)
+		txn.Status = roachpb.STAGING
+		txn.InFlightWrites = []roachpb.SequencedWrite{{Key: roachpb.Key("b"), Sequence: 1}}
+		txnKey := keys.TransactionKey(txn.Key, txn.ID)
+		if err := engine.MVCCPutProto(ctx, eng, nil, txnKey, hlc.Timestamp{}, nil, &txn); err != nil {
+			t.Fatal(err)
+		}
+		return txn
+	}
+
+	t.Run("push", func(t *testing.T) {
+		eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+		defer eng.Close()
+		txn := stage(t, eng)
+
+		push := func(now hlc.Timestamp) (roachpb.PushTxnResponse, error) {
+			var resp roachpb.PushTxnResponse
+			_, err := PushTxn(ctx, eng, CommandArgs{
+				Args: &roachpb.PushTxnRequest{
+					RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+					PusherTxn: roachpb.Transaction{
+						TxnMeta: enginepb.TxnMeta{Priority: roachpb.MaxTxnPriority},
+					},
+					PusheeTxn: txn.TxnMeta,
+					Now:       now,
+					PushType:  roachpb.PUSH_ABORT,
+					Force:     true,
+				},
+				Stats: &enginepb.MVCCStats{},
+			}, &resp)
+			return resp, err
+		}
+
+		// A live STAGING txn can't be pushed, even with a forced push.
+		if _, err := push(ts.Next()); err == nil {
+			t.Fatal("expected push of staging txn to fail")
+		} else if _, ok := err.(*roachpb.TransactionPushError); !ok {
+			t.Fatalf("expected TransactionPushError, got %T: %v", err, err)
+		}
+
+		// An expired STAGING txn is returned unchanged.
+		expired := ts.Add(2*txnwait.TxnLivenessThreshold.Nanoseconds(), 0)
+		resp, err := push(expired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.PusheeTxn.Equal(&txn) {
+			t.Fatalf("expected unchanged staging txn %s, got %s", txn, resp.PusheeTxn)
+		}
+	})
+
+	testCases := []struct {
+		name                string
+		implicitlyCommitted bool
+		// Modifies the recovered transaction to make it stale.
+		mutate    func(*enginepb.TxnMeta)
+		expStatus roachpb.TransactionStatus
+	}{
+		{"committed", true, nil, roachpb.COMMITTED},
+		{"aborted", false, nil, roachpb.ABORTED},
+		{"new epoch", true, func(m *enginepb.TxnMeta) { m.Epoch++ }, roachpb.STAGING},
+		{"new timestamp", false, func(m *enginepb.TxnMeta) { m.Timestamp = ts.Prev() }, roachpb.STAGING},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+			txn := stage(t, eng)
+
+			meta := txn.TxnMeta
+			if tc.mutate != nil {
+				tc.mutate(&meta)
+			}
+			var resp roachpb.RecoverTxnResponse
+			res, err := RecoverTxn(ctx, eng, CommandArgs{
+				Args: &roachpb.RecoverTxnRequest{
+					RequestHeader:       roachpb.RequestHeader{Key: txn.Key},
+					Txn:                 meta,
+					ImplicitlyCommitted: tc.implicitlyCommitted,
+				},
+				Stats: &enginepb.MVCCStats{},
+			}, &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := resp.RecoveredTxn.Status; s != tc.expStatus {
+				t.Fatalf("expected status %s, got %s", tc.expStatus, s)
+			}
+
+			var persisted roachpb.Transaction
+			txnKey := keys.TransactionKey(txn.Key, txn.ID)
+			if _, err := engine.MVCCGetProto(
+				ctx, eng, txnKey, hlc.Timestamp{}, true, nil, &persisted,
+			); err != nil {
+				t.Fatal(err)
+			}
+			if !persisted.Equal(&resp.RecoveredTxn) {
+				t.Fatalf("expected persisted txn %s, got %s", resp.RecoveredTxn, persisted)
+			}
+			finalized := tc.expStatus.IsFinalized()
+			if finalized != (len(persisted.InFlightWrites) == 0) {
+				t.Fatalf("unexpected in-flight writes in %s: %v", persisted.Status, persisted.InFlightWrites)
+			}
+			if finalized != (res.Local.UpdatedTxns != nil) {
+				t.Fatalf("expected updated txns only for finalized txn, got %v", res.Local.UpdatedTxns)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -43,6 +44,7 @@ type mockEvalCtx struct {
 	stats           enginepb.MVCCStats
 	abortSpan       *abortspan.AbortSpan
 	gcThreshold     hlc.Timestamp
+	protectedTS     hlc.Timestamp
 }
 
 func (m *mockEvalCtx) String() string {
@@ -75,6 +77,9 @@ func (m *mockEvalCtx) AbortSpan() *abortspan.AbortSpan {
 func (m *mockEvalCtx) GetTxnWaitQueue() *txnwait.Queue {
 	panic("unimplemented")
 }
+func (m *mockEvalCtx) GetLockTable() *locktable.Table {
+	panic("unimplemented")
+}
 func (m *mockEvalCtx) NodeID() roachpb.NodeID {
 	panic("unimplemented")
 }
@@ -108,6 +113,9 @@ func (m *mockEvalCtx) GetGCThreshold() hlc.Timestamp {
 func (m *mockEvalCtx) GetTxnSpanGCThreshold() hlc.Timestamp {
 	panic("unimplemented")
 }
+func (m *mockEvalCtx) GetProtectedTimestamp() (hlc.Timestamp, bool) {
+	return m.protectedTS, true
+}
 func (m *mockEvalCtx) GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error) {
 	panic("unimplemented")
 }
@@ -25,7 +25,7 @@ import (
 )
 
 func init() {
-	RegisterCommand(roachpb.ReverseScan, DefaultDeclareKeys, ReverseScan)
+	RegisterCommand(roachpb.ReverseScan, declareKeysScan, ReverseScan)
 }
 
 // ReverseScan scans the key range specified by start key through
@@ -39,6 +39,21 @@ func ReverseScan(
 	h := cArgs.Header
 	reply := resp.(*roachpb.ReverseScanResponse)
 
+	if args.KeyLocking != roachpb.LOCK_NONE && args.LockWaitPolicy == roachpb.LOCK_WAIT_SKIP {
+		res, err := scanSkipLocked(ctx, batch, cArgs, args.ScanFormat, true /* reverse */)
+		if err != nil {
+			return result.Result{}, err
+		}
+		reply.NumKeys = res.numKeys
+		reply.Rows = res.rows
+		reply.BatchResponse = res.kvData
+		if res.resumeSpan != nil {
+			reply.ResumeSpan = res.resumeSpan
+			reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		}
+		return result.Result{}, nil
+	}
+
 	var err error
 	var intents []roachpb.Intent
 	var resumeSpan *roachpb.Span
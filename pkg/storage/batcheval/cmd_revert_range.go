@@ -0,0 +1,116 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func init() {
+	RegisterCommand(roachpb.RevertRange, declareKeysRevertRange, RevertRange)
+}
+
+func declareKeysRevertRange(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	DefaultDeclareKeys(desc, header, req, spans)
+	// We look up the range descriptor key to check whether the span is equal
+	// to the entire range, in which case the GC threshold may be advanced.
+	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(desc.StartKey)})
+	spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeLastGCKey(header.RangeID)})
+}
+
+// RevertRange reverts the keys in the specified span to their state as of
+// the request's target time. See engine.MVCCRevertRange for details.
+//
+// If the span covers the entire range and none of its keys existed at the
+// target time, the span is cleared using a range deletion (as ClearRange
+// does) and the GC threshold is advanced to the request timestamp so that
+// reads can no longer observe the history that was removed. This is not done
+// if a protected timestamp record protects a timestamp at or below the
+// request timestamp, in which case deletion tombstones are written instead.
+//
+// Otherwise, at most MaxSpanRequestKeys keys are reverted and the span to
+// resume from is returned.
+func RevertRange(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	if cArgs.Header.Txn != nil {
+		return result.Result{}, errors.New("cannot execute RevertRange within a transaction")
+	}
+	log.VEventf(ctx, 2, "RevertRange %+v", cArgs.Args)
+
+	args := cArgs.Args.(*roachpb.RevertRangeRequest)
+	reply := resp.(*roachpb.RevertRangeResponse)
+	h := cArgs.Header
+
+	if gcThreshold := cArgs.EvalCtx.GetGCThreshold(); args.TargetTime.Less(gcThreshold) {
+		return result.Result{}, errors.Errorf(
+			"cannot revert to %s: below the GC threshold %s", args.TargetTime, gcThreshold)
+	}
+	if !args.TargetTime.Less(h.Timestamp) {
+		return result.Result{}, errors.Errorf(
+			"cannot revert to %s: not before the request timestamp %s", args.TargetTime, h.Timestamp)
+	}
+
+	desc := cArgs.EvalCtx.Desc()
+	allowClear := desc.StartKey.Equal(args.Key) && desc.EndKey.Equal(args.EndKey)
+	if allowClear {
+		// Clearing the span removes its history up to the request timestamp.
+		protected, ok := cArgs.EvalCtx.GetProtectedTimestamp()
+		if !ok || (protected != (hlc.Timestamp{}) && !h.Timestamp.Less(protected)) {
+			log.VEventf(ctx, 2, "not clearing: history protected by protected timestamps")
+			allowClear = false
+		}
+	}
+
+	reverted, resumeSpan, cleared, err := engine.MVCCRevertRange(
+		ctx, batch, cArgs.Stats, args.Key, args.EndKey, cArgs.MaxKeys,
+		args.TargetTime, h.Timestamp, allowClear,
+	)
+	if err != nil {
+		return result.Result{}, err
+	}
+	log.VEventf(ctx, 2, "reverted %d keys (cleared=%t)", reverted, cleared)
+	if !cleared {
+		reply.NumKeys = reverted
+		if resumeSpan != nil {
+			reply.ResumeSpan = resumeSpan
+			reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		}
+		return result.Result{}, nil
+	}
+
+	var pd result.Result
+	newThreshold := cArgs.EvalCtx.GetGCThreshold()
+	newThreshold.Forward(h.Timestamp)
+	stateLoader := MakeStateLoader(cArgs.EvalCtx)
+	if err := stateLoader.SetGCThreshold(ctx, batch, cArgs.Stats, &newThreshold); err != nil {
+		return result.Result{}, err
+	}
+	pd.Replicated.State = &storagebase.ReplicaState{GCThreshold: &newThreshold}
+	return pd, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestCmdRevertRangeProtectedTimestamp verifies that RevertRange only clears
+// a range outright, and advances its GC threshold, if no protected timestamp
+// prevents it.
+func TestCmdRevertRangeProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	startKey := roachpb.Key("0000")
+	endKey := roachpb.Key("9999")
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKey(endKey),
+	}
+	key := roachpb.Key("0001")
+	ts := func(w int64) hlc.Timestamp { return hlc.Timestamp{WallTime: w} }
+
+	tests := []struct {
+		name               string
+		protectedTS        hlc.Timestamp
+		expClearRangeCount int
+	}{
+		{"unprotected", hlc.Timestamp{}, 1},
+		{"protected above request", ts(10), 1},
+		{"protected at request", ts(5), 0},
+		{"protected below request", ts(4), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+
+			// The key does not exist at the target time, t=2.
+			var stats enginepb.MVCCStats
+			if err := engine.MVCCPut(
+				ctx, eng, &stats, key, ts(3), roachpb.MakeValueFromString("a"), nil,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			batch := &wrappedBatch{Batch: eng.NewBatch()}
+			defer batch.Close()
+
+			var h roachpb.Header
+			h.RangeID = desc.RangeID
+			h.Timestamp = ts(5)
+
+			cArgs := CommandArgs{Header: h, MaxKeys: math.MaxInt64}
+			cArgs.EvalCtx = &mockEvalCtx{
+				desc:        &desc,
+				clock:       hlc.NewClock(hlc.UnixNano, time.Nanosecond),
+				stats:       stats,
+				protectedTS: test.protectedTS,
+			}
+			cArgs.Args = &roachpb.RevertRangeRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key:    startKey,
+					EndKey: endKey,
+				},
+				TargetTime: ts(2),
+			}
+			cArgs.Stats = &enginepb.MVCCStats{}
+
+			res, err := RevertRange(ctx, batch, cArgs, &roachpb.RevertRangeResponse{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a, e := batch.clearRangeCount, test.expClearRangeCount; a != e {
+				t.Errorf("expected %d clear ranges; got %d", e, a)
+			}
+			advanced, expAdvanced := res.Replicated.State != nil, test.expClearRangeCount > 0
+			if advanced != expAdvanced {
+				t.Errorf("expected GC threshold advanced to be %t, got %t", expAdvanced, advanced)
+			}
+
+			// Either way, the key no longer exists at the request timestamp.
+			if err := batch.Commit(true /* commit */); err != nil {
+				t.Fatal(err)
+			}
+			value, _, err := engine.MVCCGet(ctx, eng, key, ts(5), true /* consistent */, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != nil {
+				t.Errorf("expected key to be reverted, found %s", value)
+			}
+		})
+	}
+}
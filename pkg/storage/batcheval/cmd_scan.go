@@ -25,7 +25,7 @@ import (
 )
 
 func init() {
-	RegisterCommand(roachpb.Scan, DefaultDeclareKeys, Scan)
+	RegisterCommand(roachpb.Scan, declareKeysScan, Scan)
 }
 
 // Scan scans the key range specified by start key through end key
@@ -39,6 +39,21 @@ func Scan(
 	h := cArgs.Header
 	reply := resp.(*roachpb.ScanResponse)
 
+	if args.KeyLocking != roachpb.LOCK_NONE && args.LockWaitPolicy == roachpb.LOCK_WAIT_SKIP {
+		res, err := scanSkipLocked(ctx, batch, cArgs, args.ScanFormat, false /* reverse */)
+		if err != nil {
+			return result.Result{}, err
+		}
+		reply.NumKeys = res.numKeys
+		reply.Rows = res.rows
+		reply.BatchResponse = res.kvData
+		if res.resumeSpan != nil {
+			reply.ResumeSpan = res.resumeSpan
+			reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		}
+		return result.Result{}, nil
+	}
+
 	var err error
 	var intents []roachpb.Intent
 	var resumeSpan *roachpb.Span
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/limit"
@@ -54,6 +55,7 @@ type EvalContext interface {
 	DB() *client.DB
 	AbortSpan() *abortspan.AbortSpan
 	GetTxnWaitQueue() *txnwait.Queue
+	GetLockTable() *locktable.Table
 	GetLimiters() *Limiters
 
 	NodeID() roachpb.NodeID
@@ -70,6 +72,11 @@ type EvalContext interface {
 	GetMVCCStats() enginepb.MVCCStats
 	GetGCThreshold() hlc.Timestamp
 	GetTxnSpanGCThreshold() hlc.Timestamp
+	// GetProtectedTimestamp returns the earliest timestamp protected by a
+	// protected timestamp record overlapping the range, or the zero timestamp
+	// if there is no such record. It returns false if the records have not
+	// been read yet.
+	GetProtectedTimestamp() (hlc.Timestamp, bool)
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, *roachpb.Lease)
 }
@@ -0,0 +1,230 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+)
+
+// declareKeysScan declares the keys of a Scan or ReverseScan. Scans which
+// acquire exclusive locks also declare their span as written, which
+// serializes them with each other and with writes so that no two
+// transactions can acquire conflicting locks (see storage/locktable).
+func declareKeysScan(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	DefaultDeclareKeys(desc, header, req, spans)
+	if str, _ := roachpb.Locking(req); str == roachpb.LOCK_EXCLUSIVE {
+		spans.Add(spanset.SpanReadWrite, req.Header().Span())
+	}
+}
+
+// skipLockedResult is the result of a scan which skips locked rows.
+type skipLockedResult struct {
+	rows       []roachpb.KeyValue
+	kvData     []byte
+	numKeys    int64
+	resumeSpan *roachpb.Span
+}
+
+// scanSkipLocked evaluates a locking Scan or ReverseScan with the
+// LOCK_WAIT_SKIP policy (SELECT ... SKIP LOCKED). Instead of returning a
+// WriteIntentError for the intents of other transactions, or waiting for the
+// locks held by other transactions, it skips the rows they're on. It does so
+// by scanning the sub-spans between the locked rows.
+func scanSkipLocked(
+	ctx context.Context,
+	batch engine.ReadWriter,
+	cArgs CommandArgs,
+	format roachpb.ScanFormat,
+	reverse bool,
+) (skipLockedResult, error) {
+	h := cArgs.Header
+	if h.Txn == nil || h.ReadConsistency != roachpb.CONSISTENT {
+		return skipLockedResult{}, errors.Errorf("%s cannot skip locked keys outside of a transaction",
+			cArgs.Args.Method())
+	}
+	var res skipLockedResult
+	scan := func(span roachpb.Span, max int64) (int64, *roachpb.Span, error) {
+		if span.Key.Compare(span.EndKey) >= 0 {
+			return 0, nil, nil
+		}
+		switch format {
+		case roachpb.BATCH_RESPONSE:
+			scanFn := engine.MVCCScanToBytes
+			if reverse {
+				scanFn = engine.MVCCReverseScanToBytes
+			}
+			kvData, numKvs, resumeSpan, _, err := scanFn(
+				ctx, batch, span.Key, span.EndKey, max, h.Timestamp, true /* consistent */, h.Txn)
+			if err != nil {
+				return 0, nil, err
+			}
+			res.kvData = append(res.kvData, kvData...)
+			return numKvs, resumeSpan, nil
+		case roachpb.KEY_VALUES:
+			scanFn := engine.MVCCScan
+			if reverse {
+				scanFn = engine.MVCCReverseScan
+			}
+			rows, resumeSpan, _, err := scanFn(
+				ctx, batch, span.Key, span.EndKey, max, h.Timestamp, true /* consistent */, h.Txn)
+			if err != nil {
+				return 0, nil, err
+			}
+			res.rows = append(res.rows, rows...)
+			return int64(len(rows)), resumeSpan, nil
+		default:
+			panic(fmt.Sprintf("Unknown scanFormat %d", format))
+		}
+	}
+
+	// The rows locked by other transactions, in the order of the scan.
+	span := cArgs.Args.Header().Span()
+	str, _ := roachpb.Locking(cArgs.Args)
+	var locked []roachpb.Span
+	for _, intent := range cArgs.EvalCtx.GetLockTable().Conflicts(&h.Txn.TxnMeta, span, str) {
+		if n := len(locked); n == 0 || !locked[n-1].Key.Equal(intent.Key) {
+			locked = append(locked, intent.Span)
+		}
+	}
+	if reverse {
+		for i, j := 0, len(locked)-1; i < j; i, j = i+1, j-1 {
+			locked[i], locked[j] = locked[j], locked[i]
+		}
+	}
+
+	max := cArgs.MaxKeys
+	remaining := span
+	for {
+		if max <= 0 {
+			res.resumeSpan = &remaining
+			return res, nil
+		}
+		// Scan up to the next locked row, if any.
+		var skip *roachpb.Span
+		for len(locked) > 0 && skip == nil {
+			// Rows which were skipped over along with an intent are ignored.
+			if !skippedOver(remaining, locked[0], reverse) {
+				skip = &locked[0]
+			}
+			locked = locked[1:]
+		}
+		numKeys, resumeSpan, err := scan(spanBefore(remaining, skip, reverse), max)
+		if wiErr, ok := err.(*roachpb.WriteIntentError); ok {
+			// Scan up to the row of the first intent instead, and skip it.
+			intentRow := locktable.LockSpan(firstIntentKey(wiErr.Intents, reverse))
+			skip = &intentRow
+			numKeys, resumeSpan, err = scan(spanBefore(remaining, skip, reverse), max)
+		}
+		if err != nil {
+			return skipLockedResult{}, err
+		}
+		res.numKeys += numKeys
+		max -= numKeys
+		if resumeSpan != nil {
+			// The scan ran into the key limit. Resume it at the end of the
+			// remaining span, including any locked rows further along.
+			if reverse {
+				resumeSpan.Key = remaining.Key
+			} else {
+				resumeSpan.EndKey = remaining.EndKey
+			}
+			res.resumeSpan = resumeSpan
+			return res, nil
+		}
+		if skip == nil {
+			return res, nil
+		}
+		if remaining = spanAfter(remaining, *skip, reverse); len(remaining.Key) == 0 {
+			return res, nil
+		}
+	}
+}
+
+// firstIntentKey returns the first of the intents' keys in the order of a
+// scan.
+func firstIntentKey(intents []roachpb.Intent, reverse bool) roachpb.Key {
+	first := intents[0].Key
+	for _, intent := range intents[1:] {
+		if c := intent.Key.Compare(first); (c < 0) != reverse && c != 0 {
+			first = intent.Key
+		}
+	}
+	return first
+}
+
+// skippedOver returns whether a scan which has span left to visit already
+// went past the locked row.
+func skippedOver(span roachpb.Span, row roachpb.Span, reverse bool) bool {
+	if reverse {
+		return row.Key.Compare(span.EndKey) >= 0
+	}
+	end := row.EndKey
+	if len(end) == 0 {
+		end = row.Key.Next()
+	}
+	return end.Compare(span.Key) <= 0
+}
+
+// spanBefore returns the part of span which a scan visits before it gets to
+// skip, which is the entire span if skip is nil. The result may be empty.
+func spanBefore(span roachpb.Span, skip *roachpb.Span, reverse bool) roachpb.Span {
+	if skip == nil {
+		return span
+	}
+	if reverse {
+		start := skip.EndKey
+		if len(start) == 0 {
+			start = skip.Key.Next()
+		}
+		if start.Compare(span.Key) > 0 {
+			span.Key = start
+		}
+	} else if skip.Key.Compare(span.EndKey) < 0 {
+		span.EndKey = skip.Key
+	}
+	return span
+}
+
+// spanAfter returns the part of span which a scan visits after it skipped
+// over skip. The result has an empty Key if there is nothing left to scan.
+func spanAfter(span roachpb.Span, skip roachpb.Span, reverse bool) roachpb.Span {
+	if reverse {
+		if skip.Key.Compare(span.EndKey) < 0 {
+			span.EndKey = skip.Key
+		}
+	} else {
+		end := skip.EndKey
+		if len(end) == 0 {
+			end = skip.Key.Next()
+		}
+		if end.Compare(span.Key) > 0 {
+			span.Key = end
+		}
+	}
+	if span.Key.Compare(span.EndKey) >= 0 {
+		return roachpb.Span{}
+	}
+	return span
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestSkipLockedSpans verifies the computation of the sub-spans which a scan
+// that skips a locked row visits before and after the row.
+func TestSkipLockedSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sp := func(key, endKey string) roachpb.Span {
+		s := roachpb.Span{Key: roachpb.Key(key)}
+		if endKey != "" {
+			s.EndKey = roachpb.Key(endKey)
+		}
+		return s
+	}
+	span := sp("a", "z")
+	testCases := []struct {
+		skip          roachpb.Span
+		reverse       bool
+		before, after roachpb.Span
+		skippedOver   bool
+	}{
+		{sp("c", "d"), false, sp("a", "c"), sp("d", "z"), false},
+		{sp("c", "d"), true, sp("d", "z"), sp("a", "c"), false},
+		// A locked key rather than a locked row.
+		{sp("c", ""), false, sp("a", "c"), sp("c\x00", "z"), false},
+		{sp("c", ""), true, sp("c\x00", "z"), sp("a", "c"), false},
+		// A locked row at the start of the scan.
+		{sp("a", "b"), false, sp("a", "a"), sp("b", "z"), false},
+		// A locked row covering the rest of the scan.
+		{sp("c", "zz"), false, sp("a", "c"), roachpb.Span{}, false},
+		// Rows outside of the span have been skipped over already.
+		{sp("0", "1"), false, roachpb.Span{}, roachpb.Span{}, true},
+		{sp("zz", ""), true, roachpb.Span{}, roachpb.Span{}, true},
+	}
+	for i, c := range testCases {
+		if so := skippedOver(span, c.skip, c.reverse); so != c.skippedOver {
+			t.Errorf("%d: expected skippedOver(%s) to be %t", i, c.skip, c.skippedOver)
+		}
+		if c.skippedOver {
+			continue
+		}
+		skip := c.skip
+		if before := spanBefore(span, &skip, c.reverse); !before.Equal(c.before) {
+			t.Errorf("%d: expected span before %s to be %s, got %s", i, c.skip, c.before, before)
+		}
+		if after := spanAfter(span, c.skip, c.reverse); !after.Equal(c.after) {
+			t.Errorf("%d: expected span after %s to be %s, got %s", i, c.skip, c.after, after)
+		}
+	}
+	if s := spanBefore(span, nil, false); !s.Equal(span) {
+		t.Errorf("expected the entire span without a locked row, got %s", s)
+	}
+}
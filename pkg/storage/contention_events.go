@@ -0,0 +1,121 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// contentionEventsMaxKeys is the number of contended keys for which a node
+// keeps aggregated contention events.
+var contentionEventsMaxKeys = envutil.EnvOrDefaultInt("COCKROACH_CONTENTION_EVENTS", 1000)
+
+// ContentionEvent aggregates the events of the requests which were blocked by
+// the intents of other transactions on a key.
+type ContentionEvent struct {
+	// Key is the key of the intents.
+	Key roachpb.Key
+	// StoreID is the store on which the most recent request was blocked.
+	StoreID roachpb.StoreID
+	// BlockingTxnID is the ID of the transaction which owned the intent in the
+	// most recent event.
+	BlockingTxnID uuid.UUID
+	// BlockedTxnID is the ID of the transaction of the most recently blocked
+	// request. It is the nil UUID if the request was not transactional.
+	BlockedTxnID uuid.UUID
+	// Timestamp is the time at which the most recently blocked request stopped
+	// being blocked.
+	Timestamp time.Time
+	// Count is the number of requests which were blocked.
+	Count int64
+	// Duration is the total time the requests were blocked for. It includes
+	// the time spent waiting in the contention queue and in the txn wait queue
+	// of the blocking transactions' records.
+	Duration time.Duration
+}
+
+// ContentionEventRegistry aggregates the contention events of the stores of a
+// node by key. It only keeps the maxKeys most recently contended keys.
+type ContentionEventRegistry struct {
+	maxKeys int
+	mu      struct {
+		syncutil.Mutex
+		// keys maps a key to its element in lru.
+		keys map[string]*list.Element
+		// lru holds the aggregated *ContentionEvents, the most recent first.
+		lru list.List
+	}
+}
+
+// NewContentionEventRegistry returns a new ContentionEventRegistry.
+func NewContentionEventRegistry() *ContentionEventRegistry {
+	return newContentionEventRegistry(contentionEventsMaxKeys)
+}
+
+func newContentionEventRegistry(maxKeys int) *ContentionEventRegistry {
+	r := &ContentionEventRegistry{maxKeys: maxKeys}
+	r.mu.keys = map[string]*list.Element{}
+	return r
+}
+
+// add records that a request was blocked on the supplied store for the given
+// duration until now by the intent of a transaction on key.
+func (r *ContentionEventRegistry) add(
+	storeID roachpb.StoreID,
+	key roachpb.Key,
+	blockingTxnID, blockedTxnID uuid.UUID,
+	now time.Time,
+	duration time.Duration,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var event *ContentionEvent
+	if e, ok := r.mu.keys[string(key)]; ok {
+		event = e.Value.(*ContentionEvent)
+		r.mu.lru.MoveToFront(e)
+	} else {
+		event = &ContentionEvent{Key: key}
+		r.mu.keys[string(key)] = r.mu.lru.PushFront(event)
+		if r.mu.lru.Len() > r.maxKeys {
+			oldest := r.mu.lru.Back()
+			r.mu.lru.Remove(oldest)
+			delete(r.mu.keys, string(oldest.Value.(*ContentionEvent).Key))
+		}
+	}
+	event.StoreID = storeID
+	event.BlockingTxnID = blockingTxnID
+	event.BlockedTxnID = blockedTxnID
+	event.Timestamp = now
+	event.Count++
+	event.Duration += duration
+}
+
+// Events returns the aggregated contention events, the most recent first.
+func (r *ContentionEventRegistry) Events() []ContentionEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]ContentionEvent, 0, r.mu.lru.Len())
+	for e := r.mu.lru.Front(); e != nil; e = e.Next() {
+		events = append(events, *e.Value.(*ContentionEvent))
+	}
+	return events
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestContentionEventRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := newContentionEventRegistry(2 /* maxKeys */)
+	txn1, txn2, txn3 := uuid.MakeV4(), uuid.MakeV4(), uuid.MakeV4()
+	now := time.Unix(1000, 0)
+	a, b, c := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+
+	r.add(1, a, txn1, txn2, now, time.Second)
+	r.add(1, b, txn1, uuid.UUID{}, now.Add(time.Second), 2*time.Second)
+	r.add(2, a, txn2, txn3, now.Add(2*time.Second), 3*time.Second)
+
+	// The events on a are aggregated and describe the most recent event.
+	expected := []ContentionEvent{
+		{
+			Key:           a,
+			StoreID:       2,
+			BlockingTxnID: txn2,
+			BlockedTxnID:  txn3,
+			Timestamp:     now.Add(2 * time.Second),
+			Count:         2,
+			Duration:      4 * time.Second,
+		},
+		{
+			Key:           b,
+			StoreID:       1,
+			BlockingTxnID: txn1,
+			Timestamp:     now.Add(time.Second),
+			Count:         1,
+			Duration:      2 * time.Second,
+		},
+	}
+	if events := r.Events(); !reflect.DeepEqual(expected, events) {
+		t.Fatalf("expected %+v, got %+v", expected, events)
+	}
+
+	// Contention on a third key evicts the least recently contended key, b.
+	r.add(1, c, txn3, txn1, now.Add(3*time.Second), time.Second)
+	expected = append([]ContentionEvent{{
+		Key:           c,
+		StoreID:       1,
+		BlockingTxnID: txn3,
+		BlockedTxnID:  txn1,
+		Timestamp:     now.Add(3 * time.Second),
+		Count:         1,
+		Duration:      time.Second,
+	}}, expected[0])
+	if events := r.Events(); !reflect.DeepEqual(expected, events) {
+		t.Fatalf("expected %+v, got %+v", expected, events)
+	}
+
+	// The aggregation of an evicted key starts over.
+	r.add(1, b, txn1, txn2, now.Add(4*time.Second), time.Second)
+	if events := r.Events(); len(events) != 2 || !events[0].Key.Equal(b) || events[0].Count != 1 ||
+		!events[1].Key.Equal(c) {
+		t.Fatalf("expected b with a count of 1 followed by c, got %+v", events)
+	}
+}
+
+// TestStoreContentionEvents verifies that a contention event is only recorded
+// when a request is blocked by an intent, not when the intent's transaction
+// can be pushed right away.
+func TestStoreContentionEvents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, _ := createTestStore(t, stopper)
+
+	for i, resolvable := range []bool{true, false} {
+		key := roachpb.Key(fmt.Sprintf("key-%d", i))
+		pusher := newTransaction("test", key, 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+		pushee := newTransaction("test", key, 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+		if resolvable {
+			pushee.Priority = roachpb.MinTxnPriority
+			pusher.Priority = roachpb.MaxTxnPriority // Pusher will win.
+		} else {
+			pushee.Priority = roachpb.MaxTxnPriority
+			pusher.Priority = roachpb.MinTxnPriority // Pusher will lose.
+		}
+
+		// First lay down intent using the pushee's txn.
+		pArgs := putArgs(key, []byte("value"))
+		h := roachpb.Header{Txn: pushee}
+		assignSeqNumsForReqs(pushee, &pArgs)
+		if _, err := maybeWrapWithBeginTransaction(context.Background(), store.TestSender(), h, &pArgs); err != nil {
+			t.Fatal(err)
+		}
+
+		// Now, try a put using the pusher's txn.
+		h.Txn = pusher
+		resultCh := make(chan *roachpb.Error, 1)
+		go func() {
+			_, pErr := client.SendWrappedWith(context.Background(), store.TestSender(), h, &pArgs)
+			resultCh <- pErr
+		}()
+
+		const blockedFor = 10 * time.Millisecond
+		if !resolvable {
+			select {
+			case pErr := <-resultCh:
+				t.Fatalf("did not expect put to complete with lower priority: %s", pErr)
+			case <-time.After(blockedFor):
+			}
+			// Send an end transaction to allow the original push to complete.
+			etArgs, h := endTxnArgs(pushee, true)
+			assignSeqNumsForReqs(pushee, &etArgs)
+			if _, pErr := client.SendWrappedWith(context.Background(), store.TestSender(), h, &etArgs); pErr != nil {
+				t.Fatal(pErr)
+			}
+		}
+		if pErr := <-resultCh; pErr != nil {
+			t.Fatal(pErr)
+		}
+
+		var events []ContentionEvent
+		for _, event := range store.cfg.ContentionEvents.Events() {
+			if event.Key.Equal(key) {
+				events = append(events, event)
+			}
+		}
+		if resolvable {
+			if len(events) != 0 {
+				t.Fatalf("expected no contention event, got %+v", events)
+			}
+			continue
+		}
+		if len(events) != 1 {
+			t.Fatalf("expected a contention event, got %+v", events)
+		}
+		event := events[0]
+		if event.StoreID != store.StoreID() || event.BlockingTxnID != pushee.ID ||
+			event.BlockedTxnID != pusher.ID || event.Count != 1 || event.Duration < blockedFor {
+			t.Fatalf("unexpected contention event %+v", event)
+		}
+	}
+}
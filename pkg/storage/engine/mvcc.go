@@ -1639,6 +1639,170 @@ func MVCCDeleteRange(
 	return keys, resumeSpan, int64(len(kvs)), err
 }
 
+// MVCCRevertRange reverts the keys in the span [key, endKey) to their state
+// as of targetTime. For every key whose most recent version is newer than
+// targetTime and differs from its value at targetTime, a new version is
+// written at timestamp which holds the value the key had at targetTime, or a
+// deletion tombstone if the key did not exist at targetTime. Inline
+// (non-versioned) values are left untouched. If any intents are found in the
+// span, a WriteIntentError is returned and nothing is written. It returns the
+// number of keys that were reverted. At most max keys are reverted; if more
+// keys need to be reverted, the span to resume from is returned.
+//
+// If allowClear is true, no key in the span existed at targetTime and the
+// span contains neither inline values nor versions newer than timestamp, the
+// span is cleared outright instead (including all of its history) and
+// cleared is returned as true. In that case it is the caller's
+// responsibility to prevent reads between targetTime and timestamp from
+// observing the missing history.
+func MVCCRevertRange(
+	ctx context.Context,
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	key, endKey roachpb.Key,
+	max int64,
+	targetTime, timestamp hlc.Timestamp,
+	allowClear bool,
+) (reverted int64, resumeSpan *roachpb.Span, cleared bool, _ error) {
+	type revertKey struct {
+		key   roachpb.Key
+		value []byte
+	}
+	var reverts []revertKey
+	// resumeKey is the first key to revert past the max keys.
+	var resumeKey roachpb.Key
+	var intents []roachpb.Intent
+	existed := false
+	// clearable is unset if the span contains data that clearing it would
+	// wrongly remove: inline values or versions newer than timestamp.
+	clearable := allowClear
+
+	iter := engine.NewIterator(IterOptions{UpperBound: endKey})
+	meta := &enginepb.MVCCMetadata{}
+	iter.Seek(MakeMVCCMetadataKey(key))
+	for {
+		if ok, err := iter.Valid(); err != nil {
+			iter.Close()
+			return 0, nil, false, err
+		} else if !ok {
+			break
+		}
+		unsafeKey := iter.UnsafeKey()
+		if !unsafeKey.IsValue() {
+			// Either an intent or an inline value. Inline values are not
+			// versioned and are not reverted.
+			if err := protoutil.Unmarshal(iter.UnsafeValue(), meta); err != nil {
+				iter.Close()
+				return 0, nil, false, err
+			}
+			if meta.Txn != nil {
+				intents = append(intents, roachpb.Intent{
+					Span:   roachpb.Span{Key: append(roachpb.Key(nil), unsafeKey.Key...)},
+					Status: roachpb.PENDING,
+					Txn:    *meta.Txn,
+				})
+			} else {
+				clearable = false
+			}
+			iter.NextKey()
+			continue
+		}
+		// The first version of a key is its most recent one.
+		if timestamp.Less(unsafeKey.Timestamp) {
+			clearable = false
+		}
+		if !targetTime.Less(unsafeKey.Timestamp) {
+			// The key has not been modified since targetTime.
+			if len(iter.UnsafeValue()) > 0 {
+				existed = true
+			}
+			iter.NextKey()
+			continue
+		}
+
+		k := append(roachpb.Key(nil), unsafeKey.Key...)
+		latest := append([]byte(nil), iter.UnsafeValue()...)
+		// Find the version of the key as of targetTime, if any.
+		iter.Seek(MVCCKey{Key: k, Timestamp: targetTime})
+		ok, err := iter.Valid()
+		if err != nil {
+			iter.Close()
+			return 0, nil, false, err
+		}
+		var value []byte
+		if ok && iter.UnsafeKey().Key.Equal(k) {
+			value = append([]byte(nil), iter.UnsafeValue()...)
+			iter.NextKey()
+		}
+		if len(value) > 0 {
+			existed = true
+		}
+		if revertValueEqual(latest, value) {
+			// The key already has its value as of targetTime (it may have been
+			// reverted before), nothing to do.
+			continue
+		}
+		if int64(len(reverts)) >= max {
+			resumeKey = k
+			// The span can still be cleared unless the rest of it is known to
+			// require the keys to be reverted one by one.
+			if !clearable || existed {
+				break
+			}
+			continue
+		}
+		reverts = append(reverts, revertKey{key: k, value: value})
+	}
+	iter.Close()
+
+	if len(intents) > 0 {
+		return 0, nil, false, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	if clearable && !existed {
+		from, to := MakeMVCCMetadataKey(key), MakeMVCCMetadataKey(endKey)
+		if ms != nil {
+			statsIter := engine.NewIterator(IterOptions{UpperBound: endKey})
+			delta, err := statsIter.ComputeStats(from, to, timestamp.WallTime)
+			statsIter.Close()
+			if err != nil {
+				return 0, nil, false, err
+			}
+			ms.Subtract(delta)
+		}
+		if err := engine.ClearRange(from, to); err != nil {
+			return 0, nil, false, err
+		}
+		return int64(len(reverts)), nil, true, nil
+	}
+
+	buf := newPutBuffer()
+	defer buf.release()
+	putIter := engine.NewIterator(IterOptions{Prefix: true})
+	defer putIter.Close()
+	for _, r := range reverts {
+		// A nil value writes a deletion tombstone.
+		if err := mvccPutInternal(
+			ctx, engine, putIter, ms, r.key, timestamp, r.value, nil /* txn */, buf, nil /* valueFn */); err != nil {
+			return 0, nil, false, err
+		}
+	}
+	if resumeKey != nil {
+		resumeSpan = &roachpb.Span{Key: resumeKey, EndKey: endKey}
+	}
+	return int64(len(reverts)), resumeSpan, false, nil
+}
+
+// revertValueEqual returns whether two raw MVCC values of the same key hold
+// the same data, ignoring their checksums. An empty value is a deletion
+// tombstone.
+func revertValueEqual(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return roachpb.Value{RawBytes: a}.EqualData(roachpb.Value{RawBytes: b})
+}
+
 // mvccScanInternal scans the key range [key,endKey) up to some maximum number
 // of results. Specify reverse=true to scan in descending instead of ascending
 // order. If iter is not specified, a new iterator is created from engine. It
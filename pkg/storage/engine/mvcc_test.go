@@ -1919,6 +1919,143 @@ func TestMVCCDeleteRangeConcurrentTxn(t *testing.T) {
 	}
 }
 
+// TestMVCCRevertRange verifies that MVCCRevertRange writes new versions which
+// restore the values as of the target time, clears spans without any history
+// when allowed to, and refuses to revert spans containing intents.
+func TestMVCCRevertRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ctx := context.Background()
+	var ms enginepb.MVCCStats
+	ts := func(w int64) hlc.Timestamp { return hlc.Timestamp{WallTime: w} }
+
+	for _, kv := range []struct {
+		key   roachpb.Key
+		ts    int64
+		value roachpb.Value
+	}{
+		{testKey1, 1, value1},
+		{testKey2, 1, value2},
+		{testKey1, 3, value3},
+		{testKey3, 3, value3},
+		{testKey5, 3, value5},
+	} {
+		if err := MVCCPut(ctx, engine, &ms, kv.key, ts(kv.ts), kv.value, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := MVCCDelete(ctx, engine, &ms, testKey2, ts(3), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expectValue := func(key roachpb.Key, at int64, expected *roachpb.Value) {
+		t.Helper()
+		value, _, err := MVCCGet(ctx, engine, key, ts(at), true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected == nil {
+			if value != nil {
+				t.Fatalf("%s@%d: expected no value, got %s", key, at, value)
+			}
+			return
+		}
+		if value == nil || !value.EqualData(*expected) {
+			t.Fatalf("%s@%d: expected %s, got %v", key, at, expected, value)
+		}
+	}
+
+	// Revert [testKey1, testKey4) to t=2 at t=5, two keys at a time.
+	reverted, resumeSpan, cleared, err := MVCCRevertRange(
+		ctx, engine, &ms, testKey1, testKey4, 2 /* max */, ts(2), ts(5), true, /* allowClear */
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != 2 || cleared {
+		t.Fatalf("expected 2 keys reverted without clearing, got %d (cleared=%t)", reverted, cleared)
+	}
+	if e := (roachpb.Span{Key: testKey3, EndKey: testKey4}); resumeSpan == nil || !resumeSpan.Equal(e) {
+		t.Fatalf("expected resume span %s, got %v", e, resumeSpan)
+	}
+	expectValue(testKey3, 5, &value3)
+	reverted, resumeSpan, _, err = MVCCRevertRange(
+		ctx, engine, &ms, resumeSpan.Key, resumeSpan.EndKey, 2 /* max */, ts(2), ts(5), false, /* allowClear */
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != 1 || resumeSpan != nil {
+		t.Fatalf("expected 1 key reverted without resume span, got %d (resume span %v)", reverted, resumeSpan)
+	}
+	expectValue(testKey1, 5, &value1)
+	expectValue(testKey2, 5, &value2)
+	expectValue(testKey3, 5, nil)
+	// History is preserved.
+	expectValue(testKey1, 4, &value3)
+	expectValue(testKey3, 4, &value3)
+	assertEq(t, engine, "after revert", &ms, &ms)
+
+	// Reverting again is a no-op.
+	if reverted, _, _, err := MVCCRevertRange(
+		ctx, engine, &ms, testKey1, testKey4, math.MaxInt64, ts(2), ts(6), false, /* allowClear */
+	); err != nil {
+		t.Fatal(err)
+	} else if reverted != 0 {
+		t.Fatalf("expected no keys reverted, got %d", reverted)
+	}
+
+	// testKey5 did not exist at t=2, so its span can be cleared outright.
+	if _, _, cleared, err := MVCCRevertRange(
+		ctx, engine, &ms, testKey5, testKey6, math.MaxInt64, ts(2), ts(7), true, /* allowClear */
+	); err != nil {
+		t.Fatal(err)
+	} else if !cleared {
+		t.Fatal("expected span to be cleared")
+	}
+	expectValue(testKey5, 4, nil)
+	assertEq(t, engine, "after clear", &ms, &ms)
+
+	// Inline values are never cleared.
+	if err := MVCCPut(ctx, engine, &ms, testKey5, hlc.Timestamp{}, value5, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, cleared, err := MVCCRevertRange(
+		ctx, engine, &ms, testKey5, testKey6, math.MaxInt64, ts(2), ts(8), true, /* allowClear */
+	); err != nil {
+		t.Fatal(err)
+	} else if cleared {
+		t.Fatal("expected inline value to prevent clearing the span")
+	}
+	expectValue(testKey5, 8, &value5)
+
+	// Neither are versions newer than the request timestamp.
+	if err := MVCCPut(ctx, engine, &ms, testKey6, ts(20), value6, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, cleared, err := MVCCRevertRange(
+		ctx, engine, &ms, testKey6, testKey6.PrefixEnd(), math.MaxInt64, ts(2), ts(8), true, /* allowClear */
+	); cleared {
+		t.Fatal("expected newer version to prevent clearing the span")
+	} else if _, ok := err.(*roachpb.WriteTooOldError); !ok {
+		t.Fatalf("expected WriteTooOldError, got %v", err)
+	}
+	expectValue(testKey6, 20, &value6)
+
+	// Intents prevent reverting.
+	if err := MVCCPut(ctx, engine, &ms, testKey4, ts(8), value4, makeTxn(*txn1, ts(8))); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = MVCCRevertRange(
+		ctx, engine, &ms, testKey1, testKey6, math.MaxInt64, ts(2), ts(9), false, /* allowClear */
+	)
+	if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("expected WriteIntentError, got %v", err)
+	}
+}
+
 // TestMVCCUncommittedDeleteRangeVisible tests that the keys in an uncommitted
 // DeleteRange are visible to the same transaction at a higher epoch.
 func TestMVCCUncommittedDeleteRangeVisible(t *testing.T) {
@@ -125,6 +125,9 @@ type SSTableInfo struct {
 	Size  int64
 	Start MVCCKey
 	End   MVCCKey
+	// KeyID is the ID of the data key used to encrypt the sstable, or "plain"
+	// for unencrypted sstables. It is empty if encryption-at-rest is not in use.
+	KeyID string
 }
 
 // SSTableInfos is a slice of SSTableInfo structures.
@@ -275,6 +278,38 @@ func (s SSTableInfos) ReadAmplification() int {
 	return readAmp
 }
 
+// DataKeyUsage summarizes the sstables encrypted with a single data key.
+type DataKeyUsage struct {
+	KeyID string
+	Files int
+	Bytes int64
+}
+
+// ByDataKey returns the number of sstables and bytes using each data key,
+// sorted by key ID. SSTables without a key ID (when encryption-at-rest is not
+// in use) are omitted.
+func (s SSTableInfos) ByDataKey() []DataKeyUsage {
+	usage := make(map[string]*DataKeyUsage)
+	for _, t := range s {
+		if t.KeyID == "" {
+			continue
+		}
+		u, ok := usage[t.KeyID]
+		if !ok {
+			u = &DataKeyUsage{KeyID: t.KeyID}
+			usage[t.KeyID] = u
+		}
+		u.Files++
+		u.Bytes += t.Size
+	}
+	result := make([]DataKeyUsage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].KeyID < result[j].KeyID })
+	return result
+}
+
 // SSTableInfosByLevel maintains slices of SSTableInfo objects, one
 // per level. The slice for each level contains the SSTableInfo
 // objects for SSTables at that level, sorted by start key.
@@ -1086,6 +1121,7 @@ func (r *RocksDB) GetSSTables() SSTableInfos {
 		if ptr := tv.end_key.key.data; ptr != nil {
 			C.free(unsafe.Pointer(ptr))
 		}
+		r.KeyID = cStringToGoString(tv.key_id)
 	}
 	C.free(unsafe.Pointer(tables))
 
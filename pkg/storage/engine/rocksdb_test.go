@@ -523,6 +523,33 @@ func TestSSTableInfosString(t *testing.T) {
 	}
 }
 
+func TestSSTableInfosByDataKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	info := func(keyID string, size int64) SSTableInfo {
+		return SSTableInfo{
+			Size:  size,
+			KeyID: keyID,
+		}
+	}
+
+	tables := SSTableInfos{
+		info("plain", 10),
+		info("b", 20),
+		info("", 30),
+		info("a", 40),
+		info("b", 50),
+	}
+	expected := []DataKeyUsage{
+		{KeyID: "a", Files: 1, Bytes: 40},
+		{KeyID: "b", Files: 2, Bytes: 70},
+		{KeyID: "plain", Files: 1, Bytes: 10},
+	}
+	if usage := tables.ByDataKey(); !reflect.DeepEqual(expected, usage) {
+		t.Fatalf("expected %+v, got %+v", expected, usage)
+	}
+}
+
 func TestReadAmplification(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
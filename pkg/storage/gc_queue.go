@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/rditer"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -370,7 +371,7 @@ func processLocalKeyRange(
 	handleTxnIntents := func(key roachpb.Key, txn *roachpb.Transaction) error {
 		// If the transaction needs to be pushed or there are intents to
 		// resolve, invoke the cleanup function.
-		if txn.Status == roachpb.PENDING || len(txn.Intents) > 0 {
+		if !txn.Status.IsFinalized() || len(txn.Intents) > 0 {
 			return cleanupTxnIntentsAsyncFn(ctx, txn, roachpb.AsIntents(txn.Intents, txn))
 		}
 		gcKeys = append(gcKeys, roachpb.GCRequest_GCKey{Key: key}) // zero timestamp
@@ -389,7 +390,7 @@ func processLocalKeyRange(
 
 		// The transaction record should be considered for removal.
 		switch txn.Status {
-		case roachpb.PENDING:
+		case roachpb.PENDING, roachpb.STAGING:
 			infoMu.TransactionSpanGCPending++
 		case roachpb.ABORTED:
 			infoMu.TransactionSpanGCAborted++
@@ -580,7 +581,17 @@ func (gcq *gcQueue) processImpl(
 		return errors.Errorf("could not find zone config for range %s: %s", repl, err)
 	}
 
-	info, err := RunGC(ctx, desc, snap, now, zone.GC, &replicaGCer{repl: repl},
+	maxThreshold, ok := protectedGCThreshold(
+		repl.store.cfg.ProtectedTimestampCache, desc, zone.GC)
+	if !ok {
+		log.Event(ctx, "skipping GC: protected timestamp records have not been read yet")
+		return nil
+	}
+	if maxThreshold != (hlc.Timestamp{}) {
+		log.Eventf(ctx, "GC threshold limited to %s by protected timestamps", maxThreshold)
+	}
+
+	info, err := RunGC(ctx, desc, snap, now, zone.GC, maxThreshold, &replicaGCer{repl: repl},
 		func(ctx context.Context, intents []roachpb.Intent) error {
 			intentCount, err := repl.store.intentResolver.cleanupIntents(ctx, intents, now, roachpb.PUSH_ABORT)
 			if err == nil {
@@ -683,18 +694,45 @@ type GCer interface {
 	GC(context.Context, []roachpb.GCRequest_GCKey) error
 }
 
+// protectedGCThreshold returns the timestamp past which the GC threshold of
+// the range with the given descriptor must not be advanced because of
+// protected timestamp records, or the zero timestamp if there is no such
+// limit. Records written after the cache was last refreshed are not known to
+// it, so the threshold is also capped to the one computed from policy as of
+// that refresh: such records can only protect timestamps which were not yet
+// expired when they were written. It returns false if the records have not
+// been read yet.
+func protectedGCThreshold(
+	cache protectedts.Cache, desc *roachpb.RangeDescriptor, policy config.GCPolicy,
+) (hlc.Timestamp, bool) {
+	if cache == nil {
+		return hlc.Timestamp{}, true
+	}
+	protected, asOf := cache.Protected(desc.RSpan().AsRawSpanWithNoLocals())
+	if asOf == (hlc.Timestamp{}) {
+		return hlc.Timestamp{}, false
+	}
+	maxThreshold := engine.MakeGarbageCollector(asOf, policy).Threshold
+	if protected != (hlc.Timestamp{}) && protected.Prev().Less(maxThreshold) {
+		maxThreshold = protected.Prev()
+	}
+	return maxThreshold, true
+}
+
 // RunGC runs garbage collection for the specified descriptor on the
 // provided Engine (which is not mutated). It uses the provided gcFn
 // to run garbage collection once on all implicated spans,
 // cleanupIntentsFn to resolve intents synchronously, and
 // cleanupTxnIntentsAsyncFn to asynchronously cleanup intents and
-// associated transaction record on success.
+// associated transaction record on success. If maxThreshold is not
+// zero, the GC threshold computed from policy is capped to it.
 func RunGC(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
 	snap engine.Reader,
 	now hlc.Timestamp,
 	policy config.GCPolicy,
+	maxThreshold hlc.Timestamp,
 	gcer GCer,
 	cleanupIntentsFn cleanupIntentsFunc,
 	cleanupTxnIntentsAsyncFn cleanupTxnIntentsAsyncFunc,
@@ -712,6 +750,9 @@ func RunGC(
 	txnExp := now.Add(-storagebase.TxnCleanupThreshold.Nanoseconds(), 0)
 
 	gc := engine.MakeGarbageCollector(now, policy)
+	if maxThreshold != (hlc.Timestamp{}) && maxThreshold.Less(gc.Threshold) {
+		gc.Threshold = maxThreshold
+	}
 	infoMu.Threshold = gc.Threshold
 	infoMu.TxnSpanGCThreshold = txnExp
 
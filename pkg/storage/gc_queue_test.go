@@ -507,7 +507,7 @@ func TestGCQueueProcess(t *testing.T) {
 
 		ctx := context.Background()
 		now := tc.Clock().Now()
-		return RunGC(ctx, desc, snap, now, zone.GC,
+		return RunGC(ctx, desc, snap, now, zone.GC, hlc.Timestamp{}, /* maxThreshold */
 			NoopGCer{},
 			func(ctx context.Context, intents []roachpb.Intent) error {
 				return nil
@@ -935,6 +935,80 @@ func TestGCQueueLastProcessedTimestamps(t *testing.T) {
 	})
 }
 
+type fakeProtectedTimestampCache struct {
+	protected, asOf hlc.Timestamp
+}
+
+func (c *fakeProtectedTimestampCache) Protected(roachpb.Span) (protected, asOf hlc.Timestamp) {
+	return c.protected, c.asOf
+}
+
+// TestGCQueueProtectedTimestamp verifies that the GC threshold isn't advanced
+// past a protected timestamp, nor past the threshold as of the time at which
+// the protected timestamp records were read.
+func TestGCQueueProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	manual := hlc.NewManualClock(123)
+	tsc := TestStoreConfig(hlc.NewClock(manual.UnixNano, time.Nanosecond))
+	cache := &fakeProtectedTimestampCache{}
+	tsc.ProtectedTimestampCache = cache
+	tc := testContext{manualClock: manual}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.StartWithStoreConfig(t, stopper, tsc)
+
+	manual.Increment(48 * 60 * 60 * 1E9) // 2d past the epoch
+	now := tc.Clock().Now()
+	ts1 := makeTS(now.WallTime-47*60*60*1E9, 0) // 47h old
+	ts2 := makeTS(now.WallTime-46*60*60*1E9, 0) // 46h old
+	key := roachpb.Key("a")
+	for _, ts := range []hlc.Timestamp{ts1, ts2} {
+		pArgs := putArgs(key, []byte(fmt.Sprintf("value-%s", ts)))
+		if _, err := tc.SendWrappedWith(roachpb.Header{Timestamp: ts}, &pArgs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	zone, err := cfg.GetZoneConfigForKey(tc.repl.Desc().StartKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcQ := newGCQueue(tc.store, tc.gossip)
+	process := func(expThreshold hlc.Timestamp, expVersionAtTS1 bool) {
+		t.Helper()
+		if err := gcQ.processImpl(context.Background(), tc.repl, cfg, now); err != nil {
+			t.Fatal(err)
+		}
+		if threshold := tc.repl.GetGCThreshold(); threshold != expThreshold {
+			t.Fatalf("expected GC threshold %s, got %s", expThreshold, threshold)
+		}
+		val, _, err := engine.MVCCGet(context.Background(), tc.engine, key, ts1, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (val != nil) != expVersionAtTS1 {
+			t.Fatalf("expected version at %s to exist: %t, got %v", ts1, expVersionAtTS1, val)
+		}
+	}
+
+	// The records haven't been read yet, so nothing may be GC'ed.
+	process(hlc.Timestamp{}, true)
+
+	// The version at ts1 is protected.
+	cache.protected, cache.asOf = ts1, now
+	process(ts1.Prev(), true)
+
+	// Once the record is released, the threshold is limited by the time at
+	// which the records were read.
+	cache.protected, cache.asOf = hlc.Timestamp{}, now.Add(-60*60*1E9, 0)
+	process(engine.MakeGarbageCollector(cache.asOf, zone.GC).Threshold, false)
+}
+
 // TestGCQueueChunkRequests verifies that many intents are chunked
 // into separate batches. This is verified both for many different
 // keys and also for many different versions of keys.
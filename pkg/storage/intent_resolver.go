@@ -21,6 +21,7 @@ import (
 	"sort"
 	"time"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -33,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -171,13 +173,15 @@ func txnID(txn *roachpb.Transaction) string {
 // newWIErr, non-nil in case the re-executed request experienced
 // another write intent error and could not complete; and
 // newIntentTxn, nil if the re-executed request left no intent, and
-// non-nil if it did.
+// non-nil if it did. The first bool indicates whether the pusher
+// waited on a prior pusher in the queue.
 func (cq *contentionQueue) add(
 	ctx context.Context, wiErr *roachpb.WriteIntentError, h roachpb.Header,
 ) (
 	func(newWIErr *roachpb.WriteIntentError, newIntentTxn *enginepb.TxnMeta),
 	*roachpb.WriteIntentError,
 	bool,
+	bool,
 ) {
 	if len(wiErr.Intents) != 1 {
 		log.Fatalf(ctx, "write intent error must contain only a single intent: %s", wiErr)
@@ -350,7 +354,7 @@ func (cq *contentionQueue) add(
 		curPusher.waitCh <- newIntentTxn
 		close(curPusher.waitCh)
 		cq.mu.Unlock()
-	}, wiErr, done
+	}, wiErr, waitCh != nil, done
 }
 
 // intentResolver manages the process of pushing transactions and
@@ -406,23 +410,35 @@ func (ir *intentResolver) processWriteIntentError(
 	if log.V(6) {
 		log.Infof(ctx, "resolving write intent %s", wiErr)
 	}
+	start := timeutil.Now()
 
 	// Possibly queue this processing if the write intent error is for a
 	// single intent affecting a unitary key.
 	var cleanup func(*roachpb.WriteIntentError, *enginepb.TxnMeta)
+	// The intents which blocked the request, if any.
+	var blocked []roachpb.Intent
 	if len(wiErr.Intents) == 1 && len(wiErr.Intents[0].Span.EndKey) == 0 {
-		var done bool
+		var queued, done bool
 		// Note that the write intent error may be mutated here in the event
 		// that this pusher is queued to wait for a different transaction
 		// instead.
-		if cleanup, wiErr, done = ir.contentionQ.add(ctx, wiErr, h); done {
+		cleanup, wiErr, queued, done = ir.contentionQ.add(ctx, wiErr, h)
+		if queued {
+			blocked = wiErr.Intents
+		}
+		if done {
+			ir.recordContention(ctx, blocked, h, start)
 			return cleanup, nil
 		}
 	}
 
-	resolveIntents, pErr := ir.maybePushTransactions(
+	resolveIntents, waited, pErr := ir.maybePushTransactions(
 		ctx, wiErr.Intents, h, pushType, false, /* skipIfInFlight */
 	)
+	if blocked == nil {
+		blocked = waited
+	}
+	ir.recordContention(ctx, blocked, h, start)
 	if pErr != nil {
 		return cleanup, pErr
 	}
@@ -445,6 +461,31 @@ func (ir *intentResolver) processWriteIntentError(
 	return cleanup, nil
 }
 
+// recordContention records a contention event for each of the supplied
+// intents, which blocked a request from start until now. The time the request
+// was blocked for is also logged in its trace.
+func (ir *intentResolver) recordContention(
+	ctx context.Context, intents []roachpb.Intent, h roachpb.Header, start time.Time,
+) {
+	if len(intents) == 0 {
+		return
+	}
+	now := timeutil.Now()
+	duration := now.Sub(start)
+	var blockedTxnID uuid.UUID
+	if h.Txn != nil {
+		blockedTxnID = h.Txn.ID
+	}
+	for _, intent := range intents {
+		ir.store.cfg.ContentionEvents.add(
+			ir.store.StoreID(), intent.Key, intent.Txn.ID, blockedTxnID, now, duration,
+		)
+	}
+	if sp := opentracing.SpanFromContext(ctx); sp != nil {
+		sp.LogKV(roachpb.ContentionTimeLogKey, duration.Nanoseconds())
+	}
+}
+
 func getPusherTxn(h roachpb.Header) roachpb.Transaction {
 	// If the txn is nil, we communicate a priority by sending an empty
 	// txn with only the priority set. This is official usage of PushTxn.
@@ -465,8 +506,10 @@ func getPusherTxn(h roachpb.Header) roachpb.Transaction {
 // write/write conflict, or do nothing if the transaction is no longer
 // pending.
 //
-// Returns a slice of intents which can now be resolved, and an error.
-// The returned intents should be resolved via intentResolver.resolveIntents.
+// Returns a slice of intents which can now be resolved, the slice of
+// intents whose transaction could only be pushed after waiting for it,
+// and an error. The intents to resolve should be resolved via
+// intentResolver.resolveIntents.
 //
 // If skipIfInFlight is true, then no PushTxns will be sent and no
 // intents will be returned for any transaction for which there is
@@ -489,7 +532,7 @@ func (ir *intentResolver) maybePushTransactions(
 	h roachpb.Header,
 	pushType roachpb.PushTxnType,
 	skipIfInFlight bool,
-) ([]roachpb.Intent, *roachpb.Error) {
+) ([]roachpb.Intent, []roachpb.Intent, *roachpb.Error) {
 	now := ir.store.Clock().Now()
 
 	// Split intents into those we need to push and those which are good to
@@ -514,7 +557,7 @@ func (ir *intentResolver) maybePushTransactions(
 			// the PENDING status.
 			cleanupInFlightPushesLocked()
 			ir.mu.Unlock()
-			return nil, roachpb.NewErrorf("unexpected %s intent: %+v", intent.Status, intent)
+			return nil, nil, roachpb.NewErrorf("unexpected %s intent: %+v", intent.Status, intent)
 		}
 		_, alreadyPushing := pushTxns[intent.Txn.ID]
 		_, pushTxnInFlight := ir.mu.inFlightPushes[intent.Txn.ID]
@@ -533,7 +576,7 @@ func (ir *intentResolver) maybePushTransactions(
 	}
 	ir.mu.Unlock()
 	if len(pushIntents) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	log.Eventf(ctx, "pushing %d transaction(s)", len(pushTxns))
@@ -567,31 +610,104 @@ func (ir *intentResolver) maybePushTransactions(
 	cleanupInFlightPushesLocked()
 	ir.mu.Unlock()
 	if pErr != nil {
-		return nil, pErr
+		return nil, nil, pErr
 	}
 
 	br := b.RawResponse()
 	pushedTxns := map[uuid.UUID]roachpb.Transaction{}
+	waitedTxns := map[uuid.UUID]struct{}{}
 	for _, resp := range br.Responses {
-		txn := resp.GetInner().(*roachpb.PushTxnResponse).PusheeTxn
+		pushResp := resp.GetInner().(*roachpb.PushTxnResponse)
+		txn := pushResp.PusheeTxn
+		if pushResp.Waited {
+			waitedTxns[txn.ID] = struct{}{}
+		}
 		if _, ok := pushedTxns[txn.ID]; ok {
 			log.Fatalf(ctx, "have two PushTxn responses for %s\nreqs: %+v", txn.ID, pushReqs)
 		}
+		// An abandoned STAGING transaction can't be pushed; its outcome has
+		// to be recovered before its intents can be resolved.
+		if txn.Status == roachpb.STAGING {
+			recovered, pErr := ir.recoverTxn(ctx, &txn)
+			if pErr != nil {
+				return nil, nil, pErr
+			}
+			txn = *recovered
+		}
 		pushedTxns[txn.ID] = txn
 		log.Eventf(ctx, "%s is now %s", txn.ID, txn.Status)
 	}
 
-	var resolveIntents []roachpb.Intent
+	var resolveIntents, waitedIntents []roachpb.Intent
 	for _, intent := range pushIntents {
 		pushee, ok := pushedTxns[intent.Txn.ID]
 		if !ok {
 			log.Fatalf(ctx, "no PushTxn response for intent %+v\nreqs: %+v", intent, pushReqs)
 		}
+		if _, ok := waitedTxns[intent.Txn.ID]; ok {
+			waitedIntents = append(waitedIntents, intent)
+		}
 		intent.Txn = pushee.TxnMeta
 		intent.Status = pushee.Status
 		resolveIntents = append(resolveIntents, intent)
 	}
-	return resolveIntents, nil
+	return resolveIntents, waitedIntents, nil
+}
+
+// recoverTxn determines the outcome of an abandoned STAGING transaction
+// and finalizes its record accordingly. The transaction is implicitly
+// committed if all of its in-flight writes succeeded at or below the
+// timestamp of its record. Each write is queried with IfMissing set to
+// PREVENT, so that a write which is found missing can no longer succeed
+// at that timestamp, in which case the transaction is aborted.
+//
+// An error is returned if the transaction record was modified in the
+// meantime and the transaction is still STAGING, in which case the
+// caller should retry.
+func (ir *intentResolver) recoverTxn(
+	ctx context.Context, txn *roachpb.Transaction,
+) (*roachpb.Transaction, *roachpb.Error) {
+	log.Eventf(ctx, "recovering staging transaction %s", txn.ID)
+	implicitlyCommitted := true
+	if len(txn.InFlightWrites) > 0 {
+		var queryReqs []roachpb.Request
+		for _, w := range txn.InFlightWrites {
+			meta := txn.TxnMeta
+			meta.Sequence = w.Sequence
+			queryReqs = append(queryReqs, &roachpb.QueryIntentRequest{
+				RequestHeader: roachpb.RequestHeader{Key: w.Key},
+				Txn:           meta,
+				IfMissing:     roachpb.QueryIntentRequest_PREVENT,
+			})
+		}
+		b := &client.Batch{}
+		b.AddRawRequest(queryReqs...)
+		if err := ir.store.db.Run(ctx, b); err != nil {
+			return nil, b.MustPErr()
+		}
+		for _, resp := range b.RawResponse().Responses {
+			if !resp.GetInner().(*roachpb.QueryIntentResponse).FoundIntent {
+				implicitlyCommitted = false
+				break
+			}
+		}
+	}
+
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.RecoverTxnRequest{
+		RequestHeader:       roachpb.RequestHeader{Key: txn.Key},
+		Txn:                 txn.TxnMeta,
+		ImplicitlyCommitted: implicitlyCommitted,
+	})
+	ir.store.metrics.RecoverTxn.Inc(1)
+	if err := ir.store.db.Run(ctx, b); err != nil {
+		return nil, b.MustPErr()
+	}
+	recovered := &b.RawResponse().Responses[0].GetInner().(*roachpb.RecoverTxnResponse).RecoveredTxn
+	if recovered.Status == roachpb.STAGING {
+		return nil, roachpb.NewErrorf("staging transaction %s changed during recovery", txn.ID.Short())
+	}
+	return recovered, nil
 }
 
 // runAsyncTask semi-synchronously runs a generic task function. If
@@ -658,7 +774,7 @@ func (ir *intentResolver) cleanupIntents(
 	ctx context.Context, intents []roachpb.Intent, now hlc.Timestamp, pushType roachpb.PushTxnType,
 ) (int, error) {
 	h := roachpb.Header{Timestamp: now}
-	resolveIntents, pushErr := ir.maybePushTransactions(
+	resolveIntents, _, pushErr := ir.maybePushTransactions(
 		ctx, intents, h, pushType, true, /* skipIfInFlight */
 	)
 	if pushErr != nil {
@@ -767,9 +883,9 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 			}
 			defer release()
 
-			// If the transaction is still pending, but expired, push it
-			// before resolving the intents.
-			if txn.Status == roachpb.PENDING {
+			// If the transaction is still pending or staging, but expired,
+			// push it before resolving the intents.
+			if !txn.Status.IsFinalized() {
 				if !txnwait.IsExpired(now, txn) {
 					log.VErrEventf(ctx, 3, "cannot push a PENDING transaction which is not expired: %s", txn)
 					return
@@ -791,9 +907,17 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 				}
 				// Get the pushed txn and update the intents slice.
 				txn = &b.RawResponse().Responses[0].GetInner().(*roachpb.PushTxnResponse).PusheeTxn
-				for _, intent := range intents {
-					intent.Txn = txn.TxnMeta
-					intent.Status = txn.Status
+				if txn.Status == roachpb.STAGING {
+					recovered, pErr := ir.recoverTxn(ctx, txn)
+					if pErr != nil {
+						log.VErrEventf(ctx, 2, "failed to recover STAGING, expired txn (%s): %s", txn, pErr)
+						return
+					}
+					txn = recovered
+				}
+				for i := range intents {
+					intents[i].Txn = txn.TxnMeta
+					intents[i].Status = txn.Status
 				}
 			}
 
@@ -0,0 +1,265 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package locktable provides the table of unreplicated locks that
+// transactions acquire on the keys returned by locking scans (see
+// roachpb.KeyLockingStrength), e.g. for SELECT ... FOR UPDATE.
+//
+// The locks are held in memory by the leaseholder of a range and are lost
+// when the lease changes hands, so they aren't needed for correctness:
+// isolation is still provided by intents and the timestamp cache. Their
+// purpose is to make transactions which are going to conflict queue up behind
+// each other early, instead of all reading the same keys and then running
+// into each other's intents (or the timestamp cache) and retrying.
+//
+// A conflict with a lock is reported in the same way as a conflict with an
+// intent, by a WriteIntentError naming the transaction holding the lock. The
+// conflicting request then pushes the holder through the txnwait.Queue and
+// retries once the holder has finished (or has been aborted) and its locks
+// have been released. Locks are released when the holder's intents are
+// resolved, which requires the holder to include the spans of its locking
+// scans in the intent spans of its EndTransaction request.
+package locktable
+
+import (
+	"bytes"
+
+	"github.com/google/btree"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// lock is the set of transactions holding a lock on a key or SQL row.
+type lock struct {
+	span     roachpb.Span
+	strength roachpb.KeyLockingStrength
+	// The holders of the lock. Only LOCK_SHARED locks can have more than one
+	// holder.
+	holders []enginepb.TxnMeta
+}
+
+// Less implements the btree.Item interface.
+func (l *lock) Less(than btree.Item) bool {
+	return l.span.Key.Compare(than.(*lock).span.Key) < 0
+}
+
+func (l *lock) heldBy(txnID uuid.UUID) int {
+	for i := range l.holders {
+		if l.holders[i].ID == txnID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Table is a table of the unreplicated locks held on the keys of a range.
+// It is safe for concurrent use.
+type Table struct {
+	mu struct {
+		syncutil.Mutex
+		locks *btree.BTree
+		// The number of locks held by each transaction.
+		byTxn map[uuid.UUID]int
+	}
+}
+
+// New returns a new, empty lock table.
+func New() *Table {
+	t := &Table{}
+	t.Clear()
+	return t
+}
+
+// LockSpan returns the span on which the lock protecting the given key is
+// held. For SQL table keys, this is the span of the row, so that all of the
+// column families of a row are locked together. For all other keys, it's the
+// key itself.
+func LockSpan(key roachpb.Key) roachpb.Span {
+	if rowKey, err := keys.EnsureSafeSplitKey(key); err == nil &&
+		len(rowKey) > 0 && len(rowKey) < len(key) {
+		return roachpb.Span{Key: rowKey, EndKey: rowKey.PrefixEnd()}
+	}
+	return roachpb.Span{Key: key}
+}
+
+// conflicts returns whether a lock of the given strength held by another
+// transaction conflicts with a request of the given strength. Writes are
+// treated as requests of strength LOCK_EXCLUSIVE.
+func conflicts(held, req roachpb.KeyLockingStrength) bool {
+	return held == roachpb.LOCK_EXCLUSIVE || req == roachpb.LOCK_EXCLUSIVE
+}
+
+// Conflicts returns the locks held by transactions other than txn (which is
+// nil for non-transactional requests) which conflict with a request of the
+// given strength over the span, in key order. Writes must pass
+// LOCK_EXCLUSIVE. Each conflicting lock is returned as a pending intent of its
+// holder over the span of the lock (see LockSpan), suitable for use in a
+// WriteIntentError.
+func (t *Table) Conflicts(
+	txn *enginepb.TxnMeta, span roachpb.Span, str roachpb.KeyLockingStrength,
+) []roachpb.Intent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.locks.Len() == 0 {
+		return nil
+	}
+	var intents []roachpb.Intent
+	t.ascendSpanLocked(span, func(l *lock) {
+		if !conflicts(l.strength, str) {
+			return
+		}
+		for _, holder := range l.holders {
+			if txn != nil && holder.ID == txn.ID {
+				continue
+			}
+			intents = append(intents, roachpb.Intent{
+				Span:   l.span,
+				Txn:    holder,
+				Status: roachpb.PENDING,
+			})
+		}
+	})
+	return intents
+}
+
+// Acquire acquires locks of the given strength on the given keys for the
+// transaction. Locks already held by the transaction are upgraded to the
+// given strength if necessary. The caller must have checked for conflicts
+// with Conflicts and must prevent conflicting requests from running
+// concurrently, which the command queue does for the requests that might
+// acquire locks.
+func (t *Table) Acquire(
+	txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength, lockedKeys []roachpb.Key,
+) {
+	if str == roachpb.LOCK_NONE {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range lockedKeys {
+		span := LockSpan(key)
+		if i := t.mu.locks.Get(&lock{span: span}); i != nil {
+			l := i.(*lock)
+			if idx := l.heldBy(txn.ID); idx >= 0 {
+				l.holders[idx] = *txn
+			} else {
+				l.holders = append(l.holders, *txn)
+				t.mu.byTxn[txn.ID]++
+			}
+			if str > l.strength {
+				l.strength = str
+			}
+			continue
+		}
+		// Copy the key, which may point into a larger buffer (such as a scan
+		// response) that we don't want to hold on to.
+		span.Key = append(roachpb.Key(nil), span.Key...)
+		t.mu.locks.ReplaceOrInsert(&lock{
+			span:     span,
+			strength: str,
+			holders:  []enginepb.TxnMeta{*txn},
+		})
+		t.mu.byTxn[txn.ID]++
+	}
+}
+
+// Release releases the locks held by the transaction in the span.
+func (t *Table) Release(txnID uuid.UUID, span roachpb.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.byTxn[txnID] == 0 {
+		return
+	}
+	var released []*lock
+	t.ascendSpanLocked(span, func(l *lock) {
+		if l.heldBy(txnID) >= 0 {
+			released = append(released, l)
+		}
+	})
+	for _, l := range released {
+		t.releaseLocked(txnID, l)
+	}
+}
+
+// ReleaseTxn releases all of the locks held by the transaction.
+func (t *Table) ReleaseTxn(txnID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.byTxn[txnID] == 0 {
+		return
+	}
+	var released []*lock
+	t.mu.locks.Ascend(func(i btree.Item) bool {
+		if l := i.(*lock); l.heldBy(txnID) >= 0 {
+			released = append(released, l)
+		}
+		return true
+	})
+	for _, l := range released {
+		t.releaseLocked(txnID, l)
+	}
+}
+
+// Clear releases all locks. It is called when the replica loses its lease,
+// at which point the locks can no longer be enforced.
+func (t *Table) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.locks = btree.New(16 /* degree */)
+	t.mu.byTxn = make(map[uuid.UUID]int)
+}
+
+// Len returns the number of locked keys.
+func (t *Table) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mu.locks.Len()
+}
+
+func (t *Table) releaseLocked(txnID uuid.UUID, l *lock) {
+	idx := l.heldBy(txnID)
+	l.holders = append(l.holders[:idx], l.holders[idx+1:]...)
+	if len(l.holders) == 0 {
+		t.mu.locks.Delete(l)
+	}
+	if t.mu.byTxn[txnID]--; t.mu.byTxn[txnID] <= 0 {
+		delete(t.mu.byTxn, txnID)
+	}
+}
+
+// ascendSpanLocked calls fn for each lock overlapping the span, in key
+// order. A span with no end key covers the single key.
+func (t *Table) ascendSpanLocked(span roachpb.Span, fn func(*lock)) {
+	// Widen the start of the span to the start of its row, which covers the
+	// lock held on the row by requests for any of its column families.
+	start := &lock{span: LockSpan(span.Key)}
+	if len(span.EndKey) == 0 {
+		if i := t.mu.locks.Get(start); i != nil {
+			fn(i.(*lock))
+		}
+		return
+	}
+	t.mu.locks.AscendGreaterOrEqual(start, func(i btree.Item) bool {
+		l := i.(*lock)
+		if bytes.Compare(l.span.Key, span.EndKey) >= 0 {
+			return false
+		}
+		fn(l)
+		return true
+	})
+}
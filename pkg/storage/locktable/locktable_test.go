@@ -0,0 +1,141 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package locktable
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func makeTxn() *enginepb.TxnMeta {
+	return &enginepb.TxnMeta{ID: uuid.MakeV4(), Key: roachpb.Key("txn")}
+}
+
+func conflictingTxns(intents []roachpb.Intent) map[uuid.UUID]bool {
+	m := make(map[uuid.UUID]bool)
+	for _, intent := range intents {
+		m[intent.Txn.ID] = true
+	}
+	return m
+}
+
+func TestLockTableConflicts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	spanAC := roachpb.Span{Key: keyA, EndKey: keyC}
+	txn1, txn2, txn3 := makeTxn(), makeTxn(), makeTxn()
+
+	lt := New()
+	lt.Acquire(txn1, roachpb.LOCK_SHARED, []roachpb.Key{keyA})
+	lt.Acquire(txn2, roachpb.LOCK_SHARED, []roachpb.Key{keyA})
+	lt.Acquire(txn3, roachpb.LOCK_EXCLUSIVE, []roachpb.Key{keyB})
+	if l := lt.Len(); l != 2 {
+		t.Fatalf("expected 2 locked keys, got %d", l)
+	}
+
+	testCases := []struct {
+		name string
+		txn  *enginepb.TxnMeta
+		span roachpb.Span
+		str  roachpb.KeyLockingStrength
+		exp  []*enginepb.TxnMeta
+	}{
+		{"shared on shared", txn3, roachpb.Span{Key: keyA}, roachpb.LOCK_SHARED, nil},
+		{"exclusive on shared", txn3, roachpb.Span{Key: keyA}, roachpb.LOCK_EXCLUSIVE,
+			[]*enginepb.TxnMeta{txn1, txn2}},
+		{"exclusive on own shared", txn1, roachpb.Span{Key: keyA}, roachpb.LOCK_EXCLUSIVE,
+			[]*enginepb.TxnMeta{txn2}},
+		{"shared on exclusive", txn1, roachpb.Span{Key: keyB}, roachpb.LOCK_SHARED,
+			[]*enginepb.TxnMeta{txn3}},
+		{"own exclusive", txn3, roachpb.Span{Key: keyB}, roachpb.LOCK_EXCLUSIVE, nil},
+		{"non-transactional", nil, spanAC, roachpb.LOCK_EXCLUSIVE,
+			[]*enginepb.TxnMeta{txn1, txn2, txn3}},
+		{"unlocked", txn1, roachpb.Span{Key: keyC}, roachpb.LOCK_EXCLUSIVE, nil},
+		{"span end is exclusive", txn1, roachpb.Span{Key: keyA, EndKey: keyB}, roachpb.LOCK_SHARED, nil},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			intents := lt.Conflicts(c.txn, c.span, c.str)
+			if len(intents) != len(c.exp) {
+				t.Fatalf("expected %d conflicts, got %v", len(c.exp), intents)
+			}
+			found := conflictingTxns(intents)
+			for _, txn := range c.exp {
+				if !found[txn.ID] {
+					t.Errorf("expected conflict with %s, got %v", txn.ID, intents)
+				}
+			}
+		})
+	}
+
+	// Releasing the locks of txn1 and txn2 leaves the lock held by txn3.
+	lt.Release(txn1.ID, spanAC)
+	lt.ReleaseTxn(txn2.ID)
+	if l := lt.Len(); l != 1 {
+		t.Fatalf("expected 1 locked key, got %d", l)
+	}
+	if intents := lt.Conflicts(txn1, spanAC, roachpb.LOCK_EXCLUSIVE); len(intents) != 1 ||
+		intents[0].Txn.ID != txn3.ID || !intents[0].Span.Equal(roachpb.Span{Key: keyB}) {
+		t.Fatalf("expected only a conflict with txn3 on %s, got %v", keyB, intents)
+	}
+	// Releasing a span that doesn't contain the lock is a no-op.
+	lt.Release(txn3.ID, roachpb.Span{Key: keyA})
+	if l := lt.Len(); l != 1 {
+		t.Fatalf("expected 1 locked key, got %d", l)
+	}
+	lt.Clear()
+	if l := lt.Len(); l != 0 {
+		t.Fatalf("expected no locked keys, got %d", l)
+	}
+}
+
+// TestLockTableRows verifies that the locks on the column families of a SQL
+// row are held on the row.
+func TestLockTableRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rowKey := roachpb.Key(encoding.EncodeVarintAscending(keys.MakeTablePrefix(51), 1))
+	rowKey = encoding.EncodeVarintAscending(rowKey, 7)
+	fam0 := roachpb.Key(keys.MakeFamilyKey(append([]byte(nil), rowKey...), 0))
+	fam1 := roachpb.Key(keys.MakeFamilyKey(append([]byte(nil), rowKey...), 1))
+
+	txn1, txn2 := makeTxn(), makeTxn()
+	lt := New()
+	lt.Acquire(txn1, roachpb.LOCK_EXCLUSIVE, []roachpb.Key{fam0, fam1})
+	if l := lt.Len(); l != 1 {
+		t.Fatalf("expected 1 locked row, got %d", l)
+	}
+	rowSpan := roachpb.Span{Key: rowKey, EndKey: rowKey.PrefixEnd()}
+	if intents := lt.Conflicts(txn2, roachpb.Span{Key: fam1}, roachpb.LOCK_EXCLUSIVE); len(intents) != 1 ||
+		!intents[0].Span.Equal(rowSpan) {
+		t.Fatalf("expected a conflict on %s, got %v", rowSpan, intents)
+	}
+	if intents := lt.Conflicts(
+		txn2, roachpb.Span{Key: fam1, EndKey: fam1.PrefixEnd()}, roachpb.LOCK_EXCLUSIVE,
+	); len(intents) != 1 {
+		t.Fatalf("expected a conflict on %s, got %v", fam1, intents)
+	}
+	lt.Release(txn1.ID, roachpb.Span{Key: fam0})
+	if l := lt.Len(); l != 0 {
+		t.Fatalf("expected no locked rows, got %d", l)
+	}
+}
@@ -892,6 +892,12 @@ var (
 		Measurement: "Intent Resolutions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRecoverTxn = metric.Metadata{
+		Name:        "intentresolver.recover_txn",
+		Help:        "Number of attempts to recover the outcome of abandoned STAGING transactions",
+		Measurement: "Recovery Attempts",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Slow request metrics.
 	metaSlowCommandQueueRequests = metric.Metadata{
@@ -1125,6 +1131,7 @@ type StoreMetrics struct {
 
 	// Intent resolver metrics.
 	IntentResolverAsyncThrottled *metric.Counter
+	RecoverTxn                   *metric.Counter
 
 	// Slow request counts.
 	SlowCommandQueueRequests *metric.Gauge
@@ -1322,6 +1329,7 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 
 		// Intent resolver metrics.
 		IntentResolverAsyncThrottled: metric.NewCounter(metaIntentResolverAsyncThrottled),
+		RecoverTxn:                   metric.NewCounter(metaRecoverTxn),
 
 		// Wedge request counters.
 		SlowCommandQueueRequests: metric.NewGauge(metaSlowCommandQueueRequests),
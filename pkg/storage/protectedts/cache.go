@@ -0,0 +1,134 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package protectedts
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// PollInterval is the interval at which each node refreshes its cache of
+// protected timestamp records.
+var PollInterval = settings.RegisterValidatedDurationSetting(
+	"kv.protectedts.poll_interval",
+	"the interval at which the protected timestamp records are read by each node",
+	2*time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("poll interval must be positive: %s", v)
+		}
+		return nil
+	},
+)
+
+// Cache provides the protected timestamps which apply to spans.
+type Cache interface {
+	// Protected returns the earliest timestamp protected by a record which
+	// overlaps sp, or the zero timestamp if no record overlaps it. asOf is the
+	// timestamp at which the records were read; records written later are not
+	// taken into account. A zero asOf indicates that the records have not been
+	// read yet.
+	Protected(sp roachpb.Span) (protected, asOf hlc.Timestamp)
+}
+
+// PollingCache is a Cache which periodically reads all the records.
+type PollingCache struct {
+	db *client.DB
+	ex sqlutil.InternalExecutor
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.RWMutex
+		records []Record
+		asOf    hlc.Timestamp
+	}
+}
+
+var _ Cache = &PollingCache{}
+
+// NewPollingCache creates a new PollingCache. The cache is empty until it is
+// started.
+func NewPollingCache(
+	db *client.DB, ex sqlutil.InternalExecutor, st *cluster.Settings,
+) *PollingCache {
+	return &PollingCache{db: db, ex: ex, st: st}
+}
+
+// Protected implements the Cache interface.
+func (c *PollingCache) Protected(sp roachpb.Span) (protected, asOf hlc.Timestamp) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.mu.records {
+		for _, rsp := range r.Spans {
+			if !rsp.Overlaps(sp) {
+				continue
+			}
+			if protected == (hlc.Timestamp{}) || r.Timestamp.Less(protected) {
+				protected = r.Timestamp
+			}
+			break
+		}
+	}
+	return protected, c.mu.asOf
+}
+
+// Refresh reads all the records and replaces the contents of the cache.
+func (c *PollingCache) Refresh(ctx context.Context) error {
+	var records []Record
+	var asOf hlc.Timestamp
+	if err := c.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		records, err = GetRecords(ctx, c.ex, txn)
+		asOf = txn.OrigTimestamp()
+		return err
+	}); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.records = records
+	c.mu.asOf = asOf
+	return nil
+}
+
+// Start refreshes the cache and then keeps refreshing it every PollInterval
+// until the stopper is stopped. It must be called once the system.protected_ts
+// table exists.
+func (c *PollingCache) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			if err := c.Refresh(ctx); err != nil {
+				log.Warningf(ctx, "failed to refresh protected timestamp records: %s", err)
+			}
+			select {
+			case <-time.After(PollInterval.Get(&c.st.SV)):
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
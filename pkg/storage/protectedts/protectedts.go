@@ -0,0 +1,191 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package protectedts implements protected timestamps. A protected timestamp
+// record, stored in system.protected_ts, prevents the GC queue from advancing
+// the GC threshold of any range overlapping the record's spans past the
+// record's timestamp. Long-running operations which read at a fixed timestamp
+// (backups, changefeeds) use them so that they do not fail when they fall
+// further behind than the zone's gc.ttlseconds.
+//
+// Records are read by the GC queue through a Cache, which is refreshed
+// periodically on each node. Records which are written after a Cache has
+// been refreshed are not known to it, so the GC queue also never advances the
+// GC threshold past the time of the last refresh minus the zone's TTL. As a
+// consequence, a record is only guaranteed to be effective if its timestamp
+// is not older than the zone's TTL at the time it is written.
+package protectedts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// Record is a protected timestamp record.
+type Record struct {
+	// ID uniquely identifies the record.
+	ID int64
+	// Timestamp is the protected timestamp. Versions of keys in Spans which
+	// are visible at Timestamp are not garbage collected.
+	Timestamp hlc.Timestamp
+	// Spans are the spans protected by the record.
+	Spans []roachpb.Span
+	// JobID is the ID of the job which owns the record, or zero if the record
+	// is not associated with a job. Records owned by a job are removed when
+	// the job finishes.
+	JobID int64
+}
+
+// Protect writes a new record protecting the given spans at ts. If jobID is
+// non-zero, the record is owned by that job. It returns the ID of the record.
+func Protect(
+	ctx context.Context,
+	ex sqlutil.InternalExecutor,
+	txn *client.Txn,
+	ts hlc.Timestamp,
+	spans []roachpb.Span,
+	jobID int64,
+) (int64, error) {
+	if ts == (hlc.Timestamp{}) {
+		return 0, errors.New("cannot protect the zero timestamp")
+	}
+	if len(spans) == 0 {
+		return 0, errors.New("cannot protect an empty set of spans")
+	}
+	var job interface{}
+	if jobID != 0 {
+		job = jobID
+	}
+	const stmt = `INSERT INTO system.protected_ts (ts, job_id, spans) VALUES ($1, $2, $3) RETURNING id`
+	row, err := ex.QueryRow(ctx, "protectedts-protect", txn, stmt,
+		tree.TimestampToDecimal(ts), job, encodeSpans(spans))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to write protected timestamp record")
+	}
+	return int64(tree.MustBeDInt(row[0])), nil
+}
+
+// Release removes the record with the given ID.
+func Release(ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, id int64) error {
+	const stmt = `DELETE FROM system.protected_ts WHERE id = $1`
+	_, err := ex.Exec(ctx, "protectedts-release", txn, stmt, id)
+	return err
+}
+
+// ReleaseJob removes all the records owned by the given job.
+func ReleaseJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, jobID int64,
+) error {
+	const stmt = `DELETE FROM system.protected_ts WHERE job_id = $1`
+	_, err := ex.Exec(ctx, "protectedts-release-job", txn, stmt, jobID)
+	return err
+}
+
+// UpdateJob advances the timestamp of all the records owned by the given job
+// to ts. Records which already protect a later timestamp are not modified.
+func UpdateJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, jobID int64, ts hlc.Timestamp,
+) error {
+	const stmt = `UPDATE system.protected_ts SET ts = $1 WHERE job_id = $2 AND ts < $1`
+	_, err := ex.Exec(ctx, "protectedts-update-job", txn, stmt, tree.TimestampToDecimal(ts), jobID)
+	return err
+}
+
+// GetRecords returns all the records.
+func GetRecords(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn,
+) ([]Record, error) {
+	const stmt = `SELECT id, ts, job_id, spans FROM system.protected_ts`
+	rows, _, err := ex.Query(ctx, "protectedts-get-records", txn, stmt)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		r := Record{ID: int64(tree.MustBeDInt(row[0]))}
+		d, ok := row[1].(*tree.DDecimal)
+		if !ok {
+			return nil, errors.Errorf("expected decimal timestamp for record %d, got %T", r.ID, row[1])
+		}
+		if r.Timestamp, err = tree.DecimalToHLC(&d.Decimal); err != nil {
+			return nil, errors.Wrapf(err, "decoding timestamp of record %d", r.ID)
+		}
+		if row[2] != tree.DNull {
+			r.JobID = int64(tree.MustBeDInt(row[2]))
+		}
+		if r.Spans, err = decodeSpans([]byte(tree.MustBeDBytes(row[3]))); err != nil {
+			return nil, errors.Wrapf(err, "decoding spans of record %d", r.ID)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// ReclaimStale removes the records owned by jobs which no longer exist or
+// whose status is not one of liveStatuses. It returns the number of records
+// removed.
+func ReclaimStale(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, liveStatuses ...string,
+) (int, error) {
+	placeholders := make([]string, len(liveStatuses))
+	args := make([]interface{}, len(liveStatuses))
+	for i, s := range liveStatuses {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = s
+	}
+	statusFilter := "false"
+	if len(placeholders) > 0 {
+		statusFilter = fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", "))
+	}
+	stmt := fmt.Sprintf(`DELETE FROM system.protected_ts WHERE job_id IS NOT NULL AND
+job_id NOT IN (SELECT id FROM system.jobs WHERE %s)`, statusFilter)
+	return ex.Exec(ctx, "protectedts-reclaim", txn, stmt, args...)
+}
+
+// encodeSpans encodes spans as a sequence of (key, end key) pairs.
+func encodeSpans(spans []roachpb.Span) []byte {
+	var b []byte
+	for _, sp := range spans {
+		b = encoding.EncodeBytesAscending(b, sp.Key)
+		b = encoding.EncodeBytesAscending(b, sp.EndKey)
+	}
+	return b
+}
+
+// decodeSpans decodes spans encoded by encodeSpans.
+func decodeSpans(b []byte) ([]roachpb.Span, error) {
+	var spans []roachpb.Span
+	for len(b) > 0 {
+		var sp roachpb.Span
+		var err error
+		if b, sp.Key, err = encoding.DecodeBytesAscending(b, nil); err != nil {
+			return nil, err
+		}
+		if b, sp.EndKey, err = encoding.DecodeBytesAscending(b, nil); err != nil {
+			return nil, err
+		}
+		spans = append(spans, sp)
+	}
+	return spans, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package protectedts_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestProtectedTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+	r := sqlutils.MakeSQLRunner(sqlDB)
+
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime, Logical: 1}
+	}
+	protect := func(ts hlc.Timestamp, jobID int64, spans ...roachpb.Span) int64 {
+		t.Helper()
+		id, err := protectedts.Protect(ctx, ex, nil /* txn */, ts, spans, jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	records := func() map[int64]protectedts.Record {
+		t.Helper()
+		recs, err := protectedts.GetRecords(ctx, ex, nil /* txn */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[int64]protectedts.Record, len(recs))
+		for _, rec := range recs {
+			m[rec.ID] = rec
+		}
+		return m
+	}
+
+	// A record which isn't owned by a job, and two records owned by a running
+	// job and a job which doesn't exist, respectively.
+	r.Exec(t, `INSERT INTO system.jobs (id, status, payload) VALUES (1, 'running', '')`)
+	id1 := protect(ts(10), 0 /* jobID */, span("a", "c"), span("x", "z"))
+	id2 := protect(ts(20), 1 /* jobID */, span("b", "d"))
+	id3 := protect(ts(30), 2 /* jobID */, span("e", "f"))
+
+	expected := map[int64]protectedts.Record{
+		id1: {ID: id1, Timestamp: ts(10), Spans: []roachpb.Span{span("a", "c"), span("x", "z")}},
+		id2: {ID: id2, Timestamp: ts(20), Spans: []roachpb.Span{span("b", "d")}, JobID: 1},
+		id3: {ID: id3, Timestamp: ts(30), Spans: []roachpb.Span{span("e", "f")}, JobID: 2},
+	}
+	if recs := records(); !reflect.DeepEqual(expected, recs) {
+		t.Fatalf("expected %+v, got %+v", expected, recs)
+	}
+
+	t.Run("cache", func(t *testing.T) {
+		c := protectedts.NewPollingCache(kvDB, ex, s.ClusterSettings())
+		if _, asOf := c.Protected(span("a", "b")); asOf != (hlc.Timestamp{}) {
+			t.Fatalf("expected empty cache, got asOf %s", asOf)
+		}
+		before := s.Clock().Now()
+		if err := c.Refresh(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			sp  roachpb.Span
+			exp hlc.Timestamp
+		}{
+			{span("a", "b"), ts(10)},
+			{span("c", "d"), ts(20)},
+			{span("a", "z"), ts(10)},
+			{span("d", "e"), hlc.Timestamp{}},
+			{span("e", "x"), ts(30)},
+		} {
+			protected, asOf := c.Protected(tc.sp)
+			if protected != tc.exp {
+				t.Errorf("%s: expected protected timestamp %s, got %s", tc.sp, tc.exp, protected)
+			}
+			if asOf.Less(before) {
+				t.Errorf("%s: expected records read after %s, got %s", tc.sp, before, asOf)
+			}
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		// Records are only ever advanced.
+		if err := protectedts.UpdateJob(ctx, ex, nil /* txn */, 1 /* jobID */, ts(15)); err != nil {
+			t.Fatal(err)
+		}
+		if rec := records()[id2]; rec.Timestamp != ts(20) {
+			t.Fatalf("expected %s, got %s", ts(20), rec.Timestamp)
+		}
+		if err := protectedts.UpdateJob(ctx, ex, nil /* txn */, 1 /* jobID */, ts(25)); err != nil {
+			t.Fatal(err)
+		}
+		if rec := records()[id2]; rec.Timestamp != ts(25) {
+			t.Fatalf("expected %s, got %s", ts(25), rec.Timestamp)
+		}
+	})
+
+	t.Run("reclaim", func(t *testing.T) {
+		n, err := protectedts.ReclaimStale(ctx, ex, nil /* txn */, "running")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Fatalf("expected 1 record to be reclaimed, got %d", n)
+		}
+		recs := records()
+		if _, ok := recs[id3]; ok {
+			t.Fatalf("expected record %d of missing job to be reclaimed", id3)
+		}
+
+		// Once the job finishes, its record is reclaimed as well.
+		r.Exec(t, `UPDATE system.jobs SET status = 'succeeded' WHERE id = 1`)
+		if _, err := protectedts.ReclaimStale(ctx, ex, nil /* txn */, "running"); err != nil {
+			t.Fatal(err)
+		}
+		if recs := records(); len(recs) != 1 || recs[id1].ID != id1 {
+			t.Fatalf("expected only record %d to remain, got %+v", id1, recs)
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		protect(ts(40), 3 /* jobID */, span("a", "b"))
+		if err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			if err := protectedts.ReleaseJob(ctx, ex, txn, 3 /* jobID */); err != nil {
+				return err
+			}
+			return protectedts.Release(ctx, ex, txn, id1)
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if recs := records(); len(recs) != 0 {
+			t.Fatalf("expected no records, got %+v", recs)
+		}
+		spans := []roachpb.Span{span("a", "b")}
+		_, err := protectedts.Protect(ctx, ex, nil /* txn */, hlc.Timestamp{}, spans, 0 /* jobID */)
+		if !testutils.IsError(err, "cannot protect the zero timestamp") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -48,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
@@ -262,6 +263,7 @@ type Replica struct {
 	store        *Store
 	abortSpan    *abortspan.AbortSpan // Avoids anomalous reads after abort
 	txnWaitQueue *txnwait.Queue       // Queues push txn attempts by txn ID
+	lockTable    *locktable.Table     // Unreplicated locks of locking scans
 
 	// leaseholderStats tracks all incoming BatchRequests to the replica and which
 	// localities they come from in order to aid in lease rebalancing decisions.
@@ -269,6 +271,9 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// writeBytesStats tracks the number of bytes written by applied raft
+	// commands, in order to report the ranges with the most write traffic.
+	writeBytesStats *replicaStats
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -639,6 +644,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 		store:          store,
 		abortSpan:      abortspan.New(rangeID),
 		txnWaitQueue:   txnwait.NewQueue(store),
+		lockTable:      locktable.New(),
 	}
 	r.mu.pendingLeaseRequest = makePendingLeaseRequest(r)
 	r.mu.stateLoader = stateloader.Make(r.store.cfg.Settings, rangeID)
@@ -652,6 +658,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 	// Pass nil for the localityOracle because we intentionally don't track the
 	// origin locality of write load.
 	r.writeStats = newReplicaStats(store.Clock(), nil)
+	r.writeBytesStats = newReplicaStats(store.Clock(), nil)
 
 	// Init rangeStr with the range ID.
 	r.rangeStr.store(0, &roachpb.RangeDescriptor{RangeID: rangeID})
@@ -1630,6 +1637,11 @@ func (r *Replica) GetTxnWaitQueue() *txnwait.Queue {
 	return r.txnWaitQueue
 }
 
+// GetLockTable returns the Replica's locktable.Table.
+func (r *Replica) GetLockTable() *locktable.Table {
+	return r.lockTable
+}
+
 // GetTerm returns the term of the given index in the raft log.
 func (r *Replica) GetTerm(i uint64) (uint64, error) {
 	r.mu.RLock()
@@ -1657,6 +1669,19 @@ func (r *Replica) GetTxnSpanGCThreshold() hlc.Timestamp {
 	return *r.mu.state.TxnSpanGCThreshold
 }
 
+// GetProtectedTimestamp returns the earliest timestamp protected by a
+// protected timestamp record overlapping the range, or the zero timestamp if
+// there is no such record. It returns false if the records have not been read
+// yet.
+func (r *Replica) GetProtectedTimestamp() (hlc.Timestamp, bool) {
+	cache := r.store.cfg.ProtectedTimestampCache
+	if cache == nil {
+		return hlc.Timestamp{}, true
+	}
+	protected, asOf := cache.Protected(r.Desc().RSpan().AsRawSpanWithNoLocals())
+	return protected, asOf != (hlc.Timestamp{})
+}
+
 // setDesc atomically sets the range's descriptor. This method calls
 // processRangeDescriptorUpdate() to make the Store handle the descriptor
 // update. Requires raftMu to be locked.
@@ -2097,7 +2122,8 @@ type endCmds struct {
 }
 
 // done removes pending commands from the command queue and updates
-// the timestamp cache using the final timestamp of each command.
+// the timestamp cache using the final timestamp of each command, as
+// well as the lock table.
 func (ec *endCmds) done(br *roachpb.BatchResponse, pErr *roachpb.Error, retry proposalRetryReason) {
 	// Update the timestamp cache if the command is not being
 	// retried. Each request is considered in turn; only those marked as
@@ -2105,6 +2131,9 @@ func (ec *endCmds) done(br *roachpb.BatchResponse, pErr *roachpb.Error, retry pr
 	// excluded.
 	if retry == proposalNoRetry && ec.ba.ReadConsistency == roachpb.CONSISTENT {
 		ec.repl.updateTimestampCache(&ec.ba, br, pErr)
+		if pErr == nil {
+			ec.repl.updateLockTable(ec.repl.AnnotateCtx(context.TODO()), &ec.ba, br)
+		}
 	}
 
 	if fn := ec.repl.store.cfg.TestingKnobs.OnCommandQueueAction; fn != nil {
@@ -2802,6 +2831,7 @@ func (r *Replica) maybeWatchForMerge(ctx context.Context) error {
 			// Clear the wait queue to redirect the queued transactions to the
 			// left-hand replica, if necessary.
 			r.txnWaitQueue.Clear(true /* disable */)
+			r.lockTable.Clear()
 		}
 		// Unblock pending requests. If the merge committed, the requests will
 		// notice that the replica has been destroyed and return an appropriate
@@ -2875,6 +2905,12 @@ func (r *Replica) executeReadOnlyBatch(
 		return nil, roachpb.NewError(err)
 	}
 
+	if ba.IsLocking() {
+		if pErr := r.checkLockConflicts(&ba); pErr != nil {
+			return nil, pErr
+		}
+	}
+
 	// Evaluate read-only batch command. It checks for matching key range; note
 	// that holding readOnlyCmdMu throughout is important to avoid reads from the
 	// "wrong" key range being served after the range has been split.
@@ -3055,6 +3091,12 @@ func (r *Replica) tryExecuteWriteBatch(
 	}
 	r.limitTxnMaxTimestamp(ctx, &ba, status)
 
+	if !ba.IsLeaseRequest() {
+		if pErr := r.checkLockConflicts(&ba); pErr != nil {
+			return nil, pErr, proposalNoRetry
+		}
+	}
+
 	// TODO(tschottdorf): hook up the closed timestamp subsystem. For now, this
 	// block serves as an easily enabled canary that verifies whether the system
 	// is set up where it's needed.
@@ -5484,6 +5526,7 @@ func (r *Replica) applyRaftCommand(
 		} else {
 			r.writeStats.recordCount(float64(mutationCount), 0 /* nodeID */)
 		}
+		r.writeBytesStats.recordCount(float64(len(writeBatch.Data)), 0 /* nodeID */)
 	}
 
 	r.mu.Lock()
@@ -5924,6 +5967,52 @@ func optimizePuts(
 	return reqs
 }
 
+// stripInFlightWritesInBatch removes the in-flight writes of a staging
+// EndTransaction request which are evaluated in the same batch, ahead of it.
+// These writes succeed if and only if the EndTransaction does, so they don't
+// need to be proven. If no in-flight writes remain, the EndTransaction
+// commits the transaction directly instead of staging it.
+func stripInFlightWritesInBatch(reqs []roachpb.RequestUnion) []roachpb.RequestUnion {
+	last := len(reqs) - 1
+	if last < 1 {
+		return reqs
+	}
+	et, ok := reqs[last].GetInner().(*roachpb.EndTransactionRequest)
+	if !ok || len(et.InFlightWrites) == 0 {
+		return reqs
+	}
+	type write struct {
+		key string
+		seq int32
+	}
+	inBatch := make(map[write]struct{}, last)
+	for _, ru := range reqs[:last] {
+		switch t := ru.GetInner().(type) {
+		case *roachpb.QueryIntentRequest:
+			inBatch[write{string(t.Key), t.Txn.Sequence}] = struct{}{}
+		default:
+			if roachpb.IsTransactionWrite(t) && !roachpb.IsRange(t) {
+				h := t.Header()
+				inBatch[write{string(h.Key), h.Sequence}] = struct{}{}
+			}
+		}
+	}
+	var remaining []roachpb.SequencedWrite
+	for _, w := range et.InFlightWrites {
+		if _, ok := inBatch[write{string(w.Key), w.Sequence}]; !ok {
+			remaining = append(remaining, w)
+		}
+	}
+	if len(remaining) == len(et.InFlightWrites) {
+		return reqs
+	}
+	etCopy := *et
+	etCopy.InFlightWrites = remaining
+	reqs = append([]roachpb.RequestUnion(nil), reqs...)
+	reqs[last].MustSetInner(&etCopy)
+	return reqs
+}
+
 // evaluateBatch evaluates a batch request by splitting it up into its
 // individual commands, passing them to evaluateCommand, and combining
 // the results.
@@ -5955,6 +6044,7 @@ func evaluateBatch(
 	if ba.Txn != nil {
 		txnShallow := *ba.Txn
 		ba.Txn = &txnShallow
+		ba.Requests = stripInFlightWritesInBatch(ba.Requests)
 
 		// Check whether this transaction has been aborted, if applicable.
 		// This applies to writes that leave intents (the use of the
@@ -6664,6 +6754,12 @@ func (r *Replica) WritesPerSecond() float64 {
 	return wps
 }
 
+// WriteBytesPerSecond returns the range's average bytes written per second.
+func (r *Replica) WriteBytesPerSecond() float64 {
+	bps, _ := r.writeBytesStats.avgQPS()
+	return bps
+}
+
 // GetLeaseHistory returns the lease history stored on this replica.
 func (r *Replica) GetLeaseHistory() []roachpb.Lease {
 	if r.leaseHistory == nil {
@@ -59,6 +59,7 @@ var backpressurableReqMethods = util.MakeFastIntSet(
 	int(roachpb.Delete),
 	int(roachpb.DeleteRange),
 	int(roachpb.ClearRange),
+	int(roachpb.RevertRange),
 )
 
 // backpressurableSpans contains spans of keys where write backpressuring
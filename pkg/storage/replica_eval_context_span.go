@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
@@ -83,6 +84,11 @@ func (rec *SpanSetReplicaEvalContext) GetTxnWaitQueue() *txnwait.Queue {
 	return rec.i.GetTxnWaitQueue()
 }
 
+// GetLockTable returns the locktable.Table.
+func (rec *SpanSetReplicaEvalContext) GetLockTable() *locktable.Table {
+	return rec.i.GetLockTable()
+}
+
 // NodeID returns the NodeID.
 func (rec *SpanSetReplicaEvalContext) NodeID() roachpb.NodeID {
 	return rec.i.NodeID()
@@ -158,6 +164,12 @@ func (rec SpanSetReplicaEvalContext) GetTxnSpanGCThreshold() hlc.Timestamp {
 	return rec.i.GetTxnSpanGCThreshold()
 }
 
+// GetProtectedTimestamp returns the earliest timestamp protected by a
+// protected timestamp record overlapping the range.
+func (rec SpanSetReplicaEvalContext) GetProtectedTimestamp() (hlc.Timestamp, bool) {
+	return rec.i.GetProtectedTimestamp()
+}
+
 // String implements Stringer.
 func (rec SpanSetReplicaEvalContext) String() string {
 	return rec.i.String()
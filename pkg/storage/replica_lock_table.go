@@ -0,0 +1,121 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// checkLockConflicts returns a WriteIntentError if a request in the batch
+// conflicts with an unreplicated lock held by another transaction (see
+// storage/locktable). Writes conflict with all locks and locking scans with
+// locks of an incompatible strength, unless they skip locked keys (in which
+// case the conflicts are handled during evaluation). Non-locking reads never
+// conflict with locks.
+//
+// The batch must be in the command queue, which prevents conflicting
+// requests from acquiring locks until it's done.
+func (r *Replica) checkLockConflicts(ba *roachpb.BatchRequest) *roachpb.Error {
+	var txn *enginepb.TxnMeta
+	if ba.Txn != nil {
+		txn = &ba.Txn.TxnMeta
+	}
+	for i, union := range ba.Requests {
+		args := union.GetInner()
+		str := roachpb.LOCK_EXCLUSIVE
+		if !roachpb.IsTransactionWrite(args) {
+			var policy roachpb.LockWaitPolicy
+			str, policy = roachpb.Locking(args)
+			if str == roachpb.LOCK_NONE || policy == roachpb.LOCK_WAIT_SKIP {
+				continue
+			}
+		}
+		if intents := r.lockTable.Conflicts(txn, args.Header().Span(), str); len(intents) > 0 {
+			pErr := roachpb.NewError(&roachpb.WriteIntentError{Intents: intents})
+			pErr.SetErrorIndex(int32(i))
+			return pErr
+		}
+	}
+	return nil
+}
+
+// updateLockTable acquires locks on the keys returned by the locking scans in
+// the batch, and releases the locks of the transactions whose intents the
+// batch resolved or which the batch finished. It's called when a successful
+// batch is removed from the command queue.
+func (r *Replica) updateLockTable(
+	ctx context.Context, ba *roachpb.BatchRequest, br *roachpb.BatchResponse,
+) {
+	for i, union := range ba.Requests {
+		switch t := union.GetInner().(type) {
+		case *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+			str, _ := roachpb.Locking(t)
+			if str == roachpb.LOCK_NONE || ba.Txn == nil {
+				continue
+			}
+			lockedKeys, err := scannedKeys(br.Responses[i].GetInner())
+			if err != nil {
+				// The locks are only an optimization, so don't fail the request.
+				log.Warningf(ctx, "unable to acquire locks for %s: %s", t.Method(), err)
+				continue
+			}
+			r.lockTable.Acquire(&ba.Txn.TxnMeta, str, lockedKeys)
+		case *roachpb.ResolveIntentRequest:
+			if t.Status.IsFinalized() {
+				r.lockTable.Release(t.IntentTxn.ID, t.Span())
+			}
+		case *roachpb.ResolveIntentRangeRequest:
+			if t.Status.IsFinalized() {
+				r.lockTable.Release(t.IntentTxn.ID, t.Span())
+			}
+		}
+	}
+	// An EndTransaction resolves the intents on its range synchronously, so it
+	// releases all of its transaction's locks on the range. This has to come
+	// last in case the batch also contains locking scans.
+	if _, ok := ba.GetArg(roachpb.EndTransaction); ok && ba.Txn != nil {
+		r.lockTable.ReleaseTxn(ba.Txn.ID)
+	}
+}
+
+// scannedKeys returns the keys in the response to a Scan or ReverseScan.
+func scannedKeys(resp roachpb.Response) ([]roachpb.Key, error) {
+	var rows []roachpb.KeyValue
+	var batchResp []byte
+	switch t := resp.(type) {
+	case *roachpb.ScanResponse:
+		rows, batchResp = t.Rows, t.BatchResponse
+	case *roachpb.ReverseScanResponse:
+		rows, batchResp = t.Rows, t.BatchResponse
+	}
+	scanned := make([]roachpb.Key, 0, resp.Header().NumKeys)
+	for _, kv := range rows {
+		scanned = append(scanned, kv.Key)
+	}
+	for len(batchResp) > 0 {
+		key, _, rest, err := engine.MVCCScanDecodeKeyValue(batchResp)
+		if err != nil {
+			return nil, err
+		}
+		scanned = append(scanned, key.Key)
+		batchResp = rest
+	}
+	return scanned, nil
+}
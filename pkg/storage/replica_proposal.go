@@ -300,6 +300,9 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease) {
 		// Also clear and disable the push transaction queue. Any waiters
 		// must be redirected to the new lease holder.
 		r.txnWaitQueue.Clear(true /* disable */)
+		// The new lease holder doesn't know about the locks held here, so
+		// drop them.
+		r.lockTable.Clear()
 	}
 
 	if !iAmTheLeaseHolder && r.IsLeaseValid(newLease, r.store.Clock().Now()) &&
@@ -10531,3 +10531,90 @@ func TestRangeStatsRequest(t *testing.T) {
 	require.Equal(t, expMS.ValCount+1, resMS.ValCount)
 	require.Equal(t, expMS.LiveCount+1, resMS.LiveCount)
 }
+
+// TestReplicaRevertRange verifies that RevertRange clears a range which had no
+// data at the target time and advances its GC threshold, unless its history
+// is protected by a protected timestamp record, in which case the keys are
+// reverted in pages.
+func TestReplicaRevertRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	manual := hlc.NewManualClock(123)
+	tsc := TestStoreConfig(hlc.NewClock(manual.UnixNano, time.Nanosecond))
+	cache := &fakeProtectedTimestampCache{}
+	tsc.ProtectedTimestampCache = cache
+	tc := testContext{manualClock: manual}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.StartWithStoreConfig(t, stopper, tsc)
+
+	splitTestRange(tc.store, roachpb.RKey("b"), roachpb.RKey("b"), t)
+	splitTestRange(tc.store, roachpb.RKey("c"), roachpb.RKey("c"), t)
+	repl := tc.store.LookupReplica(roachpb.RKey("b"), nil)
+
+	targetTime := tc.Clock().Now()
+	manual.Increment(1)
+	keys := []roachpb.Key{roachpb.Key("b1"), roachpb.Key("b2")}
+	for _, key := range keys {
+		pArgs := putArgs(key, []byte("value"))
+		if _, pErr := client.SendWrappedWith(
+			context.Background(), repl, roachpb.Header{RangeID: repl.RangeID, Timestamp: tc.Clock().Now()}, &pArgs,
+		); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	revert := func(
+		span roachpb.Span, maxKeys int64,
+	) (*roachpb.RevertRangeResponse, hlc.Timestamp) {
+		t.Helper()
+		manual.Increment(1)
+		ts := tc.Clock().Now()
+		resp, pErr := client.SendWrappedWith(context.Background(), repl, roachpb.Header{
+			RangeID:            repl.RangeID,
+			Timestamp:          ts,
+			MaxSpanRequestKeys: maxKeys,
+		}, &roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{Key: span.Key, EndKey: span.EndKey},
+			TargetTime:    targetTime,
+		})
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		return resp.(*roachpb.RevertRangeResponse), ts
+	}
+	rangeSpan := roachpb.Span{Key: roachpb.Key("b"), EndKey: roachpb.Key("c")}
+
+	// The history of the range is protected: the keys are reverted one by one.
+	cache.protected, cache.asOf = targetTime, tc.Clock().Now()
+	resp, _ := revert(rangeSpan, 1 /* maxKeys */)
+	if e := (roachpb.Span{Key: keys[1], EndKey: rangeSpan.EndKey}); resp.NumKeys != 1 ||
+		resp.ResumeSpan == nil || !resp.ResumeSpan.EqualValue(e) {
+		t.Fatalf("expected 1 key reverted and resume span %s, got %d and %v",
+			e, resp.NumKeys, resp.ResumeSpan)
+	}
+	resp, ts := revert(*resp.ResumeSpan, 1 /* maxKeys */)
+	if resp.NumKeys != 1 || resp.ResumeSpan != nil {
+		t.Fatalf("expected 1 key reverted without resume span, got %d and %v",
+			resp.NumKeys, resp.ResumeSpan)
+	}
+	for _, key := range keys {
+		value, _, err := engine.MVCCGet(context.Background(), tc.engine, key, ts, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != nil {
+			t.Fatalf("expected %s to be reverted, found %s", key, value)
+		}
+	}
+	if threshold := repl.GetGCThreshold(); threshold != (hlc.Timestamp{}) {
+		t.Fatalf("expected the GC threshold not to be advanced, got %s", threshold)
+	}
+
+	// Once the record is released, the range is cleared.
+	cache.protected, cache.asOf = hlc.Timestamp{}, tc.Clock().Now()
+	_, ts = revert(rangeSpan, 0 /* maxKeys */)
+	if threshold := repl.GetGCThreshold(); threshold != ts {
+		t.Fatalf("expected the GC threshold to be advanced to %s, got %s", ts, threshold)
+	}
+}
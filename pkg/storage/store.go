@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -49,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
@@ -128,6 +130,13 @@ var ExportRequestsLimit = settings.RegisterIntSetting(
 	5,
 )
 
+// EngineMaintenanceHook, if set, is invoked when a store backed by RocksDB is
+// started and may launch background maintenance tasks (such as
+// re-encryption of sstables) on the store's stopper. It is set by CCL code.
+var EngineMaintenanceHook func(
+	ctx context.Context, st *cluster.Settings, eng *engine.RocksDB, stopper *stop.Stopper,
+)
+
 // TestStoreConfig has some fields initialized with values relevant in tests.
 func TestStoreConfig(clock *hlc.Clock) StoreConfig {
 	if clock == nil {
@@ -585,6 +594,15 @@ type StoreConfig struct {
 	// maintenance queue to dispatch individual maintenance tasks.
 	TimeSeriesDataStore TimeSeriesDataStore
 
+	// ProtectedTimestampCache is used by the GC queue to avoid garbage
+	// collecting data protected by protected timestamp records. If nil, only
+	// the zone's GC policy is taken into account.
+	ProtectedTimestampCache protectedts.Cache
+
+	// ContentionEvents aggregates the contention events of the stores of the
+	// node. If nil, the store uses its own registry.
+	ContentionEvents *ContentionEventRegistry
+
 	// DontRetryPushTxnFailures will propagate a push txn failure immediately
 	// instead of utilizing the txn wait queue to wait for the transaction to
 	// finish or be pushed by a higher priority contender.
@@ -859,6 +877,9 @@ func (sc *StoreConfig) SetDefaults() {
 	if sc.GossipWhenCapacityDeltaExceedsFraction == 0 {
 		sc.GossipWhenCapacityDeltaExceedsFraction = defaultGossipWhenCapacityDeltaExceedsFraction
 	}
+	if sc.ContentionEvents == nil {
+		sc.ContentionEvents = NewContentionEventRegistry()
+	}
 }
 
 // LeaseExpiration returns an int64 to increment a manual clock with to
@@ -1507,6 +1528,12 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		s.compactor.Start(s.AnnotateCtx(context.Background()), s.stopper)
 	}
 
+	if EngineMaintenanceHook != nil {
+		if rocksdb, ok := s.engine.(*engine.RocksDB); ok {
+			EngineMaintenanceHook(s.AnnotateCtx(context.Background()), s.cfg.Settings, rocksdb, s.stopper)
+		}
+	}
+
 	// Set the started flag (for unittests).
 	atomic.StoreInt32(&s.started, 1)
 
@@ -2347,6 +2374,7 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	// spans that are now owned by the new range.
 	origRng.leaseholderStats.resetRequestCounts()
 	origRng.writeStats.splitRequestCounts(newRng.writeStats)
+	origRng.writeBytesStats.splitRequestCounts(newRng.writeBytesStats)
 
 	if kr := s.mu.replicasByKey.ReplaceOrInsert(origRng); kr != nil {
 		return errors.Errorf("replicasByKey unexpectedly contains %s when inserting replica %s", kr, origRng)
@@ -2421,6 +2449,9 @@ func (s *Store) MergeRange(
 		// logic that depends on them.
 		leftRepl.writeStats.resetRequestCounts()
 	}
+	if leftRepl.writeBytesStats != nil {
+		leftRepl.writeBytesStats.resetRequestCounts()
+	}
 
 	// TODO(benesch): drain the RHS txn wait queue.
 
@@ -2752,6 +2783,70 @@ func (s *Store) Capacity(useCached bool) (roachpb.StoreCapacity, error) {
 	return capacity, nil
 }
 
+// HotReplicaInfo contains the descriptor of a range and the load served by
+// its replica on a store.
+type HotReplicaInfo struct {
+	Desc                *roachpb.RangeDescriptor
+	QueriesPerSecond    float64
+	WriteBytesPerSecond float64
+}
+
+// HottestReplicas returns the replicas of the store with the highest QPS and
+// the replicas with the highest write throughput, at most n of each, ordered
+// by decreasing QPS. Replicas whose load statistics don't cover
+// MinStatsDuration yet are ignored.
+func (s *Store) HottestReplicas(n int) []HotReplicaInfo {
+	var infos []HotReplicaInfo
+	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
+		var qps, bps float64
+		if r.leaseholderStats != nil {
+			if avg, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+				qps = avg
+			}
+		}
+		if avg, dur := r.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+			bps = avg
+		}
+		if qps > 0 || bps > 0 {
+			infos = append(infos, HotReplicaInfo{
+				Desc:                r.Desc(),
+				QueriesPerSecond:    qps,
+				WriteBytesPerSecond: bps,
+			})
+		}
+		return true
+	})
+	if len(infos) <= n {
+		sort.Slice(infos, func(i, j int) bool {
+			return infos[i].QueriesPerSecond > infos[j].QueriesPerSecond
+		})
+		return infos
+	}
+
+	// Keep the top n replicas by write throughput, then put the top n
+	// replicas by QPS in front of them.
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].WriteBytesPerSecond > infos[j].WriteBytesPerSecond
+	})
+	hottest := make(map[roachpb.RangeID]struct{}, 2*n)
+	for _, info := range infos[:n] {
+		hottest[info.Desc.RangeID] = struct{}{}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].QueriesPerSecond > infos[j].QueriesPerSecond
+	})
+	for _, info := range infos[:n] {
+		hottest[info.Desc.RangeID] = struct{}{}
+	}
+	result := infos[:0]
+	for _, info := range infos {
+		if _, ok := hottest[info.Desc.RangeID]; ok {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
 // ReplicaCount returns the number of replicas contained by this store. This
 // method is O(n) in the number of replicas and should not be called from
 // performance critical code.
@@ -2964,6 +3059,10 @@ func (s *Store) Send(
 		}
 	}()
 
+	// Whether a PushTxn request waited in the txn wait queue of the pushee,
+	// in which case its response says so.
+	var waitedForPushee bool
+
 	// Add the command to the range for execution; exit retry loop on success.
 	for {
 		// Exit loop if context has been canceled or timed out.
@@ -3004,11 +3103,19 @@ func (s *Store) Send(
 		// If necessary, the request may need to wait in the txn wait queue,
 		// pending updates to the target transaction for either PushTxn or
 		// QueryTxn requests.
-		if br, pErr = s.maybeWaitForPushee(ctx, &ba, repl); br != nil || pErr != nil {
-			return br, pErr
+		var waited bool
+		br, waited, pErr = s.maybeWaitForPushee(ctx, &ba, repl)
+		waitedForPushee = waitedForPushee || waited
+		if pErr != nil {
+			return nil, pErr
+		}
+		if br == nil {
+			br, pErr = repl.Send(ctx, ba)
 		}
-		br, pErr = repl.Send(ctx, ba)
 		if pErr == nil {
+			if waitedForPushee {
+				br.Responses[0].GetPushTxn().Waited = true
+			}
 			return br, nil
 		}
 
@@ -3045,7 +3152,9 @@ func (s *Store) Send(
 			// this is the code path with the requesting client waiting.
 			if pErr.Index != nil {
 				var pushType roachpb.PushTxnType
-				if ba.IsWrite() {
+				if ba.IsWrite() || ba.IsLocking() {
+					// Locking reads conflict with the intents and locks of other
+					// transactions in the same way as writes do.
 					pushType = roachpb.PUSH_ABORT
 				} else {
 					pushType = roachpb.PUSH_TIMESTAMP
@@ -3053,6 +3162,11 @@ func (s *Store) Send(
 
 				index := pErr.Index
 				args := ba.Requests[index.Index].GetInner()
+				if _, policy := roachpb.Locking(args); policy == roachpb.LOCK_WAIT_ERROR {
+					// The request doesn't want to wait for the conflicting
+					// transactions (SELECT ... FOR UPDATE NOWAIT).
+					return nil, pErr
+				}
 				// Make a copy of the header for the upcoming push; we will update
 				// the timestamp.
 				h := ba.Header
@@ -3117,16 +3231,16 @@ func (s *Store) Send(
 
 // maybeWaitForPushee potentially diverts the incoming request to
 // the txnwait.Queue, where it will wait for updates to the target
-// transaction.
+// transaction. It returns whether the request waited in the queue.
 func (s *Store) maybeWaitForPushee(
 	ctx context.Context, ba *roachpb.BatchRequest, repl *Replica,
-) (*roachpb.BatchResponse, *roachpb.Error) {
+) (*roachpb.BatchResponse, bool, *roachpb.Error) {
 	// If this is a push txn request, check the push queue first, which
 	// may cause this request to wait and either return a successful push
 	// txn response or else allow this request to proceed.
 	if ba.IsSinglePushTxnRequest() {
 		pushReq := ba.Requests[0].GetInner().(*roachpb.PushTxnRequest)
-		pushResp, pErr := repl.txnWaitQueue.MaybeWaitForPush(repl.AnnotateCtx(ctx), repl, pushReq)
+		pushResp, waited, pErr := repl.txnWaitQueue.MaybeWaitForPush(repl.AnnotateCtx(ctx), repl, pushReq)
 		// Copy the request in anticipation of setting the force arg and
 		// updating the Now timestamp (see below).
 		pushReqCopy := *pushReq
@@ -3136,11 +3250,11 @@ func (s *Store) maybeWaitForPushee(
 			pushReqCopy.Force = true
 			pushReqCopy.PushType = roachpb.PUSH_ABORT
 		} else if pErr != nil {
-			return nil, pErr
+			return nil, waited, pErr
 		} else if pushResp != nil {
 			br := &roachpb.BatchResponse{}
 			br.Add(pushResp)
-			return br, nil
+			return br, waited, nil
 		}
 		// Move the push timestamp forward to the current time, as this
 		// request may have been waiting to push the txn. If we don't
@@ -3149,17 +3263,18 @@ func (s *Store) maybeWaitForPushee(
 		pushReqCopy.Now.Forward(s.Clock().Now())
 		ba.Requests = nil
 		ba.Add(&pushReqCopy)
+		return nil, waited, nil
 	} else if ba.IsSingleQueryTxnRequest() {
 		// For query txn requests, wait in the txn wait queue either for
 		// transaction update or for dependent transactions to change.
 		queryReq := ba.Requests[0].GetInner().(*roachpb.QueryTxnRequest)
 		pErr := repl.txnWaitQueue.MaybeWaitForQuery(repl.AnnotateCtx(ctx), repl, queryReq)
 		if pErr != nil {
-			return nil, pErr
+			return nil, false, pErr
 		}
 	}
 
-	return nil, nil
+	return nil, false, nil
 }
 
 // HandleSnapshot reads an incoming streaming snapshot and applies it if
@@ -803,6 +803,93 @@ func TestStoreReplicaVisitor(t *testing.T) {
 	}
 }
 
+func TestStoreHottestReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, manual := createTestStore(t, stopper)
+
+	// Remove range 1 so that only the ranges with known load remain.
+	repl1, err := store.GetReplica(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RemoveReplica(context.Background(), repl1, repl1.Desc().NextReplicaID, RemoveOptions{
+		DestroyData: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The requests and bytes written by each range over the measurement
+	// period. Range 6 has no load, and range 7 was only measured for a short
+	// time.
+	const period = 2 * MinStatsDuration
+	load := []struct {
+		requests, bytes float64
+	}{
+		{requests: 10, bytes: 10000},
+		{requests: 20, bytes: 9000},
+		{requests: 30, bytes: 10},
+		{requests: 40, bytes: 20},
+		{requests: 50, bytes: 30},
+		{requests: 0, bytes: 0},
+		{requests: 1000, bytes: 100000},
+	}
+	var repls []*Replica
+	for i := range load {
+		repl := createReplica(store, roachpb.RangeID(i+1), roachpb.RKey(fmt.Sprintf("a%02d", i)), roachpb.RKey(fmt.Sprintf("a%02d", i+1)))
+		if err := store.AddReplica(repl); err != nil {
+			t.Fatal(err)
+		}
+		repl.leaseholderStats = newReplicaStats(store.Clock(), nil)
+		repl.writeBytesStats = newReplicaStats(store.Clock(), nil)
+		repls = append(repls, repl)
+	}
+	manual.Increment(int64(period - time.Second))
+	last := repls[len(repls)-1]
+	last.leaseholderStats.resetRequestCounts()
+	last.writeBytesStats.resetRequestCounts()
+	manual.Increment(int64(time.Second))
+	for i, l := range load {
+		repls[i].leaseholderStats.recordCount(l.requests, 0 /* nodeID */)
+		repls[i].writeBytesStats.recordCount(l.bytes, 0 /* nodeID */)
+	}
+
+	type hotRange struct {
+		rangeID  roachpb.RangeID
+		qps, bps float64
+	}
+	hottest := func(n int) []hotRange {
+		var res []hotRange
+		for _, info := range store.HottestReplicas(n) {
+			res = append(res, hotRange{info.Desc.RangeID, info.QueriesPerSecond, info.WriteBytesPerSecond})
+		}
+		return res
+	}
+	secs := period.Seconds()
+
+	// The two ranges with the most QPS and the two with the most write
+	// throughput, by decreasing QPS.
+	if e, a := []hotRange{
+		{5, 50 / secs, 30 / secs},
+		{4, 40 / secs, 20 / secs},
+		{2, 20 / secs, 9000 / secs},
+		{1, 10 / secs, 10000 / secs},
+	}, hottest(2); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	// All the ranges with load statistics.
+	if e, a := []hotRange{
+		{5, 50 / secs, 30 / secs},
+		{4, 40 / secs, 20 / secs},
+		{3, 30 / secs, 10 / secs},
+		{2, 20 / secs, 9000 / secs},
+		{1, 10 / secs, 10000 / secs},
+	}, hottest(10); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
+
 func TestHasOverlappingReplica(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
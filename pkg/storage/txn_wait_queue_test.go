@@ -49,8 +49,9 @@ func createTxnForPushQueue(ctx context.Context, tc *testContext) (*roachpb.Trans
 }
 
 type RespWithErr struct {
-	resp *roachpb.PushTxnResponse
-	pErr *roachpb.Error
+	resp   *roachpb.PushTxnResponse
+	waited bool
+	pErr   *roachpb.Error
 }
 
 func TestTxnWaitQueueEnableDisable(t *testing.T) {
@@ -85,8 +86,8 @@ func TestTxnWaitQueueEnableDisable(t *testing.T) {
 
 	retCh := make(chan RespWithErr, 1)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 
 	testutils.SucceedsSoon(t, func() error {
@@ -110,6 +111,9 @@ func TestTxnWaitQueueEnableDisable(t *testing.T) {
 	if respWithErr.pErr != nil {
 		t.Errorf("expected nil err; got %+v", respWithErr.pErr)
 	}
+	if !respWithErr.waited {
+		t.Errorf("expected the push to have waited")
+	}
 
 	if deps := q.GetDependents(txn.ID); deps != nil {
 		t.Errorf("expected GetDependents to return nil as queue is disabled; got %+v", deps)
@@ -125,8 +129,8 @@ func TestTxnWaitQueueEnableDisable(t *testing.T) {
 		t.Fatalf("expected update to silently fail since queue is disabled")
 	}
 
-	if resp, pErr := q.MaybeWaitForPush(context.TODO(), tc.repl, &req); resp != nil || pErr != nil {
-		t.Errorf("expected nil resp and err as queue is disabled; got %+v, %s", resp, pErr)
+	if resp, waited, pErr := q.MaybeWaitForPush(context.TODO(), tc.repl, &req); resp != nil || waited || pErr != nil {
+		t.Errorf("expected nil resp and err as queue is disabled; got %+v, %t, %s", resp, waited, pErr)
 	}
 }
 
@@ -155,8 +159,8 @@ func TestTxnWaitQueueCancel(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	retCh := make(chan RespWithErr, 1)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(ctx, tc.repl, &req)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(ctx, tc.repl, &req)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 
 	testutils.SucceedsSoon(t, func() error {
@@ -211,8 +215,8 @@ func TestTxnWaitQueueUpdateTxn(t *testing.T) {
 
 	retCh := make(chan RespWithErr, 2)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req1)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req1)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 	testutils.SucceedsSoon(t, func() error {
 		expDeps := []uuid.UUID{pusher1.ID}
@@ -223,8 +227,8 @@ func TestTxnWaitQueueUpdateTxn(t *testing.T) {
 	})
 
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req2)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req2)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 	testutils.SucceedsSoon(t, func() error {
 		expDeps := []uuid.UUID{pusher1.ID, pusher2.ID}
@@ -293,8 +297,8 @@ func TestTxnWaitQueueTxnSilentlyCompletes(t *testing.T) {
 
 	retCh := make(chan RespWithErr, 2)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, req)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, req)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 	testutils.SucceedsSoon(t, func() error {
 		expDeps := []uuid.UUID{pusher.ID}
@@ -345,8 +349,8 @@ func TestTxnWaitQueueUpdateNotPushedTxn(t *testing.T) {
 
 	retCh := make(chan RespWithErr, 1)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 
 	testutils.SucceedsSoon(t, func() error {
@@ -417,8 +421,8 @@ func TestTxnWaitQueuePusheeExpires(t *testing.T) {
 
 	retCh := make(chan RespWithErr, 2)
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req1)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req1)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 	testutils.SucceedsSoon(t, func() error {
 		expDeps := []uuid.UUID{pusher1.ID}
@@ -429,8 +433,8 @@ func TestTxnWaitQueuePusheeExpires(t *testing.T) {
 	})
 
 	go func() {
-		resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req2)
-		retCh <- RespWithErr{resp, pErr}
+		resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req2)
+		retCh <- RespWithErr{resp, waited, pErr}
 	}()
 	testutils.SucceedsSoon(t, func() error {
 		expDeps := []uuid.UUID{pusher1.ID, pusher2.ID}
@@ -492,8 +496,8 @@ func TestTxnWaitQueuePusherUpdate(t *testing.T) {
 
 		retCh := make(chan RespWithErr, 1)
 		go func() {
-			resp, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
-			retCh <- RespWithErr{resp, pErr}
+			resp, waited, pErr := q.MaybeWaitForPush(context.Background(), tc.repl, &req)
+			retCh <- RespWithErr{resp, waited, pErr}
 		}()
 
 		testutils.SucceedsSoon(t, func() error {
@@ -578,8 +582,8 @@ func TestTxnWaitQueueDependencyCycle(t *testing.T) {
 	retCh := make(chan RespWithErr, 3)
 	for _, req := range []*roachpb.PushTxnRequest{reqA, reqB, reqC} {
 		go func(req *roachpb.PushTxnRequest) {
-			resp, pErr := q.MaybeWaitForPush(ctx, tc.repl, req)
-			retCh <- RespWithErr{resp, pErr}
+			resp, waited, pErr := q.MaybeWaitForPush(ctx, tc.repl, req)
+			retCh <- RespWithErr{resp, waited, pErr}
 		}(req)
 	}
 
@@ -654,7 +658,7 @@ func TestTxnWaitQueueDependencyCycleWithPriorityInversion(t *testing.T) {
 	retCh := make(chan ReqWithErr, 2)
 	for _, req := range []*roachpb.PushTxnRequest{reqA, reqB} {
 		go func(req *roachpb.PushTxnRequest) {
-			_, pErr := q.MaybeWaitForPush(ctx, tc.repl, req)
+			_, _, pErr := q.MaybeWaitForPush(ctx, tc.repl, req)
 			retCh <- ReqWithErr{req, pErr}
 		}(req)
 	}
@@ -59,7 +59,7 @@ func ShouldPushImmediately(req *roachpb.PushTxnRequest) bool {
 // fulfilled by the current transaction state. This may be true
 // for transactions with pushed timestamps.
 func isPushed(req *roachpb.PushTxnRequest, txn *roachpb.Transaction) bool {
-	return (txn.Status != roachpb.PENDING ||
+	return (txn.Status.IsFinalized() ||
 		(req.PushType == roachpb.PUSH_TIMESTAMP && req.PushTo.Less(txn.Timestamp)))
 }
 
@@ -375,15 +375,16 @@ var ErrDeadlock = roachpb.NewErrorf("deadlock detected")
 // for resolution.
 //
 // If the transaction is successfully pushed while this method is waiting,
-// the first return value is a non-nil PushTxnResponse object.
+// the first return value is a non-nil PushTxnResponse object. The second
+// return value indicates whether the request waited in the queue.
 //
 // In the event of a dependency cycle of pushers leading to deadlock,
 // this method will return an ErrDeadlock error.
 func (q *Queue) MaybeWaitForPush(
 	ctx context.Context, repl ReplicaInterface, req *roachpb.PushTxnRequest,
-) (*roachpb.PushTxnResponse, *roachpb.Error) {
+) (*roachpb.PushTxnResponse, bool, *roachpb.Error) {
 	if ShouldPushImmediately(req) {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	q.mu.Lock()
@@ -394,7 +395,7 @@ func (q *Queue) MaybeWaitForPush(
 	// ensure that it's not cleared before an incorrect insertion happens.
 	if q.mu.txns == nil || !repl.ContainsKey(req.Key) {
 		q.mu.Unlock()
-		return nil, nil
+		return nil, false, nil
 	}
 
 	// If there's no pending queue for this txn, return not pushed. If
@@ -402,11 +403,11 @@ func (q *Queue) MaybeWaitForPush(
 	pending, ok := q.mu.txns[req.PusheeTxn.ID]
 	if !ok {
 		q.mu.Unlock()
-		return nil, nil
+		return nil, false, nil
 	}
 	if txn := pending.getTxn(); isPushed(req, txn) {
 		q.mu.Unlock()
-		return createPushTxnResponse(txn), nil
+		return createPushTxnResponse(txn), false, nil
 	}
 
 	push := &waitingPush{
@@ -485,7 +486,7 @@ func (q *Queue) MaybeWaitForPush(
 		case <-ctx.Done():
 			// Caller has given up.
 			log.VEvent(ctx, 2, "pusher giving up due to context cancellation")
-			return nil, roachpb.NewError(ctx.Err())
+			return nil, true, roachpb.NewError(ctx.Err())
 
 		case txn := <-push.pending:
 			log.VEventf(ctx, 2, "result of pending push: %v", txn)
@@ -493,18 +494,18 @@ func (q *Queue) MaybeWaitForPush(
 			// replica lost the range lease. Return not pushed so request
 			// proceeds and is redirected to the new range lease holder.
 			if txn == nil {
-				return nil, nil
+				return nil, true, nil
 			}
 			// Transaction was committed, aborted or had its timestamp
 			// pushed. If this PushTxn request is satisfied, return
 			// successful PushTxn response.
 			if isPushed(req, txn) {
 				log.VEvent(ctx, 2, "push request is satisfied")
-				return createPushTxnResponse(txn), nil
+				return createPushTxnResponse(txn), true, nil
 			}
 			// If not successfully pushed, return not pushed so request proceeds.
 			log.VEvent(ctx, 2, "not pushed; returning to caller")
-			return nil, nil
+			return nil, true, nil
 
 		case <-pusheeTxnTimer.C:
 			log.VEvent(ctx, 2, "querying pushee")
@@ -514,31 +515,31 @@ func (q *Queue) MaybeWaitForPush(
 				ctx, req.PusheeTxn, false, nil, q.store.Clock().Now(),
 			)
 			if pErr != nil {
-				return nil, pErr
+				return nil, true, pErr
 			} else if updatedPushee == nil {
 				// Continue with push.
 				log.VEvent(ctx, 2, "pushee not found, push should now succeed")
-				return nil, nil
+				return nil, true, nil
 			}
 			pusheePriority = updatedPushee.Priority
 			pending.txn.Store(updatedPushee)
-			if updatedPushee.Status != roachpb.PENDING {
+			if updatedPushee.Status.IsFinalized() {
 				log.VEvent(ctx, 2, "push request is satisfied")
-				return createPushTxnResponse(updatedPushee), nil
+				return createPushTxnResponse(updatedPushee), true, nil
 			}
 			if IsExpired(q.store.Clock().Now(), updatedPushee) {
 				log.VEventf(ctx, 1, "pushing expired txn %s", req.PusheeTxn.ID.Short())
-				return nil, nil
+				return nil, true, nil
 			}
 
 		case updatedPusher := <-queryPusherCh:
 			switch updatedPusher.Status {
 			case roachpb.COMMITTED:
 				log.VEventf(ctx, 1, "pusher committed: %v", updatedPusher)
-				return nil, roachpb.NewErrorWithTxn(roachpb.NewTransactionStatusError("already committed"), updatedPusher)
+				return nil, true, roachpb.NewErrorWithTxn(roachpb.NewTransactionStatusError("already committed"), updatedPusher)
 			case roachpb.ABORTED:
 				log.VEventf(ctx, 1, "pusher aborted: %v", updatedPusher)
-				return nil, roachpb.NewErrorWithTxn(roachpb.NewTransactionAbortedError(), updatedPusher)
+				return nil, true, roachpb.NewErrorWithTxn(roachpb.NewTransactionAbortedError(), updatedPusher)
 			}
 			log.VEventf(ctx, 2, "pusher was updated: %v", updatedPusher)
 			if updatedPusher.Priority > pusherPriority {
@@ -582,7 +583,7 @@ func (q *Queue) MaybeWaitForPush(
 						req.PusheeTxn.ID.Short(),
 						dependents,
 					)
-					return nil, ErrDeadlock
+					return nil, true, ErrDeadlock
 
 				}
 			}
@@ -591,7 +592,7 @@ func (q *Queue) MaybeWaitForPush(
 
 		case pErr := <-queryPusherErrCh:
 			queryPusherErrCh = nil
-			return nil, pErr
+			return nil, true, pErr
 		}
 	}
 }
@@ -175,6 +175,43 @@ func (s *Server) Query(
 	if len(request.Queries) == 0 {
 		return nil, status.Errorf(codes.InvalidArgument, "Queries cannot be empty")
 	}
+	response, memContexts, err := s.query(ctx, request)
+	for idx := range memContexts {
+		memContexts[idx].Close(ctx)
+	}
+	return response, err
+}
+
+// QueryTimeSeries is like Query, but the memory accounted for the results
+// remains reserved until the returned function is called, which the caller
+// must do once it no longer uses them. It is used to query time series from
+// SQL.
+func (s *Server) QueryTimeSeries(
+	ctx context.Context, request *tspb.TimeSeriesQueryRequest,
+) (*tspb.TimeSeriesQueryResponse, func(context.Context), error) {
+	ctx = s.AnnotateCtx(ctx)
+	if len(request.Queries) == 0 {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Queries cannot be empty")
+	}
+	response, memContexts, err := s.query(ctx, request)
+	release := func(ctx context.Context) {
+		for idx := range memContexts {
+			memContexts[idx].Close(ctx)
+		}
+	}
+	if err != nil {
+		release(ctx)
+		return nil, nil, err
+	}
+	return response, release, nil
+}
+
+// query runs the queries of the request in parallel. It returns the memory
+// contexts which account for the results; the caller must close them, even if
+// an error is returned.
+func (s *Server) query(
+	ctx context.Context, request *tspb.TimeSeriesQueryRequest,
+) (*tspb.TimeSeriesQueryResponse, []QueryMemoryContext, error) {
 
 	// If not set, sampleNanos should default to ten second resolution.
 	sampleNanos := request.SampleNanos
@@ -212,11 +249,6 @@ func (s *Server) Query(
 	// Create a separate memory management context for each query, allowing them
 	// to be run in parallel.
 	memContexts := make([]QueryMemoryContext, len(request.Queries))
-	defer func() {
-		for idx := range memContexts {
-			memContexts[idx].Close(ctx)
-		}
-	}()
 
 	timespan := QueryTimespan{
 		StartNanos:          request.StartNanos,
@@ -292,7 +324,7 @@ func (s *Server) Query(
 			}
 		}
 	}); err != nil {
-		return nil, err
+		return nil, memContexts, err
 	}
 
 	for range request.Queries {
@@ -302,14 +334,14 @@ func (s *Server) Query(
 				// Return the first error encountered. This will cancel the
 				// worker context and cause all other in-progress workers to
 				// exit.
-				return nil, err
+				return nil, memContexts, err
 			}
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, memContexts, ctx.Err()
 		}
 	}
 
-	return &response, nil
+	return &response, memContexts, nil
 }
 
 // Dump returns a stream of raw timeseries data that has been stored on the
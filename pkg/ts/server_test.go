@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -258,6 +259,75 @@ func TestServerQuery(t *testing.T) {
 	}
 }
 
+// TestServerQuerySQL verifies that the crdb_internal.timeseries() generator
+// returns the stored datapoints, per source and aggregated.
+func TestServerQuerySQL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &storage.StoreTestingKnobs{
+				DisableTimeSeriesMaintenanceQueue: true,
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.TODO())
+	tsrv := s.(*server.TestServer)
+
+	if err := tsrv.TsDB().StoreData(context.TODO(), ts.Resolution10s, []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{TimestampNanos: 500 * 1e9, Value: 100.0},
+				{TimestampNanos: 510 * 1e9, Value: 200.0},
+			},
+		},
+		{
+			Name:   "test.metric",
+			Source: "source2",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{TimestampNanos: 500 * 1e9, Value: 300.0},
+				{TimestampNanos: 510 * 1e9, Value: 400.0},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := sqlutils.MakeSQLRunner(db)
+	// The datapoints are queried between 500 and 526 seconds after the epoch.
+	const span = `'1970-01-01 00:08:20+00:00', '1970-01-01 00:08:46+00:00'`
+	r.CheckQueryResults(t, `
+SELECT extract(epoch FROM timestamp)::INT, source, value
+  FROM crdb_internal.timeseries('test.metric', `+span+`)`,
+		[][]string{
+			{"500", "source1", "100"},
+			{"510", "source1", "200"},
+			{"500", "source2", "300"},
+			{"510", "source2", "400"},
+		},
+	)
+	r.CheckQueryResults(t, `
+SELECT extract(epoch FROM timestamp)::INT, source IS NULL, value
+  FROM crdb_internal.timeseries('test.metric', `+span+`, '10s', 'sum', 'avg')`,
+		[][]string{
+			{"500", "true", "400"},
+			{"510", "true", "600"},
+		},
+	)
+	r.CheckQueryResults(t, `
+SELECT extract(epoch FROM timestamp)::INT, value
+  FROM crdb_internal.timeseries('test.metric', `+span+`, '20s', 'max', 'max')`,
+		[][]string{
+			{"500", "400"},
+		},
+	)
+	r.CheckQueryResults(t,
+		`SELECT count(*) FROM crdb_internal.timeseries('unknown.metric', `+span+`)`,
+		[][]string{{"0"}},
+	)
+}
+
 // TestServerQueryStarvation tests a very specific scenario, wherein a single
 // query request has more queries than the server's MaxWorkers count.
 func TestServerQueryStarvation(t *testing.T) {
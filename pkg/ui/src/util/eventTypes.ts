@@ -55,6 +55,14 @@ export const SET_CLUSTER_SETTING = "set_cluster_setting";
 export const SET_ZONE_CONFIG = "set_zone_config";
 // Recorded when a zone config is removed.
 export const REMOVE_ZONE_CONFIG = "remove_zone_config";
+// Recorded when an alert rule starts firing.
+export const ALERT_FIRING = "alert_firing";
+// Recorded when an alert rule stops firing.
+export const ALERT_RESOLVED = "alert_resolved";
+// Recorded when a user fails to log in.
+export const USER_LOGIN_FAILURE = "user_login_failure";
+// Recorded when a locked out user is unlocked.
+export const UNLOCK_USER = "unlock_user";
 
 // Node Event Types
 export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONED, NODE_RECOMMISSIONED];
@@ -65,12 +73,19 @@ export const tableEvents = [
   FINISH_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE_ROLLBACK,
 ];
 export const settingsEvents = [SET_CLUSTER_SETTING, SET_ZONE_CONFIG, REMOVE_ZONE_CONFIG];
-export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
+export const alertEvents = [ALERT_FIRING, ALERT_RESOLVED];
+export const userEvents = [USER_LOGIN_FAILURE, UNLOCK_USER];
+export const allEvents = [
+  ...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents, ...alertEvents,
+  ...userEvents,
+];
 
 const nodeEventSet = _.invert(nodeEvents);
 const databaseEventSet = _.invert(databaseEvents);
 const tableEventSet = _.invert(tableEvents);
 const settingsEventSet = _.invert(settingsEvents);
+const alertEventSet = _.invert(alertEvents);
+const userEventSet = _.invert(userEvents);
 
 export function isNodeEvent(e: Event): boolean {
   return !_.isUndefined(nodeEventSet[e.event_type]);
@@ -87,3 +102,11 @@ export function isTableEvent(e: Event): boolean {
 export function isSettingsEvent(e: Event): boolean {
   return !_.isUndefined(settingsEventSet[e.event_type]);
 }
+
+export function isAlertEvent(e: Event): boolean {
+  return !_.isUndefined(alertEventSet[e.event_type]);
+}
+
+export function isUserEvent(e: Event): boolean {
+  return !_.isUndefined(userEventSet[e.event_type]);
+}
@@ -65,6 +65,17 @@ export function getEventDescription(e: Event$Properties): string {
     return `Zone Config Changed: User ${info.User} set the zone config for ${info.Target} to ${info.Config}`;
     case eventTypes.REMOVE_ZONE_CONFIG:
       return `Zone Config Removed: User ${info.User} removed the zone config for ${info.Target}`;
+    case eventTypes.ALERT_FIRING:
+      return `Alert Firing: Alert rule ${info.Rule} started firing on node ${targetId}`;
+    case eventTypes.ALERT_RESOLVED:
+      return `Alert Resolved: Alert rule ${info.Rule} stopped firing on node ${targetId}`;
+    case eventTypes.USER_LOGIN_FAILURE:
+      if (info.LockedOut) {
+        return `User Locked Out: User ${info.User} was locked out after ${info.FailedLogins} failed login attempts`;
+      }
+      return `Login Failed: User ${info.User} failed to log in: ${info.Error}`;
+    case eventTypes.UNLOCK_USER:
+      return `User Unlocked: User ${info.User} unlocked user ${info.UserName}`;
     default:
       return `Unknown Event Type: ${e.event_type}, content: ${JSON.stringify(info, null, 2)}`;
   }
@@ -84,6 +95,13 @@ export interface EventInfo {
   Value?: string;
   Target?: string;
   Config?: string;
+  Rule?: string;
+  UserName?: string;
+  RemoteAddr?: string;
+  Method?: string;
+  Error?: string;
+  FailedLogins?: number;
+  LockedOut?: boolean;
   // The following are three names for the same key (it was renamed twice).
   // All ar included for backwards compatibility.
   DroppedTables?: string[];
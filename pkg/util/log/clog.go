@@ -322,11 +322,14 @@ var entryRE = regexp.MustCompile(
 	`(?m)^([IWEF])(\d{6} \d{2}:\d{2}:\d{2}.\d{6}) (?:(\d+) )?([^:]+):(\d+)`)
 
 // EntryDecoder reads successive encoded log entries from the input
-// buffer. Each entry is preceded by a single big-ending uint32
-// describing the next entry's length.
+// buffer. The entries can be in either of the formats of --log-format,
+// which is detected from the first entry.
 type EntryDecoder struct {
 	scanner            *bufio.Scanner
 	truncatedLastEntry bool
+	// formatKnown is set once the format of the input has been detected.
+	formatKnown bool
+	json        bool
 }
 
 // NewEntryDecoder creates a new instance of EntryDecoder.
@@ -346,6 +349,13 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return io.EOF
 		}
 		b := d.scanner.Bytes()
+		if d.json {
+			if err := decodeJSONEntry(b, entry); err != nil {
+				// Skip entries which were truncated or mangled.
+				continue
+			}
+			return nil
+		}
 		m := entryRE.FindSubmatch(b)
 		if m == nil {
 			continue
@@ -369,7 +379,14 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return err
 		}
 		entry.Line = int64(line)
+		// The tags are left in the message, as they can't be told apart from
+		// a message which starts with a bracket.
+		entry.Tags = ""
 		entry.Message = strings.TrimSpace(string(b[len(m[0]):]))
+		entry.Redactable = strings.HasPrefix(entry.Message, redactablePrefix)
+		if entry.Redactable {
+			entry.Message = entry.Message[len(redactablePrefix):]
+		}
 		return nil
 	}
 }
@@ -378,6 +395,13 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
 	}
+	if !d.formatKnown {
+		d.formatKnown = true
+		d.json = data[0] == '{'
+	}
+	if d.json {
+		return d.splitJSON(data, atEOF)
+	}
 	if d.truncatedLastEntry {
 		i := entryRE.FindIndex(data)
 		if i == nil {
@@ -418,6 +442,32 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 	return i[0], data[:i[0]], nil
 }
 
+// splitJSON splits the input into JSON entries, which are on a line each.
+func (d *EntryDecoder) splitJSON(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	i := bytes.IndexByte(data, '\n')
+	if d.truncatedLastEntry {
+		if i < 0 {
+			return len(data), nil, nil
+		}
+		// Skip the rest of the truncated entry.
+		d.truncatedLastEntry = false
+		return i + 1, nil, nil
+	}
+	if i < 0 {
+		if atEOF {
+			return len(data), data, nil
+		}
+		if len(data) >= bufio.MaxScanTokenSize {
+			// The entry doesn't fit in the buffer. Unlike the text format, a
+			// truncated JSON entry can't be decoded, so skip it.
+			d.truncatedLastEntry = true
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	}
+	return i + 1, data[:i], nil
+}
+
 // flushSyncWriter is the interface satisfied by logging destinations.
 type flushSyncWriter interface {
 	Flush() error
@@ -428,6 +478,47 @@ type flushSyncWriter interface {
 // the --no-color flag.
 var noColor bool
 
+// logEntryFormat is the format in which log entries are written.
+type logEntryFormat int
+
+const (
+	// logFormatText is the glog-style format described in formatHeader.
+	logFormatText logEntryFormat = iota
+	// logFormatJSON writes each entry as a JSON object on a line of its own,
+	// see formatJSONEntry.
+	logFormatJSON
+)
+
+// the --log-format flag.
+var logFormat logEntryFormat
+
+var logFormatNames = []string{
+	logFormatText: "text",
+	logFormatJSON: "json",
+}
+
+// String is part of the flag.Value interface.
+func (f *logEntryFormat) String() string {
+	return logFormatNames[*f]
+}
+
+// Set is part of the flag.Value interface.
+func (f *logEntryFormat) Set(value string) error {
+	for i, name := range logFormatNames {
+		if strings.EqualFold(value, name) {
+			*f = logEntryFormat(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log format %q; expected one of %s",
+		value, strings.Join(logFormatNames, ", "))
+}
+
+// Type is part of the pflag.Value interface.
+func (f *logEntryFormat) Type() string {
+	return "string"
+}
+
 // formatHeader formats a log header using the provided file name and
 // line number. Log lines are colorized depending on severity.
 //
@@ -562,9 +653,21 @@ func (buf *buffer) someDigits(i, d int) int {
 	return copy(buf.tmp[i:], buf.tmp[j:])
 }
 
+// formatLogEntry formats a log entry in the format selected by --log-format.
 func formatLogEntry(entry Entry, stacks []byte, cp ttycolor.Profile) *buffer {
+	if logFormat == logFormatJSON {
+		return formatJSONEntry(entry, stacks)
+	}
 	buf := formatHeader(entry.Severity, timeutil.Unix(0, entry.Time),
 		int(entry.Goroutine), entry.File, int(entry.Line), cp)
+	if entry.Redactable {
+		_, _ = buf.WriteString(redactablePrefix)
+	}
+	if entry.Tags != "" {
+		_ = buf.WriteByte('[')
+		_, _ = buf.WriteString(entry.Tags)
+		_, _ = buf.WriteString("] ")
+	}
 	_, _ = buf.WriteString(entry.Message)
 	if buf.Bytes()[buf.Len()-1] != '\n' {
 		_ = buf.WriteByte('\n')
@@ -702,6 +805,29 @@ type loggingT struct {
 	// The Cluster ID is reported on every new log file so as to ease the correlation
 	// of panic reports with self-reported log files.
 	clusterID string
+
+	// fileMaxSize and combinedMaxSize override LogFileMaxSize and
+	// LogFilesCombinedMaxSize for this logger when non-zero. They are
+	// accessed atomically.
+	fileMaxSize     int64
+	combinedMaxSize int64
+}
+
+// logFileMaxSize returns the maximum size of a log file of the logger.
+func (l *loggingT) logFileMaxSize() int64 {
+	if s := atomic.LoadInt64(&l.fileMaxSize); s > 0 {
+		return s
+	}
+	return atomic.LoadInt64(&LogFileMaxSize)
+}
+
+// logFilesCombinedMaxSize returns the maximum total size of the log files
+// of the logger.
+func (l *loggingT) logFilesCombinedMaxSize() int64 {
+	if s := atomic.LoadInt64(&l.combinedMaxSize); s > 0 {
+		return s
+	}
+	return atomic.LoadInt64(&LogFilesCombinedMaxSize)
 }
 
 // buffer holds a byte Buffer for reuse. The zero value is ready for use.
@@ -802,13 +928,16 @@ func (l *loggingT) writeToFile(data []byte) error {
 	return nil
 }
 
-// outputLogEntry marshals a log entry proto into bytes, and writes
+// outputLogEntry creates a log entry for the message and outputs it.
+func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string) {
+	l.outputEntry(MakeEntry(s, timeutil.Now().UnixNano(), file, line, msg))
+}
+
+// outputEntry marshals a log entry proto into bytes, and writes
 // the data to the log files. If a trace location is set, stack traces
 // are added to the entry before marshaling.
-func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string) {
-	// Set additional details in log entry.
-	now := timeutil.Now()
-	entry := MakeEntry(s, now.UnixNano(), file, line, msg)
+func (l *loggingT) outputEntry(entry Entry) {
+	s, file, line := entry.Severity, entry.File, int(entry.Line)
 
 	if f, ok := l.interceptor.Load().(InterceptorFn); ok && f != nil {
 		f(entry)
@@ -1031,7 +1160,7 @@ func (sb *syncBuffer) Sync() error {
 }
 
 func (sb *syncBuffer) Write(p []byte) (n int, err error) {
-	if sb.nbytes+int64(len(p)) >= atomic.LoadInt64(&LogFileMaxSize) {
+	if sb.nbytes+int64(len(p)) >= sb.logger.logFileMaxSize() {
 		if err := sb.rotateFile(timeutil.Now()); err != nil {
 			sb.logger.exitLocked(err)
 		}
@@ -1088,7 +1217,11 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	}
 	// Including a non-ascii character in the first 1024 bytes of the log helps
 	// viewers that attempt to guess the character encoding.
-	messages = append(messages, fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713\n"))
+	if logFormat == logFormatJSON {
+		messages = append(messages, fmt.Sprintf("line format: json utf8=\u2713\n"))
+	} else {
+		messages = append(messages, fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line [⋮] msg utf8=\u2713\n"))
+	}
 
 	f, l, _ := caller.Lookup(1)
 	for _, msg := range messages {
@@ -1244,7 +1377,7 @@ func (l *loggingT) gcOldFiles() {
 		return
 	}
 
-	logFilesCombinedMaxSize := atomic.LoadInt64(&LogFilesCombinedMaxSize)
+	logFilesCombinedMaxSize := l.logFilesCombinedMaxSize()
 	files := selectFiles(allFiles, math.MaxInt64)
 	if len(files) == 0 {
 		return
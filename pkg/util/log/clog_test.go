@@ -273,6 +273,76 @@ trace`,
 	}
 }
 
+// Verify that a log written in the JSON format can be decoded.
+func TestJSONEntryDecoder(t *testing.T) {
+	defer func(f logEntryFormat) { logFormat = f }(logFormat)
+	logFormat = logFormatJSON
+
+	now := timeutil.Now()
+	entries := []Entry{
+		{
+			Severity:  Severity_INFO,
+			Time:      now.UnixNano(),
+			Goroutine: 1,
+			File:      `clog_test.go`,
+			Line:      136,
+			Tags:      `n1,s2`,
+			Message:   `info with "quotes" and <html>`,
+		},
+		{
+			Severity:  Severity_WARNING,
+			Time:      now.Add(time.Microsecond).UnixNano(),
+			Goroutine: 2,
+			File:      `clog_test.go`,
+			Line:      137,
+			Message: `multi-
+line with ‹redactable›`,
+			Redactable: true,
+		},
+	}
+	var contents bytes.Buffer
+	for _, e := range entries {
+		buf := formatLogEntry(e, nil, nil)
+		if line := buf.String(); !strings.HasPrefix(line, "{") || strings.Count(line, "\n") != 1 {
+			t.Fatalf("expected a single line of JSON, got %q", line)
+		}
+		contents.Write(buf.Bytes())
+		logging.putBuffer(buf)
+	}
+	// Lines which aren't entries, such as panics written to the log file
+	// through the redirected stderr, are skipped.
+	contents.WriteString("panic: boom\n\n")
+	fatal := Entry{
+		Severity:  Severity_FATAL,
+		Time:      now.Add(2 * time.Microsecond).UnixNano(),
+		Goroutine: 3,
+		File:      `clog_test.go`,
+		Line:      138,
+		Message:   `fatal`,
+	}
+	buf := formatLogEntry(fatal, []byte("stack\ntrace\n"), nil)
+	contents.Write(buf.Bytes())
+	logging.putBuffer(buf)
+	fatal.Message = "fatal\nstack\ntrace"
+	expected := append(entries, fatal)
+
+	decoder := NewEntryDecoder(&contents)
+	var decoded []Entry
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatal(err)
+		}
+		decoded = append(decoded, entry)
+	}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Fatalf("%s\n", strings.Join(pretty.Diff(expected, decoded), "\n"))
+	}
+}
+
 // Test that an Error log goes to Warning and Info.
 // Even in the Info log, the source character will be E, so the data should
 // all be identical.
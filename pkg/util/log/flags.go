@@ -33,4 +33,11 @@ func init() {
 		logflags.LogToStderrName, "logs at or above this threshold go to stderr")
 	flag.Var(&logging.fileThreshold,
 		logflags.LogFileVerbosityThresholdName, "minimum verbosity of messages written to the log file")
+	// The format and redactable flags are defined here because they apply
+	// to the variables of this package which aren't exported.
+	flag.Var(&logFormat, logflags.LogFormatName,
+		"format of the log entries written to the log files and stderr: text or json")
+	flag.BoolVar(&redactableLogs, logflags.RedactableLogsName, redactableLogs,
+		"enclose the arguments of log messages that may contain user data in redaction markers, "+
+			"so that a redacted copy of the logs can be produced")
 }
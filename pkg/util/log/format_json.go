@@ -0,0 +1,104 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// jsonEntry is the representation of a log entry in the JSON format
+// (--log-format=json). Each entry is written as a JSON object on a line of
+// its own, for example:
+//
+//	{"severity":"INFO","time":"2018-09-14T13:24:01.123456789Z","goroutine":42,
+//	 "file":"server/server.go","line":1254,"tags":"n1","message":"..."}
+type jsonEntry struct {
+	Severity   string `json:"severity"`
+	Time       string `json:"time"`
+	Goroutine  int64  `json:"goroutine,omitempty"`
+	File       string `json:"file"`
+	Line       int64  `json:"line"`
+	Tags       string `json:"tags,omitempty"`
+	Message    string `json:"message"`
+	Redactable bool   `json:"redactable,omitempty"`
+	Stacks     string `json:"stacks,omitempty"`
+}
+
+// jsonTimeFormat is the format of the timestamps of JSON entries. It is
+// RFC 3339 with a fixed number of fractional digits, so that the timestamps
+// sort lexicographically.
+const jsonTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatJSONEntry formats a log entry as a line of JSON.
+func formatJSONEntry(entry Entry, stacks []byte) *buffer {
+	s := entry.Severity
+	if s > Severity_FATAL || s <= Severity_UNKNOWN {
+		s = Severity_INFO // for safety.
+	}
+	buf := logging.getBuffer()
+	enc := json.NewEncoder(buf)
+	// The messages are not meant to be embedded in HTML.
+	enc.SetEscapeHTML(false)
+	// Encode appends the newline which terminates the entry. It can't fail
+	// for a jsonEntry.
+	_ = enc.Encode(jsonEntry{
+		Severity:   s.String(),
+		Time:       timeutil.Unix(0, entry.Time).UTC().Format(jsonTimeFormat),
+		Goroutine:  entry.Goroutine,
+		File:       entry.File,
+		Line:       entry.Line,
+		Tags:       entry.Tags,
+		Message:    entry.Message,
+		Redactable: entry.Redactable,
+		Stacks:     string(stacks),
+	})
+	return buf
+}
+
+// decodeJSONEntry decodes a log entry formatted by formatJSONEntry.
+func decodeJSONEntry(data []byte, entry *Entry) error {
+	var j jsonEntry
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	s, ok := SeverityByName(j.Severity)
+	if !ok {
+		return fmt.Errorf("unknown severity %q", j.Severity)
+	}
+	t, err := time.Parse(jsonTimeFormat, j.Time)
+	if err != nil {
+		return err
+	}
+	*entry = Entry{
+		Severity:   s,
+		Time:       t.UnixNano(),
+		Goroutine:  j.Goroutine,
+		File:       j.File,
+		Line:       j.Line,
+		Tags:       j.Tags,
+		Message:    j.Message,
+		Redactable: j.Redactable,
+	}
+	if j.Stacks != "" {
+		// The text format appends the stacks to the message.
+		entry.Message = strings.TrimSpace(entry.Message + "\n" + j.Stacks)
+	}
+	return nil
+}
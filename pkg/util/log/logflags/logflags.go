@@ -70,6 +70,8 @@ const (
 	LogFileMaxSizeName            = "log-file-max-size"
 	LogFilesCombinedMaxSizeName   = "log-dir-max-size"
 	LogFileVerbosityThresholdName = "log-file-verbosity"
+	LogFormatName                 = "log-format"
+	RedactableLogsName            = "redactable-logs"
 )
 
 // InitFlags creates logging flags which update the given variables. The passed mutex is
@@ -0,0 +1,173 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// When redactable logs are enabled (--redactable-logs), the arguments of a
+// log call are enclosed in redaction markers in the message, unless they are
+// wrapped in log.Safe. The format string itself is considered safe. The
+// markers let a redacted copy of the logs be produced after the fact (see
+// Entry.Redact), for example to share the logs with a third party without
+// sharing the user data in them. The values of the log tags, such as the
+// client address and the user of a SQL session, are enclosed in the markers
+// too, except for the numbers.
+const (
+	startRedactable = "‹"
+	endRedactable   = "›"
+	// RedactedMarker replaces the redactable parts of a message when it is
+	// redacted.
+	RedactedMarker = startRedactable + "×" + endRedactable
+	// escapedMarker replaces the redaction markers which occur in the values
+	// of the arguments, so that they can't be confused with actual markers.
+	escapedMarker = "?"
+	// redactablePrefix precedes the message of redactable entries in the text
+	// format.
+	redactablePrefix = "⋮ "
+)
+
+// the --redactable-logs flag.
+var redactableLogs bool
+
+var markerEscaper = strings.NewReplacer(startRedactable, escapedMarker, endRedactable, escapedMarker)
+
+// redactableArg encloses the formatted value of a log argument in redaction
+// markers.
+type redactableArg struct {
+	v interface{}
+}
+
+// Format implements fmt.Formatter.
+func (a redactableArg) Format(s fmt.State, verb rune) {
+	v := fmt.Sprintf(formatDirective(s, verb), a.v)
+	fmt.Fprint(s, startRedactable, markerEscaper.Replace(v), endRedactable)
+}
+
+// formatDirective reconstructs the formatting directive, including the flags,
+// width and precision, that is being applied to an argument.
+func formatDirective(s fmt.State, verb rune) string {
+	var buf bytes.Buffer
+	buf.WriteByte('%')
+	for _, f := range "+-# 0" {
+		if s.Flag(int(f)) {
+			buf.WriteRune(f)
+		}
+	}
+	if w, ok := s.Width(); ok {
+		buf.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := s.Precision(); ok {
+		buf.WriteByte('.')
+		buf.WriteString(strconv.Itoa(p))
+	}
+	buf.WriteRune(verb)
+	return buf.String()
+}
+
+// makeRedactable returns the arguments with all of the ones that aren't
+// wrapped in log.Safe enclosed in redaction markers.
+func makeRedactable(args []interface{}) []interface{} {
+	res := make([]interface{}, len(args))
+	for i, arg := range args {
+		if st, ok := arg.(SafeType); ok {
+			res[i] = st.V
+		} else {
+			res[i] = redactableArg{v: arg}
+		}
+	}
+	return res
+}
+
+// formatRedactable formats a log message with its arguments enclosed in
+// redaction markers. It is equivalent to fmt.Fprint(buf, args...) or
+// fmt.Fprintf(buf, format, args...) otherwise.
+func formatRedactable(buf *msgBuf, format string, args []interface{}) {
+	if len(format) > 0 {
+		fmt.Fprintf(buf, format, makeRedactable(args)...)
+		return
+	}
+	// Like fmt.Fprint, add spaces between operands when neither is a string.
+	// This has to be decided based on the original arguments as none of the
+	// redactable ones are strings.
+	for i, arg := range makeRedactable(args) {
+		if i > 0 && !isString(args[i-1]) && !isString(args[i]) {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprint(buf, arg)
+	}
+}
+
+func isString(arg interface{}) bool {
+	_, ok := arg.(string)
+	return ok
+}
+
+// Redact removes the redactable parts of the message and tags of the entry.
+// The messages and tags of entries that aren't redactable are removed
+// entirely, since it isn't known which of their parts are safe.
+func (e *Entry) Redact() {
+	if !e.Redactable {
+		if e.Message != "" {
+			e.Message = RedactedMarker
+		}
+		if e.Tags != "" {
+			e.Tags = RedactedMarker
+		}
+		e.Redactable = true
+		return
+	}
+	e.Message = redactMessage(e.Message)
+	e.Tags = redactMessage(e.Tags)
+}
+
+// redactMessage replaces the parts of the message enclosed in redaction
+// markers with a redacted marker.
+func redactMessage(msg string) string {
+	var buf strings.Builder
+	for {
+		i := strings.Index(msg, startRedactable)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(msg[i:], endRedactable)
+		if j < 0 {
+			// An unterminated marker, for example because the message was
+			// truncated. Redact everything after it.
+			buf.WriteString(msg[:i])
+			buf.WriteString(RedactedMarker)
+			return buf.String()
+		}
+		buf.WriteString(msg[:i])
+		buf.WriteString(RedactedMarker)
+		msg = msg[i+j+len(endRedactable):]
+	}
+	buf.WriteString(msg)
+	return buf.String()
+}
+
+// StripMarkers removes the redaction markers from the message of the entry,
+// if it's redactable, leaving the message as it would have been without
+// --redactable-logs.
+func (e *Entry) StripMarkers() {
+	if e.Redactable {
+		e.Message = strings.NewReplacer(startRedactable, "", endRedactable, "").Replace(e.Message)
+		e.Redactable = false
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatRedactable(t *testing.T) {
+	testCases := []struct {
+		format   string
+		args     []interface{}
+		expected string
+	}{
+		{"no args", nil, "no args"},
+		{"%d rows in %s", []interface{}{3, "t"}, "‹3› rows in ‹t›"},
+		{"%5.2f", []interface{}{1.5}, "‹ 1.50›"},
+		{"%s %v", []interface{}{Safe("safe"), Safe(7)}, "safe 7"},
+		{"%s", []interface{}{errors.New("boom")}, "‹boom›"},
+		// Markers in the values are escaped.
+		{"%s", []interface{}{"‹a›"}, "‹?a?›"},
+		// The spacing of Print is preserved.
+		{"", []interface{}{"a", 1, 2, Safe("b")}, "‹a›‹1› ‹2› b"},
+	}
+	for _, c := range testCases {
+		var buf msgBuf
+		formatRedactable(&buf, c.format, c.args)
+		if s := buf.String(); s != c.expected {
+			t.Errorf("%q %v: expected %q, got %q", c.format, c.args, c.expected, s)
+		}
+	}
+}
+
+func TestEntryRedact(t *testing.T) {
+	testCases := []struct {
+		msg, tags                 string
+		redactable                bool
+		expectedMsg, expectedTags string
+	}{
+		{"‹3› rows in ‹t›", "n1", true, "‹×› rows in ‹×›", "n1"},
+		{"nothing to redact", "", true, "nothing to redact", ""},
+		// A marker left open by truncation redacts the rest of the message.
+		{"rows in ‹t", "n1", true, "rows in ‹×›", "n1"},
+		// The values of the tags are redacted like the message.
+		{"‹3› rows", "n1,client=‹127.0.0.1:1234›,user=‹bob›", true,
+			"‹×› rows", "n1,client=‹×›,user=‹×›"},
+		// The message and tags of an entry which isn't redactable are removed
+		// entirely.
+		{"3 rows in t", "n1,user=bob", false, "‹×›", "‹×›"},
+	}
+	for _, c := range testCases {
+		e := Entry{Message: c.msg, Tags: c.tags, Redactable: c.redactable}
+		e.Redact()
+		if e.Message != c.expectedMsg || e.Tags != c.expectedTags || !e.Redactable {
+			t.Errorf("%q [%s]: expected %q [%s], got %+v",
+				c.msg, c.tags, c.expectedMsg, c.expectedTags, e)
+		}
+	}
+
+	e := Entry{Message: "‹3› rows", Redactable: true}
+	e.StripMarkers()
+	if e.Message != "3 rows" || e.Redactable {
+		t.Errorf("expected the markers to be stripped, got %+v", e)
+	}
+}
+
+// Verify that the entries logged with --redactable-logs can be decoded and
+// redacted.
+func TestRedactableLogs(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+	defer func(r bool) { redactableLogs = r }(redactableLogs)
+	redactableLogs = true
+
+	ctx := WithLogTagInt(context.Background(), "n", 1)
+	ctx = WithLogTagStr(ctx, "client", "127.0.0.1:1234")
+	ctx = WithLogTag(ctx, "user", "bob")
+	ctx = WithLogTag(ctx, "s", 2)
+	Infof(ctx, "safe %s unsafe %s", Safe("value"), "secret")
+	if !contains("⋮ [n1,client=‹127.0.0.1:1234›,user=‹bob›,s2] safe value unsafe ‹secret›", t) {
+		t.Fatalf("expected a redactable entry, got %q", contents())
+	}
+
+	var entry Entry
+	if err := NewEntryDecoder(strings.NewReader(contents())).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Redactable {
+		t.Fatalf("expected the decoded entry to be redactable, got %+v", entry)
+	}
+	entry.Redact()
+	if entry.Message != "[n1,client=‹×›,user=‹×›,s2] safe value unsafe ‹×›" {
+		t.Errorf("unexpected redacted message %q", entry.Message)
+	}
+}
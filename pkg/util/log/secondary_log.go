@@ -83,6 +83,14 @@ func NewSecondaryLogger(
 	return l
 }
 
+// SetRotationPolicy sets the maximum size of the log files of the secondary
+// logger and the maximum combined size of its log files. A zero value keeps
+// the corresponding setting of the main logger.
+func (l *SecondaryLogger) SetRotationPolicy(fileMaxSize, combinedMaxSize int64) {
+	atomic.StoreInt64(&l.logger.fileMaxSize, fileMaxSize)
+	atomic.StoreInt64(&l.logger.combinedMaxSize, combinedMaxSize)
+}
+
 // Logf logs an event on a secondary logger.
 func (l *SecondaryLogger) Logf(ctx context.Context, format string, args ...interface{}) {
 	file, line, _ := caller.Lookup(1)
@@ -65,3 +65,34 @@ func TestSecondaryLog(t *testing.T) {
 	}
 
 }
+
+func TestSecondaryLogRotationPolicy(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	l := NewSecondaryLogger(&logging.logDir, "woo", false, false)
+	const fileMaxSize = 2048
+	l.SetRotationPolicy(fileMaxSize, 0 /* combinedMaxSize */)
+	if a, e := l.logger.logFilesCombinedMaxSize(), LogFilesCombinedMaxSize; a != e {
+		t.Errorf("expected the combined size of the main logger %d, got %d", e, a)
+	}
+
+	ctx := context.Background()
+	l.Logf(ctx, "x") // Be sure we have a file.
+	sb := l.logger.file.(*syncBuffer)
+	fname0 := sb.file.Name()
+	l.Logf(ctx, "%s", strings.Repeat("x", fileMaxSize)) // force a rollover
+	l.Logf(ctx, "x")
+	if fname1 := sb.file.Name(); fname0 == fname1 {
+		t.Errorf("the secondary log file was not rotated: %s", fname0)
+	}
+	if sb.nbytes >= fileMaxSize {
+		t.Errorf("file size was not reset: %d", sb.nbytes)
+	}
+
+	// The main logger keeps its own policy.
+	if a, e := logging.logFileMaxSize(), LogFileMaxSize; a != e {
+		t.Errorf("expected the main log file size to be %d, got %d", e, a)
+	}
+}
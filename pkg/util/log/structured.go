@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	otlog "github.com/opentracing/opentracing-go/log"
 )
 
@@ -29,6 +30,9 @@ import (
 type msgBuf struct {
 	bytes.Buffer
 	tagBuf [8]*logTag
+	// redactable is set to enclose the values of the tags in redaction
+	// markers, except for numbers.
+	redactable bool
 }
 
 var _ otlog.Encoder = &msgBuf{}
@@ -42,9 +46,29 @@ func (b *msgBuf) writeKey(key string, hasValue bool) {
 	}
 }
 
+// writeValue writes the value of a tag which isn't known to be a number.
+func (b *msgBuf) writeValue(value string) {
+	if !b.redactable || value == "" || isDigits(value) {
+		b.WriteString(value)
+		return
+	}
+	b.WriteString(startRedactable)
+	b.WriteString(markerEscaper.Replace(value))
+	b.WriteString(endRedactable)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *msgBuf) EmitString(key, value string) {
 	b.writeKey(key, value != "")
-	b.WriteString(value)
+	b.writeValue(value)
 }
 
 func (b *msgBuf) EmitBool(key string, value bool) {
@@ -92,9 +116,9 @@ func (b *msgBuf) EmitObject(key string, value interface{}) {
 	b.writeKey(key, hasValue)
 	if hasValue {
 		if s, ok := value.(fmt.Stringer); ok {
-			b.WriteString(s.String())
+			b.writeValue(s.String())
 		} else {
-			fmt.Fprint(b, value)
+			b.writeValue(fmt.Sprint(value))
 		}
 	}
 }
@@ -109,18 +133,23 @@ func formatTags(ctx context.Context, buf *msgBuf) bool {
 	tags := contextLogTags(ctx, buf.tagBuf[:0])
 	if len(tags) > 0 {
 		buf.WriteByte('[')
-		for i, t := range tags {
-			if i > 0 {
-				buf.WriteByte(',')
-			}
-			t.Field.Marshal(buf)
-		}
+		writeTags(buf, tags)
 		buf.WriteString("] ")
 		return true
 	}
 	return false
 }
 
+// writeTags appends the comma-separated tags to a bytes.Buffer.
+func writeTags(buf *msgBuf, tags []*logTag) {
+	for i, t := range tags {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		t.Field.Marshal(buf)
+	}
+}
+
 // MakeMessage creates a structured log entry.
 func MakeMessage(ctx context.Context, format string, args []interface{}) string {
 	var buf msgBuf
@@ -139,7 +168,24 @@ func MakeMessage(ctx context.Context, format string, args []interface{}) string
 // specified facility of the logger.
 func addStructured(ctx context.Context, s Severity, depth int, format string, args []interface{}) {
 	file, line, _ := caller.Lookup(depth + 1)
-	msg := MakeMessage(ctx, format, args)
+	// The tags are kept separately from the message in the entry, so that
+	// they can be formatted as their own field by the JSON format.
+	redactable := redactableLogs
+	var buf msgBuf
+	buf.redactable = redactable
+	writeTags(&buf, contextLogTags(ctx, buf.tagBuf[:0]))
+	tags := buf.String()
+	buf.Reset()
+	if len(args) == 0 {
+		buf.WriteString(format)
+	} else if redactable {
+		formatRedactable(&buf, format, args)
+	} else if len(format) == 0 {
+		fmt.Fprint(&buf, args...)
+	} else {
+		fmt.Fprintf(&buf, format, args...)
+	}
+	msg := buf.String()
 
 	if s == Severity_FATAL {
 		// We load the ReportingSettings from the a global singleton in this
@@ -148,8 +194,16 @@ func addStructured(ctx context.Context, s Severity, depth int, format string, ar
 			SendCrashReport(ctx, sv, depth+2, format, args)
 		}
 	}
-	// MakeMessage already added the tags when forming msg, we don't want
-	// eventInternal to prepend them again.
-	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, msg)
-	logging.outputLogEntry(s, file, line, msg)
+	// We add the tags to the message for eventInternal ourselves, since they
+	// were formatted already.
+	traceMsg := msg
+	if tags != "" {
+		traceMsg = "[" + tags + "] " + msg
+	}
+	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, traceMsg)
+
+	entry := MakeEntry(s, timeutil.Now().UnixNano(), file, line, msg)
+	entry.Tags = tags
+	entry.Redactable = redactable
+	logging.outputEntry(entry)
 }
@@ -82,6 +82,11 @@ func ScopeWithoutShowLogs(t tShim) *TestLogScope {
 	return &TestLogScope{logDir: tempDir, cleanup: undo}
 }
 
+// GetDirectory retrieves the log directory for this scope.
+func (l *TestLogScope) GetDirectory() string {
+	return l.logDir
+}
+
 // enableLogFileOutput turns on logging using the specified directory.
 // For unittesting only.
 func enableLogFileOutput(dir string, stderrSeverity Severity) (func(), error) {
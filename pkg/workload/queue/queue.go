@@ -23,6 +23,9 @@ import (
 	"strings"
 	"sync/atomic"
 
+	"github.com/cockroachdb/cockroach-go/crdb"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -33,10 +36,25 @@ const (
 	queueSchema = `(ts BIGINT NOT NULL, id BIGINT NOT NULL, PRIMARY KEY(ts, id))`
 )
 
+// The ways in which the workers can consume entries from the queue.
+const (
+	// claimNone deletes whole batches of entries at once.
+	claimNone = ``
+	// claimForUpdate claims entries one at a time with SELECT ... FOR UPDATE.
+	claimForUpdate = `for-update`
+	// claimSkipLocked claims entries one at a time with SELECT ... FOR UPDATE
+	// SKIP LOCKED.
+	claimSkipLocked = `skip-locked`
+	// claimNoWait claims entries one at a time with SELECT ... FOR UPDATE
+	// NOWAIT.
+	claimNoWait = `nowait`
+)
+
 type queue struct {
 	flags     workload.Flags
 	connFlags *workload.ConnFlags
 	batchSize int
+	claim     string
 }
 
 func init() {
@@ -54,6 +72,10 @@ var queueMeta = workload.Meta{
 		g.flags.FlagSet = pflag.NewFlagSet(`queue`, pflag.ContinueOnError)
 		g.connFlags = workload.NewConnFlags(&g.flags)
 		g.flags.IntVar(&g.batchSize, `batch`, 1, `Number of blocks to insert in a single SQL statement`)
+		g.flags.StringVar(&g.claim, `claim`, claimNone,
+			`If set, consume entries one at a time by claiming the first one with SELECT ... FOR UPDATE `+
+				`and then deleting it, as a job queue would. One of for-update, skip-locked or nowait, `+
+				`which determines the behavior of the claim when the first entry is locked.`)
 		return g
 	},
 }
@@ -64,6 +86,20 @@ func (*queue) Meta() workload.Meta { return queueMeta }
 // Flags implements the Flagser interface.
 func (w *queue) Flags() workload.Flags { return w.flags }
 
+// Hooks implements the Hookser interface.
+func (w *queue) Hooks() workload.Hooks {
+	return workload.Hooks{
+		Validate: func() error {
+			switch w.claim {
+			case claimNone, claimForUpdate, claimSkipLocked, claimNoWait:
+				return nil
+			default:
+				return errors.Errorf(`unknown claim mode: %s`, w.claim)
+			}
+		},
+	}
+}
+
 // Tables implements the Generator interface.
 func (w *queue) Tables() []workload.Table {
 	table := workload.Table{
@@ -108,18 +144,39 @@ func (w *queue) Ops(urls []string, reg *workload.HistogramRegistry) (workload.Qu
 		return workload.QueryLoad{}, err
 	}
 
+	// Generate the statements which claim and delete a single entry.
+	var claimStmt, claimDeleteStmt *gosql.Stmt
+	if w.claim != claimNone {
+		claim := `SELECT ts, id FROM queue ORDER BY ts, id LIMIT 1 FOR UPDATE`
+		switch w.claim {
+		case claimSkipLocked:
+			claim += ` SKIP LOCKED`
+		case claimNoWait:
+			claim += ` NOWAIT`
+		}
+		if claimStmt, err = db.Prepare(claim); err != nil {
+			return workload.QueryLoad{}, err
+		}
+		claimDeleteStmt, err = db.Prepare(`DELETE FROM queue WHERE ts = $1 AND id = $2`)
+		if err != nil {
+			return workload.QueryLoad{}, err
+		}
+	}
+
 	seqFunc := makeSequenceFunc()
 
 	ql := workload.QueryLoad{SQLDatabase: sqlDatabase}
 	for i := 0; i < w.connFlags.Concurrency; i++ {
 		op := queueOp{
-			workerID:   i + 1,
-			config:     w,
-			hists:      reg.GetHandle(),
-			db:         db,
-			insertStmt: insertStmt,
-			deleteStmt: deleteStmt,
-			getSeq:     seqFunc,
+			workerID:        i + 1,
+			config:          w,
+			hists:           reg.GetHandle(),
+			db:              db,
+			insertStmt:      insertStmt,
+			deleteStmt:      deleteStmt,
+			claimStmt:       claimStmt,
+			claimDeleteStmt: claimDeleteStmt,
+			getSeq:          seqFunc,
 		}
 		ql.WorkerFns = append(ql.WorkerFns, op.run)
 	}
@@ -136,7 +193,11 @@ type queueOp struct {
 	db         *gosql.DB
 	insertStmt *gosql.Stmt
 	deleteStmt *gosql.Stmt
-	getSeq     func() int
+	// claimStmt and claimDeleteStmt are set if the worker consumes entries
+	// one at a time.
+	claimStmt       *gosql.Stmt
+	claimDeleteStmt *gosql.Stmt
+	getSeq          func() int
 }
 
 func (o *queueOp) run(ctx context.Context) error {
@@ -158,6 +219,16 @@ func (o *queueOp) run(ctx context.Context) error {
 	}
 	o.hists.Get("write").Record(timeutil.Since(startTime))
 
+	if o.claimStmt != nil {
+		// Consume as many entries as were just written.
+		for i := 0; i < o.config.batchSize; i++ {
+			if err := o.claimAndDelete(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Delete batch which was just written.
 	startTime = timeutil.Now()
 	_, err = o.deleteStmt.Exec(end)
@@ -165,6 +236,45 @@ func (o *queueOp) run(ctx context.Context) error {
 	return err
 }
 
+// claimAndDelete claims the first entry of the queue and deletes it, in a
+// transaction. Attempts of the transaction which are retried because of
+// contention are recorded as `retry`, and claims which fail because the first
+// entry is locked (with NOWAIT) are recorded as `locked`.
+func (o *queueOp) claimAndDelete(ctx context.Context) error {
+	startTime := timeutil.Now()
+	attemptStart := startTime
+	attempts := 0
+	err := crdb.ExecuteTx(ctx, o.db, nil /* txopts */, func(tx *gosql.Tx) error {
+		if attempts++; attempts > 1 {
+			o.hists.Get(`retry`).Record(timeutil.Since(attemptStart))
+			attemptStart = timeutil.Now()
+		}
+		var ts, id int64
+		if err := tx.StmtContext(ctx, o.claimStmt).QueryRowContext(ctx).Scan(&ts, &id); err != nil {
+			if err == gosql.ErrNoRows {
+				// Everything was claimed by other workers.
+				return nil
+			}
+			return err
+		}
+		_, err := tx.StmtContext(ctx, o.claimDeleteStmt).ExecContext(ctx, ts, id)
+		return err
+	})
+	if pqErr, ok := errors.Cause(err).(*pq.Error); ok && pqErr.Code == lockNotAvailable {
+		o.hists.Get(`locked`).Record(timeutil.Since(startTime))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	o.hists.Get(`claim`).Record(timeutil.Since(startTime))
+	return nil
+}
+
+// lockNotAvailable is the error code returned by a claim with NOWAIT when the
+// first entry of the queue is locked.
+const lockNotAvailable = "55P03"
+
 func makeSequenceFunc() func() int {
 	i := int64(0)
 	return func() int {